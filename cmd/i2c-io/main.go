@@ -3,6 +3,9 @@
 // that can be found in the LICENSE file.
 
 // i2c-io communicates to an I²C device.
+//
+// Use -record to save the session as a transcript that can be loaded with
+// i2ctest.ReadOps and played back in a unit test.
 package main
 
 import (
@@ -16,6 +19,7 @@ import (
 
 	"github.com/meandrewdev/periph/conn/i2c"
 	"github.com/meandrewdev/periph/conn/i2c/i2creg"
+	"github.com/meandrewdev/periph/conn/i2c/i2ctest"
 	"github.com/meandrewdev/periph/conn/physic"
 )
 
@@ -29,6 +33,7 @@ func mainImpl() error {
 	var hz physic.Frequency
 	flag.Var(&hz, "hz", "I²C bus speed (may require root)")
 	l := flag.Int("l", 1, "length of data to read; ignored if -w is specified")
+	record := flag.String("record", "", "save the session as a transcript to this file")
 	flag.Parse()
 	if !*verbose {
 		log.SetOutput(ioutil.Discard)
@@ -89,7 +94,12 @@ func mainImpl() error {
 			log.Printf("Using pins SCL: %s  SDA: %s", p.SCL(), p.SDA())
 		}
 	}
+	var rec *i2ctest.Record
 	d := i2c.Dev{Bus: bus, Addr: uint16(*addr)}
+	if *record != "" {
+		rec = &i2ctest.Record{Bus: bus}
+		d.Bus = rec
+	}
 	if *write {
 		_, err = d.Write(buf)
 	} else {
@@ -108,9 +118,25 @@ func mainImpl() error {
 		}
 		_, err = fmt.Print("\n")
 	}
+	if err == nil && rec != nil {
+		err = writeTranscript(*record, rec.Ops)
+	}
 	return err
 }
 
+// writeTranscript saves the recorded operations.
+func writeTranscript(path string, ops []i2ctest.IO) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := i2ctest.WriteOps(f, ops); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func main() {
 	if err := mainImpl(); err != nil {
 		fmt.Fprintf(os.Stderr, "i2c-io: %s.\n", err)
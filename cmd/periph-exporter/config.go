@@ -0,0 +1,104 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/meandrewdev/periph/devices/devreg"
+)
+
+// config is the content of the file specified with -config.
+//
+// Example:
+//
+//   {
+//     "interval": "30s",
+//     "thermal": true,
+//     "board": {
+//       "buses": {"main": {"type": "i2c", "name": "1"}},
+//       "devices": [
+//         {"name": "attic", "driver": "bmxx80", "bus": "main", "opts": {"addr": 118}},
+//         {"name": "solar", "driver": "ina219", "bus": "main", "opts": {"addr": 64}}
+//       ]
+//     },
+//     "sensors": {
+//       "attic": {"labels": {"room": "attic"}},
+//       "solar": {"interval": "1s"}
+//     }
+//   }
+type config struct {
+	// Interval is the default sampling interval. It defaults to -interval.
+	Interval duration `json:"interval"`
+	// Thermal exports the thermal zones exposed via sysfs.
+	Thermal bool `json:"thermal"`
+	// Board declares the buses and the devices to open, as described in
+	// package devreg.
+	Board devreg.Config `json:"board"`
+	// Sensors overrides the settings of sensors by name.
+	Sensors map[string]sensorConfig `json:"sensors"`
+}
+
+// sensorConfig is the settings of a single sensor.
+type sensorConfig struct {
+	// Interval is the sampling interval of this sensor.
+	Interval duration `json:"interval"`
+	// Labels are added to all the metrics of this sensor.
+	Labels map[string]string `json:"labels"`
+}
+
+// duration is a time.Duration decoded from a string like "10s".
+type duration time.Duration
+
+func (d *duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	if v <= 0 {
+		return errors.New("interval must be positive, got " + string(b))
+	}
+	*d = duration(v)
+	return nil
+}
+
+func loadConfig(path string) (*config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c := &config{}
+	d := json.NewDecoder(f)
+	d.DisallowUnknownFields()
+	if err := d.Decode(c); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	for name, s := range c.Sensors {
+		for k := range s.Labels {
+			if !isLabelName(k) || k == "sensor" || k == "channel" {
+				return nil, errors.New(path + ": sensor " + strconv.Quote(name) + ": invalid label name " + strconv.Quote(k))
+			}
+		}
+	}
+	return c, nil
+}
+
+// isLabelName returns true if s is a valid OpenMetrics label name.
+func isLabelName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i != 0 && c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
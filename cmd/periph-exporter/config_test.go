@@ -0,0 +1,115 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meandrewdev/periph/devices/devreg"
+)
+
+func TestIsLabelName(t *testing.T) {
+	data := []struct {
+		in       string
+		expected bool
+	}{
+		{"", false},
+		{"room", true},
+		{"_room", true},
+		{"Room_2", true},
+		{"2room", false},
+		{"ro-om", false},
+		{"ro.om", false},
+		{"pièce", false},
+	}
+	for i, line := range data {
+		if actual := isLabelName(line.in); actual != line.expected {
+			t.Fatalf("#%d: isLabelName(%q) = %t, expected %t", i, line.in, actual, line.expected)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	const valid = `{
+  "interval": "30s",
+  "thermal": true,
+  "board": {
+    "buses": {"main": {"type": "i2c", "name": "1"}},
+    "devices": [{"name": "attic", "driver": "bmxx80", "bus": "main", "opts": {"addr": 118}}]
+  },
+  "sensors": {
+    "attic": {"interval": "1s", "labels": {"room": "attic"}}
+  }
+}`
+	expected := &config{
+		Interval: duration(30 * time.Second),
+		Thermal:  true,
+		Board: devreg.Config{
+			Buses:   map[string]devreg.BusConfig{"main": {Type: "i2c", Name: "1"}},
+			Devices: []devreg.DeviceConfig{{Name: "attic", Driver: "bmxx80", Bus: "main", Opts: []byte(`{"addr": 118}`)}},
+		},
+		Sensors: map[string]sensorConfig{
+			"attic": {Interval: duration(time.Second), Labels: map[string]string{"room": "attic"}},
+		},
+	}
+	dir, err := ioutil.TempDir("", "periph-exporter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(p, []byte(valid), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := loadConfig(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Fatalf("%#v != %#v", c, expected)
+	}
+}
+
+func TestLoadConfig_fail(t *testing.T) {
+	data := []struct {
+		in       string
+		expected string
+	}{
+		{`{"interval": "30"}`, "missing unit"},
+		{`{"interval": "-1s"}`, "interval must be positive, got -1s"},
+		{`{"unknown": true}`, `unknown field "unknown"`},
+		{`{"sensors": {"a": {"labels": {"2room": "x"}}}}`, `sensor "a": invalid label name "2room"`},
+		{`{"sensors": {"a": {"labels": {"sensor": "x"}}}}`, `sensor "a": invalid label name "sensor"`},
+		{`{"sensors": {"a": {"labels": {"channel": "x"}}}}`, `sensor "a": invalid label name "channel"`},
+		{`{`, "unexpected EOF"},
+	}
+	dir, err := ioutil.TempDir("", "periph-exporter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "config.json")
+	for i, line := range data {
+		if err := ioutil.WriteFile(p, []byte(line.in), 0600); err != nil {
+			t.Fatal(err)
+		}
+		c, err := loadConfig(p)
+		if c != nil || err == nil {
+			t.Fatalf("#%d: expected failure", i)
+		}
+		if s := err.Error(); !strings.HasPrefix(s, p+": ") || !strings.Contains(s, line.expected) {
+			t.Fatalf("#%d: unexpected error %q", i, s)
+		}
+	}
+	if _, err := loadConfig(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("expected failure")
+	}
+}
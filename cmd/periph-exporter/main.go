@@ -0,0 +1,254 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// periph-exporter serves the measurements of sensors as Prometheus metrics in
+// the OpenMetrics text format on /metrics.
+//
+// Sensors are specified with flags, or with a JSON configuration file for
+// more complex boards, per-sensor labels and sampling intervals. Run with
+// -help for the flags.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/onewire/onewirereg"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+	"github.com/meandrewdev/periph/conn/sensor/sensorreg"
+	"github.com/meandrewdev/periph/devices/devreg"
+	"github.com/meandrewdev/periph/devices/ds18b20"
+	"github.com/meandrewdev/periph/host/sysfs"
+
+	// Drivers usable in the configuration file.
+	_ "github.com/meandrewdev/periph/devices/bmxx80"
+	_ "github.com/meandrewdev/periph/experimental/devices/ina219"
+	_ "github.com/meandrewdev/periph/experimental/devices/mcp9808"
+)
+
+// addrList is a comma separated list of I²C addresses.
+type addrList []uint16
+
+func (a *addrList) String() string {
+	s := make([]string, len(*a))
+	for i, v := range *a {
+		s[i] = "0x" + strconv.FormatUint(uint64(v), 16)
+	}
+	return strings.Join(s, ",")
+}
+
+func (a *addrList) Set(s string) error {
+	for _, p := range strings.Split(s, ",") {
+		v, err := strconv.ParseUint(p, 0, 16)
+		if err != nil {
+			return err
+		}
+		*a = append(*a, uint16(v))
+	}
+	return nil
+}
+
+// addI2C adds the devices specified with flags to the board description.
+func addI2C(c *devreg.Config, bus string, driver string, addrs addrList) {
+	if len(addrs) == 0 {
+		return
+	}
+	if c.Buses == nil {
+		c.Buses = map[string]devreg.BusConfig{}
+	}
+	c.Buses["-i2c"] = devreg.BusConfig{Type: "i2c", Name: bus}
+	for _, a := range addrs {
+		opts, _ := json.Marshal(map[string]uint16{"addr": a})
+		c.Devices = append(c.Devices, devreg.DeviceConfig{
+			Name:   fmt.Sprintf("%s-0x%x", driver, a),
+			Driver: driver,
+			Bus:    "-i2c",
+			Opts:   opts,
+		})
+	}
+}
+
+// asSensor returns the device as a sensor.Sensor, if possible.
+func asSensor(d interface{}) sensor.Sensor {
+	switch s := d.(type) {
+	case sensor.Sensor:
+		return s
+	case physic.SenseEnv:
+		return sensor.FromEnv(s)
+	default:
+		return nil
+	}
+}
+
+func mainImpl() error {
+	addr := flag.String("http", ":9700", "IP and port to serve /metrics on")
+	cfgPath := flag.String("config", "", "JSON configuration file; see config.go for the format")
+	interval := flag.Duration("interval", 10*time.Second, "default sampling interval")
+	thermal := flag.Bool("thermal", false, "export the thermal zones exposed via sysfs")
+	i2cName := flag.String("i2c", "", "I²C bus to use for -bmxx80, -ina219 and -mcp9808")
+	var bmx, ina, mcp addrList
+	flag.Var(&bmx, "bmxx80", "comma separated I²C addresses of BME280, BMP280 or BMP180 sensors, e.g. 0x76")
+	flag.Var(&ina, "ina219", "comma separated I²C addresses of INA219 sensors, e.g. 0x40")
+	flag.Var(&mcp, "mcp9808", "comma separated I²C addresses of MCP9808 sensors, e.g. 0x18")
+	oneWireName := flag.String("onewire", "", "1-wire bus to use for -ds18b20")
+	ds := flag.Bool("ds18b20", false, "export all the DS18B20 sensors found on the 1-wire bus")
+	verbose := flag.Bool("v", false, "verbose mode")
+	flag.Parse()
+	if !*verbose {
+		log.SetOutput(ioutil.Discard)
+	}
+	log.SetFlags(log.Lmicroseconds)
+	if flag.NArg() != 0 {
+		return errors.New("unexpected argument, try -help")
+	}
+	if *interval <= 0 {
+		return errors.New("-interval must be positive")
+	}
+
+	cfg := &config{}
+	if *cfgPath != "" {
+		var err error
+		if cfg, err = loadConfig(*cfgPath); err != nil {
+			return err
+		}
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = duration(*interval)
+	}
+	addI2C(&cfg.Board, *i2cName, "bmxx80", bmx)
+	addI2C(&cfg.Board, *i2cName, "ina219", ina)
+	addI2C(&cfg.Board, *i2cName, "mcp9808", mcp)
+
+	if _, err := hostInit(); err != nil {
+		return err
+	}
+
+	board, err := devreg.Open(&cfg.Board)
+	if err != nil {
+		return err
+	}
+	defer board.Close()
+	names := make([]string, 0, len(board.Devices))
+	for n := range board.Devices {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		s := asSensor(board.Devices[n])
+		if s == nil {
+			if _, ok := cfg.Sensors[n]; ok {
+				return fmt.Errorf("device %q is not a sensor", n)
+			}
+			log.Printf("skipping %s: not a sensor", n)
+			continue
+		}
+		if err := sensorreg.Register(n, s); err != nil {
+			return err
+		}
+	}
+
+	if *ds {
+		bus, err := onewirereg.Open(*oneWireName)
+		if err != nil {
+			return err
+		}
+		defer bus.Close()
+		addrs, err := bus.Search(false)
+		if err != nil {
+			return err
+		}
+		for _, a := range addrs {
+			// The family code of the DS18B20 is 0x28.
+			if a&0xff != 0x28 {
+				continue
+			}
+			d, err := ds18b20.New(bus, a, 10)
+			if err != nil {
+				return err
+			}
+			if err := sensorreg.RegisterEnv(fmt.Sprintf("ds18b20-%016x", uint64(a)), d); err != nil {
+				return err
+			}
+		}
+	}
+
+	if *thermal || cfg.Thermal {
+		for _, t := range sysfs.ThermalSensors {
+			if err := sensorreg.RegisterEnv(t.String(), t); err != nil {
+				return err
+			}
+		}
+	}
+
+	e := &exporter{}
+	for _, r := range sensorreg.All() {
+		s := cfg.Sensors[r.Name]
+		i := time.Duration(cfg.Interval)
+		if s.Interval != 0 {
+			i = time.Duration(s.Interval)
+		}
+		e.targets = append(e.targets, newTarget(r.Name, r.Sensor, i, s.Labels))
+	}
+	for n := range cfg.Sensors {
+		if sensorreg.ByName(n) == nil {
+			return fmt.Errorf("unknown sensor %q in %s", n, *cfgPath)
+		}
+	}
+	if len(e.targets) == 0 {
+		return errors.New("no sensor to export, try -help")
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, t := range e.targets {
+		wg.Add(1)
+		go func(t *target) {
+			defer wg.Done()
+			t.run(done)
+		}(t)
+	}
+	// The devices are halted by board.Close().
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	srv := &http.Server{Addr: *addr, Handler: mux}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+	log.Printf("Serving %d sensors on %s", len(e.targets), *addr)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-errc:
+		return err
+	case <-c:
+		return srv.Close()
+	}
+}
+
+func main() {
+	if err := mainImpl(); err != nil {
+		fmt.Fprintf(os.Stderr, "periph-exporter: %s.\n", err)
+		os.Exit(1)
+	}
+}
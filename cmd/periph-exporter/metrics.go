@@ -0,0 +1,289 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+// metric describes how a quantity is exported.
+type metric struct {
+	// name is the metric family name, including the unit suffix.
+	name string
+	// unit is the OpenMetrics unit, if any.
+	unit string
+	help string
+	// scale and offset convert the value returned by sensor.Float() into unit.
+	scale  float64
+	offset float64
+}
+
+// metrics maps each known quantity to its metric, in Prometheus base units.
+var metrics = map[sensor.Quantity]metric{
+	sensor.Temperature:         {"periph_temperature_celsius", "celsius", "Temperature.", 1, -273.15},
+	sensor.Pressure:            {"periph_pressure_pascals", "pascals", "Pressure.", 1, 0},
+	sensor.Humidity:            {"periph_humidity_ratio", "ratio", "Relative humidity.", 0.01, 0},
+	sensor.Voltage:             {"periph_voltage_volts", "volts", "Electric potential.", 1, 0},
+	sensor.Current:             {"periph_current_amperes", "amperes", "Electric current.", 1, 0},
+	sensor.Power:               {"periph_power_watts", "watts", "Power.", 1, 0},
+	sensor.LuminousFlux:        {"periph_luminous_flux_lumens", "lumens", "Luminous flux.", 1, 0},
+	sensor.MagneticFluxDensity: {"periph_magnetic_flux_density_teslas", "teslas", "Magnetic flux density.", 1, 0},
+	sensor.SpectralIrradiance:  {"periph_spectral_irradiance_watts_per_square_meter", "watts_per_square_meter", "Spectral irradiance, per channel wavelength.", 0.01, 0},
+	sensor.Illuminance:         {"periph_illuminance_lux", "lux", "Illuminance.", 1, 0},
+	sensor.CO2:                 {"periph_co2_ratio", "ratio", "Equivalent CO2 concentration.", 1, 0},
+	sensor.TVOC:                {"periph_tvoc_ratio", "ratio", "Total volatile organic compounds concentration.", 1, 0},
+	sensor.SoundLevel:          {"periph_sound_level_decibels", "decibels", "Sound pressure level.", 1, 0},
+	sensor.PM1:                 {"periph_pm1_grams_per_cubic_meter", "grams_per_cubic_meter", "Particulate matter smaller than 1µm.", 1000, 0},
+	sensor.PM2_5:               {"periph_pm2_5_grams_per_cubic_meter", "grams_per_cubic_meter", "Particulate matter smaller than 2.5µm.", 1000, 0},
+	sensor.PM10:                {"periph_pm10_grams_per_cubic_meter", "grams_per_cubic_meter", "Particulate matter smaller than 10µm.", 1000, 0},
+	sensor.Acceleration:        {"periph_acceleration_meters_per_second_squared", "meters_per_second_squared", "Acceleration.", 1, 0},
+	sensor.AngularVelocity:     {"periph_angular_velocity_radians_per_second", "radians_per_second", "Angular velocity.", 1, 0},
+	sensor.Raw:                 {"periph_raw", "", "Raw reading, in a sensor specific unit.", 1, 0},
+}
+
+// metricFor returns the metric to use for q, whose value is v.
+//
+// Unknown quantities, like the ones a driver adds to physic.EnvExtra, are
+// named after q with the unit suffix derived from the type of v.
+func metricFor(q sensor.Quantity, v sensor.Value) metric {
+	if m, ok := metrics[q]; ok {
+		return m
+	}
+	b := []byte("periph_" + string(q))
+	for i, c := range b {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			b[i] = '_'
+		}
+	}
+	m := unitOf(v)
+	m.name = string(b)
+	if m.unit != "" && !strings.HasSuffix(m.name, "_"+m.unit) {
+		m.name += "_" + m.unit
+	}
+	m.help = string(q) + "."
+	return m
+}
+
+// unitOf returns the unit, scale and offset to convert the value returned by
+// sensor.Float(v) into Prometheus base units.
+//
+// Values without a known unit, like sensor.Number, are exported as is.
+func unitOf(v sensor.Value) metric {
+	switch v.(type) {
+	case physic.Acceleration:
+		return metric{unit: "meters_per_second_squared", scale: 1}
+	case physic.Angle:
+		return metric{unit: "radians", scale: 1}
+	case physic.AngularVelocity:
+		return metric{unit: "radians_per_second", scale: 1}
+	case physic.Concentration:
+		return metric{unit: "ratio", scale: 1}
+	case physic.Distance:
+		return metric{unit: "meters", scale: 1}
+	case physic.ElectricCurrent:
+		return metric{unit: "amperes", scale: 1}
+	case physic.ElectricPotential:
+		return metric{unit: "volts", scale: 1}
+	case physic.ElectricResistance:
+		return metric{unit: "ohms", scale: 1}
+	case physic.ElectricalCapacitance:
+		return metric{unit: "farads", scale: 1}
+	case physic.Energy:
+		return metric{unit: "joules", scale: 1}
+	case physic.Force:
+		return metric{unit: "newtons", scale: 1}
+	case physic.Frequency:
+		return metric{unit: "hertz", scale: 1}
+	case physic.Illuminance:
+		return metric{unit: "lux", scale: 1}
+	case physic.Irradiance:
+		return metric{unit: "watts_per_square_meter", scale: 1}
+	case physic.LuminousFlux:
+		return metric{unit: "lumens", scale: 1}
+	case physic.LuminousIntensity:
+		return metric{unit: "candelas", scale: 1}
+	case physic.MagneticFluxDensity:
+		return metric{unit: "teslas", scale: 1}
+	case physic.Mass:
+		return metric{unit: "kilograms", scale: 1}
+	case physic.MassConcentration:
+		return metric{unit: "grams_per_cubic_meter", scale: 1000}
+	case physic.Power:
+		return metric{unit: "watts", scale: 1}
+	case physic.Pressure:
+		return metric{unit: "pascals", scale: 1}
+	case physic.RelativeHumidity:
+		return metric{unit: "ratio", scale: 0.01}
+	case physic.SoundPressureLevel:
+		return metric{unit: "decibels", scale: 1}
+	case physic.Speed:
+		return metric{unit: "meters_per_second", scale: 1}
+	case physic.Temperature:
+		return metric{unit: "celsius", scale: 1, offset: -273.15}
+	default:
+		return metric{scale: 1}
+	}
+}
+
+// target is a sensor sampled periodically.
+type target struct {
+	name     string
+	s        sensor.Sensor
+	interval time.Duration
+	// labels is the preformatted labels of all the metrics of this sensor.
+	labels string
+
+	mu       sync.Mutex
+	last     []sensor.Measurement
+	lastRead time.Time
+	reads    uint64
+	errors   uint64
+}
+
+func newTarget(name string, s sensor.Sensor, interval time.Duration, labels map[string]string) *target {
+	l := "sensor=\"" + escape(name) + "\""
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		l += "," + k + "=\"" + escape(labels[k]) + "\""
+	}
+	return &target{name: name, s: s, interval: interval, labels: l}
+}
+
+// run samples the sensor until done is closed.
+func (t *target) run(done <-chan struct{}) {
+	t.sample()
+	tick := time.NewTicker(t.interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-tick.C:
+			t.sample()
+		}
+	}
+}
+
+func (t *target) sample() {
+	m, err := t.s.Measure()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reads++
+	t.lastRead = time.Now()
+	if err != nil {
+		// Do not export stale values.
+		t.errors++
+		t.last = nil
+		log.Printf("%s: %v", t.name, err)
+		return
+	}
+	t.last = m
+}
+
+// family is a metric family and its samples, formatted.
+type family struct {
+	name    string
+	typ     string
+	unit    string
+	help    string
+	samples []string
+}
+
+// exporter serves the metrics of the targets.
+type exporter struct {
+	targets []*target
+}
+
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	if err := e.write(w); err != nil {
+		log.Printf("%s: %v", r.RemoteAddr, err)
+	}
+}
+
+// write writes all the metrics in the OpenMetrics text format.
+func (e *exporter) write(w io.Writer) error {
+	families := map[string]*family{}
+	add := func(name, typ, unit, help, sample string) {
+		f := families[name]
+		if f == nil {
+			f = &family{name: name, typ: typ, unit: unit, help: help}
+			families[name] = f
+		}
+		f.samples = append(f.samples, sample)
+	}
+	for _, t := range e.targets {
+		t.mu.Lock()
+		up := "0"
+		if t.last != nil {
+			up = "1"
+		}
+		add("periph_sensor_up", "gauge", "", "Whether the last read of the sensor succeeded.", "periph_sensor_up{"+t.labels+"} "+up)
+		add("periph_sensor_reads", "counter", "", "Reads of the sensor.", "periph_sensor_reads_total{"+t.labels+"} "+strconv.FormatUint(t.reads, 10))
+		add("periph_sensor_read_errors", "counter", "", "Failed reads of the sensor.", "periph_sensor_read_errors_total{"+t.labels+"} "+strconv.FormatUint(t.errors, 10))
+		if !t.lastRead.IsZero() {
+			ts := float64(t.lastRead.UnixNano()) / float64(time.Second)
+			add("periph_sensor_last_read_timestamp_seconds", "gauge", "seconds", "Time of the last read of the sensor.", "periph_sensor_last_read_timestamp_seconds{"+t.labels+"} "+formatFloat(ts))
+		}
+		for _, m := range t.last {
+			v, ok := sensor.Float(m.Value)
+			if !ok {
+				continue
+			}
+			d := metricFor(m.Quantity, m.Value)
+			l := t.labels
+			if m.Channel != "" {
+				l += ",channel=\"" + escape(m.Channel) + "\""
+			}
+			add(d.name, "gauge", d.unit, d.help, d.name+"{"+l+"} "+formatFloat(v*d.scale+d.offset))
+		}
+		t.mu.Unlock()
+	}
+
+	names := make([]string, 0, len(families))
+	for n := range families {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var b bytes.Buffer
+	for _, n := range names {
+		f := families[n]
+		b.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+		if f.unit != "" {
+			b.WriteString("# UNIT " + f.name + " " + f.unit + "\n")
+		}
+		b.WriteString("# HELP " + f.name + " " + f.help + "\n")
+		for _, s := range f.samples {
+			b.WriteString(s + "\n")
+		}
+	}
+	b.WriteString("# EOF\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escape escapes a label value.
+func escape(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(s)
+}
@@ -0,0 +1,143 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+func TestEscape(t *testing.T) {
+	data := []struct {
+		in       string
+		expected string
+	}{
+		{"", ""},
+		{"attic", "attic"},
+		{`a"b`, `a\"b`},
+		{`a\b`, `a\\b`},
+		{"a\nb", `a\nb`},
+		{"\\\"\n", `\\\"\n`},
+	}
+	for i, line := range data {
+		if actual := escape(line.in); actual != line.expected {
+			t.Fatalf("#%d: escape(%q) = %q, expected %q", i, line.in, actual, line.expected)
+		}
+	}
+}
+
+func TestMetricFor(t *testing.T) {
+	data := []struct {
+		q        sensor.Quantity
+		v        sensor.Value
+		expected metric
+	}{
+		{sensor.Temperature, physic.ZeroCelsius, metrics[sensor.Temperature]},
+		{sensor.Raw, sensor.Number(1), metrics[sensor.Raw]},
+		{"irradiance_450nm", physic.WattPerSquareMetre, metric{"periph_irradiance_450nm_watts_per_square_meter", "watts_per_square_meter", "irradiance_450nm.", 1, 0}},
+		{"dew_point", physic.ZeroCelsius, metric{"periph_dew_point_celsius", "celsius", "dew_point.", 1, -273.15}},
+		{"soil", physic.PercentRH, metric{"periph_soil_ratio", "ratio", "soil.", 0.01, 0}},
+		{"wind_speed_meters_per_second", physic.MetrePerSecond, metric{"periph_wind_speed_meters_per_second", "meters_per_second", "wind_speed_meters_per_second.", 1, 0}},
+		{"pm0.3", physic.MicroGramPerCubicMetre, metric{"periph_pm0_3_grams_per_cubic_meter", "grams_per_cubic_meter", "pm0.3.", 1000, 0}},
+		{"count-1", sensor.Number(1), metric{"periph_count_1", "", "count-1.", 1, 0}},
+	}
+	for i, line := range data {
+		if actual := metricFor(line.q, line.v); actual != line.expected {
+			t.Fatalf("#%d: metricFor(%q) = %#v, expected %#v", i, line.q, actual, line.expected)
+		}
+	}
+}
+
+func TestExporter_write(t *testing.T) {
+	attic := newTarget("attic", &fakeSensor{m: []sensor.Measurement{
+		{Quantity: sensor.Temperature, Value: physic.ZeroCelsius + 20*physic.Kelvin},
+		{Quantity: sensor.Humidity, Value: 45 * physic.PercentRH},
+		{Quantity: sensor.Voltage, Channel: "bus", Value: 5 * physic.Volt},
+		{Quantity: "irradiance_450nm", Value: physic.WattPerSquareMetre / 2},
+		{Quantity: "unsupported", Value: fakeValue{}},
+	}}, time.Second, map[string]string{"room": "at\"tic", "floor": "2"})
+	solar := newTarget("so\nlar", &fakeSensor{err: errors.New("fail")}, time.Second, nil)
+	idle := newTarget("idle", &fakeSensor{}, time.Second, nil)
+	attic.sample()
+	solar.sample()
+	attic.lastRead = time.Unix(1500000000, 500000000)
+	solar.lastRead = time.Unix(1500000001, 0)
+	e := exporter{targets: []*target{attic, solar, idle}}
+	var b bytes.Buffer
+	if err := e.write(&b); err != nil {
+		t.Fatal(err)
+	}
+	const expected = `# TYPE periph_humidity_ratio gauge
+# UNIT periph_humidity_ratio ratio
+# HELP periph_humidity_ratio Relative humidity.
+periph_humidity_ratio{sensor="attic",floor="2",room="at\"tic"} 0.45
+# TYPE periph_irradiance_450nm_watts_per_square_meter gauge
+# UNIT periph_irradiance_450nm_watts_per_square_meter watts_per_square_meter
+# HELP periph_irradiance_450nm_watts_per_square_meter irradiance_450nm.
+periph_irradiance_450nm_watts_per_square_meter{sensor="attic",floor="2",room="at\"tic"} 0.5
+# TYPE periph_sensor_last_read_timestamp_seconds gauge
+# UNIT periph_sensor_last_read_timestamp_seconds seconds
+# HELP periph_sensor_last_read_timestamp_seconds Time of the last read of the sensor.
+periph_sensor_last_read_timestamp_seconds{sensor="attic",floor="2",room="at\"tic"} 1.5000000005e+09
+periph_sensor_last_read_timestamp_seconds{sensor="so\nlar"} 1.500000001e+09
+# TYPE periph_sensor_read_errors counter
+# HELP periph_sensor_read_errors Failed reads of the sensor.
+periph_sensor_read_errors_total{sensor="attic",floor="2",room="at\"tic"} 0
+periph_sensor_read_errors_total{sensor="so\nlar"} 1
+periph_sensor_read_errors_total{sensor="idle"} 0
+# TYPE periph_sensor_reads counter
+# HELP periph_sensor_reads Reads of the sensor.
+periph_sensor_reads_total{sensor="attic",floor="2",room="at\"tic"} 1
+periph_sensor_reads_total{sensor="so\nlar"} 1
+periph_sensor_reads_total{sensor="idle"} 0
+# TYPE periph_sensor_up gauge
+# HELP periph_sensor_up Whether the last read of the sensor succeeded.
+periph_sensor_up{sensor="attic",floor="2",room="at\"tic"} 1
+periph_sensor_up{sensor="so\nlar"} 0
+periph_sensor_up{sensor="idle"} 0
+# TYPE periph_temperature_celsius gauge
+# UNIT periph_temperature_celsius celsius
+# HELP periph_temperature_celsius Temperature.
+periph_temperature_celsius{sensor="attic",floor="2",room="at\"tic"} 20
+# TYPE periph_voltage_volts gauge
+# UNIT periph_voltage_volts volts
+# HELP periph_voltage_volts Electric potential.
+periph_voltage_volts{sensor="attic",floor="2",room="at\"tic",channel="bus"} 5
+# EOF
+`
+	if actual := b.String(); actual != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+//
+
+type fakeSensor struct {
+	m   []sensor.Measurement
+	err error
+}
+
+func (f *fakeSensor) String() string {
+	return "fake"
+}
+
+func (f *fakeSensor) Halt() error {
+	return nil
+}
+
+func (f *fakeSensor) Measure() ([]sensor.Measurement, error) {
+	return f.m, f.err
+}
+
+type fakeValue struct{}
+
+func (fakeValue) String() string {
+	return "fake"
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// rpi-hat reads, dumps and writes the ID EEPROM of a Raspberry Pi HAT.
+//
+// The ID EEPROM is on the ID_SD/ID_SC pins, usually exposed as I²C bus 0
+// once the i2c-0 overlay is loaded.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/meandrewdev/periph/conn/i2c/i2creg"
+	"github.com/meandrewdev/periph/devices/at24"
+	"github.com/meandrewdev/periph/host/rpi"
+	"github.com/meandrewdev/periph/host/rpi/hateeprom"
+)
+
+func printEEPROM(e *hateeprom.EEPROM) {
+	fmt.Printf("Vendor:      %s\n", e.Vendor.Vendor)
+	fmt.Printf("Product:     %s\n", e.Vendor.Product)
+	fmt.Printf("UUID:        %s\n", e.Vendor.UUID)
+	fmt.Printf("Product ID:  0x%04x\n", e.Vendor.ProductID)
+	fmt.Printf("Product ver: 0x%04x\n", e.Vendor.ProductVer)
+	for i, p := range e.GPIO.Pins {
+		if p.Used {
+			fmt.Printf("GPIO%-2d       func %d, pull %d\n", i, p.Func, p.Pull)
+		}
+	}
+	if len(e.DTB) != 0 {
+		fmt.Printf("DTB:         %d bytes\n", len(e.DTB))
+	}
+	for i, c := range e.Custom {
+		fmt.Printf("Custom #%d:   %d bytes\n", i, len(c))
+	}
+}
+
+func mainImpl() error {
+	busName := flag.String("b", "0", "I²C bus to use")
+	addr := flag.Int("a", int(at24.DefaultOpts.Addr), "I²C address of the EEPROM")
+	fw := flag.Bool("fw", false, "print the HAT detected by the firmware at boot instead of reading the EEPROM")
+	dump := flag.String("dump", "", "save the EEPROM content to this file")
+	write := flag.String("write", "", "write this file, as generated by -dump or eepmake, to the EEPROM")
+	verbose := flag.Bool("v", false, "verbose mode")
+	flag.Parse()
+	if !*verbose {
+		log.SetOutput(ioutil.Discard)
+	}
+	log.SetFlags(log.Lmicroseconds)
+	if flag.NArg() != 0 {
+		return errors.New("unexpected argument, try -help")
+	}
+
+	if *fw {
+		h := rpi.DetectedHAT()
+		if h == nil {
+			return errors.New("no HAT detected by the firmware")
+		}
+		fmt.Printf("Vendor:      %s\n", h.Vendor)
+		fmt.Printf("Product:     %s\n", h.Product)
+		fmt.Printf("UUID:        %s\n", h.UUID)
+		fmt.Printf("Product ID:  0x%04x\n", h.ProductID)
+		fmt.Printf("Product ver: 0x%04x\n", h.ProductVer)
+		return nil
+	}
+
+	if _, err := hostInit(); err != nil {
+		return err
+	}
+	bus, err := i2creg.Open(*busName)
+	if err != nil {
+		return err
+	}
+	defer bus.Close()
+	o := at24.DefaultOpts
+	o.Addr = uint16(*addr)
+	d, err := at24.New(bus, &o)
+	if err != nil {
+		return err
+	}
+
+	if *write != "" {
+		b, err := ioutil.ReadFile(*write)
+		if err != nil {
+			return err
+		}
+		// Refuse to write garbage.
+		if err := (&hateeprom.EEPROM{}).UnmarshalBinary(b); err != nil {
+			return err
+		}
+		log.Printf("Writing %d bytes", len(b))
+		if _, err := d.WriteAt(b, 0); err != nil {
+			return err
+		}
+	}
+
+	b, err := hateeprom.ReadRaw(d)
+	if err != nil {
+		return err
+	}
+	e := &hateeprom.EEPROM{}
+	if err := e.UnmarshalBinary(b); err != nil {
+		return err
+	}
+	printEEPROM(e)
+	if *dump != "" {
+		// Save the bytes as read, not a re-encoding of the parsed content.
+		return ioutil.WriteFile(*dump, b, 0644)
+	}
+	return nil
+}
+
+func main() {
+	if err := mainImpl(); err != nil {
+		fmt.Fprintf(os.Stderr, "rpi-hat: %s.\n", err)
+		os.Exit(1)
+	}
+}
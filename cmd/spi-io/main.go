@@ -11,6 +11,9 @@
 //
 // For "read only" operation, writes zeros.
 // For "write only" operation, ignore stdout.
+//
+// Use -record to save the session as a transcript that can be loaded with
+// spitest.ReadOps and played back in a unit test.
 package main
 
 import (
@@ -22,9 +25,11 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/meandrewdev/periph/conn/conntest"
 	"github.com/meandrewdev/periph/conn/physic"
 	"github.com/meandrewdev/periph/conn/spi"
 	"github.com/meandrewdev/periph/conn/spi/spireg"
+	"github.com/meandrewdev/periph/conn/spi/spitest"
 )
 
 // runTx does the I/O.
@@ -96,6 +101,7 @@ func mainImpl() error {
 	lsbfirst := flag.Bool("lsb", false, "lsb first (default is msb)")
 	mode := flag.Int("mode", 0, "CLK and data polarity, between 0 and 3")
 	bits := flag.Int("bits", 8, "bits per word")
+	record := flag.String("record", "", "save the session as a transcript to this file")
 
 	verbose := flag.Bool("v", false, "verbose mode")
 	flag.Parse()
@@ -127,6 +133,11 @@ func mainImpl() error {
 	if err != nil {
 		return err
 	}
+	var rec *spitest.Record
+	if *record != "" {
+		rec = &spitest.Record{Port: s}
+		s = rec
+	}
 	defer s.Close()
 	c, err := s.Connect(hz, m, *bits)
 	if err != nil {
@@ -137,7 +148,26 @@ func mainImpl() error {
 			log.Printf("Using pins CLK: %s  MOSI: %s  MISO:  %s", p.CLK(), p.MOSI(), p.MISO())
 		}
 	}
-	return runTx(c, flag.Args())
+	if err := runTx(c, flag.Args()); err != nil {
+		return err
+	}
+	if rec != nil {
+		return writeTranscript(*record, rec.Ops)
+	}
+	return nil
+}
+
+// writeTranscript saves the recorded operations.
+func writeTranscript(path string, ops []conntest.IO) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := spitest.WriteOps(f, ops); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 func main() {
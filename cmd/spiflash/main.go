@@ -0,0 +1,139 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// spiflash dumps, erases, writes and verifies a SPI NOR flash.
+//
+// The operations are done in the order: erase, write, verify, read.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/meandrewdev/periph/conn/spi/spireg"
+	"github.com/meandrewdev/periph/devices/spiflash"
+)
+
+func mainImpl() error {
+	verbose := flag.Bool("v", false, "verbose mode")
+	spiID := flag.String("spi", "", "SPI port to use")
+	hz := spiflash.DefaultOpts.Speed
+	flag.Var(&hz, "hz", "SPI port speed")
+	off := flag.Int64("off", 0, "offset in the flash")
+	length := flag.Int64("n", 0, "number of bytes to erase or read; defaults to the file size or to the end of the flash")
+	erase := flag.Bool("erase", false, "erase the area; it is rounded to the erase block size")
+	write := flag.String("write", "", "file to write into the flash")
+	verify := flag.String("verify", "", "file to compare the flash content to")
+	read := flag.String("read", "", "file to dump the flash content into; - for stdout")
+	flag.Parse()
+	if !*verbose {
+		log.SetOutput(ioutil.Discard)
+	}
+	log.SetFlags(log.Lmicroseconds)
+	if flag.NArg() != 0 {
+		return errors.New("unexpected argument, try -help")
+	}
+	if *off < 0 || *length < 0 {
+		return errors.New("-off and -n must be positive")
+	}
+	if _, err := hostInit(); err != nil {
+		return err
+	}
+
+	p, err := spireg.Open(*spiID)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	o := spiflash.DefaultOpts
+	o.Speed = hz
+	d, err := spiflash.New(p, &o)
+	if err != nil {
+		return err
+	}
+	params := d.Params()
+	if *read != "-" {
+		fmt.Printf("%s: JEDEC ID %s, %d bytes, %d bytes pages\n", d, d.ID(), params.Size, params.PageSize)
+	}
+	for _, e := range params.Erases {
+		log.Printf("Erase 0x%02X: %d bytes", e.Opcode, e.Size)
+	}
+
+	var data []byte
+	if *write != "" {
+		if data, err = ioutil.ReadFile(*write); err != nil {
+			return err
+		}
+	}
+	n := *length
+	if n == 0 {
+		if data != nil {
+			n = int64(len(data))
+		} else {
+			n = d.Size() - *off
+		}
+	}
+	if *off >= d.Size() || n > d.Size()-*off {
+		return fmt.Errorf("%d bytes at 0x%X do not fit in the %d bytes of the device", n, *off, d.Size())
+	}
+
+	if *erase {
+		// Round to the smallest erase block.
+		if len(params.Erases) == 0 {
+			return errors.New("device doesn't support erase")
+		}
+		b := int64(params.Erases[0].Size)
+		start := *off &^ (b - 1)
+		end := (*off + n + b - 1) &^ (b - 1)
+		log.Printf("Erasing [0x%X, 0x%X)", start, end)
+		if err := d.Erase(start, end-start); err != nil {
+			return err
+		}
+	}
+	if data != nil {
+		log.Printf("Writing %d bytes at 0x%X", len(data), *off)
+		if _, err := d.WriteAt(data, *off); err != nil {
+			return err
+		}
+	}
+	if *verify != "" {
+		expected, err := ioutil.ReadFile(*verify)
+		if err != nil {
+			return err
+		}
+		got := make([]byte, len(expected))
+		if _, err := d.ReadAt(got, *off); err != nil {
+			return err
+		}
+		for i := range got {
+			if got[i] != expected[i] {
+				return fmt.Errorf("mismatch at 0x%X: got 0x%02X, expected 0x%02X", *off+int64(i), got[i], expected[i])
+			}
+		}
+		log.Printf("Verified %d bytes at 0x%X", len(got), *off)
+	}
+	if *read != "" {
+		got := make([]byte, n)
+		if _, err := d.ReadAt(got, *off); err != nil {
+			return err
+		}
+		if *read == "-" {
+			_, err = os.Stdout.Write(got)
+			return err
+		}
+		return ioutil.WriteFile(*read, got, 0644)
+	}
+	return nil
+}
+
+func main() {
+	if err := mainImpl(); err != nil {
+		fmt.Fprintf(os.Stderr, "spiflash: %s.\n", err)
+		os.Exit(1)
+	}
+}
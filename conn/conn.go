@@ -4,7 +4,10 @@
 
 package conn
 
-import "strconv"
+import (
+	"context"
+	"strconv"
+)
 
 // Resource is a basic resource (like a gpio pin) or a device.
 type Resource interface {
@@ -101,3 +104,28 @@ type Limits interface {
 	// Returns 0 if undefined.
 	MaxTxSize() int
 }
+
+// TxContexter is an optional interface implemented by a Conn that supports
+// aborting a transaction when a context is done.
+type TxContexter interface {
+	// TxContext does a single transaction like Conn.Tx() but returns early
+	// with ctx.Err() when ctx is done.
+	//
+	// Whether an on-going transaction can be interrupted is implementation
+	// specific; at worst the context is only checked before starting.
+	TxContext(ctx context.Context, w, r []byte) error
+}
+
+// TxContext does a single transaction on c, honoring ctx.
+//
+// If c implements TxContexter, it is used. Otherwise ctx is checked before
+// calling c.Tx(), which cannot be interrupted once started.
+func TxContext(ctx context.Context, c Conn, w, r []byte) error {
+	if t, ok := c.(TxContexter); ok {
+		return t.TxContext(ctx, w, r)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Tx(w, r)
+}
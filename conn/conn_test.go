@@ -5,6 +5,8 @@
 package conn
 
 import (
+	"context"
+	"errors"
 	"testing"
 )
 
@@ -13,3 +15,45 @@ func TestDuplex(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestTxContext(t *testing.T) {
+	c := &fakeConn{}
+	if err := TxContext(context.Background(), c, []byte{1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if c.count != 1 {
+		t.Fatal(c.count)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := TxContext(ctx, c, []byte{1}, nil); err != context.Canceled {
+		t.Fatal(err)
+	}
+	if c.count != 1 {
+		t.Fatal("Tx() shouldn't have been called")
+	}
+	cc := &fakeConnContext{}
+	if err := TxContext(ctx, cc, []byte{1}, nil); err != errCtx {
+		t.Fatal(err)
+	}
+}
+
+//
+
+var errCtx = errors.New("ctx")
+
+type fakeConn struct {
+	count int
+}
+
+func (f *fakeConn) String() string       { return "fake" }
+func (f *fakeConn) Duplex() Duplex       { return Half }
+func (f *fakeConn) Tx(w, r []byte) error { f.count++; return nil }
+
+type fakeConnContext struct {
+	fakeConn
+}
+
+func (f *fakeConnContext) TxContext(ctx context.Context, w, r []byte) error {
+	return errCtx
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package conntest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// TranscriptVersion is the version of the transcript file format written by
+// TranscriptWriter.
+//
+// A transcript is a stream of JSON objects, one per line. The first line is a
+// TranscriptHeader, each following line is an I/O operation whose fields
+// depend on the kind of transcript. Buffers are encoded as Hex.
+const TranscriptVersion = 1
+
+// TranscriptHeader is the first line of a transcript.
+type TranscriptHeader struct {
+	// Version is the file format version, at most TranscriptVersion.
+	Version int `json:"version"`
+	// Kind is the kind of bus recorded, e.g. "conn", "i2c", "spi" or
+	// "onewire".
+	Kind string `json:"kind"`
+}
+
+// Hex is a buffer encoded as a hexadecimal string in a transcript, so the
+// file is readable and can be edited by hand.
+type Hex []byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (h Hex) MarshalText() ([]byte, error) {
+	out := make([]byte, hex.EncodedLen(len(h)))
+	hex.Encode(out, h)
+	return out, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (h *Hex) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*h = nil
+		return nil
+	}
+	b := make([]byte, hex.DecodedLen(len(text)))
+	if _, err := hex.Decode(b, text); err != nil {
+		return err
+	}
+	*h = b
+	return nil
+}
+
+// TranscriptWriter writes a transcript.
+type TranscriptWriter struct {
+	e *json.Encoder
+}
+
+// NewTranscriptWriter writes the header of a transcript of the specified kind
+// and returns a writer for the operations.
+func NewTranscriptWriter(w io.Writer, kind string) (*TranscriptWriter, error) {
+	t := &TranscriptWriter{e: json.NewEncoder(w)}
+	if err := t.e.Encode(TranscriptHeader{Version: TranscriptVersion, Kind: kind}); err != nil {
+		return nil, fmt.Errorf("conntest: %v", err)
+	}
+	return t, nil
+}
+
+// Write writes one operation as a line.
+func (t *TranscriptWriter) Write(op interface{}) error {
+	if err := t.e.Encode(op); err != nil {
+		return fmt.Errorf("conntest: %v", err)
+	}
+	return nil
+}
+
+// TranscriptReader reads a transcript.
+type TranscriptReader struct {
+	d *json.Decoder
+}
+
+// NewTranscriptReader reads the header of a transcript and returns a reader
+// for the operations.
+//
+// It fails if the transcript is not of the specified kind or was written with
+// a newer format version.
+func NewTranscriptReader(r io.Reader, kind string) (*TranscriptReader, error) {
+	t := &TranscriptReader{d: json.NewDecoder(r)}
+	t.d.DisallowUnknownFields()
+	var h TranscriptHeader
+	if err := t.d.Decode(&h); err != nil {
+		return nil, fmt.Errorf("conntest: invalid transcript header: %v", err)
+	}
+	if h.Version < 1 || h.Version > TranscriptVersion {
+		return nil, fmt.Errorf("conntest: unsupported transcript version %d", h.Version)
+	}
+	if h.Kind != kind {
+		return nil, fmt.Errorf("conntest: expected a %q transcript, got %q", kind, h.Kind)
+	}
+	return t, nil
+}
+
+// Read reads the next operation into op.
+//
+// It returns io.EOF when there is no operation left.
+func (t *TranscriptReader) Read(op interface{}) error {
+	if err := t.d.Decode(op); err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return fmt.Errorf("conntest: invalid transcript: %v", err)
+	}
+	return nil
+}
+
+// WriteOps writes ops as a transcript of kind "conn".
+func WriteOps(w io.Writer, ops []IO) error {
+	return WriteOpsKind(w, "conn", ops)
+}
+
+// ReadOps reads a transcript of kind "conn" written by WriteOps.
+//
+// The result can be used as Playback.Ops.
+func ReadOps(r io.Reader) ([]IO, error) {
+	return ReadOpsKind(r, "conn")
+}
+
+// WriteOpsKind writes ops as a transcript of the specified kind.
+//
+// It is meant to be used by packages like spitest that record IO.
+func WriteOpsKind(w io.Writer, kind string, ops []IO) error {
+	t, err := NewTranscriptWriter(w, kind)
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if err := t.Write(ioJSON{W: op.W, R: op.R}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadOpsKind reads a transcript of the specified kind written by
+// WriteOpsKind.
+func ReadOpsKind(r io.Reader, kind string) ([]IO, error) {
+	t, err := NewTranscriptReader(r, kind)
+	if err != nil {
+		return nil, err
+	}
+	var ops []IO
+	for {
+		var op ioJSON
+		if err := t.Read(&op); err != nil {
+			if err == io.EOF {
+				return ops, nil
+			}
+			return nil, err
+		}
+		ops = append(ops, IO{W: op.W, R: op.R})
+	}
+}
+
+//
+
+type ioJSON struct {
+	W Hex `json:"w,omitempty"`
+	R Hex `json:"r,omitempty"`
+}
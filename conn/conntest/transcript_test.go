@@ -0,0 +1,89 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package conntest
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteOps_ReadOps(t *testing.T) {
+	ops := []IO{
+		{W: []byte{0x10}, R: []byte{0xAB, 0xCD}},
+		{W: []byte{0x20, 0x01}},
+		{R: []byte{0}},
+	}
+	var b bytes.Buffer
+	if err := WriteOps(&b, ops); err != nil {
+		t.Fatal(err)
+	}
+	expected := "{\"version\":1,\"kind\":\"conn\"}\n{\"w\":\"10\",\"r\":\"abcd\"}\n{\"w\":\"2001\"}\n{\"r\":\"00\"}\n"
+	if s := b.String(); s != expected {
+		t.Fatalf("%q", s)
+	}
+	got, err := ReadOps(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, ops) {
+		t.Fatalf("%#v", got)
+	}
+
+	// The loaded transcript can be played back.
+	p := Playback{Ops: got}
+	r := make([]byte, 2)
+	if err := p.Tx([]byte{0x10}, r); err != nil || !bytes.Equal(r, []byte{0xAB, 0xCD}) {
+		t.Fatal(r, err)
+	}
+}
+
+func TestReadOps_empty(t *testing.T) {
+	ops, err := ReadOps(strings.NewReader("{\"version\":1,\"kind\":\"conn\"}\n"))
+	if err != nil || ops != nil {
+		t.Fatal(ops, err)
+	}
+}
+
+func TestReadOps_invalid(t *testing.T) {
+	data := []string{
+		"",
+		"garbage",
+		"{\"version\":2,\"kind\":\"conn\"}\n",
+		"{\"version\":0,\"kind\":\"conn\"}\n",
+		"{\"version\":1,\"kind\":\"i2c\"}\n",
+		"{\"version\":1,\"kind\":\"conn\"}\n{\"w\":\"1\"}\n",
+		"{\"version\":1,\"kind\":\"conn\"}\n{\"x\":\"10\"}\n",
+	}
+	for i, line := range data {
+		if _, err := ReadOps(strings.NewReader(line)); err == nil {
+			t.Fatal(i)
+		}
+	}
+}
+
+func TestWriteOps_fail(t *testing.T) {
+	if err := WriteOps(&failWriter{}, nil); err == nil {
+		t.Fatal("header write failed")
+	}
+	if err := WriteOps(&failWriter{n: 1}, []IO{{W: []byte{1}}}); err == nil {
+		t.Fatal("op write failed")
+	}
+}
+
+//
+
+type failWriter struct {
+	n int
+}
+
+func (f *failWriter) Write(b []byte) (int, error) {
+	if f.n == 0 {
+		return 0, Errorf("failing")
+	}
+	f.n--
+	return len(b), nil
+}
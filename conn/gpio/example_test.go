@@ -66,6 +66,43 @@ func ExamplePinIn() {
 	}
 }
 
+func ExamplePinEventer() {
+	// Make sure periph is initialized.
+	if _, err := host.Init(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Use gpioreg GPIO pin registry to find a GPIO pin by name.
+	p := gpioreg.ByName("GPIO6")
+	if p == nil {
+		log.Fatal("Failed to find GPIO6")
+	}
+	e, ok := p.(gpio.PinEventer)
+	if !ok {
+		log.Fatalf("%s doesn't support edge events", p)
+	}
+
+	if err := p.In(gpio.PullDown, gpio.BothEdges); err != nil {
+		log.Fatal(err)
+	}
+
+	// Print the duration of each pulse and report missed edges.
+	var last gpio.EdgeEvent
+	for {
+		ev, ok := e.WaitForEvent(-1)
+		if !ok {
+			break
+		}
+		if last.Seq != 0 && ev.Seq != last.Seq+1 {
+			fmt.Printf("missed %d edges\n", ev.Seq-last.Seq-1)
+		}
+		if ev.Edge == gpio.FallingEdge && last.Edge == gpio.RisingEdge {
+			fmt.Printf("pulse of %s\n", ev.Time-last.Time)
+		}
+		last = ev
+	}
+}
+
 func ExamplePinOut() {
 	// Make sure periph is initialized.
 	if _, err := host.Init(); err != nil {
@@ -9,6 +9,7 @@
 package gpio
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"strings"
@@ -235,6 +236,80 @@ type PinIO interface {
 	PWM(duty Duty, f physic.Frequency) error
 }
 
+// EdgeEvent is an edge detected on an input pin.
+type EdgeEvent struct {
+	// Edge is either RisingEdge or FallingEdge.
+	Edge Edge
+	// Time is when the edge was detected, as measured by a monotonic clock with
+	// an implementation specific epoch. It is only meaningful to calculate the
+	// interval between two events of the same pin.
+	Time time.Duration
+	// Seq is the sequence number of the edge since edge detection was enabled
+	// via In(). A gap between two consecutive events means that edges were
+	// missed.
+	Seq uint32
+}
+
+// PinEventer is an optional interface implemented by input pins that can
+// report each detected edge individually, along its timestamp.
+//
+// The precision of Time is implementation specific. Implementations
+// leveraging the kernel or the hardware timestamp the edge when the interrupt
+// is serviced, while others timestamp it when the process is woken up.
+type PinEventer interface {
+	// WaitForEvent waits for the next edge or immediately returns if an edge
+	// occurred since the last call.
+	//
+	// Only waits for the kind of edge as specified in a previous In() call.
+	// Behavior is undefined if In() with a value other than NoEdge wasn't called
+	// before.
+	//
+	// Returns false if the timeout occurred or In() was called while waiting,
+	// causing the function to exit.
+	//
+	// Unlike WaitForEdge(), edges queued by the implementation are returned one
+	// at a time. Mixing calls to WaitForEdge() and WaitForEvent() is undefined.
+	//
+	// Specify -1 to effectively disable timeout.
+	WaitForEvent(timeout time.Duration) (EdgeEvent, bool)
+}
+
+// WaitForEdgeContexter is an optional interface implemented by input pins
+// that can abort waiting for an edge when a context is done.
+type WaitForEdgeContexter interface {
+	// WaitForEdgeContext waits for the next edge like WaitForEdge() but returns
+	// false as soon as ctx is done instead of relying on a timeout.
+	WaitForEdgeContext(ctx context.Context) bool
+}
+
+// WaitForEdgeContext waits for the next edge on p until ctx is done.
+//
+// If p implements WaitForEdgeContexter, it is used. Otherwise
+// p.WaitForEdge() is called repeatedly with a short timeout, so cancellation
+// is detected with a latency of up to 100ms.
+func WaitForEdgeContext(ctx context.Context, p PinIn) bool {
+	if w, ok := p.(WaitForEdgeContexter); ok {
+		return w.WaitForEdgeContext(ctx)
+	}
+	for {
+		if ctx.Err() != nil {
+			return false
+		}
+		t := pollEdgeTimeout
+		if d, ok := ctx.Deadline(); ok {
+			if r := time.Until(d); r < t {
+				t = r
+			}
+			if t <= 0 {
+				return false
+			}
+		}
+		if p.WaitForEdge(t) {
+			return true
+		}
+	}
+}
+
 // INVALID implements PinIO and fails on all access.
 var INVALID PinIO
 
@@ -252,6 +327,10 @@ type RealPin interface {
 
 //
 
+// pollEdgeTimeout is the maximum latency of WaitForEdgeContext() to detect
+// cancellation when the pin doesn't implement WaitForEdgeContexter.
+const pollEdgeTimeout = 100 * time.Millisecond
+
 // errInvalidPin is returned when trying to use INVALID.
 var errInvalidPin = errors.New("gpio: invalid pin")
 
@@ -307,6 +386,10 @@ func (invalidPin) WaitForEdge(timeout time.Duration) bool {
 	return false
 }
 
+func (invalidPin) WaitForEdgeContext(ctx context.Context) bool {
+	return false
+}
+
 func (invalidPin) Pull() Pull {
 	return PullNoChange
 }
@@ -327,3 +410,4 @@ var _ PinIn = INVALID
 var _ PinOut = INVALID
 var _ PinIO = INVALID
 var _ pin.PinFunc = &invalidPin{}
+var _ WaitForEdgeContexter = &invalidPin{}
@@ -5,6 +5,7 @@
 package gpio
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -131,6 +132,9 @@ func TestInvalid(t *testing.T) {
 	if INVALID.WaitForEdge(time.Minute) {
 		t.Fatal("unexpected edge")
 	}
+	if WaitForEdgeContext(context.Background(), INVALID) {
+		t.Fatal("unexpected edge")
+	}
 	if p := INVALID.Pull(); p != PullNoChange {
 		t.Fatal(p)
 	}
@@ -155,3 +159,50 @@ func TestInvalid(t *testing.T) {
 		t.Fatal("can't set func")
 	}
 }
+
+func TestWaitForEdgeContext(t *testing.T) {
+	p := &edgePin{PinIn: INVALID, edges: 1}
+	if !WaitForEdgeContext(context.Background(), p) {
+		t.Fatal("expected edge")
+	}
+	if p.calls != 1 || p.timeout != pollEdgeTimeout {
+		t.Fatal(p.calls, p.timeout)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if WaitForEdgeContext(ctx, p) {
+		t.Fatal("unexpected edge")
+	}
+	if p.calls != 1 {
+		t.Fatal("WaitForEdge() shouldn't have been called")
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if WaitForEdgeContext(ctx, p) {
+		t.Fatal("unexpected edge")
+	}
+	if p.timeout > 10*time.Millisecond {
+		t.Fatal(p.timeout)
+	}
+}
+
+//
+
+// edgePin detects the specified number of edges then only times out.
+type edgePin struct {
+	PinIn
+	edges   int
+	calls   int
+	timeout time.Duration
+}
+
+func (e *edgePin) WaitForEdge(timeout time.Duration) bool {
+	e.calls++
+	e.timeout = timeout
+	if e.edges > 0 {
+		e.edges--
+		return true
+	}
+	time.Sleep(timeout)
+	return false
+}
@@ -30,9 +30,10 @@ type Pin struct {
 	sync.Mutex
 	L         gpio.Level // Used for both input and output
 	P         gpio.Pull
-	EdgesChan chan gpio.Level  // Use it to fake edges
-	D         gpio.Duty        // PWM duty
-	F         physic.Frequency // PWM period
+	EdgesChan chan gpio.Level     // Use it to fake edges
+	EventChan chan gpio.EdgeEvent // Use it to fake timestamped edges
+	D         gpio.Duty           // PWM duty
+	F         physic.Frequency    // PWM period
 }
 
 // String implements conn.Resource.
@@ -87,13 +88,14 @@ func (p *Pin) In(pull gpio.Pull, edge gpio.Edge) error {
 	} else if pull == gpio.PullUp {
 		p.L = gpio.High
 	}
-	if edge != gpio.NoEdge && p.EdgesChan == nil {
-		return errors.New("gpiotest: please set p.EdgesChan first")
+	if edge != gpio.NoEdge && p.EdgesChan == nil && p.EventChan == nil {
+		return errors.New("gpiotest: please set p.EdgesChan or p.EventChan first")
 	}
 	// Flush any buffered edges.
 	for {
 		select {
 		case <-p.EdgesChan:
+		case <-p.EventChan:
 		default:
 			return nil
 		}
@@ -108,17 +110,40 @@ func (p *Pin) Read() gpio.Level {
 }
 
 // WaitForEdge implements gpio.PinIn.
+//
+// It consumes edges from both EdgesChan and EventChan.
 func (p *Pin) WaitForEdge(timeout time.Duration) bool {
-	if timeout == -1 {
-		_ = p.Out(<-p.EdgesChan)
-		return true
+	var t <-chan time.Time
+	if timeout != -1 {
+		t = time.After(timeout)
 	}
 	select {
-	case <-time.After(timeout):
+	case <-t:
 		return false
 	case l := <-p.EdgesChan:
 		_ = p.Out(l)
 		return true
+	case e := <-p.EventChan:
+		_ = p.Out(e.Edge == gpio.RisingEdge)
+		return true
+	}
+}
+
+// WaitForEvent implements gpio.PinEventer.
+//
+// It returns the events sent to EventChan as-is, so a test can control their
+// timestamp and sequence number.
+func (p *Pin) WaitForEvent(timeout time.Duration) (gpio.EdgeEvent, bool) {
+	var t <-chan time.Time
+	if timeout != -1 {
+		t = time.After(timeout)
+	}
+	select {
+	case <-t:
+		return gpio.EdgeEvent{}, false
+	case e := <-p.EventChan:
+		_ = p.Out(e.Edge == gpio.RisingEdge)
+		return e, true
 	}
 }
 
@@ -193,4 +218,5 @@ func (p *LogPinIO) PWM(duty gpio.Duty, f physic.Frequency) error {
 }
 
 var _ gpio.PinIO = &Pin{}
+var _ gpio.PinEventer = &Pin{}
 var _ pin.PinFunc = &Pin{}
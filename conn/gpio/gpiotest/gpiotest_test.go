@@ -98,6 +98,35 @@ func TestPin_edge(t *testing.T) {
 	}
 }
 
+func TestPin_event(t *testing.T) {
+	p := &Pin{N: "GPIO1", Num: 1, Fn: "I2C1_SDA", EventChan: make(chan gpio.EdgeEvent, 2)}
+	p.EventChan <- gpio.EdgeEvent{Edge: gpio.RisingEdge, Time: time.Second, Seq: 1}
+	if err := p.In(gpio.Float, gpio.BothEdges); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.WaitForEvent(time.Millisecond); ok {
+		t.Fatal("In() must flush events")
+	}
+	p.EventChan <- gpio.EdgeEvent{Edge: gpio.RisingEdge, Time: time.Second, Seq: 1}
+	p.EventChan <- gpio.EdgeEvent{Edge: gpio.FallingEdge, Time: 2 * time.Second, Seq: 3}
+	want := gpio.EdgeEvent{Edge: gpio.RisingEdge, Time: time.Second, Seq: 1}
+	if e, ok := p.WaitForEvent(-1); !ok || e != want {
+		t.Fatal(e, ok)
+	}
+	if l := p.Read(); l != gpio.High {
+		t.Fatalf("unexpected %s", l)
+	}
+	if !p.WaitForEdge(time.Minute) {
+		t.Fatal("expected edge")
+	}
+	if l := p.Read(); l != gpio.Low {
+		t.Fatalf("unexpected %s", l)
+	}
+	if _, ok := p.WaitForEvent(time.Millisecond); ok {
+		t.Fatal("unexpected event")
+	}
+}
+
 func TestPin_fail(t *testing.T) {
 	p := &Pin{N: "GPIO1", Num: 1, Fn: "I2C1_SDA"}
 	if err := p.In(gpio.Float, gpio.BothEdges); err == nil {
@@ -19,9 +19,11 @@
 package i2c
 
 import (
+	"context"
 	"errors"
 	"io"
 	"strconv"
+	"strings"
 
 	"github.com/meandrewdev/periph/conn"
 	"github.com/meandrewdev/periph/conn/gpio"
@@ -49,6 +51,125 @@ type Bus interface {
 	SetSpeed(f physic.Frequency) error
 }
 
+// TxContexter is an optional interface implemented by a Bus that supports
+// aborting a transaction when a context is done.
+type TxContexter interface {
+	// TxContext does a transaction like Bus.Tx() but returns early with
+	// ctx.Err() when ctx is done.
+	//
+	// Whether an on-going transaction can be interrupted is implementation
+	// specific; at worst the context is only checked before starting.
+	TxContext(ctx context.Context, addr uint16, w, r []byte) error
+}
+
+// TxContext does a transaction at the specified device address on b, honoring
+// ctx.
+//
+// If b implements TxContexter, it is used. Otherwise ctx is checked before
+// calling b.Tx(), which cannot be interrupted once started.
+func TxContext(ctx context.Context, b Bus, addr uint16, w, r []byte) error {
+	if t, ok := b.(TxContexter); ok {
+		return t.TxContext(ctx, addr, w, r)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return b.Tx(addr, w, r)
+}
+
+// Flags modify how a Msg is sent on the bus.
+//
+// The values match the Linux I2C_M_xxx flags. Except for Read and TenBit,
+// support for the flags is adapter specific.
+type Flags uint16
+
+// Valid Flags.
+const (
+	// Read reads into Msg.Buf instead of writing it.
+	Read Flags = 0x0001
+	// TenBit uses 10 bits addressing for Msg.Addr.
+	TenBit Flags = 0x0010
+	// RecvLen is used for SMBus block reads; the first byte read is the number
+	// of bytes that follow. Msg.Buf must be at least 33 bytes long and Buf[0]
+	// receives the length followed by the data.
+	RecvLen Flags = 0x0400
+	// NoReadAck doesn't acknowledge the bytes read.
+	NoReadAck Flags = 0x0800
+	// IgnoreNAK continues the transaction even if the device doesn't
+	// acknowledge.
+	IgnoreNAK Flags = 0x1000
+	// RevDirAddr inverts the R/W bit sent with the address.
+	RevDirAddr Flags = 0x2000
+	// NoStart skips the repeated start and the address, so Msg.Buf continues
+	// the previous message.
+	NoStart Flags = 0x4000
+	// Stop sends a stop condition after this message instead of a repeated
+	// start.
+	Stop Flags = 0x8000
+)
+
+func (f Flags) String() string {
+	var out []string
+	for _, n := range flagNames {
+		if f&n.f != 0 {
+			out = append(out, n.name)
+			f &^= n.f
+		}
+	}
+	if f != 0 {
+		out = append(out, "0x"+strconv.FormatUint(uint64(f), 16))
+	}
+	if len(out) == 0 {
+		return "0"
+	}
+	return strings.Join(out, "|")
+}
+
+// Msg is a single message in a multi-message transaction.
+type Msg struct {
+	Addr  uint16
+	Flags Flags
+	Buf   []byte
+}
+
+// Messenger is an optional interface implemented by a Bus that supports
+// transactions of arbitrary messages.
+//
+// This enables multiple reads and writes with repeated starts, messages to
+// different devices in a single transaction and the use of Flags.
+type Messenger interface {
+	// Transfer executes the messages as a single transaction; a repeated
+	// start is sent between each message and a stop at the end.
+	Transfer(msgs []Msg) error
+}
+
+// Transfer executes msgs as a single transaction on b.
+//
+// If b implements Messenger, it is used. Otherwise msgs are emulated with
+// b.Tx() when possible, that is a single write, a single read or a write
+// followed by a read at the same address, without flags other than Read.
+func Transfer(b Bus, msgs []Msg) error {
+	if m, ok := b.(Messenger); ok {
+		return m.Transfer(msgs)
+	}
+	for i := range msgs {
+		if msgs[i].Flags&^Read != 0 {
+			return errors.New("i2c: flags " + msgs[i].Flags.String() + " are not supported by " + b.String())
+		}
+	}
+	switch {
+	case len(msgs) == 0:
+		return nil
+	case len(msgs) == 1 && msgs[0].Flags&Read == 0:
+		return b.Tx(msgs[0].Addr, msgs[0].Buf, nil)
+	case len(msgs) == 1:
+		return b.Tx(msgs[0].Addr, nil, msgs[0].Buf)
+	case len(msgs) == 2 && msgs[0].Flags&Read == 0 && msgs[1].Flags&Read != 0 && msgs[0].Addr == msgs[1].Addr:
+		return b.Tx(msgs[0].Addr, msgs[0].Buf, msgs[1].Buf)
+	}
+	return errors.New("i2c: " + b.String() + " doesn't support multi-message transactions")
+}
+
 // BusCloser is an I²C bus that can be closed.
 //
 // This interface is meant to be handled by the application and not the device
@@ -95,6 +216,14 @@ func (d *Dev) Tx(w, r []byte) error {
 	return d.Bus.Tx(d.Addr, w, r)
 }
 
+// TxContext does a transaction by adding the device's address to each
+// command, honoring ctx.
+//
+// It's a wrapper for TxContext().
+func (d *Dev) TxContext(ctx context.Context, w, r []byte) error {
+	return TxContext(ctx, d.Bus, d.Addr, w, r)
+}
+
 // Write writes to the I²C bus without reading, implementing io.Writer.
 //
 // It's a wrapper for Tx()
@@ -132,4 +261,19 @@ func (a Addr) String() string {
 
 var errI2CSetError = errors.New("invalid i2c address")
 
+var flagNames = []struct {
+	f    Flags
+	name string
+}{
+	{Read, "Read"},
+	{TenBit, "TenBit"},
+	{RecvLen, "RecvLen"},
+	{NoReadAck, "NoReadAck"},
+	{IgnoreNAK, "IgnoreNAK"},
+	{RevDirAddr, "RevDirAddr"},
+	{NoStart, "NoStart"},
+	{Stop, "Stop"},
+}
+
 var _ conn.Conn = &Dev{}
+var _ conn.TxContexter = &Dev{}
@@ -6,6 +6,7 @@ package i2c
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"testing"
 
@@ -75,8 +76,99 @@ func TestDevWriteErr(t *testing.T) {
 	}
 }
 
+func TestTxContext(t *testing.T) {
+	b := &fakeBus{}
+	if err := TxContext(context.Background(), b, 12, []byte{'a'}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if b.addr != 12 || !bytes.Equal(b.w, []byte{'a'}) {
+		t.Fatal(b)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b = &fakeBus{}
+	if err := TxContext(ctx, b, 12, []byte{'a'}, nil); err != context.Canceled {
+		t.Fatal(err)
+	}
+	if b.w != nil {
+		t.Fatal("Tx() shouldn't have been called")
+	}
+	d := Dev{Bus: &fakeBusContext{}, Addr: 12}
+	if err := d.TxContext(ctx, []byte{'a'}, nil); err != errCtx {
+		t.Fatal(err)
+	}
+}
+
+func TestFlags_String(t *testing.T) {
+	if s := (Read | TenBit | Stop).String(); s != "Read|TenBit|Stop" {
+		t.Fatal(s)
+	}
+	if s := Flags(0x2).String(); s != "0x2" {
+		t.Fatal(s)
+	}
+	if s := Flags(0).String(); s != "0" {
+		t.Fatal(s)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	b := &fakeBus{r: []byte{1, 2}}
+	if err := Transfer(b, nil); err != nil {
+		t.Fatal(err)
+	}
+	r := make([]byte, 2)
+	if err := Transfer(b, []Msg{{Addr: 12, Buf: []byte{'a'}}, {Addr: 12, Flags: Read, Buf: r}}); err != nil {
+		t.Fatal(err)
+	}
+	if b.addr != 12 || !bytes.Equal(b.w, []byte{'a'}) || !bytes.Equal(r, []byte{1, 2}) {
+		t.Fatal(b, r)
+	}
+	if err := Transfer(b, []Msg{{Addr: 13, Buf: []byte{'b'}}}); err != nil {
+		t.Fatal(err)
+	}
+	if b.addr != 13 || !bytes.Equal(b.w, []byte{'a', 'b'}) {
+		t.Fatal(b)
+	}
+	if err := Transfer(b, []Msg{{Addr: 14, Flags: Read}}); err != nil || b.addr != 14 {
+		t.Fatal(err)
+	}
+	if Transfer(b, []Msg{{Addr: 12, Flags: TenBit}}) == nil {
+		t.Fatal("flags can't be emulated")
+	}
+	if Transfer(b, []Msg{{Addr: 12}, {Addr: 13, Flags: Read}}) == nil {
+		t.Fatal("different addresses can't be emulated")
+	}
+	m := &fakeBusMessenger{}
+	if err := Transfer(m, []Msg{{Addr: 12, Flags: TenBit}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.msgs) != 1 || m.msgs[0].Flags != TenBit {
+		t.Fatal(m.msgs)
+	}
+}
+
 //
 
+var errCtx = errors.New("ctx")
+
+type fakeBusMessenger struct {
+	fakeBus
+	msgs []Msg
+}
+
+func (f *fakeBusMessenger) Transfer(msgs []Msg) error {
+	f.msgs = msgs
+	return nil
+}
+
+type fakeBusContext struct {
+	fakeBus
+}
+
+func (f *fakeBusContext) TxContext(ctx context.Context, addr uint16, w, r []byte) error {
+	return errCtx
+}
+
 type fakeBus struct {
 	freq physic.Frequency
 	err  error
@@ -16,10 +16,14 @@ import (
 )
 
 // IO registers the I/O that happened on either a real or fake I²C bus.
+//
+// A Transfer() call is registered in Msgs instead of Addr, W and R. The Buf of
+// read messages contains the data read.
 type IO struct {
 	Addr uint16
 	W    []byte
 	R    []byte
+	Msgs []i2c.Msg
 }
 
 // Record implements i2c.Bus that records everything written to it.
@@ -63,6 +67,36 @@ func (r *Record) Tx(addr uint16, w, read []byte) error {
 	return nil
 }
 
+// Transfer implements i2c.Messenger.
+//
+// The messages are forwarded with i2c.Transfer() so Bus doesn't have to
+// implement i2c.Messenger for simple transactions.
+func (r *Record) Transfer(msgs []i2c.Msg) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.Bus == nil {
+		for i := range msgs {
+			if msgs[i].Flags&i2c.Read != 0 {
+				return conntest.Errorf("i2ctest: read unsupported when no bus is connected")
+			}
+		}
+	} else {
+		if err := i2c.Transfer(r.Bus, msgs); err != nil {
+			return err
+		}
+	}
+	io := IO{Msgs: make([]i2c.Msg, len(msgs))}
+	for i, m := range msgs {
+		io.Msgs[i] = i2c.Msg{Addr: m.Addr, Flags: m.Flags}
+		if len(m.Buf) != 0 {
+			io.Msgs[i].Buf = make([]byte, len(m.Buf))
+			copy(io.Msgs[i].Buf, m.Buf)
+		}
+	}
+	r.Ops = append(r.Ops, io)
+	return nil
+}
+
 // SetSpeed implements i2c.Bus.
 func (r *Record) SetSpeed(f physic.Frequency) error {
 	if r.Bus != nil {
@@ -140,6 +174,40 @@ func (p *Playback) Tx(addr uint16, w, r []byte) error {
 	return nil
 }
 
+// Transfer implements i2c.Messenger.
+//
+// The written messages are compared and the read messages are filled from
+// the Msgs of the expected IO.
+func (p *Playback) Transfer(msgs []i2c.Msg) error {
+	p.Lock()
+	defer p.Unlock()
+	if len(p.Ops) <= p.Count {
+		return errorf(p.DontPanic, "i2ctest: unexpected Transfer() (count #%d) expecting i2ctest.IO{Msgs:%#v}", p.Count, msgs)
+	}
+	exp := p.Ops[p.Count].Msgs
+	if len(exp) != len(msgs) {
+		return errorf(p.DontPanic, "i2ctest: unexpected number of messages (count #%d) %d != %d", p.Count, len(msgs), len(exp))
+	}
+	for i, m := range msgs {
+		if m.Addr != exp[i].Addr || m.Flags != exp[i].Flags {
+			return errorf(p.DontPanic, "i2ctest: unexpected message #%d (count #%d) addr %d flags %s != addr %d flags %s", i, p.Count, m.Addr, m.Flags, exp[i].Addr, exp[i].Flags)
+		}
+		if len(m.Buf) != len(exp[i].Buf) {
+			return errorf(p.DontPanic, "i2ctest: unexpected message #%d buffer length (count #%d) %d != %d", i, p.Count, len(m.Buf), len(exp[i].Buf))
+		}
+		if m.Flags&i2c.Read == 0 && !bytes.Equal(m.Buf, exp[i].Buf) {
+			return errorf(p.DontPanic, "i2ctest: unexpected message #%d write (count #%d) %#v != %#v", i, p.Count, m.Buf, exp[i].Buf)
+		}
+	}
+	for i, m := range msgs {
+		if m.Flags&i2c.Read != 0 {
+			copy(m.Buf, exp[i].Buf)
+		}
+	}
+	p.Count++
+	return nil
+}
+
 // SetSpeed implements i2c.Bus.
 func (p *Playback) SetSpeed(f physic.Frequency) error {
 	return nil
@@ -169,6 +237,8 @@ func errorf(dontPanic bool, format string, a ...interface{}) error {
 }
 
 var _ i2c.Bus = &Record{}
+var _ i2c.Messenger = &Record{}
 var _ i2c.Pins = &Record{}
 var _ i2c.Bus = &Playback{}
+var _ i2c.Messenger = &Playback{}
 var _ i2c.Pins = &Playback{}
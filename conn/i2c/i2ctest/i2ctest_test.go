@@ -5,11 +5,14 @@
 package i2ctest
 
 import (
+	"bytes"
+	"reflect"
 	"testing"
 
 	"github.com/meandrewdev/periph/conn/conntest"
 	"github.com/meandrewdev/periph/conn/gpio"
 	"github.com/meandrewdev/periph/conn/gpio/gpiotest"
+	"github.com/meandrewdev/periph/conn/i2c"
 )
 
 func TestRecord_empty(t *testing.T) {
@@ -165,3 +168,62 @@ func TestRecord_Playback(t *testing.T) {
 		t.Fatal("Playback.Ops is empty")
 	}
 }
+
+func TestRecord_Transfer(t *testing.T) {
+	r := Record{}
+	if r.Transfer([]i2c.Msg{{Addr: 23, Flags: i2c.Read, Buf: make([]byte, 1)}}) == nil {
+		t.Fatal("Bus is nil")
+	}
+	if err := r.Transfer([]i2c.Msg{{Addr: 23, Flags: i2c.TenBit, Buf: []byte{10}}}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []IO{{Msgs: []i2c.Msg{{Addr: 23, Flags: i2c.TenBit, Buf: []byte{10}}}}}
+	if !reflect.DeepEqual(expected, r.Ops) {
+		t.Fatalf("%#v", r.Ops)
+	}
+}
+
+func TestRecord_Playback_Transfer(t *testing.T) {
+	msgs := []i2c.Msg{
+		{Addr: 23, Buf: []byte{10}},
+		{Addr: 23, Flags: i2c.Read, Buf: []byte{12, 13}},
+		{Addr: 24, Flags: i2c.Read | i2c.Stop, Buf: []byte{14}},
+	}
+	p := &Playback{Ops: []IO{{Msgs: msgs}}, DontPanic: true}
+	r := Record{Bus: p}
+	v1 := make([]byte, 2)
+	v2 := make([]byte, 1)
+	in := []i2c.Msg{
+		{Addr: 23, Buf: []byte{10}},
+		{Addr: 23, Flags: i2c.Read, Buf: v1},
+		{Addr: 24, Flags: i2c.Read | i2c.Stop, Buf: v2},
+	}
+	if err := r.Transfer(in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(v1, []byte{12, 13}) || v2[0] != 14 {
+		t.Fatal(v1, v2)
+	}
+	if !reflect.DeepEqual([]IO{{Msgs: msgs}}, r.Ops) {
+		t.Fatalf("%#v", r.Ops)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Transfer(in) == nil {
+		t.Fatal("Playback.Ops is empty")
+	}
+
+	data := [][]i2c.Msg{
+		{{Addr: 23, Buf: []byte{10}}},
+		{{Addr: 22, Buf: []byte{10}}, in[1], in[2]},
+		{{Addr: 23, Buf: []byte{10, 11}}, in[1], in[2]},
+		{{Addr: 23, Buf: []byte{11}}, in[1], in[2]},
+	}
+	for i, line := range data {
+		p := &Playback{Ops: []IO{{Msgs: msgs}}, DontPanic: true}
+		if p.Transfer(line) == nil {
+			t.Fatal(i)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package i2ctest
+
+import (
+	"sync"
+
+	"github.com/meandrewdev/periph/conn/conntest"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+// Loopback is both an i2c.Bus and an i2c.Target; the transactions done on
+// the bus are handled by the handlers registered on the target.
+//
+// It permits testing a controller driver against a target implementation in
+// the same process. A transaction to an address without handler fails as if
+// the target didn't acknowledge.
+//
+// The handlers are called synchronously within Tx() and Transfer().
+type Loopback struct {
+	mu       sync.Mutex
+	handlers map[uint16]i2c.TargetHandler
+}
+
+func (l *Loopback) String() string {
+	return "loopback"
+}
+
+// Close implements i2c.BusCloser.
+func (l *Loopback) Close() error {
+	return nil
+}
+
+// Tx implements i2c.Bus.
+func (l *Loopback) Tx(addr uint16, w, r []byte) error {
+	h, err := l.handler(addr)
+	if err != nil {
+		return err
+	}
+	if len(w) != 0 {
+		h.Write(append([]byte(nil), w...))
+	}
+	if len(r) != 0 {
+		fill(r, h.Read())
+	}
+	return nil
+}
+
+// Transfer implements i2c.Messenger.
+//
+// The flags other than Read are ignored.
+func (l *Loopback) Transfer(msgs []i2c.Msg) error {
+	for _, m := range msgs {
+		h, err := l.handler(m.Addr)
+		if err != nil {
+			return err
+		}
+		if m.Flags&i2c.Read != 0 {
+			fill(m.Buf, h.Read())
+		} else if len(m.Buf) != 0 {
+			h.Write(append([]byte(nil), m.Buf...))
+		}
+	}
+	return nil
+}
+
+// SetSpeed implements i2c.Bus.
+func (l *Loopback) SetSpeed(f physic.Frequency) error {
+	return nil
+}
+
+// SCL implements i2c.Pins.
+func (l *Loopback) SCL() gpio.PinIO {
+	return gpio.INVALID
+}
+
+// SDA implements i2c.Pins.
+func (l *Loopback) SDA() gpio.PinIO {
+	return gpio.INVALID
+}
+
+// Register implements i2c.Target.
+func (l *Loopback) Register(addr uint16, h i2c.TargetHandler) error {
+	if addr >= 0x400 {
+		return conntest.Errorf("i2ctest: invalid address %d", addr)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.handlers[addr]; ok {
+		return conntest.Errorf("i2ctest: address %d is already registered", addr)
+	}
+	if l.handlers == nil {
+		l.handlers = map[uint16]i2c.TargetHandler{}
+	}
+	l.handlers[addr] = h
+	return nil
+}
+
+// Unregister implements i2c.Target.
+func (l *Loopback) Unregister(addr uint16) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.handlers[addr]; !ok {
+		return conntest.Errorf("i2ctest: address %d is not registered", addr)
+	}
+	delete(l.handlers, addr)
+	return nil
+}
+
+//
+
+func (l *Loopback) handler(addr uint16) (i2c.TargetHandler, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h, ok := l.handlers[addr]
+	if !ok {
+		return nil, conntest.Errorf("i2ctest: no target answered at address %d", addr)
+	}
+	return h, nil
+}
+
+// fill copies src into dst and pads with 0xFF, as the pulled up bus does.
+func fill(dst, src []byte) {
+	n := copy(dst, src)
+	for i := n; i < len(dst); i++ {
+		dst[i] = 0xFF
+	}
+}
+
+var _ i2c.BusCloser = &Loopback{}
+var _ i2c.Messenger = &Loopback{}
+var _ i2c.Pins = &Loopback{}
+var _ i2c.Target = &Loopback{}
@@ -0,0 +1,95 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package i2ctest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/i2c"
+)
+
+func TestLoopback(t *testing.T) {
+	l := Loopback{}
+	if s := l.String(); s != "loopback" {
+		t.Fatal(s)
+	}
+	regs := &regTarget{}
+	if err := l.Register(0x400, regs); err == nil {
+		t.Fatal("invalid address")
+	}
+	if err := l.Register(0x20, regs); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Register(0x20, regs); err == nil {
+		t.Fatal("already registered")
+	}
+
+	d := i2c.Dev{Bus: &l, Addr: 0x20}
+	if err := d.Tx([]byte{2, 0xA, 0xB}, nil); err != nil {
+		t.Fatal(err)
+	}
+	r := make([]byte, 4)
+	if err := d.Tx([]byte{1}, r); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r, []byte{0, 0xA, 0xB, 0}) {
+		t.Fatal(r)
+	}
+	r = make([]byte, 2)
+	if err := i2c.Transfer(&l, []i2c.Msg{{Addr: 0x20, Buf: []byte{regsLen - 1}}, {Addr: 0x20, Flags: i2c.Read, Buf: r}}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r, []byte{0, 0xFF}) {
+		t.Fatal(r)
+	}
+	if err := d.Tx(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Tx(0x21, []byte{1}, nil); err == nil {
+		t.Fatal("no target at 0x21")
+	}
+	if err := l.Transfer([]i2c.Msg{{Addr: 0x21}}); err == nil {
+		t.Fatal("no target at 0x21")
+	}
+	if err := l.Unregister(0x20); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Unregister(0x20); err == nil {
+		t.Fatal("not registered")
+	}
+	if err := d.Tx([]byte{1}, nil); err == nil {
+		t.Fatal("unregistered")
+	}
+	if err := l.SetSpeed(0); err != nil {
+		t.Fatal(err)
+	}
+	l.SCL()
+	l.SDA()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//
+
+const regsLen = 8
+
+// regTarget is a typical register based device; the first byte written is
+// the register pointer.
+type regTarget struct {
+	ptr  byte
+	regs [regsLen]byte
+}
+
+func (r *regTarget) Write(w []byte) {
+	r.ptr = w[0] % regsLen
+	copy(r.regs[r.ptr:], w[1:])
+}
+
+func (r *regTarget) Read() []byte {
+	return r.regs[r.ptr:]
+}
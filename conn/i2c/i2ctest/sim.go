@@ -0,0 +1,198 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package i2ctest
+
+import (
+	"sync"
+
+	"github.com/meandrewdev/periph/conn/conntest"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+// Sim implements i2c.Bus and simulates devices as register maps.
+//
+// Unlike Playback, the transactions are not compared to a transcript; the
+// driver under test can access the registers in any order, so the test
+// verifies the behavior instead of the exact I/O.
+//
+// A transaction to an address without device fails as if the device didn't
+// acknowledge.
+type Sim struct {
+	sync.Mutex
+	Devices map[uint16]*SimDevice
+}
+
+func (s *Sim) String() string {
+	return "sim"
+}
+
+// Close implements i2c.BusCloser.
+func (s *Sim) Close() error {
+	return nil
+}
+
+// Tx implements i2c.Bus.
+//
+// The transaction is handled by SimDevice.Tx.
+func (s *Sim) Tx(addr uint16, w, r []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	d := s.Devices[addr]
+	if d == nil {
+		return conntest.Errorf("i2ctest: no device at address 0x%x", addr)
+	}
+	return d.Tx(w, r)
+}
+
+// SetSpeed implements i2c.Bus.
+func (s *Sim) SetSpeed(f physic.Frequency) error {
+	return nil
+}
+
+// SCL implements i2c.Pins.
+func (s *Sim) SCL() gpio.PinIO {
+	return gpio.INVALID
+}
+
+// SDA implements i2c.Pins.
+func (s *Sim) SDA() gpio.PinIO {
+	return gpio.INVALID
+}
+
+// SimDevice is a device simulated as a register map.
+//
+// It is used by Sim and by spitest.Sim.
+type SimDevice struct {
+	// Regs are the registers of the device. Accessing a register that is not
+	// in the map fails the transaction.
+	Regs map[uint8]*SimReg
+	// AutoIncrement moves the pointer to the next register after each
+	// register access, permitting burst reads and writes. Otherwise the same
+	// register is accessed repeatedly.
+	AutoIncrement bool
+	// PairedWrite makes writes a sequence of register and value pairs, like
+	// the Bosch sensors do. Reads are not affected.
+	PairedWrite bool
+	// Ptr is the register pointer.
+	Ptr uint8
+}
+
+// Tx accesses the registers of the device.
+//
+// The first byte written sets the register pointer; the following bytes are
+// written to the registers starting at the pointer, unless PairedWrite is
+// set. The bytes read come from the registers starting at the pointer.
+func (d *SimDevice) Tx(w, r []byte) error {
+	if d.PairedWrite && len(w) > 1 {
+		if len(w)&1 != 0 {
+			return conntest.Errorf("i2ctest: expected register and value pairs")
+		}
+		for i := 0; i < len(w); i += 2 {
+			d.Ptr = w[i]
+			if err := d.write(w[i+1 : i+2]); err != nil {
+				return err
+			}
+		}
+	} else if len(w) != 0 {
+		d.Ptr = w[0]
+		if err := d.write(w[1:]); err != nil {
+			return err
+		}
+	}
+	return d.read(r)
+}
+
+// SimReg is a register of a SimDevice.
+//
+// The callbacks are called with the Sim locked; they can modify any register
+// of the same device but must not use the Sim.
+type SimReg struct {
+	// Value is the content of the register; its length is the register width
+	// in bytes, generally in big endian order.
+	Value []byte
+	// ReadOnly registers ignore writes, like the hardware does.
+	ReadOnly bool
+	// ClearOnRead registers are reset to 0 after being read, like status
+	// registers.
+	ClearOnRead bool
+	// OnRead is called before the register is read. It can be used to
+	// update Value.
+	OnRead func()
+	// OnWrite is called after Value was written. It can be used to simulate
+	// side effects like starting a conversion.
+	OnWrite func()
+}
+
+// Uint16 returns Value as a big endian 16 bits value.
+func (r *SimReg) Uint16() uint16 {
+	return uint16(r.Value[0])<<8 | uint16(r.Value[1])
+}
+
+// SetUint16 sets Value as a big endian 16 bits value.
+func (r *SimReg) SetUint16(v uint16) {
+	r.Value = []byte{byte(v >> 8), byte(v)}
+}
+
+//
+
+func (d *SimDevice) reg() (*SimReg, error) {
+	r := d.Regs[d.Ptr]
+	if r == nil || len(r.Value) == 0 {
+		return nil, conntest.Errorf("i2ctest: no register 0x%02x", d.Ptr)
+	}
+	return r, nil
+}
+
+func (d *SimDevice) write(w []byte) error {
+	for len(w) != 0 {
+		r, err := d.reg()
+		if err != nil {
+			return err
+		}
+		n := len(r.Value)
+		if n > len(w) {
+			n = len(w)
+		}
+		if !r.ReadOnly {
+			copy(r.Value, w[:n])
+			if r.OnWrite != nil {
+				r.OnWrite()
+			}
+		}
+		w = w[n:]
+		if d.AutoIncrement {
+			d.Ptr++
+		}
+	}
+	return nil
+}
+
+func (d *SimDevice) read(b []byte) error {
+	for len(b) != 0 {
+		r, err := d.reg()
+		if err != nil {
+			return err
+		}
+		if r.OnRead != nil {
+			r.OnRead()
+		}
+		n := copy(b, r.Value)
+		if r.ClearOnRead {
+			for i := range r.Value {
+				r.Value[i] = 0
+			}
+		}
+		b = b[n:]
+		if d.AutoIncrement {
+			d.Ptr++
+		}
+	}
+	return nil
+}
+
+var _ i2c.BusCloser = &Sim{}
+var _ i2c.Pins = &Sim{}
@@ -0,0 +1,120 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package i2ctest
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSim(t *testing.T) {
+	reads := 0
+	dev := &SimDevice{
+		Regs: map[uint8]*SimReg{
+			0: {Value: []byte{0x12, 0x34}},
+			1: {Value: []byte{0xAB}, ReadOnly: true},
+			2: {Value: []byte{0x80}, ClearOnRead: true},
+			3: {Value: []byte{0, 0}},
+		},
+	}
+	dev.Regs[3].OnWrite = func() {
+		// Writing the config register starts a conversion.
+		dev.Regs[2].Value[0] = 0x80
+	}
+	dev.Regs[1].OnRead = func() {
+		reads++
+	}
+	s := Sim{Devices: map[uint16]*SimDevice{0x40: dev}}
+	if str := s.String(); str != "sim" {
+		t.Fatal(str)
+	}
+
+	r := make([]byte, 2)
+	if err := s.Tx(0x40, []byte{0}, r); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r, []byte{0x12, 0x34}) {
+		t.Fatal(r)
+	}
+	// Not auto-incremented; the same register is read again.
+	r = make([]byte, 4)
+	if err := s.Tx(0x40, nil, r); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r, []byte{0x12, 0x34, 0x12, 0x34}) {
+		t.Fatal(r)
+	}
+
+	if err := s.Tx(0x40, []byte{1, 0xCD}, nil); err != nil {
+		t.Fatal(err)
+	}
+	r = make([]byte, 1)
+	if err := s.Tx(0x40, []byte{1}, r); err != nil || r[0] != 0xAB || reads != 1 {
+		t.Fatal(r, err, reads)
+	}
+
+	if err := s.Tx(0x40, []byte{2}, r); err != nil || r[0] != 0x80 {
+		t.Fatal(r, err)
+	}
+	if err := s.Tx(0x40, []byte{2}, r); err != nil || r[0] != 0 {
+		t.Fatal(r, err)
+	}
+	if err := s.Tx(0x40, []byte{3, 0x55, 0xAA}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if v := dev.Regs[3].Uint16(); v != 0x55AA {
+		t.Fatalf("0x%x", v)
+	}
+	if err := s.Tx(0x40, []byte{2}, r); err != nil || r[0] != 0x80 {
+		t.Fatal(r, err)
+	}
+
+	dev.AutoIncrement = true
+	r = make([]byte, 3)
+	if err := s.Tx(0x40, []byte{0}, r); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r, []byte{0x12, 0x34, 0xAB}) || dev.Ptr != 2 {
+		t.Fatal(r, dev.Ptr)
+	}
+	dev.Regs[0].SetUint16(0x5678)
+	if err := s.Tx(0x40, []byte{2, 1, 2, 3}, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Register 2 was written then set by the OnWrite callback of register 3.
+	if dev.Regs[2].Value[0] != 0x80 || dev.Regs[3].Uint16() != 0x0203 {
+		t.Fatal(dev.Regs[2].Value, dev.Regs[3].Value)
+	}
+
+	dev.PairedWrite = true
+	if err := s.Tx(0x40, []byte{2, 0x11, 0, 0x22}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if dev.Regs[2].Value[0] != 0x11 || dev.Regs[0].Uint16() != 0x2278 {
+		t.Fatal(dev.Regs[2].Value, dev.Regs[0].Value)
+	}
+	if err := s.Tx(0x40, []byte{2, 0x11, 0}, nil); err == nil {
+		t.Fatal("odd length")
+	}
+	dev.PairedWrite = false
+
+	if err := s.Tx(0x40, []byte{4}, r); err == nil {
+		t.Fatal("no register 4")
+	}
+	if err := s.Tx(0x40, []byte{4, 1}, nil); err == nil {
+		t.Fatal("no register 4")
+	}
+	if err := s.Tx(0x41, nil, r); err == nil {
+		t.Fatal("no device at 0x41")
+	}
+	if err := s.SetSpeed(0); err != nil {
+		t.Fatal(err)
+	}
+	s.SCL()
+	s.SDA()
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package i2ctest
+
+import (
+	"io"
+
+	"github.com/meandrewdev/periph/conn/conntest"
+	"github.com/meandrewdev/periph/conn/i2c"
+)
+
+// WriteOps writes ops as a transcript of kind "i2c".
+//
+// See conntest.TranscriptVersion for the file format.
+func WriteOps(w io.Writer, ops []IO) error {
+	t, err := conntest.NewTranscriptWriter(w, "i2c")
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		j := ioJSON{Addr: op.Addr, W: op.W, R: op.R}
+		for _, m := range op.Msgs {
+			j.Msgs = append(j.Msgs, msgJSON{Addr: m.Addr, Flags: m.Flags, Buf: m.Buf})
+		}
+		if err := t.Write(&j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadOps reads a transcript of kind "i2c" written by WriteOps.
+//
+// The result can be used as Playback.Ops.
+func ReadOps(r io.Reader) ([]IO, error) {
+	t, err := conntest.NewTranscriptReader(r, "i2c")
+	if err != nil {
+		return nil, err
+	}
+	var ops []IO
+	for {
+		var j ioJSON
+		if err := t.Read(&j); err != nil {
+			if err == io.EOF {
+				return ops, nil
+			}
+			return nil, err
+		}
+		op := IO{Addr: j.Addr, W: j.W, R: j.R}
+		for _, m := range j.Msgs {
+			op.Msgs = append(op.Msgs, i2c.Msg{Addr: m.Addr, Flags: m.Flags, Buf: m.Buf})
+		}
+		ops = append(ops, op)
+	}
+}
+
+//
+
+type ioJSON struct {
+	Addr uint16       `json:"addr"`
+	W    conntest.Hex `json:"w,omitempty"`
+	R    conntest.Hex `json:"r,omitempty"`
+	Msgs []msgJSON    `json:"msgs,omitempty"`
+}
+
+type msgJSON struct {
+	Addr  uint16       `json:"addr"`
+	Flags i2c.Flags    `json:"flags,omitempty"`
+	Buf   conntest.Hex `json:"buf,omitempty"`
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package i2ctest
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/i2c"
+)
+
+func TestWriteOps_ReadOps(t *testing.T) {
+	ops := []IO{
+		{Addr: 0x76, W: []byte{0xD0}, R: []byte{0x60}},
+		{Addr: 0x76, W: []byte{0xF4, 0x6D}},
+		{Msgs: []i2c.Msg{{Addr: 0x50, Buf: []byte{0}}, {Addr: 0x50, Flags: i2c.Read, Buf: []byte{1, 2}}}},
+	}
+	var b bytes.Buffer
+	if err := WriteOps(&b, ops); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadOps(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, ops) {
+		t.Fatalf("%#v", got)
+	}
+
+	p := Playback{Ops: got}
+	d := i2c.Dev{Bus: &p, Addr: 0x76}
+	r := []byte{0}
+	if err := d.Tx([]byte{0xD0}, r); err != nil || r[0] != 0x60 {
+		t.Fatal(r, err)
+	}
+}
+
+func TestReadOps_invalid(t *testing.T) {
+	if _, err := ReadOps(strings.NewReader("{\"version\":1,\"kind\":\"spi\"}\n")); err == nil {
+		t.Fatal("wrong kind")
+	}
+	if _, err := ReadOps(strings.NewReader("{\"version\":1,\"kind\":\"i2c\"}\n{\"addr\":-1}\n")); err == nil {
+		t.Fatal("invalid address")
+	}
+}
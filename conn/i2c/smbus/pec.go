@@ -0,0 +1,29 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package smbus
+
+// CRC8 updates crc with the bytes in buf and returns it.
+//
+// This is the SMBus Packet Error Code; a CRC-8 with the polynomial
+// x^8 + x^2 + x + 1 (0x07), initialized to 0. The PEC of a transaction
+// covers every byte on the bus, including the address bytes.
+func CRC8(crc byte, buf []byte) byte {
+	for _, b := range buf {
+		crc = crc8(crc, b)
+	}
+	return crc
+}
+
+func crc8(crc, b byte) byte {
+	crc ^= b
+	for i := 0; i < 8; i++ {
+		if crc&0x80 != 0 {
+			crc = crc<<1 ^ 0x07
+		} else {
+			crc <<= 1
+		}
+	}
+	return crc
+}
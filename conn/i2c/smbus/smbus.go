@@ -0,0 +1,463 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package smbus implements the SMBus protocol on top of an I²C bus.
+//
+// SMBus is a subset of I²C with well defined transactions, e.g. reading a
+// byte or a word at a command (register), and optional Packet Error Checking
+// (PEC).
+//
+// When the bus implements Xferer and reports the protocol as supported, for
+// example a Linux i2c-dev bus on an SMBus only adapter, the transaction is
+// executed natively. Otherwise it is emulated via i2c.Bus.Tx().
+//
+// See http://smbus.org/specs/SMBus_3_1_20180319.pdf for more information.
+package smbus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/meandrewdev/periph/conn/i2c"
+)
+
+// BlockMax is the maximum number of bytes in a block transfer.
+const BlockMax = 32
+
+// Protocol is a SMBus transaction type.
+//
+// The values match the Linux I2C_SMBUS_xxx transaction types.
+type Protocol uint32
+
+// Supported transaction types.
+const (
+	Quick         Protocol = 0
+	Byte          Protocol = 1
+	ByteData      Protocol = 2
+	WordData      Protocol = 3
+	ProcCall      Protocol = 4
+	BlockData     Protocol = 5
+	BlockProcCall Protocol = 7
+	I2CBlockData  Protocol = 8
+)
+
+// Functionality is the bitmask of features supported by an adapter.
+//
+// The values match the Linux I2C_FUNC_xxx flags.
+type Functionality uint32
+
+// Adapter features.
+const (
+	FuncI2C              Functionality = 0x00000001 // Plain I²C transactions
+	Func10BitAddr        Functionality = 0x00000002
+	FuncProtocolMangling Functionality = 0x00000004
+	FuncPEC              Functionality = 0x00000008
+	FuncNoStart          Functionality = 0x00000010
+	FuncSlave            Functionality = 0x00000020
+	FuncBlockProcCall    Functionality = 0x00008000
+	FuncQuick            Functionality = 0x00010000
+	FuncReadByte         Functionality = 0x00020000
+	FuncWriteByte        Functionality = 0x00040000
+	FuncReadByteData     Functionality = 0x00080000
+	FuncWriteByteData    Functionality = 0x00100000
+	FuncReadWordData     Functionality = 0x00200000
+	FuncWriteWordData    Functionality = 0x00400000
+	FuncProcCall         Functionality = 0x00800000
+	FuncReadBlockData    Functionality = 0x01000000
+	FuncWriteBlockData   Functionality = 0x02000000
+	FuncReadI2CBlock     Functionality = 0x04000000
+	FuncWriteI2CBlock    Functionality = 0x08000000
+	FuncHostNotify       Functionality = 0x10000000
+)
+
+var funcNames = []struct {
+	f    Functionality
+	name string
+}{
+	{FuncI2C, "I2C"},
+	{Func10BitAddr, "10BitAddr"},
+	{FuncProtocolMangling, "ProtocolMangling"},
+	{FuncPEC, "PEC"},
+	{FuncNoStart, "NoStart"},
+	{FuncSlave, "Slave"},
+	{FuncBlockProcCall, "BlockProcCall"},
+	{FuncQuick, "Quick"},
+	{FuncReadByte, "ReadByte"},
+	{FuncWriteByte, "WriteByte"},
+	{FuncReadByteData, "ReadByteData"},
+	{FuncWriteByteData, "WriteByteData"},
+	{FuncReadWordData, "ReadWordData"},
+	{FuncWriteWordData, "WriteWordData"},
+	{FuncProcCall, "ProcCall"},
+	{FuncReadBlockData, "ReadBlockData"},
+	{FuncWriteBlockData, "WriteBlockData"},
+	{FuncReadI2CBlock, "ReadI2CBlock"},
+	{FuncWriteI2CBlock, "WriteI2CBlock"},
+	{FuncHostNotify, "HostNotify"},
+}
+
+func (f Functionality) String() string {
+	var out []string
+	for _, n := range funcNames {
+		if f&n.f != 0 {
+			out = append(out, n.name)
+			f &^= n.f
+		}
+	}
+	if f != 0 {
+		out = append(out, fmt.Sprintf("0x%x", uint32(f)))
+	}
+	if len(out) == 0 {
+		return "0"
+	}
+	return strings.Join(out, "|")
+}
+
+// Data is the buffer of a native SMBus transaction.
+//
+// It follows the layout of Linux's union i2c_smbus_data: the byte is in
+// Data[0], the word is little endian in Data[0:2] and for block transfers
+// Data[0] is the length followed by the bytes.
+type Data [BlockMax + 2]byte
+
+// Xferer is implemented by an i2c.Bus that supports native SMBus
+// transactions.
+type Xferer interface {
+	// Functionality returns the features supported by the adapter.
+	Functionality() Functionality
+	// SMBusXfer executes a single SMBus transaction.
+	//
+	// read is the direction; for Quick it is the value of the R/W bit. cmd is
+	// ignored for Quick and is the byte sent for a Byte write. When pec is
+	// true, the PEC byte is appended and verified by the adapter.
+	SMBusXfer(addr uint16, read bool, cmd byte, p Protocol, pec bool, d *Data) error
+}
+
+// Dev is a device on a SMBus.
+//
+// It uses native transactions when Bus implements Xferer and supports them,
+// otherwise transactions are emulated over Bus.Tx().
+type Dev struct {
+	Bus  i2c.Bus
+	Addr uint16
+	// PEC enables Packet Error Checking on all transactions.
+	PEC bool
+}
+
+func (d *Dev) String() string {
+	return fmt.Sprintf("%s(%d)", d.Bus, d.Addr)
+}
+
+// Quick sends the address with the R/W bit set to read.
+//
+// It is often used to probe for a device or to switch a simple device on or
+// off. It cannot be emulated over Tx().
+func (d *Dev) Quick(read bool) error {
+	var data Data
+	if ok, err := d.native(FuncQuick, read, 0, Quick, false, &data); ok {
+		return err
+	}
+	return errors.New("smbus: Quick command is not supported by this bus")
+}
+
+// ReceiveByte reads a byte without a command.
+func (d *Dev) ReceiveByte() (byte, error) {
+	var data Data
+	if ok, err := d.native(FuncReadByte, true, 0, Byte, d.PEC, &data); ok {
+		return data[0], err
+	}
+	var r [1]byte
+	if err := d.tx(nil, r[:]); err != nil {
+		return 0, err
+	}
+	return r[0], nil
+}
+
+// SendByte writes a byte without a command.
+func (d *Dev) SendByte(b byte) error {
+	var data Data
+	if ok, err := d.native(FuncWriteByte, false, b, Byte, d.PEC, &data); ok {
+		return err
+	}
+	return d.tx([]byte{b}, nil)
+}
+
+// ReadByteData reads a byte at a command.
+func (d *Dev) ReadByteData(cmd byte) (byte, error) {
+	var data Data
+	if ok, err := d.native(FuncReadByteData, true, cmd, ByteData, d.PEC, &data); ok {
+		return data[0], err
+	}
+	var r [1]byte
+	if err := d.tx([]byte{cmd}, r[:]); err != nil {
+		return 0, err
+	}
+	return r[0], nil
+}
+
+// WriteByteData writes a byte at a command.
+func (d *Dev) WriteByteData(cmd, v byte) error {
+	data := Data{v}
+	if ok, err := d.native(FuncWriteByteData, false, cmd, ByteData, d.PEC, &data); ok {
+		return err
+	}
+	return d.tx([]byte{cmd, v}, nil)
+}
+
+// ReadWordData reads a little endian word at a command.
+func (d *Dev) ReadWordData(cmd byte) (uint16, error) {
+	var data Data
+	if ok, err := d.native(FuncReadWordData, true, cmd, WordData, d.PEC, &data); ok {
+		return uint16(data[0]) | uint16(data[1])<<8, err
+	}
+	var r [2]byte
+	if err := d.tx([]byte{cmd}, r[:]); err != nil {
+		return 0, err
+	}
+	return uint16(r[0]) | uint16(r[1])<<8, nil
+}
+
+// WriteWordData writes a little endian word at a command.
+func (d *Dev) WriteWordData(cmd byte, v uint16) error {
+	data := Data{byte(v), byte(v >> 8)}
+	if ok, err := d.native(FuncWriteWordData, false, cmd, WordData, d.PEC, &data); ok {
+		return err
+	}
+	return d.tx([]byte{cmd, byte(v), byte(v >> 8)}, nil)
+}
+
+// ProcessCall writes a word at a command and reads back a word in the same
+// transaction.
+func (d *Dev) ProcessCall(cmd byte, v uint16) (uint16, error) {
+	data := Data{byte(v), byte(v >> 8)}
+	if ok, err := d.native(FuncProcCall, false, cmd, ProcCall, d.PEC, &data); ok {
+		return uint16(data[0]) | uint16(data[1])<<8, err
+	}
+	var r [2]byte
+	if err := d.tx([]byte{cmd, byte(v), byte(v >> 8)}, r[:]); err != nil {
+		return 0, err
+	}
+	return uint16(r[0]) | uint16(r[1])<<8, nil
+}
+
+// BlockRead reads a block of up to BlockMax bytes at a command. The device
+// sends the length first.
+//
+// When emulated over Tx(), the maximum length is read and the excess bytes
+// are discarded, as the length of a read can't be changed while it is
+// happening.
+func (d *Dev) BlockRead(cmd byte) ([]byte, error) {
+	var data Data
+	if ok, err := d.native(FuncReadBlockData, true, cmd, BlockData, d.PEC, &data); ok {
+		if err != nil {
+			return nil, err
+		}
+		return blockResult(&data)
+	}
+	r := make([]byte, 1+BlockMax+1)
+	if !d.PEC {
+		r = r[:1+BlockMax]
+	}
+	if err := d.txRaw([]byte{cmd}, r); err != nil {
+		return nil, err
+	}
+	l := int(r[0])
+	if l > BlockMax {
+		return nil, fmt.Errorf("smbus: invalid block length %d", l)
+	}
+	if d.PEC {
+		if err := d.checkPEC([]byte{cmd}, r[:1+l], r[1+l]); err != nil {
+			return nil, err
+		}
+	}
+	return r[1 : 1+l], nil
+}
+
+// BlockWrite writes a block of up to BlockMax bytes at a command. The length
+// is sent first.
+func (d *Dev) BlockWrite(cmd byte, b []byte) error {
+	if len(b) > BlockMax {
+		return fmt.Errorf("smbus: block of %d bytes is too long", len(b))
+	}
+	var data Data
+	data[0] = byte(len(b))
+	copy(data[1:], b)
+	if ok, err := d.native(FuncWriteBlockData, false, cmd, BlockData, d.PEC, &data); ok {
+		return err
+	}
+	return d.tx(append([]byte{cmd, byte(len(b))}, b...), nil)
+}
+
+// BlockProcessCall writes a block at a command and reads back a block in the
+// same transaction. The total length must not exceed BlockMax+1 bytes.
+func (d *Dev) BlockProcessCall(cmd byte, w []byte) ([]byte, error) {
+	if len(w) > BlockMax {
+		return nil, fmt.Errorf("smbus: block of %d bytes is too long", len(w))
+	}
+	var data Data
+	data[0] = byte(len(w))
+	copy(data[1:], w)
+	if ok, err := d.native(FuncBlockProcCall, false, cmd, BlockProcCall, d.PEC, &data); ok {
+		if err != nil {
+			return nil, err
+		}
+		return blockResult(&data)
+	}
+	wb := append([]byte{cmd, byte(len(w))}, w...)
+	r := make([]byte, 1+BlockMax+1)
+	if !d.PEC {
+		r = r[:1+BlockMax]
+	}
+	if err := d.txRaw(wb, r); err != nil {
+		return nil, err
+	}
+	l := int(r[0])
+	if l > BlockMax {
+		return nil, fmt.Errorf("smbus: invalid block length %d", l)
+	}
+	if d.PEC {
+		if err := d.checkPEC(wb, r[:1+l], r[1+l]); err != nil {
+			return nil, err
+		}
+	}
+	return r[1 : 1+l], nil
+}
+
+// ReadI2CBlock reads len(b) bytes at a command, without a length byte.
+//
+// This is not part of the SMBus specification but is supported by most
+// adapters.
+func (d *Dev) ReadI2CBlock(cmd byte, b []byte) error {
+	if len(b) > BlockMax {
+		return fmt.Errorf("smbus: block of %d bytes is too long", len(b))
+	}
+	data := Data{byte(len(b))}
+	if ok, err := d.native(FuncReadI2CBlock, true, cmd, I2CBlockData, false, &data); ok {
+		copy(b, data[1:1+len(b)])
+		return err
+	}
+	return d.Bus.Tx(d.Addr, []byte{cmd}, b)
+}
+
+// WriteI2CBlock writes len(b) bytes at a command, without a length byte.
+//
+// This is not part of the SMBus specification but is supported by most
+// adapters.
+func (d *Dev) WriteI2CBlock(cmd byte, b []byte) error {
+	if len(b) > BlockMax {
+		return fmt.Errorf("smbus: block of %d bytes is too long", len(b))
+	}
+	data := Data{byte(len(b))}
+	copy(data[1:], b)
+	if ok, err := d.native(FuncWriteI2CBlock, false, cmd, I2CBlockData, false, &data); ok {
+		return err
+	}
+	return d.Bus.Tx(d.Addr, append([]byte{cmd}, b...), nil)
+}
+
+// HostNotifyAddr is the address of the SMBus host when a device notifies it.
+const HostNotifyAddr = 0x08
+
+// HostNotify is a Host Notify message; a device acts as a bus master and
+// writes its own address and a status word to the host at HostNotifyAddr.
+type HostNotify struct {
+	Addr uint16 // Address of the device that sent the notification
+	Data uint16
+}
+
+// ParseHostNotify decodes the bytes received at HostNotifyAddr.
+func ParseHostNotify(b []byte) (HostNotify, error) {
+	if len(b) != 3 {
+		return HostNotify{}, fmt.Errorf("smbus: invalid Host Notify message length %d", len(b))
+	}
+	return HostNotify{Addr: uint16(b[0] >> 1), Data: uint16(b[1]) | uint16(b[2])<<8}, nil
+}
+
+// Notify sends a Host Notify message with d.Addr as the device address.
+//
+// This is used when the host acts as a SMBus device notifying another host on
+// the bus.
+func (d *Dev) Notify(data uint16) error {
+	return d.Bus.Tx(HostNotifyAddr, []byte{byte(d.Addr << 1), byte(data), byte(data >> 8)}, nil)
+}
+
+//
+
+// native executes the transaction natively if supported. It returns false if
+// the transaction must be emulated.
+func (d *Dev) native(f Functionality, read bool, cmd byte, p Protocol, pec bool, data *Data) (bool, error) {
+	x, ok := d.Bus.(Xferer)
+	if !ok {
+		return false, nil
+	}
+	fn := x.Functionality()
+	if fn&f == 0 || (pec && fn&FuncPEC == 0) {
+		return false, nil
+	}
+	if err := x.SMBusXfer(d.Addr, read, cmd, p, pec, data); err != nil {
+		return true, fmt.Errorf("smbus: %v", err)
+	}
+	return true, nil
+}
+
+// tx emulates a transaction over Tx(), adding and verifying the PEC byte as
+// needed.
+func (d *Dev) tx(w, r []byte) error {
+	if !d.PEC {
+		return d.txRaw(w, r)
+	}
+	if len(r) == 0 {
+		return d.txRaw(append(w, d.pec(w, nil)), nil)
+	}
+	rb := make([]byte, len(r)+1)
+	if err := d.txRaw(w, rb); err != nil {
+		return err
+	}
+	if err := d.checkPEC(w, rb[:len(r)], rb[len(r)]); err != nil {
+		return err
+	}
+	copy(r, rb)
+	return nil
+}
+
+func (d *Dev) txRaw(w, r []byte) error {
+	if err := d.Bus.Tx(d.Addr, w, r); err != nil {
+		return fmt.Errorf("smbus: %v", err)
+	}
+	return nil
+}
+
+// pec calculates the PEC of a transaction, including the address bytes.
+func (d *Dev) pec(w, r []byte) byte {
+	a := byte(d.Addr << 1)
+	c := byte(0)
+	if len(w) != 0 {
+		c = crc8(c, a)
+		c = CRC8(c, w)
+	}
+	if len(r) != 0 {
+		c = crc8(c, a|1)
+		c = CRC8(c, r)
+	}
+	return c
+}
+
+func (d *Dev) checkPEC(w, r []byte, pec byte) error {
+	if e := d.pec(w, r); e != pec {
+		return fmt.Errorf("smbus: PEC mismatch; got 0x%02x, expected 0x%02x", pec, e)
+	}
+	return nil
+}
+
+// blockResult returns the bytes of a native block read.
+func blockResult(d *Data) ([]byte, error) {
+	l := int(d[0])
+	if l > BlockMax {
+		return nil, fmt.Errorf("smbus: invalid block length %d", l)
+	}
+	out := make([]byte, l)
+	copy(out, d[1:])
+	return out, nil
+}
@@ -0,0 +1,265 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package smbus
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/i2c/i2ctest"
+)
+
+func TestCRC8(t *testing.T) {
+	// Check value of CRC-8/SMBUS.
+	if c := CRC8(0, []byte("123456789")); c != 0xF4 {
+		t.Fatalf("0x%02x", c)
+	}
+}
+
+func TestFunctionality_String(t *testing.T) {
+	if s := (FuncI2C | FuncPEC | FuncHostNotify).String(); s != "I2C|PEC|HostNotify" {
+		t.Fatal(s)
+	}
+	if s := Functionality(0x40).String(); s != "0x40" {
+		t.Fatal(s)
+	}
+	if s := Functionality(0).String(); s != "0" {
+		t.Fatal(s)
+	}
+}
+
+func TestDev_emulated(t *testing.T) {
+	block := make([]byte, 1+BlockMax)
+	block[0] = 3
+	copy(block[1:], []byte{7, 8, 9})
+	bus := i2ctest.Playback{
+		Ops: []i2ctest.IO{
+			{Addr: 0x10, R: []byte{0x42}},
+			{Addr: 0x10, W: []byte{0x43}},
+			{Addr: 0x10, W: []byte{0x01}, R: []byte{0x44}},
+			{Addr: 0x10, W: []byte{0x02, 0x45}},
+			{Addr: 0x10, W: []byte{0x03}, R: []byte{0x34, 0x12}},
+			{Addr: 0x10, W: []byte{0x04, 0x78, 0x56}},
+			{Addr: 0x10, W: []byte{0x05, 0x01, 0x00}, R: []byte{0x02, 0x00}},
+			{Addr: 0x10, W: []byte{0x06}, R: block},
+			{Addr: 0x10, W: []byte{0x07, 0x02, 0x01, 0x02}},
+			{Addr: 0x10, W: []byte{0x08, 0x01, 0x05}, R: block},
+			{Addr: 0x10, W: []byte{0x09}, R: []byte{1, 2}},
+			{Addr: 0x10, W: []byte{0x0A, 1, 2}},
+			{Addr: HostNotifyAddr, W: []byte{0x20, 0xCD, 0xAB}},
+		},
+	}
+	d := Dev{Bus: &bus, Addr: 0x10}
+	if s := d.String(); s != "playback(16)" {
+		t.Fatal(s)
+	}
+	if v, err := d.ReceiveByte(); err != nil || v != 0x42 {
+		t.Fatal(v, err)
+	}
+	if err := d.SendByte(0x43); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.ReadByteData(1); err != nil || v != 0x44 {
+		t.Fatal(v, err)
+	}
+	if err := d.WriteByteData(2, 0x45); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.ReadWordData(3); err != nil || v != 0x1234 {
+		t.Fatal(v, err)
+	}
+	if err := d.WriteWordData(4, 0x5678); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.ProcessCall(5, 1); err != nil || v != 2 {
+		t.Fatal(v, err)
+	}
+	if b, err := d.BlockRead(6); err != nil || !bytes.Equal(b, []byte{7, 8, 9}) {
+		t.Fatal(b, err)
+	}
+	if err := d.BlockWrite(7, []byte{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := d.BlockProcessCall(8, []byte{5}); err != nil || !bytes.Equal(b, []byte{7, 8, 9}) {
+		t.Fatal(b, err)
+	}
+	b := make([]byte, 2)
+	if err := d.ReadI2CBlock(9, b); err != nil || !bytes.Equal(b, []byte{1, 2}) {
+		t.Fatal(b, err)
+	}
+	if err := d.WriteI2CBlock(10, []byte{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Notify(0xABCD); err != nil {
+		t.Fatal(err)
+	}
+	if d.Quick(false) == nil {
+		t.Fatal("Quick can't be emulated")
+	}
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDev_emulated_errors(t *testing.T) {
+	bus := i2ctest.Playback{
+		Ops: []i2ctest.IO{
+			{Addr: 0x10, W: []byte{0x06}, R: append([]byte{BlockMax + 1}, make([]byte, BlockMax)...)},
+		},
+		DontPanic: true,
+	}
+	d := Dev{Bus: &bus, Addr: 0x10}
+	if _, err := d.BlockRead(6); err == nil {
+		t.Fatal("invalid length")
+	}
+	if d.BlockWrite(7, make([]byte, BlockMax+1)) == nil {
+		t.Fatal("block too long")
+	}
+	if _, err := d.BlockProcessCall(8, make([]byte, BlockMax+1)); err == nil {
+		t.Fatal("block too long")
+	}
+	if d.ReadI2CBlock(9, make([]byte, BlockMax+1)) == nil {
+		t.Fatal("block too long")
+	}
+	if d.WriteI2CBlock(9, make([]byte, BlockMax+1)) == nil {
+		t.Fatal("block too long")
+	}
+	if _, err := d.ReadByteData(1); err == nil {
+		t.Fatal("playback is empty")
+	}
+}
+
+func TestDev_PEC(t *testing.T) {
+	// Write: addr<<1, cmd, data.
+	wPEC := CRC8(0, []byte{0x20, 0x02, 0x45})
+	// Read: addr<<1, cmd, addr<<1|1, data.
+	rPEC := CRC8(0, []byte{0x20, 0x03, 0x21, 0x34, 0x12})
+	block := make([]byte, 1+BlockMax+1)
+	block[0] = 1
+	block[1] = 9
+	block[2] = CRC8(0, []byte{0x20, 0x06, 0x21, 0x01, 0x09})
+	bus := i2ctest.Playback{
+		Ops: []i2ctest.IO{
+			{Addr: 0x10, W: []byte{0x02, 0x45, wPEC}},
+			{Addr: 0x10, W: []byte{0x03}, R: []byte{0x34, 0x12, rPEC}},
+			{Addr: 0x10, W: []byte{0x06}, R: block},
+			{Addr: 0x10, W: []byte{0x03}, R: []byte{0x34, 0x12, rPEC ^ 1}},
+		},
+	}
+	d := Dev{Bus: &bus, Addr: 0x10, PEC: true}
+	if err := d.WriteByteData(2, 0x45); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.ReadWordData(3); err != nil || v != 0x1234 {
+		t.Fatal(v, err)
+	}
+	if b, err := d.BlockRead(6); err != nil || !bytes.Equal(b, []byte{9}) {
+		t.Fatal(b, err)
+	}
+	if _, err := d.ReadWordData(3); err == nil {
+		t.Fatal("PEC mismatch")
+	}
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDev_native(t *testing.T) {
+	x := &fakeXferer{fn: FuncQuick | FuncReadWordData | FuncWriteBlockData | FuncReadBlockData}
+	d := Dev{Bus: x, Addr: 0x10}
+	if err := d.Quick(true); err != nil {
+		t.Fatal(err)
+	}
+	if x.last.p != Quick || !x.last.read {
+		t.Fatalf("%#v", x.last)
+	}
+	x.data = Data{0x34, 0x12}
+	if v, err := d.ReadWordData(3); err != nil || v != 0x1234 {
+		t.Fatal(v, err)
+	}
+	if x.last.p != WordData || x.last.cmd != 3 {
+		t.Fatalf("%#v", x.last)
+	}
+	if err := d.BlockWrite(4, []byte{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if x.last.p != BlockData || x.last.read || x.data[0] != 2 || x.data[2] != 2 {
+		t.Fatalf("%#v", x.last)
+	}
+	x.data = Data{2, 5, 6}
+	if b, err := d.BlockRead(5); err != nil || !bytes.Equal(b, []byte{5, 6}) {
+		t.Fatal(b, err)
+	}
+	x.data = Data{BlockMax + 1}
+	if _, err := d.BlockRead(5); err == nil {
+		t.Fatal("invalid length")
+	}
+	x.err = errors.New("oops")
+	if _, err := d.ReadWordData(3); err == nil {
+		t.Fatal("failed xfer")
+	}
+
+	// PEC is not supported natively so it falls back to Tx().
+	d.PEC = true
+	x.err = nil
+	if _, err := d.ReadWordData(3); err == nil || !x.tx {
+		t.Fatal("expected Tx() fallback")
+	}
+}
+
+func TestParseHostNotify(t *testing.T) {
+	n, err := ParseHostNotify([]byte{0x20, 0xCD, 0xAB})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Addr != 0x10 || n.Data != 0xABCD {
+		t.Fatalf("%#v", n)
+	}
+	if _, err := ParseHostNotify([]byte{0x20}); err == nil {
+		t.Fatal("too short")
+	}
+}
+
+//
+
+type xfer struct {
+	addr uint16
+	read bool
+	cmd  byte
+	p    Protocol
+	pec  bool
+}
+
+type fakeXferer struct {
+	i2ctest.Playback
+	fn   Functionality
+	last xfer
+	data Data
+	err  error
+	tx   bool
+}
+
+func (f *fakeXferer) Tx(addr uint16, w, r []byte) error {
+	f.tx = true
+	return errors.New("unexpected Tx()")
+}
+
+func (f *fakeXferer) Functionality() Functionality {
+	return f.fn
+}
+
+func (f *fakeXferer) SMBusXfer(addr uint16, read bool, cmd byte, p Protocol, pec bool, d *Data) error {
+	f.last = xfer{addr, read, cmd, p, pec}
+	if f.err != nil {
+		return f.err
+	}
+	if read {
+		*d = f.data
+	} else {
+		f.data = *d
+	}
+	return nil
+}
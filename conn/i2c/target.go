@@ -0,0 +1,32 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package i2c
+
+// TargetHandler handles the transactions addressed to a Target.
+//
+// The methods are called sequentially from the Target's goroutine and must
+// not block, as the controller is stalled or served stale data meanwhile.
+type TargetHandler interface {
+	// Write is called with the bytes written by the controller, once the
+	// write message ended with a stop or a repeated start.
+	Write(w []byte)
+	// Read is called when the controller starts reading from the target. It
+	// returns the bytes to send; 0xFF is sent once they are exhausted.
+	Read() []byte
+}
+
+// Target is implemented by a bus that can act as an I²C target, what the
+// specification used to call a slave, answering to a controller.
+//
+// Backends may support a single address at a time, may have limited buffering
+// or may serve reads ahead of time; see the documentation of each
+// implementation.
+type Target interface {
+	String() string
+	// Register starts answering the transactions at addr by calling h.
+	Register(addr uint16, h TargetHandler) error
+	// Unregister stops answering at addr.
+	Unregister(addr uint16) error
+}
@@ -2,10 +2,171 @@
 // Use of this source code is governed under the Apache License, Version 2.0
 // that can be found in the LICENSE file.
 
-// Package i2s will eventually define the API to communicate with devices over
-// the I²S protocol.
+// Package i2s defines the API to communicate with devices over the I²S
+// protocol.
 //
-// The protocol is meant to transfer audio.
+// The protocol is meant to transfer audio. Samples are streamed as frames,
+// each frame containing one sample per channel.
+//
+// As described in https://github.com/meandrewdev/periph/conn#hdr-Concepts, periph.io uses
+// the concepts of Bus, Port and Conn.
+//
+// In the package i2s, 'Bus' is not exposed, as the protocol is point-to-point.
+//
+// Use Port.Connect() converts the uninitialized Port into a Conn.
 //
 // See https://en.wikipedia.org/wiki/I%C2%B2S for more information.
 package i2s
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+// Framing determines the position of the data bits relative to the word
+// select signal.
+type Framing uint8
+
+const (
+	// Philips is the standard I²S framing; WS is low for the left channel and
+	// the most significant bit is sent one clock after WS changes.
+	Philips Framing = 0
+	// LeftJustified sends the most significant bit as WS changes; WS is high
+	// for the left channel.
+	LeftJustified Framing = 1
+	// RightJustified aligns the least significant bit at the end of the slot;
+	// WS is high for the left channel.
+	RightJustified Framing = 2
+	// DSP uses a one clock wide frame sync pulse followed by the channels back
+	// to back, also known as PCM short frame or DSP mode A.
+	DSP Framing = 3
+)
+
+const framingName = "PhilipsLeftJustifiedRightJustifiedDSP"
+
+var framingIndex = [...]uint8{0, 7, 20, 34, 37}
+
+func (i Framing) String() string {
+	if i >= Framing(len(framingIndex)-1) {
+		return "Framing(" + strconv.Itoa(int(i)) + ")"
+	}
+	return framingName[framingIndex[i]:framingIndex[i+1]]
+}
+
+// Config is the configuration of an I²S stream.
+type Config struct {
+	// Rate is the number of frames per second, e.g. 44.1kHz or 48kHz.
+	Rate physic.Frequency
+	// Bits is the number of significant bits per sample; between 8 and 32.
+	Bits int
+	// Channels is the number of samples per frame; generally 1 or 2.
+	Channels int
+	// Framing is the position of the data bits relative to the word select.
+	Framing Framing
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c *Config) Validate() error {
+	if c.Rate <= 0 {
+		return fmt.Errorf("i2s: invalid rate %s", c.Rate)
+	}
+	if c.Bits < 8 || c.Bits > 32 {
+		return fmt.Errorf("i2s: invalid bits %d; must be between 8 and 32", c.Bits)
+	}
+	if c.Channels < 1 {
+		return fmt.Errorf("i2s: invalid channels %d", c.Channels)
+	}
+	if c.Framing > DSP {
+		return fmt.Errorf("i2s: invalid framing %s", c.Framing)
+	}
+	return nil
+}
+
+// SampleSize returns the number of bytes used to store one sample.
+//
+// Samples are signed little endian integers, padded to 1, 2 or 4 bytes. For
+// example a 24 bits sample is stored in 4 bytes, the most significant byte
+// being the sign extension.
+func (c *Config) SampleSize() int {
+	switch {
+	case c.Bits <= 8:
+		return 1
+	case c.Bits <= 16:
+		return 2
+	default:
+		return 4
+	}
+}
+
+// FrameSize returns the number of bytes used to store one frame.
+func (c *Config) FrameSize() int {
+	return c.SampleSize() * c.Channels
+}
+
+func (c *Config) String() string {
+	return fmt.Sprintf("%s %dbits %dch %s", c.Rate, c.Bits, c.Channels, c.Framing)
+}
+
+// ErrNotSupported is returned by Read() or Write() when the connection
+// doesn't support this direction.
+var ErrNotSupported = errors.New("i2s: direction not supported")
+
+// Conn defines the interface a concrete I²S driver must implement.
+//
+// Read() captures frames and Write() plays back frames. The length of the
+// buffers must be a multiple of Config().FrameSize(). A Conn may only support
+// one direction, in which case the other returns ErrNotSupported.
+//
+// Halt() stops the stream and discards the frames that were buffered. The
+// stream restarts on the next Read() or Write().
+//
+// Implementers can optionally implement Pins.
+type Conn interface {
+	conn.Resource
+	io.Reader
+	io.Writer
+	// Config returns the configuration in use, which may differ from the one
+	// requested at Connect(), e.g. if the hardware only supports a nearby rate.
+	Config() Config
+}
+
+// Port is the interface to be provided to device drivers.
+//
+// The device driver, that is the driver for the peripheral connected over
+// this port, calls Connect() to retrieve a configured connection as Conn.
+type Port interface {
+	String() string
+	// Connect sets the stream parameters for use by a device.
+	//
+	// The device driver must call this function exactly once.
+	Connect(c *Config) (Conn, error)
+}
+
+// PortCloser is an I²S port that can be closed.
+//
+// This interface is meant to be handled by the application.
+type PortCloser interface {
+	io.Closer
+	Port
+}
+
+// Pins defines the pins that an I²S port interconnect is using on the host.
+//
+// It is expected that a implementer of Conn also implement Pins but this is
+// not a requirement.
+type Pins interface {
+	// SCK returns the bit clock pin.
+	SCK() gpio.PinIO
+	// WS returns the word select pin.
+	WS() gpio.PinIO
+	// IN returns the data input pin.
+	IN() gpio.PinIn
+	// OUT returns the data output pin.
+	OUT() gpio.PinOut
+}
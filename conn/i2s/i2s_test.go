@@ -0,0 +1,62 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package i2s
+
+import (
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+func TestFraming_String(t *testing.T) {
+	if s := Philips.String(); s != "Philips" {
+		t.Fatal(s)
+	}
+	if s := DSP.String(); s != "DSP" {
+		t.Fatal(s)
+	}
+	if s := Framing(10).String(); s != "Framing(10)" {
+		t.Fatal(s)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	c := Config{Rate: 48 * physic.KiloHertz, Bits: 24, Channels: 2}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if s := c.SampleSize(); s != 4 {
+		t.Fatal(s)
+	}
+	if s := c.FrameSize(); s != 8 {
+		t.Fatal(s)
+	}
+	if s := c.String(); s != "48kHz 24bits 2ch Philips" {
+		t.Fatal(s)
+	}
+	c.Bits = 16
+	if s := c.FrameSize(); s != 4 {
+		t.Fatal(s)
+	}
+	c.Bits = 8
+	if s := c.FrameSize(); s != 2 {
+		t.Fatal(s)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	data := []Config{
+		{Bits: 16, Channels: 2},
+		{Rate: physic.KiloHertz, Bits: 7, Channels: 2},
+		{Rate: physic.KiloHertz, Bits: 33, Channels: 2},
+		{Rate: physic.KiloHertz, Bits: 16},
+		{Rate: physic.KiloHertz, Bits: 16, Channels: 2, Framing: 4},
+	}
+	for i, c := range data {
+		if c.Validate() == nil {
+			t.Fatalf("#%d: expected error", i)
+		}
+	}
+}
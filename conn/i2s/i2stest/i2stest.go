@@ -0,0 +1,310 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package i2stest is meant to be used to test drivers over a fake I²S port.
+package i2stest
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/meandrewdev/periph/conn/conntest"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/i2s"
+)
+
+// IO registers the frames streamed on either a real or fake I²S port.
+//
+// Only one of W or R is set.
+type IO struct {
+	W []byte // Frames played back
+	R []byte // Frames captured
+}
+
+// Record implements i2s.PortCloser that records everything streamed through
+// it.
+//
+// This can then be used to feed to Playback to do "replay" based unit tests.
+type Record struct {
+	sync.Mutex
+	Port i2s.Port // Port can be nil if only writes are being recorded.
+	Ops  []IO
+
+	initialized bool
+	cfg         i2s.Config
+	c           i2s.Conn
+}
+
+func (r *Record) String() string {
+	return "record"
+}
+
+// Close implements i2s.PortCloser.
+func (r *Record) Close() error {
+	r.Lock()
+	defer r.Unlock()
+	if c, ok := r.Port.(i2s.PortCloser); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Connect implements i2s.Port.
+func (r *Record) Connect(c *i2s.Config) (i2s.Conn, error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.initialized {
+		return nil, conntest.Errorf("i2stest: Connect cannot be called twice")
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	r.cfg = *c
+	if r.Port != nil {
+		conn, err := r.Port.Connect(c)
+		if err != nil {
+			return nil, err
+		}
+		r.c = conn
+		r.cfg = conn.Config()
+	}
+	r.initialized = true
+	return &recordConn{r}, nil
+}
+
+// SCK implements i2s.Pins.
+func (r *Record) SCK() gpio.PinIO {
+	if p, ok := r.Port.(i2s.Pins); ok {
+		return p.SCK()
+	}
+	return gpio.INVALID
+}
+
+// WS implements i2s.Pins.
+func (r *Record) WS() gpio.PinIO {
+	if p, ok := r.Port.(i2s.Pins); ok {
+		return p.WS()
+	}
+	return gpio.INVALID
+}
+
+// IN implements i2s.Pins.
+func (r *Record) IN() gpio.PinIn {
+	if p, ok := r.Port.(i2s.Pins); ok {
+		return p.IN()
+	}
+	return gpio.INVALID
+}
+
+// OUT implements i2s.Pins.
+func (r *Record) OUT() gpio.PinOut {
+	if p, ok := r.Port.(i2s.Pins); ok {
+		return p.OUT()
+	}
+	return gpio.INVALID
+}
+
+type recordConn struct {
+	r *Record
+}
+
+func (r *recordConn) String() string {
+	return r.r.String()
+}
+
+func (r *recordConn) Halt() error {
+	r.r.Lock()
+	defer r.r.Unlock()
+	if r.r.c != nil {
+		return r.r.c.Halt()
+	}
+	return nil
+}
+
+func (r *recordConn) Config() i2s.Config {
+	r.r.Lock()
+	defer r.r.Unlock()
+	return r.r.cfg
+}
+
+func (r *recordConn) Read(b []byte) (int, error) {
+	r.r.Lock()
+	defer r.r.Unlock()
+	if r.r.c == nil {
+		return 0, conntest.Errorf("i2stest: read unsupported when no port is connected")
+	}
+	n, err := r.r.c.Read(b)
+	if n != 0 {
+		io := IO{R: make([]byte, n)}
+		copy(io.R, b)
+		r.r.Ops = append(r.r.Ops, io)
+	}
+	return n, err
+}
+
+func (r *recordConn) Write(b []byte) (int, error) {
+	r.r.Lock()
+	defer r.r.Unlock()
+	n := len(b)
+	var err error
+	if r.r.c != nil {
+		n, err = r.r.c.Write(b)
+	}
+	if n != 0 {
+		io := IO{W: make([]byte, n)}
+		copy(io.W, b)
+		r.r.Ops = append(r.r.Ops, io)
+	}
+	return n, err
+}
+
+// Playback implements i2s.PortCloser and plays back a recorded I/O flow.
+//
+// While "replay" type of unit tests are of limited value, they still present
+// an easy way to do basic code coverage.
+//
+// Set DontPanic to true to return an error instead of panicking, which is the
+// default.
+type Playback struct {
+	sync.Mutex
+	Ops       []IO
+	Count     int
+	DontPanic bool
+	// Cfg, if its Rate is not 0, is the configuration expected at Connect().
+	Cfg    i2s.Config
+	SCKPin gpio.PinIO
+	WSPin  gpio.PinIO
+	INPin  gpio.PinIn
+	OUTPin gpio.PinOut
+	// Halted is the number of times Halt() was called.
+	Halted int
+
+	initialized bool
+}
+
+func (p *Playback) String() string {
+	return "playback"
+}
+
+// Close implements i2s.PortCloser.
+//
+// Close() verifies that all the expected Ops have been consumed.
+func (p *Playback) Close() error {
+	p.Lock()
+	defer p.Unlock()
+	if len(p.Ops) != p.Count {
+		return errorf(p.DontPanic, "i2stest: expected playback to be empty: I/O count %d; expected %d", p.Count, len(p.Ops))
+	}
+	return nil
+}
+
+// Connect implements i2s.Port.
+func (p *Playback) Connect(c *i2s.Config) (i2s.Conn, error) {
+	p.Lock()
+	defer p.Unlock()
+	if p.initialized {
+		return nil, errorf(p.DontPanic, "i2stest: Connect cannot be called twice")
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if p.Cfg.Rate != 0 && p.Cfg != *c {
+		return nil, errorf(p.DontPanic, "i2stest: unexpected config %s; expected %s", c, &p.Cfg)
+	}
+	p.Cfg = *c
+	p.initialized = true
+	return &playbackConn{p}, nil
+}
+
+// SCK implements i2s.Pins.
+func (p *Playback) SCK() gpio.PinIO {
+	return p.SCKPin
+}
+
+// WS implements i2s.Pins.
+func (p *Playback) WS() gpio.PinIO {
+	return p.WSPin
+}
+
+// IN implements i2s.Pins.
+func (p *Playback) IN() gpio.PinIn {
+	return p.INPin
+}
+
+// OUT implements i2s.Pins.
+func (p *Playback) OUT() gpio.PinOut {
+	return p.OUTPin
+}
+
+type playbackConn struct {
+	p *Playback
+}
+
+func (p *playbackConn) String() string {
+	return p.p.String()
+}
+
+func (p *playbackConn) Halt() error {
+	p.p.Lock()
+	defer p.p.Unlock()
+	p.p.Halted++
+	return nil
+}
+
+func (p *playbackConn) Config() i2s.Config {
+	p.p.Lock()
+	defer p.p.Unlock()
+	return p.p.Cfg
+}
+
+func (p *playbackConn) Read(b []byte) (int, error) {
+	p.p.Lock()
+	defer p.p.Unlock()
+	if len(p.p.Ops) <= p.p.Count {
+		return 0, errorf(p.p.DontPanic, "i2stest: unexpected Read() (count #%d) of %d bytes", p.p.Count, len(b))
+	}
+	op := p.p.Ops[p.p.Count]
+	if op.W != nil {
+		return 0, errorf(p.p.DontPanic, "i2stest: unexpected Read() (count #%d); expected Write(%#v)", p.p.Count, op.W)
+	}
+	if len(op.R) != len(b) {
+		return 0, errorf(p.p.DontPanic, "i2stest: unexpected read buffer length (count #%d) %d != %d", p.p.Count, len(b), len(op.R))
+	}
+	copy(b, op.R)
+	p.p.Count++
+	return len(b), nil
+}
+
+func (p *playbackConn) Write(b []byte) (int, error) {
+	p.p.Lock()
+	defer p.p.Unlock()
+	if len(p.p.Ops) <= p.p.Count {
+		return 0, errorf(p.p.DontPanic, "i2stest: unexpected Write() (count #%d) %#v", p.p.Count, b)
+	}
+	if !bytes.Equal(p.p.Ops[p.p.Count].W, b) {
+		return 0, errorf(p.p.DontPanic, "i2stest: unexpected write (count #%d) %#v != %#v", p.p.Count, b, p.p.Ops[p.p.Count].W)
+	}
+	p.p.Count++
+	return len(b), nil
+}
+
+//
+
+// errorf is the internal implementation that optionally panic.
+//
+// If dontPanic is false, it panics instead.
+func errorf(dontPanic bool, format string, a ...interface{}) error {
+	err := conntest.Errorf(format, a...)
+	if !dontPanic {
+		panic(err)
+	}
+	return err
+}
+
+var _ i2s.PortCloser = &Record{}
+var _ i2s.Pins = &Record{}
+var _ i2s.Conn = &recordConn{}
+var _ i2s.PortCloser = &Playback{}
+var _ i2s.Pins = &Playback{}
+var _ i2s.Conn = &playbackConn{}
@@ -0,0 +1,215 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package i2stest
+
+import (
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/conntest"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/gpio/gpiotest"
+	"github.com/meandrewdev/periph/conn/i2s"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+var cfg = i2s.Config{Rate: 48 * physic.KiloHertz, Bits: 16, Channels: 2}
+
+func TestRecord_empty(t *testing.T) {
+	r := Record{}
+	if s := r.String(); s != "record" {
+		t.Fatal(s)
+	}
+	if s := r.SCK(); s != gpio.INVALID {
+		t.Fatal(s)
+	}
+	if s := r.WS(); s != gpio.INVALID {
+		t.Fatal(s)
+	}
+	if s := r.IN(); s != gpio.INVALID {
+		t.Fatal(s)
+	}
+	if s := r.OUT(); s != gpio.INVALID {
+		t.Fatal(s)
+	}
+	if _, err := r.Connect(&i2s.Config{}); err == nil {
+		t.Fatal("invalid config")
+	}
+	c, err := r.Connect(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Connect(&cfg); err == nil {
+		t.Fatal("second Connect() must fail")
+	}
+	if c.Config() != cfg {
+		t.Fatal(c.Config())
+	}
+	if n, err := c.Write([]byte{1, 2, 3, 4}); n != 4 || err != nil {
+		t.Fatal(n, err)
+	}
+	if _, err := c.Read(make([]byte, 4)); err == nil {
+		t.Fatal("Port is nil")
+	}
+	if len(r.Ops) != 1 {
+		t.Fatal(r.Ops)
+	}
+	if err := c.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlayback(t *testing.T) {
+	p := Playback{
+		SCKPin: &gpiotest.Pin{N: "CK"},
+		WSPin:  &gpiotest.Pin{N: "WS"},
+		INPin:  &gpiotest.Pin{N: "IN"},
+		OUTPin: &gpiotest.Pin{N: "OUT"},
+	}
+	if s := p.String(); s != "playback" {
+		t.Fatal(s)
+	}
+	if n := p.SCK().Name(); n != "CK" {
+		t.Fatal(n)
+	}
+	if n := p.WS().Name(); n != "WS" {
+		t.Fatal(n)
+	}
+	if n := p.IN().Name(); n != "IN" {
+		t.Fatal(n)
+	}
+	if n := p.OUT().Name(); n != "OUT" {
+		t.Fatal(n)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlayback_Close_panic(t *testing.T) {
+	p := Playback{Ops: []IO{{W: []byte{10}}}}
+	defer func() {
+		v := recover()
+		err, ok := v.(error)
+		if !ok {
+			t.Fatal("expected error")
+		}
+		if !conntest.IsErr(err) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}()
+	_ = p.Close()
+	t.Fatal("shouldn't run")
+}
+
+func TestPlayback_Connect(t *testing.T) {
+	p := Playback{Cfg: cfg, DontPanic: true}
+	if _, err := p.Connect(&i2s.Config{}); err == nil {
+		t.Fatal("invalid config")
+	}
+	other := cfg
+	other.Rate = 44100 * physic.Hertz
+	if _, err := p.Connect(&other); err == nil {
+		t.Fatal("unexpected config")
+	}
+	if _, err := p.Connect(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Connect(&cfg); err == nil {
+		t.Fatal("second Connect() must fail")
+	}
+}
+
+func TestPlayback_ReadWrite(t *testing.T) {
+	p := Playback{
+		Ops:       []IO{{W: []byte{1, 2, 3, 4}}, {R: []byte{5, 6, 7, 8}}},
+		DontPanic: true,
+	}
+	c, err := p.Connect(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Config() != cfg {
+		t.Fatal(c.Config())
+	}
+	if _, err := c.Read(make([]byte, 4)); err == nil {
+		t.Fatal("expected Write")
+	}
+	if _, err := c.Write([]byte{1, 2, 3, 5}); err == nil {
+		t.Fatal("invalid data")
+	}
+	if n, err := c.Write([]byte{1, 2, 3, 4}); n != 4 || err != nil {
+		t.Fatal(n, err)
+	}
+	if p.Close() == nil {
+		t.Fatal("Ops is not empty")
+	}
+	if _, err := c.Read(make([]byte, 2)); err == nil {
+		t.Fatal("invalid read size")
+	}
+	var b [4]byte
+	if n, err := c.Read(b[:]); n != 4 || err != nil || b != [4]byte{5, 6, 7, 8} {
+		t.Fatal(n, err, b)
+	}
+	if _, err := c.Read(b[:]); err == nil {
+		t.Fatal("Playback.Ops is empty")
+	}
+	if _, err := c.Write(b[:]); err == nil {
+		t.Fatal("Playback.Ops is empty")
+	}
+	if err := c.Halt(); err != nil || p.Halted != 1 {
+		t.Fatal(err, p.Halted)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecord_Playback(t *testing.T) {
+	r := Record{
+		Port: &Playback{
+			Ops:       []IO{{W: []byte{1, 2, 3, 4}}, {R: []byte{5, 6, 7, 8}}},
+			DontPanic: true,
+			SCKPin:    &gpiotest.Pin{N: "CK"},
+			WSPin:     &gpiotest.Pin{N: "WS"},
+			INPin:     &gpiotest.Pin{N: "IN"},
+			OUTPin:    &gpiotest.Pin{N: "OUT"},
+		},
+	}
+	if n := r.SCK().Name(); n != "CK" {
+		t.Fatal(n)
+	}
+	if n := r.WS().Name(); n != "WS" {
+		t.Fatal(n)
+	}
+	if n := r.IN().Name(); n != "IN" {
+		t.Fatal(n)
+	}
+	if n := r.OUT().Name(); n != "OUT" {
+		t.Fatal(n)
+	}
+	c, err := r.Connect(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Write([]byte{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	var b [4]byte
+	if _, err := c.Read(b[:]); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Ops) != 2 || b != [4]byte{5, 6, 7, 8} {
+		t.Fatal(r.Ops, b)
+	}
+	if err := c.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package jtag
+
+import (
+	"errors"
+	"fmt"
+)
+
+// IDCode is the 32 bits device identification register.
+//
+// 0 means the device doesn't implement the IDCODE instruction and was in
+// BYPASS.
+type IDCode uint32
+
+// Version returns the part revision.
+func (i IDCode) Version() uint8 {
+	return uint8(i >> 28)
+}
+
+// Part returns the part number assigned by the manufacturer.
+func (i IDCode) Part() uint16 {
+	return uint16(i >> 12)
+}
+
+// Manufacturer returns the JEP106 manufacturer identity; the number of
+// continuation codes in the 4 upper bits and the identity code in the 7 lower
+// bits.
+func (i IDCode) Manufacturer() uint16 {
+	return uint16(i>>1) & 0x7FF
+}
+
+func (i IDCode) String() string {
+	if i == 0 {
+		return "BYPASS"
+	}
+	return fmt.Sprintf("0x%08X(v%d, part 0x%04X, mfg 0x%03X)", uint32(i), i.Version(), i.Part(), i.Manufacturer())
+}
+
+// MaxChainLength is the maximum number of devices ScanChain() detects.
+const MaxChainLength = 32
+
+// ScanChain resets the chain and returns the IDCODE of each device.
+//
+// Devices that do not implement IDCODE are in BYPASS after reset and are
+// reported with an IDCode of 0.
+//
+// The first device is the one closest to TDO. The TAP controllers are left in
+// RunTestIdle. When nothing is connected and TDO is pulled up, no device is
+// returned.
+func ScanChain(a Adapter) ([]IDCode, error) {
+	if err := a.Reset(); err != nil {
+		return nil, err
+	}
+	// Shift ones in. Each device shifts out either a 32 bits IDCODE starting
+	// with a 1 or a single 0 bit for BYPASS. Once all the devices shifted
+	// their data register out, the ones shifted in come out, and 32 ones is
+	// not a valid IDCODE.
+	bits := (MaxChainLength + 1) * 32
+	w := make([]byte, bits/8)
+	for i := range w {
+		w[i] = 0xFF
+	}
+	r := make([]byte, bits/8)
+	if err := a.ScanDR(w, r, bits, RunTestIdle); err != nil {
+		return nil, err
+	}
+	var out []IDCode
+	for i := 0; i+32 <= bits && len(out) <= MaxChainLength; {
+		if r[i/8]&(1<<uint(i%8)) == 0 {
+			out = append(out, 0)
+			i++
+			continue
+		}
+		v := IDCode(0)
+		for j := 0; j < 32; j++ {
+			if r[(i+j)/8]&(1<<uint((i+j)%8)) != 0 {
+				v |= 1 << uint(j)
+			}
+		}
+		if v == 0xFFFFFFFF {
+			return out, nil
+		}
+		out = append(out, v)
+		i += 32
+	}
+	return nil, errors.New("jtag: end of chain not found; is TDO stuck low?")
+}
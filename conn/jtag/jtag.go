@@ -2,8 +2,186 @@
 // Use of this source code is governed under the Apache License, Version 2.0
 // that can be found in the LICENSE file.
 
-// Package jtag will eventually define the API to communicate with devices over
-// the JTAG protocol.
+// Package jtag defines the API to communicate with devices over the JTAG
+// protocol.
+//
+// The Test Access Port (TAP) of each device is a 16 states machine driven by
+// the TMS line on the rising edge of TCK. Data is shifted in on TDI and out on
+// TDO, least significant bit first, while in the ShiftIR or ShiftDR states.
+//
+// All the devices on a chain share TCK and TMS, so they are always in the same
+// state. Their instruction and data registers are concatenated, TDO of one
+// device being connected to TDI of the next one.
 //
 // See https://en.wikipedia.org/wiki/JTAG for background information.
 package jtag
+
+import (
+	"strconv"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+// State is a state of the TAP controller.
+type State uint8
+
+// TAP controller states, as defined in IEEE 1149.1.
+const (
+	TestLogicReset State = 0
+	RunTestIdle    State = 1
+	SelectDRScan   State = 2
+	CaptureDR      State = 3
+	ShiftDR        State = 4
+	Exit1DR        State = 5
+	PauseDR        State = 6
+	Exit2DR        State = 7
+	UpdateDR       State = 8
+	SelectIRScan   State = 9
+	CaptureIR      State = 10
+	ShiftIR        State = 11
+	Exit1IR        State = 12
+	PauseIR        State = 13
+	Exit2IR        State = 14
+	UpdateIR       State = 15
+)
+
+const stateName = "TestLogicResetRunTestIdleSelectDRScanCaptureDRShiftDRExit1DRPauseDRExit2DRUpdateDRSelectIRScanCaptureIRShiftIRExit1IRPauseIRExit2IRUpdateIR"
+
+var stateIndex = [...]uint8{0, 14, 25, 37, 46, 53, 60, 67, 74, 82, 94, 103, 110, 117, 124, 131, 139}
+
+func (s State) String() string {
+	if s >= State(len(stateIndex)-1) {
+		return "State(" + strconv.Itoa(int(s)) + ")"
+	}
+	return stateName[stateIndex[s]:stateIndex[s+1]]
+}
+
+// Next returns the state the TAP controller transitions to on the next TCK
+// rising edge, given the level of TMS.
+func (s State) Next(tms gpio.Level) State {
+	if s >= State(len(transitions)) {
+		return s
+	}
+	if tms {
+		return transitions[s][1]
+	}
+	return transitions[s][0]
+}
+
+// IsStable returns true if the TAP controller can stay in this state while
+// TCK is toggled.
+//
+// These are the only valid end states for a scan.
+func (s State) IsStable() bool {
+	return s == TestLogicReset || s == RunTestIdle || s == PauseDR || s == PauseIR
+}
+
+// Path returns the shortest TMS sequence to go from one state to another.
+//
+// The TMS levels are returned as a bitmask, least significant bit first, with
+// n the number of TCK cycles. n is 0 when from and to are the same state.
+func Path(from, to State) (tms uint32, n int) {
+	if from >= State(len(transitions)) || to >= State(len(transitions)) {
+		return 0, 0
+	}
+	// Breadth first search; the graph is tiny.
+	type step struct {
+		tms uint32
+		n   int
+	}
+	var seen [len(transitions)]bool
+	var paths [len(transitions)]step
+	queue := make([]State, 1, len(transitions))
+	queue[0] = from
+	seen[from] = true
+	for len(queue) != 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if s == to {
+			break
+		}
+		for i, next := range transitions[s] {
+			if !seen[next] {
+				seen[next] = true
+				p := paths[s]
+				paths[next] = step{p.tms | uint32(i)<<uint(p.n), p.n + 1}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return paths[to].tms, paths[to].n
+}
+
+// Adapter defines the interface a concrete JTAG adapter must implement.
+//
+// The adapter keeps track of the state of the TAP controllers on the chain.
+//
+// Implementers can optionally implement Pins.
+type Adapter interface {
+	conn.Resource
+	// SetSpeed changes the TCK frequency.
+	SetSpeed(f physic.Frequency) error
+	// State returns the current state of the TAP controllers.
+	State() State
+	// Reset moves the TAP controllers to TestLogicReset by holding TMS high
+	// for 5 TCK cycles, independent of the current state.
+	//
+	// This resets the instruction register of all the devices to IDCODE or
+	// BYPASS.
+	Reset() error
+	// GoTo moves the TAP controllers to the state s via the shortest path.
+	GoTo(s State) error
+	// RunTest stays in the current stable state for n TCK cycles.
+	RunTest(n int) error
+	// ScanIR shifts bits from w into the instruction registers and captures
+	// the bits shifted out in r, then moves to the stable state end.
+	//
+	// Bits are shifted least significant bit first, starting with w[0]. w or r
+	// can be nil; in this case zeros are shifted in or the bits shifted out
+	// are discarded.
+	ScanIR(w, r []byte, bits int, end State) error
+	// ScanDR is the same as ScanIR but for the data registers.
+	ScanDR(w, r []byte, bits int, end State) error
+}
+
+// Pins defines the pins that a JTAG adapter is using on the host.
+//
+// It is expected that a implementer of Adapter also implement Pins but this
+// is not a requirement.
+type Pins interface {
+	// TCK returns the test clock pin.
+	TCK() gpio.PinOut
+	// TMS returns the test mode select pin.
+	TMS() gpio.PinOut
+	// TDI returns the test data input pin, which is an output from the adapter
+	// point of view.
+	TDI() gpio.PinOut
+	// TDO returns the test data output pin, which is an input from the adapter
+	// point of view.
+	TDO() gpio.PinIn
+}
+
+//
+
+// transitions is the TAP controller state machine, indexed by state and then
+// TMS.
+var transitions = [...][2]State{
+	TestLogicReset: {RunTestIdle, TestLogicReset},
+	RunTestIdle:    {RunTestIdle, SelectDRScan},
+	SelectDRScan:   {CaptureDR, SelectIRScan},
+	CaptureDR:      {ShiftDR, Exit1DR},
+	ShiftDR:        {ShiftDR, Exit1DR},
+	Exit1DR:        {PauseDR, UpdateDR},
+	PauseDR:        {PauseDR, Exit2DR},
+	Exit2DR:        {ShiftDR, UpdateDR},
+	UpdateDR:       {RunTestIdle, SelectDRScan},
+	SelectIRScan:   {CaptureIR, TestLogicReset},
+	CaptureIR:      {ShiftIR, Exit1IR},
+	ShiftIR:        {ShiftIR, Exit1IR},
+	Exit1IR:        {PauseIR, UpdateIR},
+	PauseIR:        {PauseIR, Exit2IR},
+	Exit2IR:        {ShiftIR, UpdateIR},
+	UpdateIR:       {RunTestIdle, SelectDRScan},
+}
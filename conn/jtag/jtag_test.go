@@ -0,0 +1,168 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package jtag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+func TestState_String(t *testing.T) {
+	if s := TestLogicReset.String(); s != "TestLogicReset" {
+		t.Fatal(s)
+	}
+	if s := ShiftDR.String(); s != "ShiftDR" {
+		t.Fatal(s)
+	}
+	if s := UpdateIR.String(); s != "UpdateIR" {
+		t.Fatal(s)
+	}
+	if s := State(16).String(); s != "State(16)" {
+		t.Fatal(s)
+	}
+}
+
+func TestState_Next(t *testing.T) {
+	// Five TMS high always end in TestLogicReset.
+	for s := TestLogicReset; s <= UpdateIR; s++ {
+		n := s
+		for i := 0; i < 5; i++ {
+			n = n.Next(gpio.High)
+		}
+		if n != TestLogicReset {
+			t.Fatalf("%s: %s", s, n)
+		}
+	}
+	if n := State(16).Next(gpio.High); n != State(16) {
+		t.Fatal(n)
+	}
+	if !PauseDR.IsStable() || ShiftDR.IsStable() {
+		t.Fatal("unexpected stable state")
+	}
+}
+
+func TestPath(t *testing.T) {
+	data := []struct {
+		from, to State
+		tms      uint32
+		n        int
+	}{
+		{TestLogicReset, TestLogicReset, 0, 0},
+		{TestLogicReset, RunTestIdle, 0, 1},
+		{TestLogicReset, ShiftIR, 0x6, 5},
+		{RunTestIdle, ShiftDR, 0x1, 3},
+		{ShiftDR, PauseIR, 0x2F, 7},
+		{PauseIR, TestLogicReset, 0x1F, 5},
+	}
+	for i, line := range data {
+		tms, n := Path(line.from, line.to)
+		if tms != line.tms || n != line.n {
+			t.Fatalf("#%d: 0x%x, %d", i, tms, n)
+		}
+	}
+	// Verify all the paths.
+	for from := TestLogicReset; from <= UpdateIR; from++ {
+		for to := TestLogicReset; to <= UpdateIR; to++ {
+			tms, n := Path(from, to)
+			s := from
+			for i := 0; i < n; i++ {
+				s = s.Next(tms&(1<<uint(i)) != 0)
+			}
+			if s != to || n > 8 {
+				t.Fatalf("%s -> %s: ended in %s after %d", from, to, s, n)
+			}
+		}
+	}
+	if _, n := Path(State(16), RunTestIdle); n != 0 {
+		t.Fatal(n)
+	}
+}
+
+func TestIDCode(t *testing.T) {
+	i := IDCode(0x4BA00477)
+	if i.Version() != 4 || i.Part() != 0xBA00 || i.Manufacturer() != 0x23B {
+		t.Fatal(i.Version(), i.Part(), i.Manufacturer())
+	}
+	if s := i.String(); s != "0x4BA00477(v4, part 0xBA00, mfg 0x23B)" {
+		t.Fatal(s)
+	}
+	if s := IDCode(0).String(); s != "BYPASS" {
+		t.Fatal(s)
+	}
+}
+
+func TestScanChain(t *testing.T) {
+	// One device with IDCODE closest to TDO, then one in BYPASS.
+	a := &fakeAdapter{dr: []uint64{0x4BA00477, 0}, drLen: []int{32, 1}}
+	ids, err := ScanChain(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 0x4BA00477 || ids[1] != 0 {
+		t.Fatal(ids)
+	}
+	if a.state != RunTestIdle {
+		t.Fatal(a.state)
+	}
+	// Nothing connected, TDO pulled up.
+	if ids, err := ScanChain(&fakeAdapter{tdo: gpio.High}); err != nil || len(ids) != 0 {
+		t.Fatal(ids, err)
+	}
+	// TDO stuck low.
+	if _, err := ScanChain(&fakeAdapter{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := ScanChain(&fakeAdapter{err: errors.New("foo")}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+//
+
+// fakeAdapter shifts out its data registers then the bits shifted in.
+type fakeAdapter struct {
+	dr    []uint64
+	drLen []int
+	tdo   gpio.Level // When there is no device
+	state State
+	err   error
+}
+
+func (f *fakeAdapter) String() string                                { return "fake" }
+func (f *fakeAdapter) Halt() error                                   { return nil }
+func (f *fakeAdapter) SetSpeed(physic.Frequency) error               { return nil }
+func (f *fakeAdapter) State() State                                  { return f.state }
+func (f *fakeAdapter) GoTo(s State) error                            { f.state = s; return nil }
+func (f *fakeAdapter) RunTest(n int) error                           { return nil }
+func (f *fakeAdapter) ScanIR(w, r []byte, bits int, end State) error { return errors.New("unexpected") }
+
+func (f *fakeAdapter) Reset() error {
+	f.state = TestLogicReset
+	return f.err
+}
+
+func (f *fakeAdapter) ScanDR(w, r []byte, bits int, end State) error {
+	var reg []bool
+	for i, v := range f.dr {
+		for j := 0; j < f.drLen[i]; j++ {
+			reg = append(reg, v&(1<<uint(j)) != 0)
+		}
+	}
+	for i := 0; i < bits; i++ {
+		b := bool(f.tdo)
+		if len(f.dr) != 0 {
+			b = reg[0]
+			reg = append(reg[1:], w[i/8]&(1<<uint(i%8)) != 0)
+		}
+		if b {
+			r[i/8] |= 1 << uint(i%8)
+		}
+	}
+	f.state = end
+	return nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package jtagtest is meant to be used to test JTAG adapters and the drivers
+// using them over a simulated chain of TAP controllers.
+package jtagtest
+
+import (
+	"sync"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/gpio/gpiotest"
+	"github.com/meandrewdev/periph/conn/jtag"
+)
+
+// Device is a simulated device on a JTAG chain.
+//
+// The instruction register is IRLen bits long. All ones selects BYPASS, the
+// IDCodeInstr instruction selects the IDCODE register and any other
+// instruction selects a DRLen bits long user data register whose content is
+// DR.
+//
+// After reset, the instruction is IDCodeInstr if IDCode is not 0 and BYPASS
+// otherwise.
+type Device struct {
+	IDCode      uint32
+	IDCodeInstr uint64
+	IRLen       int
+	DRLen       int
+
+	// Updated as the chain is operated.
+	IR uint64 // Current instruction
+	DR uint64 // User data register
+}
+
+// Chain simulates a chain of TAP controllers driven over gpiotest pins.
+//
+// Devices[0] is the device closest to TDO. The TAP controllers sample TMS
+// and TDI on the rising edge of TCK and update TDO on the falling edge, as
+// specified by IEEE 1149.1.
+//
+// Use TCK(), TMS(), TDI() and TDO() as the pins of the adapter under test.
+type Chain struct {
+	sync.Mutex
+	Devices []Device
+	State   jtag.State
+	// Clocks is the number of TCK rising edges.
+	Clocks int
+
+	tck   clockPin
+	tms   gpiotest.Pin
+	tdi   gpiotest.Pin
+	tdo   gpiotest.Pin
+	reg   []bool
+	ready bool
+}
+
+// TCK returns the test clock pin.
+func (c *Chain) TCK() gpio.PinIO {
+	c.init()
+	return &c.tck
+}
+
+// TMS returns the test mode select pin.
+func (c *Chain) TMS() gpio.PinIO {
+	c.init()
+	return &c.tms
+}
+
+// TDI returns the test data input pin.
+func (c *Chain) TDI() gpio.PinIO {
+	c.init()
+	return &c.tdi
+}
+
+// TDO returns the test data output pin.
+func (c *Chain) TDO() gpio.PinIO {
+	c.init()
+	return &c.tdo
+}
+
+//
+
+func (c *Chain) init() {
+	c.Lock()
+	defer c.Unlock()
+	if c.ready {
+		return
+	}
+	c.tck = clockPin{Pin: gpiotest.Pin{N: "TCK"}, c: c}
+	c.tms = gpiotest.Pin{N: "TMS", Num: 1}
+	c.tdi = gpiotest.Pin{N: "TDI", Num: 2}
+	c.tdo = gpiotest.Pin{N: "TDO", Num: 3}
+	if c.State == jtag.TestLogicReset {
+		c.reset()
+	}
+	c.ready = true
+}
+
+// reset sets the instruction registers to their default value.
+func (c *Chain) reset() {
+	for i := range c.Devices {
+		d := &c.Devices[i]
+		if d.IDCode != 0 {
+			d.IR = d.IDCodeInstr
+		} else {
+			d.IR = 1<<uint(d.IRLen) - 1
+		}
+	}
+}
+
+// rising is called on TCK rising edge.
+func (c *Chain) rising() {
+	tms := c.tms.Read()
+	tdi := c.tdi.Read()
+	c.Lock()
+	defer c.Unlock()
+	c.Clocks++
+	if (c.State == jtag.ShiftIR || c.State == jtag.ShiftDR) && len(c.reg) != 0 {
+		c.reg = append(c.reg[1:], bool(tdi))
+	}
+	c.State = c.State.Next(tms)
+	switch c.State {
+	case jtag.TestLogicReset:
+		c.reset()
+	case jtag.CaptureIR:
+		c.capture(true)
+	case jtag.CaptureDR:
+		c.capture(false)
+	case jtag.UpdateIR:
+		c.update(true)
+	case jtag.UpdateDR:
+		c.update(false)
+	}
+}
+
+// falling is called on TCK falling edge.
+func (c *Chain) falling() {
+	c.Lock()
+	l := gpio.Low
+	if (c.State == jtag.ShiftIR || c.State == jtag.ShiftDR) && len(c.reg) != 0 {
+		l = gpio.Level(c.reg[0])
+	}
+	c.Unlock()
+	c.tdo.Lock()
+	c.tdo.L = l
+	c.tdo.Unlock()
+}
+
+// capture loads the concatenated registers of all the devices.
+func (c *Chain) capture(ir bool) {
+	c.reg = c.reg[:0]
+	for i := range c.Devices {
+		d := &c.Devices[i]
+		if ir {
+			// IEEE 1149.1 requires the two least significant bits to be 01.
+			c.reg = appendBits(c.reg, 1, d.IRLen)
+			continue
+		}
+		switch d.IR {
+		case 1<<uint(d.IRLen) - 1:
+			c.reg = append(c.reg, false)
+		case d.IDCodeInstr:
+			if d.IDCode != 0 {
+				c.reg = appendBits(c.reg, uint64(d.IDCode), 32)
+				break
+			}
+			c.reg = append(c.reg, false)
+		default:
+			c.reg = appendBits(c.reg, d.DR, d.DRLen)
+		}
+	}
+}
+
+// update stores the shifted bits into the devices' registers.
+func (c *Chain) update(ir bool) {
+	off := 0
+	for i := range c.Devices {
+		d := &c.Devices[i]
+		if ir {
+			d.IR = getBits(c.reg[off:], d.IRLen)
+			off += d.IRLen
+			continue
+		}
+		switch {
+		case d.IR == 1<<uint(d.IRLen)-1:
+			off++
+		case d.IR == d.IDCodeInstr && d.IDCode != 0:
+			off += 32
+		case d.IR == d.IDCodeInstr:
+			off++
+		default:
+			d.DR = getBits(c.reg[off:], d.DRLen)
+			off += d.DRLen
+		}
+	}
+}
+
+func appendBits(b []bool, v uint64, n int) []bool {
+	for i := 0; i < n; i++ {
+		b = append(b, v&(1<<uint(i)) != 0)
+	}
+	return b
+}
+
+func getBits(b []bool, n int) uint64 {
+	v := uint64(0)
+	for i := 0; i < n && i < len(b); i++ {
+		if b[i] {
+			v |= 1 << uint(i)
+		}
+	}
+	return v
+}
+
+// clockPin is the TCK pin; it clocks the chain on its edges.
+type clockPin struct {
+	gpiotest.Pin
+	c *Chain
+}
+
+// Out implements gpio.PinOut.
+func (p *clockPin) Out(l gpio.Level) error {
+	p.Lock()
+	prev := p.L
+	p.L = l
+	p.Unlock()
+	if l && !prev {
+		p.c.rising()
+	} else if !l && prev {
+		p.c.falling()
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package jtagtest
+
+import (
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/jtag"
+)
+
+func TestChain_IDCode(t *testing.T) {
+	c := Chain{Devices: []Device{{IDCode: 0x12345679, IDCodeInstr: 1, IRLen: 2}}}
+	clock := func(tms gpio.Level) gpio.Level {
+		if err := c.TMS().Out(tms); err != nil {
+			t.Fatal(err)
+		}
+		l := c.TDO().Read()
+		if err := c.TCK().Out(gpio.High); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.TCK().Out(gpio.Low); err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+	// TestLogicReset -> RunTestIdle -> SelectDRScan -> CaptureDR -> ShiftDR.
+	for _, tms := range []gpio.Level{gpio.Low, gpio.High, gpio.Low, gpio.Low} {
+		clock(tms)
+	}
+	if c.State != jtag.ShiftDR {
+		t.Fatal(c.State)
+	}
+	v := uint32(0)
+	for i := 0; i < 32; i++ {
+		if clock(i == 31) {
+			v |= 1 << uint(i)
+		}
+	}
+	if v != 0x12345679 || c.State != jtag.Exit1DR || c.Clocks != 36 {
+		t.Fatalf("0x%x %s %d", v, c.State, c.Clocks)
+	}
+	// Load the BYPASS instruction.
+	for _, tms := range []gpio.Level{gpio.High, gpio.High, gpio.High, gpio.Low, gpio.Low} {
+		clock(tms)
+	}
+	if c.State != jtag.ShiftIR {
+		t.Fatal(c.State)
+	}
+	if err := c.TDI().Out(gpio.High); err != nil {
+		t.Fatal(err)
+	}
+	if clock(gpio.Low) != gpio.High || clock(gpio.High) != gpio.Low {
+		t.Fatal("IR capture must be 01")
+	}
+	clock(gpio.High)
+	if c.State != jtag.UpdateIR || c.Devices[0].IR != 3 {
+		t.Fatal(c.State, c.Devices[0].IR)
+	}
+}
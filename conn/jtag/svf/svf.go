@@ -0,0 +1,467 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package svf plays Serial Vector Format files over a JTAG adapter.
+//
+// SVF is the vendor neutral format generated by most CPLD and FPGA tools to
+// program devices over JTAG.
+//
+// The PIO and PIOMAP commands are not supported. As the adapters have no TRST
+// line, TRST ON resets the TAP controllers via TMS instead.
+//
+// See http://www.jtagtest.com/pdf/svf_specification.pdf for the specification.
+package svf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/jtag"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+// Play parses the SVF stream r and executes it on a.
+//
+// It stops at the first error, including when a TDO value doesn't match the
+// expected one.
+func Play(a jtag.Adapter, r io.Reader) error {
+	p := player{a: a, endIR: jtag.RunTestIdle, endDR: jtag.RunTestIdle, runState: jtag.RunTestIdle, runEnd: jtag.RunTestIdle}
+	return parse(r, func(line int, tokens []string) error {
+		if err := p.exec(tokens); err != nil {
+			return fmt.Errorf("svf: line %d: %v", line, err)
+		}
+		return nil
+	})
+}
+
+//
+
+// stateNames are the SVF names of the TAP states.
+var stateNames = [...]string{
+	jtag.TestLogicReset: "RESET",
+	jtag.RunTestIdle:    "IDLE",
+	jtag.SelectDRScan:   "DRSELECT",
+	jtag.CaptureDR:      "DRCAPTURE",
+	jtag.ShiftDR:        "DRSHIFT",
+	jtag.Exit1DR:        "DREXIT1",
+	jtag.PauseDR:        "DRPAUSE",
+	jtag.Exit2DR:        "DREXIT2",
+	jtag.UpdateDR:       "DRUPDATE",
+	jtag.SelectIRScan:   "IRSELECT",
+	jtag.CaptureIR:      "IRCAPTURE",
+	jtag.ShiftIR:        "IRSHIFT",
+	jtag.Exit1IR:        "IREXIT1",
+	jtag.PauseIR:        "IRPAUSE",
+	jtag.Exit2IR:        "IREXIT2",
+	jtag.UpdateIR:       "IRUPDATE",
+}
+
+func parseState(s string) (jtag.State, bool) {
+	for i, n := range stateNames {
+		if n == s {
+			return jtag.State(i), true
+		}
+	}
+	return 0, false
+}
+
+func parseStableState(s string) (jtag.State, error) {
+	st, ok := parseState(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid state %q", s)
+	}
+	if !st.IsStable() {
+		return 0, fmt.Errorf("state %s is not stable", s)
+	}
+	return st, nil
+}
+
+// scan is the sticky parameters of a HIR, HDR, TIR, TDR, SIR or SDR command.
+type scan struct {
+	bits  int
+	tdi   []byte
+	tdo   []byte
+	mask  []byte
+	smask []byte
+}
+
+// parse parses the scan parameters, reusing the sticky values when the
+// length didn't change.
+func (s *scan) parse(tokens []string) error {
+	if len(tokens) == 0 {
+		return errors.New("missing length")
+	}
+	bits, err := strconv.Atoi(tokens[0])
+	if err != nil || bits < 0 {
+		return fmt.Errorf("invalid length %q", tokens[0])
+	}
+	if bits != s.bits {
+		*s = scan{bits: bits, mask: ones(bits)}
+	}
+	s.tdo = nil
+	for i := 1; i < len(tokens); i += 2 {
+		if i+1 == len(tokens) {
+			return fmt.Errorf("missing value for %s", tokens[i])
+		}
+		v, err := parseHex(tokens[i+1], bits)
+		if err != nil {
+			return err
+		}
+		switch tokens[i] {
+		case "TDI":
+			s.tdi = v
+		case "TDO":
+			s.tdo = v
+		case "MASK":
+			s.mask = v
+		case "SMASK":
+			s.smask = v
+		default:
+			return fmt.Errorf("unknown parameter %s", tokens[i])
+		}
+	}
+	if bits != 0 && s.tdi == nil {
+		return errors.New("missing TDI")
+	}
+	return nil
+}
+
+type player struct {
+	a        jtag.Adapter
+	endIR    jtag.State
+	endDR    jtag.State
+	runState jtag.State
+	runEnd   jtag.State
+	hir      scan
+	hdr      scan
+	tir      scan
+	tdr      scan
+	sir      scan
+	sdr      scan
+}
+
+func (p *player) exec(tokens []string) error {
+	args := tokens[1:]
+	switch tokens[0] {
+	case "ENDIR", "ENDDR":
+		if len(args) != 1 {
+			return fmt.Errorf("%s expects one state", tokens[0])
+		}
+		s, err := parseStableState(args[0])
+		if err != nil {
+			return err
+		}
+		if tokens[0] == "ENDIR" {
+			p.endIR = s
+		} else {
+			p.endDR = s
+		}
+		return nil
+	case "STATE":
+		if len(args) == 0 {
+			return errors.New("STATE expects at least one state")
+		}
+		for i, n := range args {
+			s, ok := parseState(n)
+			if !ok {
+				return fmt.Errorf("invalid state %q", n)
+			}
+			if i == len(args)-1 && !s.IsStable() {
+				return fmt.Errorf("state %s is not stable", n)
+			}
+			if err := p.a.GoTo(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "HIR":
+		return p.hir.parse(args)
+	case "HDR":
+		return p.hdr.parse(args)
+	case "TIR":
+		return p.tir.parse(args)
+	case "TDR":
+		return p.tdr.parse(args)
+	case "SIR":
+		if err := p.sir.parse(args); err != nil {
+			return err
+		}
+		return p.scan(p.a.ScanIR, &p.hir, &p.sir, &p.tir, p.endIR)
+	case "SDR":
+		if err := p.sdr.parse(args); err != nil {
+			return err
+		}
+		return p.scan(p.a.ScanDR, &p.hdr, &p.sdr, &p.tdr, p.endDR)
+	case "RUNTEST":
+		return p.runTest(args)
+	case "FREQUENCY":
+		if len(args) == 0 {
+			// Back to full speed; keep the current one.
+			return nil
+		}
+		if len(args) != 2 || args[1] != "HZ" {
+			return errors.New("FREQUENCY expects a value in HZ")
+		}
+		f, err := strconv.ParseFloat(args[0], 64)
+		if err != nil || f <= 0 {
+			return fmt.Errorf("invalid frequency %q", args[0])
+		}
+		return p.a.SetSpeed(physic.Frequency(f * float64(physic.Hertz)))
+	case "TRST":
+		if len(args) != 1 {
+			return errors.New("TRST expects one mode")
+		}
+		switch args[0] {
+		case "ON":
+			return p.a.Reset()
+		case "OFF", "Z", "ABSENT":
+			return nil
+		default:
+			return fmt.Errorf("invalid TRST mode %q", args[0])
+		}
+	default:
+		return fmt.Errorf("unsupported command %s", tokens[0])
+	}
+}
+
+// scan does a complete IR or DR scan, including header and trailer, and
+// verifies TDO.
+func (p *player) scan(f func(w, r []byte, bits int, end jtag.State) error, h, s, t *scan, end jtag.State) error {
+	bits := h.bits + s.bits + t.bits
+	if bits == 0 {
+		return p.a.GoTo(end)
+	}
+	l := (bits + 7) / 8
+	w := make([]byte, l)
+	expected := make([]byte, l)
+	mask := make([]byte, l)
+	check := false
+	off := 0
+	for _, x := range []*scan{h, s, t} {
+		copyBits(w, off, x.tdi, x.bits)
+		if x.tdo != nil {
+			check = true
+			copyBits(expected, off, x.tdo, x.bits)
+			copyBits(mask, off, x.mask, x.bits)
+		}
+		off += x.bits
+	}
+	var r []byte
+	if check {
+		r = make([]byte, l)
+	}
+	if err := f(w, r, bits, end); err != nil {
+		return err
+	}
+	for i := 0; i < len(r)*8; i++ {
+		if (r[i/8]^expected[i/8])&mask[i/8]&(1<<uint(i%8)) != 0 {
+			return fmt.Errorf("TDO mismatch at bit %d; got %s, expected %s, mask %s", i, formatHex(r, bits), formatHex(expected, bits), formatHex(mask, bits))
+		}
+	}
+	return nil
+}
+
+func (p *player) runTest(args []string) error {
+	if len(args) != 0 {
+		if s, err := parseStableState(args[0]); err == nil {
+			p.runState = s
+			p.runEnd = s
+			args = args[1:]
+		}
+	}
+	count := 0
+	var minTime time.Duration
+	for len(args) != 0 {
+		if args[0] == "ENDSTATE" {
+			if len(args) < 2 {
+				return errors.New("missing ENDSTATE value")
+			}
+			s, err := parseStableState(args[1])
+			if err != nil {
+				return err
+			}
+			p.runEnd = s
+			args = args[2:]
+			continue
+		}
+		if args[0] == "MAXIMUM" {
+			// The maximum time is ignored.
+			if len(args) < 3 || args[2] != "SEC" {
+				return errors.New("MAXIMUM expects a value in SEC")
+			}
+			args = args[3:]
+			continue
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("missing unit after %s", args[0])
+		}
+		v, err := strconv.ParseFloat(args[0], 64)
+		if err != nil || v < 0 {
+			return fmt.Errorf("invalid value %q", args[0])
+		}
+		switch args[1] {
+		case "TCK":
+			count = int(v)
+		case "SEC":
+			minTime = time.Duration(v * float64(time.Second))
+		default:
+			return fmt.Errorf("unsupported unit %s", args[1])
+		}
+		args = args[2:]
+	}
+	if err := p.a.GoTo(p.runState); err != nil {
+		return err
+	}
+	start := time.Now()
+	if count != 0 {
+		if err := p.a.RunTest(count); err != nil {
+			return err
+		}
+	}
+	if d := minTime - time.Since(start); d > 0 {
+		time.Sleep(d)
+	}
+	return p.a.GoTo(p.runEnd)
+}
+
+// parse tokenizes the SVF stream and calls f for each statement.
+//
+// Comments start with ! or // and end at the end of the line. Statements end
+// with a semicolon and can span multiple lines. Values in parenthesis are
+// returned as a single token without whitespace.
+func parse(r io.Reader, f func(line int, tokens []string) error) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<24)
+	var tokens []string
+	var cur []byte
+	inParen := false
+	start := 0
+	flush := func() {
+		if len(cur) != 0 {
+			tokens = append(tokens, string(cur))
+			cur = cur[:0]
+		}
+	}
+	for line := 1; s.Scan(); line++ {
+		l := s.Text()
+		if i := strings.Index(l, "!"); i != -1 {
+			l = l[:i]
+		}
+		if i := strings.Index(l, "//"); i != -1 {
+			l = l[:i]
+		}
+		for i := 0; i < len(l); i++ {
+			c := l[i]
+			if start == 0 && c != ' ' && c != '\t' && c != '\r' {
+				start = line
+			}
+			switch {
+			case inParen:
+				switch c {
+				case ')':
+					cur = append(cur, c)
+					inParen = false
+					flush()
+				case ' ', '\t', '\r':
+				default:
+					cur = append(cur, c)
+				}
+			case c == '(':
+				flush()
+				cur = append(cur, c)
+				inParen = true
+			case c == ';':
+				flush()
+				if len(tokens) != 0 {
+					if err := f(start, tokens); err != nil {
+						return err
+					}
+				}
+				tokens = tokens[:0]
+				start = 0
+			case c == ' ' || c == '\t' || c == '\r':
+				flush()
+			default:
+				if c >= 'a' && c <= 'z' {
+					c -= 'a' - 'A'
+				}
+				cur = append(cur, c)
+			}
+		}
+		if !inParen {
+			flush()
+		}
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("svf: %v", err)
+	}
+	if inParen || len(tokens) != 0 {
+		return fmt.Errorf("svf: line %d: unterminated statement", start)
+	}
+	return nil
+}
+
+// parseHex parses a value in parenthesis, most significant digit first, into
+// a least significant byte first buffer of bits.
+func parseHex(s string, bits int) ([]byte, error) {
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return nil, fmt.Errorf("invalid value %q", s)
+	}
+	s = s[1 : len(s)-1]
+	out := make([]byte, (bits+7)/8)
+	for i := 0; i < len(s); i++ {
+		v, err := strconv.ParseUint(s[len(s)-1-i:len(s)-i], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q", s)
+		}
+		if i/2 >= len(out) {
+			if v != 0 {
+				return nil, fmt.Errorf("value %q is longer than %d bits", s, bits)
+			}
+			continue
+		}
+		out[i/2] |= byte(v) << uint(4*(i%2))
+	}
+	if r := bits % 8; r != 0 {
+		if out[len(out)-1]&^(1<<uint(r)-1) != 0 {
+			return nil, fmt.Errorf("value %q is longer than %d bits", s, bits)
+		}
+	}
+	return out, nil
+}
+
+// formatHex formats a buffer of bits in SVF notation.
+func formatHex(b []byte, bits int) string {
+	out := make([]byte, 0, bits/4+3)
+	out = append(out, '(')
+	for i := (bits+3)/4 - 1; i >= 0; i-- {
+		out = append(out, "0123456789ABCDEF"[(b[i/2]>>uint(4*(i%2)))&0xF])
+	}
+	return string(append(out, ')'))
+}
+
+// ones returns a buffer with bits set to one.
+func ones(bits int) []byte {
+	out := make([]byte, (bits+7)/8)
+	for i := 0; i < bits; i++ {
+		out[i/8] |= 1 << uint(i%8)
+	}
+	return out
+}
+
+// copyBits copies n bits from src to dst, starting at the bit offset off in
+// dst.
+func copyBits(dst []byte, off int, src []byte, n int) {
+	for i := 0; i < n; i++ {
+		if src[i/8]&(1<<uint(i%8)) != 0 {
+			j := off + i
+			dst[j/8] |= 1 << uint(j%8)
+		}
+	}
+}
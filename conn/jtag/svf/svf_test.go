@@ -0,0 +1,220 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package svf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/jtag"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+func TestParse(t *testing.T) {
+	in := "! Comment\n" +
+		"TRST off; // Comment\n" +
+		"sdr 16 TDI (AB\n  CD) TDO\n(00FF)\r\n;\n" +
+		"RUNTEST 10 TCK;"
+	var got []string
+	err := parse(strings.NewReader(in), func(line int, tokens []string) error {
+		got = append(got, fmt.Sprintf("%d:%s", line, strings.Join(tokens, ",")))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"2:TRST,OFF", "3:SDR,16,TDI,(ABCD),TDO,(00FF)", "7:RUNTEST,10,TCK"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatal(got)
+	}
+	if err := parse(strings.NewReader("SIR 8"), func(int, []string) error { return nil }); err == nil {
+		t.Fatal("unterminated")
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	data := []struct {
+		in       string
+		bits     int
+		expected []byte
+	}{
+		{"(1)", 1, []byte{1}},
+		{"(0ABC)", 12, []byte{0xBC, 0x0A}},
+		{"(00000001)", 8, []byte{1}},
+		{"(123456789)", 36, []byte{0x89, 0x67, 0x45, 0x23, 0x01}},
+	}
+	for i, line := range data {
+		b, err := parseHex(line.in, line.bits)
+		if err != nil || !bytes.Equal(b, line.expected) {
+			t.Fatalf("#%d: %v %v", i, b, err)
+		}
+		if s := formatHex(b, line.bits); strings.TrimLeft(s[1:], "0") != strings.TrimLeft(line.in[1:], "0") {
+			t.Fatalf("#%d: %s", i, s)
+		}
+	}
+	for i, in := range []string{"1", "(G)", "(3)", "(100)"} {
+		if _, err := parseHex(in, 1); err == nil {
+			t.Fatalf("#%d: expected error", i)
+		}
+	}
+}
+
+func TestPlay(t *testing.T) {
+	a := &fakeAdapter{r: [][]byte{{0x05}, {0x77, 0x04, 0xA0, 0x4B}}}
+	in := `
+TRST OFF;
+ENDIR IDLE;
+ENDDR DRPAUSE;
+STATE RESET;
+FREQUENCY 1E6 HZ;
+HIR 2 TDI (3);
+TIR 0;
+SIR 4 TDI (E) TDO (1) MASK (3);
+SDR 32 TDI (00000000) TDO (4BA00477);
+RUNTEST IDLE 100 TCK 1E-6 SEC ENDSTATE RESET;
+RUNTEST 5 TCK MAXIMUM 1 SEC;
+STATE DRPAUSE IDLE;
+`
+	if err := Play(a, strings.NewReader(in)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"GoTo(TestLogicReset)",
+		"SetSpeed(1MHz)",
+		"ScanIR(0x3b, 6, RunTestIdle)",
+		"ScanDR(0x00000000, 32, PauseDR)",
+		"GoTo(RunTestIdle)",
+		"RunTest(100)",
+		"GoTo(TestLogicReset)",
+		"GoTo(RunTestIdle)",
+		"RunTest(5)",
+		"GoTo(TestLogicReset)",
+		"GoTo(PauseDR)",
+		"GoTo(RunTestIdle)",
+	}
+	if !reflect.DeepEqual(a.ops, expected) {
+		t.Fatalf("%q", a.ops)
+	}
+}
+
+func TestPlay_Sticky(t *testing.T) {
+	a := &fakeAdapter{}
+	in := "SDR 8 TDI (A5);\nSDR 8;\nSDR 4 TDI (1);\n"
+	if err := Play(a, strings.NewReader(in)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"ScanDR(0xa5, 8, RunTestIdle)",
+		"ScanDR(0xa5, 8, RunTestIdle)",
+		"ScanDR(0x01, 4, RunTestIdle)",
+	}
+	if !reflect.DeepEqual(a.ops, expected) {
+		t.Fatalf("%q", a.ops)
+	}
+}
+
+func TestPlay_Err(t *testing.T) {
+	data := []string{
+		"SIR 4 TDI (1) TDO (2);",
+		"SIR 4 TDO (2);",
+		"SIR 4 TDI (1) FOO (2);",
+		"SIR 4 TDI;",
+		"SIR x;",
+		"SIR;",
+		"ENDIR DRSHIFT;",
+		"ENDDR FOO;",
+		"ENDDR;",
+		"STATE;",
+		"STATE DRSHIFT;",
+		"STATE FOO;",
+		"FREQUENCY 10;",
+		"FREQUENCY x HZ;",
+		"TRST;",
+		"TRST FOO;",
+		"RUNTEST 10 SCK;",
+		"RUNTEST 10;",
+		"RUNTEST x TCK;",
+		"RUNTEST 10 TCK ENDSTATE;",
+		"RUNTEST 10 TCK ENDSTATE DRSHIFT;",
+		"RUNTEST 10 TCK MAXIMUM 1;",
+		"PIOMAP (IN A);",
+	}
+	for i, in := range data {
+		a := &fakeAdapter{r: [][]byte{{1}}}
+		if err := Play(a, strings.NewReader(in)); err == nil {
+			t.Fatalf("#%d: expected error", i)
+		} else if !strings.HasPrefix(err.Error(), "svf: line 1: ") {
+			t.Fatalf("#%d: %v", i, err)
+		}
+	}
+	a := &fakeAdapter{err: errors.New("foo")}
+	if err := Play(a, strings.NewReader("TRST ON;")); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+//
+
+type fakeAdapter struct {
+	r   [][]byte
+	ops []string
+	err error
+}
+
+func (f *fakeAdapter) String() string {
+	return "fake"
+}
+
+func (f *fakeAdapter) Halt() error {
+	return nil
+}
+
+func (f *fakeAdapter) SetSpeed(s physic.Frequency) error {
+	f.ops = append(f.ops, fmt.Sprintf("SetSpeed(%s)", s))
+	return f.err
+}
+
+func (f *fakeAdapter) State() jtag.State {
+	return jtag.RunTestIdle
+}
+
+func (f *fakeAdapter) Reset() error {
+	f.ops = append(f.ops, "Reset()")
+	return f.err
+}
+
+func (f *fakeAdapter) GoTo(s jtag.State) error {
+	f.ops = append(f.ops, fmt.Sprintf("GoTo(%s)", s))
+	return f.err
+}
+
+func (f *fakeAdapter) RunTest(n int) error {
+	f.ops = append(f.ops, fmt.Sprintf("RunTest(%d)", n))
+	return f.err
+}
+
+func (f *fakeAdapter) ScanIR(w, r []byte, bits int, end jtag.State) error {
+	return f.scan("ScanIR", w, r, bits, end)
+}
+
+func (f *fakeAdapter) ScanDR(w, r []byte, bits int, end jtag.State) error {
+	return f.scan("ScanDR", w, r, bits, end)
+}
+
+func (f *fakeAdapter) scan(name string, w, r []byte, bits int, end jtag.State) error {
+	f.ops = append(f.ops, fmt.Sprintf("%s(%#x, %d, %s)", name, w, bits, end))
+	if r != nil {
+		if len(f.r) == 0 {
+			return errors.New("unexpected read")
+		}
+		copy(r, f.r[0])
+		f.r = f.r[1:]
+	}
+	return f.err
+}
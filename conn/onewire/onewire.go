@@ -25,6 +25,7 @@
 package onewire
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/meandrewdev/periph/conn"
@@ -60,6 +61,31 @@ type Bus interface {
 	Search(alarmOnly bool) ([]Address, error)
 }
 
+// TxContexter is an optional interface implemented by a Bus that supports
+// aborting a transaction when a context is done.
+type TxContexter interface {
+	// TxContext performs a bus transaction like Bus.Tx() but returns early with
+	// ctx.Err() when ctx is done.
+	//
+	// Whether an on-going transaction can be interrupted is implementation
+	// specific; at worst the context is only checked before starting.
+	TxContext(ctx context.Context, w, r []byte, power Pullup) error
+}
+
+// TxContext performs a bus transaction on b, honoring ctx.
+//
+// If b implements TxContexter, it is used. Otherwise ctx is checked before
+// calling b.Tx(), which cannot be interrupted once started.
+func TxContext(ctx context.Context, b Bus, w, r []byte, power Pullup) error {
+	if t, ok := b.(TxContexter); ok {
+		return t.TxContext(ctx, w, r, power)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return b.Tx(w, r, power)
+}
+
 // Address represents a 1-wire device address in little-endian format.
 //
 // This means that the family code ends up in the lower byte, the CRC in the
@@ -6,6 +6,7 @@ package onewire
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"testing"
 
@@ -101,8 +102,40 @@ func TestDevTxPower(t *testing.T) {
 	}
 }
 
+func TestTxContext(t *testing.T) {
+	b := &fakeBus{}
+	if err := TxContext(context.Background(), b, []byte{1}, nil, StrongPullup); err != nil {
+		t.Fatal(err)
+	}
+	if b.power != StrongPullup || !bytes.Equal(b.w, []byte{1}) {
+		t.Fatal(b)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b = &fakeBus{}
+	if err := TxContext(ctx, b, []byte{1}, nil, WeakPullup); err != context.Canceled {
+		t.Fatal(err)
+	}
+	if b.w != nil {
+		t.Fatal("Tx() shouldn't have been called")
+	}
+	if err := TxContext(ctx, &fakeBusContext{}, nil, nil, WeakPullup); err != errCtx {
+		t.Fatal(err)
+	}
+}
+
 //
 
+var errCtx = errors.New("ctx")
+
+type fakeBusContext struct {
+	nopBus
+}
+
+func (f *fakeBusContext) TxContext(ctx context.Context, w, r []byte, power Pullup) error {
+	return errCtx
+}
+
 type fakeBus struct {
 	power Pullup
 	err   error
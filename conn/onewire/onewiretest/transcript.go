@@ -0,0 +1,58 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package onewiretest
+
+import (
+	"io"
+
+	"github.com/meandrewdev/periph/conn/conntest"
+	"github.com/meandrewdev/periph/conn/onewire"
+)
+
+// WriteOps writes ops as a transcript of kind "onewire".
+//
+// See conntest.TranscriptVersion for the file format. Playback.Devices is not
+// part of the transcript.
+func WriteOps(w io.Writer, ops []IO) error {
+	t, err := conntest.NewTranscriptWriter(w, "onewire")
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if err := t.Write(&ioJSON{W: op.W, R: op.R, Pull: op.Pull}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadOps reads a transcript of kind "onewire" written by WriteOps.
+//
+// The result can be used as Playback.Ops.
+func ReadOps(r io.Reader) ([]IO, error) {
+	t, err := conntest.NewTranscriptReader(r, "onewire")
+	if err != nil {
+		return nil, err
+	}
+	var ops []IO
+	for {
+		var j ioJSON
+		if err := t.Read(&j); err != nil {
+			if err == io.EOF {
+				return ops, nil
+			}
+			return nil, err
+		}
+		ops = append(ops, IO{W: j.W, R: j.R, Pull: j.Pull})
+	}
+}
+
+//
+
+type ioJSON struct {
+	W    conntest.Hex   `json:"w,omitempty"`
+	R    conntest.Hex   `json:"r,omitempty"`
+	Pull onewire.Pullup `json:"pull,omitempty"`
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package onewiretest
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/onewire"
+)
+
+func TestWriteOps_ReadOps(t *testing.T) {
+	ops := []IO{
+		{W: []byte{0xCC, 0x44}, Pull: onewire.StrongPullup},
+		{W: []byte{0xCC, 0xBE}, R: []byte{1, 2, 3}},
+	}
+	var b bytes.Buffer
+	if err := WriteOps(&b, ops); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadOps(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, ops) {
+		t.Fatalf("%#v", got)
+	}
+	if _, err := ReadOps(strings.NewReader("{\"version\":1,\"kind\":\"i2c\"}\n")); err == nil {
+		t.Fatal("wrong kind")
+	}
+}
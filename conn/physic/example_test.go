@@ -13,6 +13,19 @@ import (
 	"github.com/meandrewdev/periph/conn/physic"
 )
 
+func ExampleAcceleration() {
+	fmt.Println(physic.StandardGravity)
+	// Output:
+	// 9.807m/s²
+}
+
+func ExampleAcceleration_flag() {
+	var a physic.Acceleration
+
+	flag.Var(&a, "threshold", "acceleration that triggers the alarm")
+	flag.Parse()
+}
+
 func ExampleAngle() {
 	fmt.Println(physic.Degree)
 	fmt.Println(physic.Pi)
@@ -72,6 +85,65 @@ func ExampleAngle_float64() {
 	// 0.785398rad
 }
 
+func ExampleAngularVelocity() {
+	fmt.Println(250 * physic.DegreePerSecond)
+	// Output:
+	// 4.363rad/s
+}
+
+func ExampleAngularVelocity_Set() {
+	var a physic.AngularVelocity
+
+	if err := a.Set("500dps"); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(a)
+
+	if err := a.Set("2rad/s"); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(a)
+
+	// Output:
+	// 8.727rad/s
+	// 2rad/s
+}
+
+func ExampleConcentration() {
+	fmt.Println(400 * physic.PartPerMillion)
+	fmt.Println(1187 * physic.PartPerBillion)
+	fmt.Println(850 * physic.PartPerBillion)
+	// Output:
+	// 400ppm
+	// 1.187ppm
+	// 850ppb
+}
+
+func ExampleConcentration_Set() {
+	var c physic.Concentration
+
+	if err := c.Set("1000ppm"); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(c)
+
+	if err := c.Set("25ppb"); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(c)
+
+	// Output:
+	// 1000ppm
+	// 25ppb
+}
+
+func ExampleConcentration_flag() {
+	var c physic.Concentration
+
+	flag.Var(&c, "co2", "CO₂ level that triggers the ventilation")
+	flag.Parse()
+}
+
 func ExampleDistance() {
 	fmt.Println(physic.Inch)
 	fmt.Println(physic.Foot)
@@ -515,6 +587,32 @@ func ExamplePeriodToFrequency() {
 	// 16.667mHz
 }
 
+func ExampleIlluminance() {
+	fmt.Println(320 * physic.Lux)
+	// Output:
+	// 320lx
+}
+
+func ExampleIlluminance_flag() {
+	var i physic.Illuminance
+
+	flag.Var(&i, "dark", "ambient light level under which the lights are turned on")
+	flag.Parse()
+}
+
+func ExampleIrradiance() {
+	fmt.Println(1000 * physic.WattPerSquareMetre)
+	// Output:
+	// 1kW/m²
+}
+
+func ExampleIrradiance_flag() {
+	var i physic.Irradiance
+
+	flag.Var(&i, "sun", "solar irradiance above which the blinds are closed")
+	flag.Parse()
+}
+
 func ExampleLuminousFlux() {
 	fmt.Println(18282 * physic.Lumen)
 	// Output:
@@ -662,6 +760,19 @@ func ExampleMass_float64() {
 	// 6.27g
 }
 
+func ExampleMassConcentration() {
+	fmt.Println(35 * physic.MicroGramPerCubicMetre)
+	// Output:
+	// 35µg/m³
+}
+
+func ExampleMassConcentration_flag() {
+	var m physic.MassConcentration
+
+	flag.Var(&m, "pm2.5", "PM2.5 level that triggers the air purifier")
+	flag.Parse()
+}
+
 func ExamplePower() {
 	fmt.Println(1 * physic.Watt)
 	fmt.Println(16 * physic.MilliWatt)
@@ -809,6 +920,19 @@ func ExampleRelativeHumidity_float64() {
 	// 80
 }
 
+func ExampleSoundPressureLevel() {
+	fmt.Println(655 * physic.Decibel / 10)
+	// Output:
+	// 65.5dB
+}
+
+func ExampleSoundPressureLevel_flag() {
+	var l physic.SoundPressureLevel
+
+	flag.Var(&l, "loud", "sound level to report as noise")
+	flag.Parse()
+}
+
 func ExampleSpeed() {
 	fmt.Println(10 * physic.MilliMetrePerSecond)
 	fmt.Println(physic.LightSpeed)
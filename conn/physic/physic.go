@@ -5,6 +5,9 @@
 package physic
 
 import (
+	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/meandrewdev/periph/conn"
@@ -17,6 +20,42 @@ type Env struct {
 	Humidity    RelativeHumidity
 }
 
+// EnvExtra represents measurements from an environmental sensor that
+// measures quantities not in Env.
+//
+// It is a separate type so that Env stays comparable.
+type EnvExtra struct {
+	Env
+
+	// Extra contains the additional quantities measured by the sensor, keyed
+	// by name. The well known names are listed as the Env* constants; other
+	// names are documented by the driver. Each value is one of the unit types
+	// of this package.
+	//
+	// Sensors allocate a new map for each EnvExtra sent by
+	// SenseContinuousExtra(), so it is safe to keep it.
+	Extra map[string]fmt.Stringer
+}
+
+// Well known EnvExtra.Extra names and the type of their value.
+const (
+	EnvIlluminance = "illuminance" // Illuminance
+	EnvCO2         = "co2"         // Concentration, equivalent CO₂
+	EnvTVOC        = "tvoc"        // Concentration, total volatile organic compounds
+	EnvSoundLevel  = "sound_level" // SoundPressureLevel
+	EnvPM1         = "pm1"         // MassConcentration, particulates <1µm
+	EnvPM2_5       = "pm2.5"       // MassConcentration, particulates <2.5µm
+	EnvPM10        = "pm10"        // MassConcentration, particulates <10µm
+)
+
+// Set sets an additional quantity in Extra, allocating it as needed.
+func (e *EnvExtra) Set(name string, v fmt.Stringer) {
+	if e.Extra == nil {
+		e.Extra = map[string]fmt.Stringer{}
+	}
+	e.Extra[name] = v
+}
+
 // SenseEnv represents an environmental sensor.
 type SenseEnv interface {
 	conn.Resource
@@ -40,3 +79,125 @@ type SenseEnv interface {
 	// or doing oversampling in software. Refer to its datasheet if available.
 	Precision(env *Env)
 }
+
+// SenseEnvExtra represents an environmental sensor that measures quantities
+// not in Env.
+//
+// Its SenseEnv methods only report the quantities in Env.
+type SenseEnvExtra interface {
+	SenseEnv
+
+	// SenseExtra is like Sense, and also returns the additional quantities.
+	SenseExtra(env *EnvExtra) error
+	// SenseContinuousExtra is like SenseContinuous, and also returns the
+	// additional quantities.
+	//
+	// Calling SenseContinuous or SenseContinuousExtra stops the previous
+	// continuous sensing, if any.
+	SenseContinuousExtra(interval time.Duration) (<-chan EnvExtra, error)
+	// PrecisionExtra is like Precision. The names set in Extra are the
+	// additional quantities that this sensor can measure.
+	PrecisionExtra(env *EnvExtra)
+}
+
+// SenseLoop runs the goroutine of SenseContinuous on behalf of a sensor
+// driver.
+//
+// Starting a loop stops the previous one. The zero value is ready to use.
+type SenseLoop struct {
+	mu   sync.Mutex
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// StartEnv stops the previous loop, if any, then calls sense right away and
+// at every interval, sending the measurements on the returned channel.
+//
+// The channel is closed when the loop is stopped or when sense fails. The
+// error is logged, prefixed with s, unless the loop was stopped.
+func (l *SenseLoop) StartEnv(s fmt.Stringer, interval time.Duration, sense func(env *Env) error) <-chan Env {
+	c := make(chan Env)
+	l.start(s, interval, func(stop <-chan struct{}) error {
+		var env Env
+		if err := sense(&env); err != nil {
+			return err
+		}
+		select {
+		case c <- env:
+		case <-stop:
+		}
+		return nil
+	}, func() { close(c) })
+	return c
+}
+
+// StartExtra is like StartEnv, for SenseContinuousExtra.
+func (l *SenseLoop) StartExtra(s fmt.Stringer, interval time.Duration, sense func(env *EnvExtra) error) <-chan EnvExtra {
+	c := make(chan EnvExtra)
+	l.start(s, interval, func(stop <-chan struct{}) error {
+		var env EnvExtra
+		if err := sense(&env); err != nil {
+			return err
+		}
+		select {
+		case c <- env:
+		case <-stop:
+		}
+		return nil
+	}, func() { close(c) })
+	return c
+}
+
+// Stop stops the loop, if any, and waits for its goroutine to return.
+//
+// cancel, if not nil, is called before waiting, to interrupt a pending
+// measurement. Its error is returned.
+func (l *SenseLoop) Stop(cancel func() error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stop != nil {
+		close(l.stop)
+		l.stop = nil
+	}
+	var err error
+	if cancel != nil {
+		err = cancel()
+	}
+	l.wg.Wait()
+	return err
+}
+
+func (l *SenseLoop) start(s fmt.Stringer, interval time.Duration, once func(stop <-chan struct{}) error, done func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stop != nil {
+		close(l.stop)
+		l.wg.Wait()
+	}
+	stop := make(chan struct{})
+	l.stop = stop
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			// Do one initial sensing right away.
+			if err := once(stop); err != nil {
+				select {
+				case <-stop:
+					// The sensing was canceled.
+				default:
+					log.Printf("%s: failed to sense: %v", s, err)
+				}
+				return
+			}
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package physic
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnvExtra_Set(t *testing.T) {
+	var e EnvExtra
+	e.Set(EnvCO2, 400*PartPerMillion)
+	if len(e.Extra) != 1 || e.Extra[EnvCO2] != 400*PartPerMillion {
+		t.Fatal(e.Extra)
+	}
+	// Env stays comparable.
+	if e.Env != (Env{}) {
+		t.Fatal(e.Env)
+	}
+}
+
+func TestSenseLoop(t *testing.T) {
+	var l SenseLoop
+	n := 0
+	c1 := l.StartEnv(stringer("loop"), time.Minute, func(e *Env) error {
+		n++
+		e.Temperature = Temperature(n)
+		return nil
+	})
+	if e := <-c1; e.Temperature != 1 {
+		t.Fatal(e)
+	}
+	// Starting again stops the previous loop.
+	c2 := l.StartExtra(stringer("loop"), time.Minute, func(e *EnvExtra) error {
+		e.Set(EnvTVOC, PartPerBillion)
+		return nil
+	})
+	if _, ok := <-c1; ok {
+		t.Fatal("expected the first channel to be closed")
+	}
+	if e := <-c2; e.Extra[EnvTVOC] != PartPerBillion {
+		t.Fatal(e)
+	}
+	canceled := false
+	if err := l.Stop(func() error { canceled = true; return errors.New("cancel") }); err == nil || !canceled {
+		t.Fatal(err)
+	}
+	if _, ok := <-c2; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	// Stopping twice is fine.
+	if err := l.Stop(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSenseLoop_fail(t *testing.T) {
+	var l SenseLoop
+	c := l.StartEnv(stringer("loop"), time.Minute, func(e *Env) error {
+		return errors.New("fail")
+	})
+	if _, ok := <-c; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if err := l.Stop(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//
+
+type stringer string
+
+func (s stringer) String() string {
+	return string(s)
+}
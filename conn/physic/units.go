@@ -1670,6 +1670,529 @@ const (
 	minMagneticFluxDensity = -9223372036854775807 * NanoTesla
 )
 
+// Illuminance is a measurement of luminous flux per unit area, stored as
+// nano lux.
+//
+// The highest representable value is 9.2Glx.
+type Illuminance int64
+
+// String returns the illuminance formatted as a string in Lux.
+func (i Illuminance) String() string {
+	return nanoAsString(int64(i)) + "lx"
+}
+
+// Set sets the Illuminance to the value represented by s. Units are to be
+// provided in "lx" with an optional SI prefix: "p", "n", "u", "µ", "m", "k",
+// "M", "G" or "T".
+func (i *Illuminance) Set(s string) error {
+	v, n, err := valueOfUnitString(s, nano)
+	if err != nil {
+		if e, ok := err.(*parseError); ok {
+			switch e.error {
+			case errNotANumber:
+				if found := hasSuffixes(s, "lx"); found != "" {
+					return err
+				}
+				return notNumberUnitErr("lx")
+			case errOverflowsInt64:
+				return maxValueErr(maxIlluminance.String())
+			case errOverflowsInt64Negative:
+				return minValueErr(minIlluminance.String())
+			}
+		}
+		return err
+	}
+
+	switch s[n:] {
+	case "lx":
+		*i = (Illuminance)(v)
+	case "":
+		return noUnitErr("lx")
+	default:
+		if found := hasSuffixes(s[n:], "lx"); found != "" {
+			return unknownUnitPrefixErr(found, "p,n,u,µ,m,k,M,G or T")
+		}
+		return incorrectUnitErr("lx")
+	}
+
+	return nil
+}
+
+// Well known Illuminance constants.
+const (
+	// Lux is a unit of illuminance. lm⋅m⁻²
+	NanoLux  Illuminance = 1
+	MicroLux Illuminance = 1000 * NanoLux
+	MilliLux Illuminance = 1000 * MicroLux
+	Lux      Illuminance = 1000 * MilliLux
+	KiloLux  Illuminance = 1000 * Lux
+	MegaLux  Illuminance = 1000 * KiloLux
+	GigaLux  Illuminance = 1000 * MegaLux
+
+	maxIlluminance = 9223372036854775807 * NanoLux
+	minIlluminance = -9223372036854775807 * NanoLux
+)
+
+// Irradiance is a measurement of radiant power received per unit area,
+// stored as nano watt per square metre.
+//
+// The highest representable value is 9.2GW/m².
+type Irradiance int64
+
+// String returns the irradiance formatted as a string in W/m².
+func (i Irradiance) String() string {
+	return nanoAsString(int64(i)) + "W/m²"
+}
+
+// Set sets the Irradiance to the value represented by s. Units are to be
+// provided in "W/m²" or "W/m2" with an optional SI prefix: "p", "n", "u",
+// "µ", "m", "k", "M", "G" or "T".
+func (i *Irradiance) Set(s string) error {
+	v, n, err := valueOfUnitString(s, nano)
+	if err != nil {
+		if e, ok := err.(*parseError); ok {
+			switch e.error {
+			case errNotANumber:
+				if found := hasSuffixes(s, "W/m²", "W/m2"); found != "" {
+					return err
+				}
+				return notNumberUnitErr("W/m² or W/m2")
+			case errOverflowsInt64:
+				return maxValueErr(maxIrradiance.String())
+			case errOverflowsInt64Negative:
+				return minValueErr(minIrradiance.String())
+			}
+		}
+		return err
+	}
+
+	switch s[n:] {
+	case "W/m²", "W/m2":
+		*i = (Irradiance)(v)
+	case "":
+		return noUnitErr("W/m² or W/m2")
+	default:
+		if found := hasSuffixes(s[n:], "W/m²", "W/m2"); found != "" {
+			return unknownUnitPrefixErr(found, "p,n,u,µ,m,k,M,G or T")
+		}
+		return incorrectUnitErr("W/m² or W/m2")
+	}
+
+	return nil
+}
+
+// Well known Irradiance constants.
+const (
+	NanoWattPerSquareMetre  Irradiance = 1
+	MicroWattPerSquareMetre Irradiance = 1000 * NanoWattPerSquareMetre
+	MilliWattPerSquareMetre Irradiance = 1000 * MicroWattPerSquareMetre
+	WattPerSquareMetre      Irradiance = 1000 * MilliWattPerSquareMetre
+	KiloWattPerSquareMetre  Irradiance = 1000 * WattPerSquareMetre
+
+	// MicroWattPerSquareCentiMetre is the unit commonly used by spectral
+	// sensors.
+	MicroWattPerSquareCentiMetre Irradiance = 10 * MilliWattPerSquareMetre
+
+	maxIrradiance = 9223372036854775807 * NanoWattPerSquareMetre
+	minIrradiance = -9223372036854775807 * NanoWattPerSquareMetre
+)
+
+// Concentration is a measurement of the amount of a substance in a mixture,
+// as a dimensionless fraction, e.g. the equivalent CO₂ or volatile organic
+// compounds measured by an air quality sensor.
+//
+// Concentration is stored as parts per quadrillion (10⁻¹⁵).
+//
+// The highest representable value is 9223372036ppm.
+type Concentration int64
+
+// String returns the concentration formatted as a string in ppm, ppb or
+// ppt.
+//
+// Concentration is not a S.I. unit, so it is not prefixed by S.I. prefixes.
+func (c Concentration) String() string {
+	a := c
+	if a < 0 {
+		a = -a
+	}
+	switch {
+	case a >= PartPerMillion:
+		return decimalAsString(int64(c), int64(PartPerMillion)) + "ppm"
+	case a >= PartPerBillion:
+		return decimalAsString(int64(c), int64(PartPerBillion)) + "ppb"
+	default:
+		return decimalAsString(int64(c), int64(PartPerTrillion)) + "ppt"
+	}
+}
+
+// Set sets the Concentration to the value represented by s. Units are to be
+// provided in "ppm", "ppb" or "ppt". S.I. prefixes are not supported.
+func (c *Concentration) Set(s string) error {
+	d, n, err := atod(s)
+	if err != nil {
+		if e, ok := err.(*parseError); ok {
+			switch e.error {
+			case errNotANumber:
+				if found := hasSuffixes(s[n:], "ppm", "ppb", "ppt"); found != "" {
+					return err
+				}
+				return notNumberUnitErr("ppm, ppb or ppt")
+			case errOverflowsInt64:
+				return maxValueErr(maxConcentration.String())
+			case errOverflowsInt64Negative:
+				return minValueErr(minConcentration.String())
+			}
+		}
+		return err
+	}
+
+	var scale int
+	switch s[n:] {
+	case "ppm":
+		scale = 9
+	case "ppb":
+		scale = 6
+	case "ppt":
+		scale = 3
+	case "":
+		return noUnitErr("ppm, ppb or ppt")
+	default:
+		return incorrectUnitErr("ppm, ppb or ppt")
+	}
+	v, overflow := dtoi(d, scale)
+	if overflow {
+		if d.neg {
+			return minValueErr(minConcentration.String())
+		}
+		return maxValueErr(maxConcentration.String())
+	}
+	*c = (Concentration)(v)
+	return nil
+}
+
+// Well known Concentration constants.
+const (
+	PartPerQuadrillion Concentration = 1
+	PartPerTrillion    Concentration = 1000 * PartPerQuadrillion
+	PartPerBillion     Concentration = 1000 * PartPerTrillion
+	PartPerMillion     Concentration = 1000 * PartPerBillion
+	// Percent is a hundredth of the whole, e.g. the concentration of O₂ in
+	// air is about 21%.
+	Percent Concentration = 10000 * PartPerMillion
+
+	maxConcentration = 9223372036854775807 * PartPerQuadrillion
+	minConcentration = -9223372036854775807 * PartPerQuadrillion
+)
+
+// MassConcentration is a measurement of mass per unit volume, e.g. the
+// amount of particulate matter suspended in air. It is stored as nano grams
+// per cubic metre.
+//
+// The highest representable value is 9.2Gg/m³.
+type MassConcentration int64
+
+// String returns the mass concentration formatted as a string in g/m³.
+func (m MassConcentration) String() string {
+	return nanoAsString(int64(m)) + "g/m³"
+}
+
+// Set sets the MassConcentration to the value represented by s. Units are to
+// be provided in "g/m³" or "g/m3" with an optional SI prefix: "p", "n", "u",
+// "µ", "m", "k", "M", "G" or "T".
+func (m *MassConcentration) Set(s string) error {
+	v, n, err := valueOfUnitString(s, nano)
+	if err != nil {
+		if e, ok := err.(*parseError); ok {
+			switch e.error {
+			case errNotANumber:
+				if found := hasSuffixes(s, "g/m³", "g/m3"); found != "" {
+					return err
+				}
+				return notNumberUnitErr("g/m³ or g/m3")
+			case errOverflowsInt64:
+				return maxValueErr(maxMassConcentration.String())
+			case errOverflowsInt64Negative:
+				return minValueErr(minMassConcentration.String())
+			}
+		}
+		return err
+	}
+
+	switch s[n:] {
+	case "g/m³", "g/m3":
+		*m = (MassConcentration)(v)
+	case "":
+		return noUnitErr("g/m³ or g/m3")
+	default:
+		if found := hasSuffixes(s[n:], "g/m³", "g/m3"); found != "" {
+			return unknownUnitPrefixErr(found, "p,n,u,µ,m,k,M,G or T")
+		}
+		return incorrectUnitErr("g/m³ or g/m3")
+	}
+
+	return nil
+}
+
+// Well known MassConcentration constants.
+const (
+	NanoGramPerCubicMetre  MassConcentration = 1
+	MicroGramPerCubicMetre MassConcentration = 1000 * NanoGramPerCubicMetre
+	MilliGramPerCubicMetre MassConcentration = 1000 * MicroGramPerCubicMetre
+	GramPerCubicMetre      MassConcentration = 1000 * MilliGramPerCubicMetre
+	KiloGramPerCubicMetre  MassConcentration = 1000 * GramPerCubicMetre
+
+	maxMassConcentration = 9223372036854775807 * NanoGramPerCubicMetre
+	minMassConcentration = -9223372036854775807 * NanoGramPerCubicMetre
+)
+
+// SoundPressureLevel is a measurement of the sound pressure relative to the
+// threshold of human hearing of 20µPa, in decibels. It is stored as nano
+// decibels.
+//
+// It is a logarithmic quantity; doubling the sound pressure increases the
+// level by about 6dB.
+//
+// The highest representable value is 9223372036dB.
+type SoundPressureLevel int64
+
+// String returns the sound pressure level formatted as a string in dB.
+//
+// Decibel is not a S.I. unit, so it is not prefixed by S.I. prefixes.
+func (l SoundPressureLevel) String() string {
+	return decimalAsString(int64(l), int64(Decibel)) + "dB"
+}
+
+// Set sets the SoundPressureLevel to the value represented by s. Units are
+// to be provided in "dB". S.I. prefixes are not supported.
+func (l *SoundPressureLevel) Set(s string) error {
+	d, n, err := atod(s)
+	if err != nil {
+		if e, ok := err.(*parseError); ok {
+			switch e.error {
+			case errNotANumber:
+				if found := hasSuffixes(s[n:], "dB"); found != "" {
+					return err
+				}
+				return notNumberUnitErr("dB")
+			case errOverflowsInt64:
+				return maxValueErr(maxSoundPressureLevel.String())
+			case errOverflowsInt64Negative:
+				return minValueErr(minSoundPressureLevel.String())
+			}
+		}
+		return err
+	}
+
+	switch s[n:] {
+	case "dB":
+		v, overflow := dtoi(d, 9)
+		if overflow {
+			if d.neg {
+				return minValueErr(minSoundPressureLevel.String())
+			}
+			return maxValueErr(maxSoundPressureLevel.String())
+		}
+		*l = (SoundPressureLevel)(v)
+	case "":
+		return noUnitErr("dB")
+	default:
+		return incorrectUnitErr("dB")
+	}
+	return nil
+}
+
+// Well known SoundPressureLevel constants.
+const (
+	NanoDecibel  SoundPressureLevel = 1
+	MilliDecibel SoundPressureLevel = 1000000 * NanoDecibel
+	Decibel      SoundPressureLevel = 1000 * MilliDecibel
+
+	maxSoundPressureLevel = 9223372036854775807 * NanoDecibel
+	minSoundPressureLevel = -9223372036854775807 * NanoDecibel
+)
+
+// Acceleration is a measurement of the rate of change of speed, stored as
+// nano metres per second squared.
+//
+// The highest representable value is 9.2Gm/s².
+type Acceleration int64
+
+// String returns the acceleration formatted as a string in m/s².
+func (a Acceleration) String() string {
+	return nanoAsString(int64(a)) + "m/s²"
+}
+
+// Set sets the Acceleration to the value represented by s. Units are to be
+// provided in "m/s²" or "m/s2" with an optional SI prefix: "p", "n", "u",
+// "µ", "m", "k", "M", "G" or "T".
+func (a *Acceleration) Set(s string) error {
+	d, n, err := atod(s)
+	if err != nil {
+		if e, ok := err.(*parseError); ok {
+			switch e.error {
+			case errNotANumber:
+				if found := hasSuffixes(s[n:], "m/s²", "m/s2"); found != "" {
+					return err
+				}
+				return notNumberUnitErr("m/s² or m/s2")
+			case errOverflowsInt64:
+				return maxValueErr(maxAcceleration.String())
+			case errOverflowsInt64Negative:
+				return minValueErr(minAcceleration.String())
+			}
+		}
+		return err
+	}
+
+	var si prefix
+	if n != len(s) {
+		r, rsize := utf8.DecodeRuneInString(s[n:])
+		if r <= 1 || rsize == 0 {
+			return errors.New("unexpected end of string")
+		}
+		var siSize int
+		si, siSize = parseSIPrefix(r)
+		if si == milli {
+			switch s[n:] {
+			case "m/s²", "m/s2":
+				si = unit
+				siSize = 0
+			}
+		}
+		n += siSize
+	}
+
+	switch s[n:] {
+	case "m/s²", "m/s2":
+		v, overflow := dtoi(d, int(si-nano))
+		if overflow {
+			if d.neg {
+				return minValueErr(minAcceleration.String())
+			}
+			return maxValueErr(maxAcceleration.String())
+		}
+		*a = (Acceleration)(v)
+	case "":
+		return noUnitErr("m/s² or m/s2")
+	default:
+		if found := hasSuffixes(s[n:], "m/s²", "m/s2"); found != "" {
+			return unknownUnitPrefixErr(found, "p,n,u,µ,m,k,M,G or T")
+		}
+		return incorrectUnitErr("m/s² or m/s2")
+	}
+	return nil
+}
+
+// Well known Acceleration constants.
+const (
+	NanoMetrePerSecondSquared  Acceleration = 1
+	MicroMetrePerSecondSquared Acceleration = 1000 * NanoMetrePerSecondSquared
+	MilliMetrePerSecondSquared Acceleration = 1000 * MicroMetrePerSecondSquared
+	MetrePerSecondSquared      Acceleration = 1000 * MilliMetrePerSecondSquared
+	KiloMetrePerSecondSquared  Acceleration = 1000 * MetrePerSecondSquared
+
+	// StandardGravity is the nominal acceleration due to gravity at sea level,
+	// usually noted g₀. Accelerometers commonly report their readings as a
+	// multiple of it.
+	StandardGravity Acceleration = 9806650 * MicroMetrePerSecondSquared
+
+	maxAcceleration = 9223372036854775807 * NanoMetrePerSecondSquared
+	minAcceleration = -9223372036854775807 * NanoMetrePerSecondSquared
+)
+
+// AngularVelocity is a measurement of the rate of rotation, stored as nano
+// radians per second.
+//
+// The highest representable value is 9.2Grad/s.
+type AngularVelocity int64
+
+// String returns the angular velocity formatted as a string in rad/s.
+func (a AngularVelocity) String() string {
+	return nanoAsString(int64(a)) + "rad/s"
+}
+
+// Set sets the AngularVelocity to the value represented by s. Units are to be
+// provided in "rad/s", "°/s", "deg/s" or "dps" with an optional SI prefix:
+// "p", "n", "u", "µ", "m", "k", "M", "G" or "T".
+func (a *AngularVelocity) Set(s string) error {
+	d, n, err := atod(s)
+	if err != nil {
+		if e, ok := err.(*parseError); ok {
+			switch e.error {
+			case errNotANumber:
+				if found := hasSuffixes(s[n:], "rad/s", "°/s", "deg/s", "dps"); found != "" {
+					return err
+				}
+				return notNumberUnitErr("rad/s, °/s, deg/s or dps")
+			case errOverflowsInt64:
+				return maxValueErr(maxAngularVelocity.String())
+			case errOverflowsInt64Negative:
+				return minValueErr(minAngularVelocity.String())
+			}
+		}
+		return err
+	}
+
+	var si prefix
+	if n != len(s) {
+		r, rsize := utf8.DecodeRuneInString(s[n:])
+		if r <= 1 || rsize == 0 {
+			return errors.New("unexpected end of string")
+		}
+		var siSize int
+		si, siSize = parseSIPrefix(r)
+		n += siSize
+	}
+
+	switch s[n:] {
+	case "°/s", "deg/s", "dps":
+		nanoRadianPerDegree := decimal{
+			base: uint64(DegreePerSecond),
+			exp:  0,
+			neg:  false,
+		}
+		deg, _ := decimalMul(d, nanoRadianPerDegree)
+		v, overflow := dtoi(deg, int(si))
+		if overflow {
+			if deg.neg {
+				return minValueErr(minAngularVelocity.String())
+			}
+			return maxValueErr(maxAngularVelocity.String())
+		}
+		*a = (AngularVelocity)(v)
+	case "rad/s":
+		v, overflow := dtoi(d, int(si-nano))
+		if overflow {
+			if d.neg {
+				return minValueErr(minAngularVelocity.String())
+			}
+			return maxValueErr(maxAngularVelocity.String())
+		}
+		*a = (AngularVelocity)(v)
+	case "":
+		return noUnitErr("rad/s, °/s, deg/s or dps")
+	default:
+		if found := hasSuffixes(s[n:], "rad/s", "°/s", "deg/s", "dps"); found != "" {
+			return unknownUnitPrefixErr(found, "p,n,u,µ,m,k,M,G or T")
+		}
+		return incorrectUnitErr("rad/s, °/s, deg/s or dps")
+	}
+	return nil
+}
+
+// Well known AngularVelocity constants.
+const (
+	NanoRadianPerSecond  AngularVelocity = 1
+	MicroRadianPerSecond AngularVelocity = 1000 * NanoRadianPerSecond
+	MilliRadianPerSecond AngularVelocity = 1000 * MicroRadianPerSecond
+	RadianPerSecond      AngularVelocity = 1000 * MilliRadianPerSecond
+
+	DegreePerSecond AngularVelocity = 17453293 * NanoRadianPerSecond
+
+	maxAngularVelocity = 9223372036854775807 * NanoRadianPerSecond
+	minAngularVelocity = -9223372036854775807 * NanoRadianPerSecond
+)
+
 //
 
 func prefixZeros(digits, v int) string {
@@ -1683,6 +2206,30 @@ func prefixZeros(digits, v int) string {
 	return s
 }
 
+// decimalAsString returns v/div formatted with up to 3 decimals.
+//
+// It is used for units that are not prefixed by S.I. prefixes.
+func decimalAsString(v, div int64) string {
+	sign := ""
+	if v < 0 {
+		if v == -9223372036854775808 {
+			v++
+		}
+		sign = "-"
+		v = -v
+	}
+	i := v / div
+	f := ((v%div)*1000 + div/2) / div
+	if f == 1000 {
+		i++
+		f = 0
+	}
+	if f == 0 {
+		return sign + strconv.FormatInt(i, 10)
+	}
+	return sign + strconv.FormatInt(i, 10) + "." + strings.TrimRight(prefixZeros(3, int(f)), "0")
+}
+
 // nanoAsString converts a value in S.I. unit in a string with the predefined
 // prefix.
 func nanoAsString(v int64) string {
@@ -364,6 +364,117 @@ func TestFlux_String(t *testing.T) {
 	}
 }
 
+func TestIlluminance_String(t *testing.T) {
+	if s := NanoLux.String(); s != "1nlx" {
+		t.Fatalf("%v", s)
+	}
+	if s := Lux.String(); s != "1lx" {
+		t.Fatalf("%v", s)
+	}
+	if s := (12500 * MilliLux).String(); s != "12.500lx" {
+		t.Fatalf("%v", s)
+	}
+	if s := GigaLux.String(); s != "1Glx" {
+		t.Fatalf("%v", s)
+	}
+}
+
+func TestIrradiance_String(t *testing.T) {
+	if s := NanoWattPerSquareMetre.String(); s != "1nW/m²" {
+		t.Fatalf("%v", s)
+	}
+	if s := WattPerSquareMetre.String(); s != "1W/m²" {
+		t.Fatalf("%v", s)
+	}
+	if s := MicroWattPerSquareCentiMetre.String(); s != "10mW/m²" {
+		t.Fatalf("%v", s)
+	}
+}
+
+func TestConcentration_String(t *testing.T) {
+	data := []struct {
+		in       Concentration
+		expected string
+	}{
+		{0, "0ppt"},
+		{PartPerQuadrillion, "0.001ppt"},
+		{PartPerTrillion, "1ppt"},
+		{999 * PartPerTrillion, "999ppt"},
+		{PartPerBillion, "1ppb"},
+		{1187 * PartPerBillion, "1.187ppm"},
+		{400 * PartPerMillion, "400ppm"},
+		{400*PartPerMillion + 4*PartPerBillion, "400.004ppm"},
+		{400*PartPerMillion + 9999*PartPerBillion/10, "401ppm"},
+		{-15 * PartPerBillion, "-15ppb"},
+		{Percent, "10000ppm"},
+		{maxConcentration, "9223372036.855ppm"},
+		{minConcentration, "-9223372036.855ppm"},
+	}
+	for i, line := range data {
+		if s := line.in.String(); s != line.expected {
+			t.Fatalf("#%d: %d: %q != %q", i, int64(line.in), s, line.expected)
+		}
+	}
+}
+
+func TestMassConcentration_String(t *testing.T) {
+	if s := NanoGramPerCubicMetre.String(); s != "1ng/m³" {
+		t.Fatalf("%v", s)
+	}
+	if s := (25 * MicroGramPerCubicMetre).String(); s != "25µg/m³" {
+		t.Fatalf("%v", s)
+	}
+	if s := GramPerCubicMetre.String(); s != "1g/m³" {
+		t.Fatalf("%v", s)
+	}
+	if s := KiloGramPerCubicMetre.String(); s != "1kg/m³" {
+		t.Fatalf("%v", s)
+	}
+}
+
+func TestSoundPressureLevel_String(t *testing.T) {
+	data := []struct {
+		in       SoundPressureLevel
+		expected string
+	}{
+		{0, "0dB"},
+		{NanoDecibel, "0dB"},
+		{MilliDecibel, "0.001dB"},
+		{65 * Decibel, "65dB"},
+		{655 * Decibel / 10, "65.5dB"},
+		{-3 * Decibel, "-3dB"},
+	}
+	for i, line := range data {
+		if s := line.in.String(); s != line.expected {
+			t.Fatalf("#%d: %d: %q != %q", i, int64(line.in), s, line.expected)
+		}
+	}
+}
+
+func TestAcceleration_String(t *testing.T) {
+	if s := NanoMetrePerSecondSquared.String(); s != "1nm/s²" {
+		t.Fatalf("%v", s)
+	}
+	if s := MetrePerSecondSquared.String(); s != "1m/s²" {
+		t.Fatalf("%v", s)
+	}
+	if s := StandardGravity.String(); s != "9.807m/s²" {
+		t.Fatalf("%v", s)
+	}
+}
+
+func TestAngularVelocity_String(t *testing.T) {
+	if s := MilliRadianPerSecond.String(); s != "1mrad/s" {
+		t.Fatalf("%v", s)
+	}
+	if s := RadianPerSecond.String(); s != "1rad/s" {
+		t.Fatalf("%v", s)
+	}
+	if s := DegreePerSecond.String(); s != "17.453mrad/s" {
+		t.Fatalf("%v", s)
+	}
+}
+
 func TestPicoAsString(t *testing.T) {
 	data := []struct {
 		in       int64
@@ -3658,6 +3769,532 @@ func TestLuminousFlux_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestIlluminance_Set(t *testing.T) {
+	succeeds := []struct {
+		in       string
+		expected Illuminance
+	}{
+		{"1nlx", 1 * NanoLux},
+		{"1ulx", 1 * MicroLux},
+		{"1µlx", 1 * MicroLux},
+		{"1mlx", 1 * MilliLux},
+		{"1lx", 1 * Lux},
+		{"12.345lx", 12345 * MilliLux},
+		{"-12.345lx", -12345 * MilliLux},
+		{"1klx", 1 * KiloLux},
+		{"1Mlx", 1 * MegaLux},
+		{"1Glx", 1 * GigaLux},
+		{"9.223372036854775807Glx", 9223372036854775807 * NanoLux},
+	}
+
+	fails := []struct {
+		in  string
+		err string
+	}{
+		{
+			"10Tlx",
+			"maximum value is 9.223Glx",
+		},
+		{
+			"-10Tlx",
+			"minimum value is -9.223Glx",
+		},
+		{
+			"10Elx",
+			"unknown unit prefix; valid prefixes for \"lx\" are p,n,u,µ,m,k,M,G or T",
+		},
+		{
+			"10",
+			"no unit provided; need lx",
+		},
+		{
+			"1random",
+			"unknown unit provided; need lx",
+		},
+		{
+			"lx",
+			"not a number",
+		},
+		{
+			"RPM",
+			"does not contain number or unit lx",
+		},
+	}
+
+	for i, tt := range succeeds {
+		var got Illuminance
+		if err := got.Set(tt.in); err != nil {
+			t.Errorf("#%d: Illuminance.Set(%s) got unexpected error: %v", i, tt.in, err)
+		}
+		if got != tt.expected {
+			t.Errorf("#%d: Illuminance.Set(%s) expected: %v(%d) but got: %v(%d)", i, tt.in, tt.expected, tt.expected, got, got)
+		}
+	}
+
+	for i, tt := range fails {
+		var got Illuminance
+		if err := got.Set(tt.in); err == nil || err.Error() != tt.err {
+			t.Errorf("#%d: Illuminance.Set(%s) \nexpected: %s\ngot:      %s", i, tt.in, tt.err, err)
+		}
+	}
+}
+
+func TestIlluminance_RoundTrip(t *testing.T) {
+	x := 123 * Lux
+	var y Illuminance
+	if err := y.Set(x.String()); err != nil {
+		t.Fatalf("Illuminance.Set(stringer) failed: %v", err)
+	}
+	if x != y {
+		t.Fatalf("Illuminance expected %s to equal %s", x, y)
+	}
+}
+
+func TestIrradiance_Set(t *testing.T) {
+	succeeds := []struct {
+		in       string
+		expected Irradiance
+	}{
+		{"1nW/m²", 1 * NanoWattPerSquareMetre},
+		{"1uW/m2", 1 * MicroWattPerSquareMetre},
+		{"1mW/m²", 1 * MilliWattPerSquareMetre},
+		{"1W/m²", 1 * WattPerSquareMetre},
+		{"-1.5W/m2", -1500 * MilliWattPerSquareMetre},
+		{"1kW/m²", 1 * KiloWattPerSquareMetre},
+	}
+
+	fails := []struct {
+		in  string
+		err string
+	}{
+		{
+			"10TW/m²",
+			"maximum value is 9.223GW/m²",
+		},
+		{
+			"-10TW/m²",
+			"minimum value is -9.223GW/m²",
+		},
+		{
+			"10EW/m2",
+			"unknown unit prefix; valid prefixes for \"W/m2\" are p,n,u,µ,m,k,M,G or T",
+		},
+		{
+			"10",
+			"no unit provided; need W/m² or W/m2",
+		},
+		{
+			"10W",
+			"unknown unit provided; need W/m² or W/m2",
+		},
+		{
+			"RPM",
+			"does not contain number or unit W/m² or W/m2",
+		},
+	}
+
+	for i, tt := range succeeds {
+		var got Irradiance
+		if err := got.Set(tt.in); err != nil {
+			t.Errorf("#%d: Irradiance.Set(%s) got unexpected error: %v", i, tt.in, err)
+		}
+		if got != tt.expected {
+			t.Errorf("#%d: Irradiance.Set(%s) expected: %v(%d) but got: %v(%d)", i, tt.in, tt.expected, tt.expected, got, got)
+		}
+	}
+
+	for i, tt := range fails {
+		var got Irradiance
+		if err := got.Set(tt.in); err == nil || err.Error() != tt.err {
+			t.Errorf("#%d: Irradiance.Set(%s) \nexpected: %s\ngot:      %s", i, tt.in, tt.err, err)
+		}
+	}
+}
+
+func TestIrradiance_RoundTrip(t *testing.T) {
+	x := 123 * WattPerSquareMetre
+	var y Irradiance
+	if err := y.Set(x.String()); err != nil {
+		t.Fatalf("Irradiance.Set(stringer) failed: %v", err)
+	}
+	if x != y {
+		t.Fatalf("Irradiance expected %s to equal %s", x, y)
+	}
+}
+
+func TestConcentration_Set(t *testing.T) {
+	succeeds := []struct {
+		in       string
+		expected Concentration
+	}{
+		{"1ppt", 1 * PartPerTrillion},
+		{"0.001ppt", 1 * PartPerQuadrillion},
+		{"1ppb", 1 * PartPerBillion},
+		{"1187ppb", 1187 * PartPerBillion},
+		{"400ppm", 400 * PartPerMillion},
+		{"1.5ppm", 1500 * PartPerBillion},
+		{"-15ppb", -15 * PartPerBillion},
+		{"9223372036.854775807ppm", maxConcentration},
+	}
+
+	fails := []struct {
+		in  string
+		err string
+	}{
+		{
+			"10000000000ppm",
+			"maximum value is 9223372036.855ppm",
+		},
+		{
+			"-10000000000ppm",
+			"minimum value is -9223372036.855ppm",
+		},
+		{
+			"9223372036854775808",
+			"maximum value is 9223372036.855ppm",
+		},
+		{
+			"1kppm",
+			"unknown unit provided; need ppm, ppb or ppt",
+		},
+		{
+			"10",
+			"no unit provided; need ppm, ppb or ppt",
+		},
+		{
+			"1%",
+			"unknown unit provided; need ppm, ppb or ppt",
+		},
+		{
+			"ppm",
+			"not a number",
+		},
+		{
+			"RPM",
+			"does not contain number or unit ppm, ppb or ppt",
+		},
+		{
+			"++1ppm",
+			"contains multiple plus symbols",
+		},
+	}
+
+	for i, tt := range succeeds {
+		var got Concentration
+		if err := got.Set(tt.in); err != nil {
+			t.Errorf("#%d: Concentration.Set(%s) got unexpected error: %v", i, tt.in, err)
+		}
+		if got != tt.expected {
+			t.Errorf("#%d: Concentration.Set(%s) expected: %v(%d) but got: %v(%d)", i, tt.in, tt.expected, tt.expected, got, got)
+		}
+	}
+
+	for i, tt := range fails {
+		var got Concentration
+		if err := got.Set(tt.in); err == nil || err.Error() != tt.err {
+			t.Errorf("#%d: Concentration.Set(%s) \nexpected: %s\ngot:      %s", i, tt.in, tt.err, err)
+		}
+	}
+}
+
+func TestConcentration_RoundTrip(t *testing.T) {
+	for _, x := range []Concentration{12 * PartPerTrillion, 850 * PartPerBillion, 400 * PartPerMillion} {
+		var y Concentration
+		if err := y.Set(x.String()); err != nil {
+			t.Fatalf("Concentration.Set(stringer) failed: %v", err)
+		}
+		if x != y {
+			t.Fatalf("Concentration expected %s to equal %s", x, y)
+		}
+	}
+}
+
+func TestMassConcentration_Set(t *testing.T) {
+	succeeds := []struct {
+		in       string
+		expected MassConcentration
+	}{
+		{"1ng/m³", 1 * NanoGramPerCubicMetre},
+		{"12µg/m³", 12 * MicroGramPerCubicMetre},
+		{"12ug/m3", 12 * MicroGramPerCubicMetre},
+		{"2.5mg/m3", 2500 * MicroGramPerCubicMetre},
+		{"1g/m³", 1 * GramPerCubicMetre},
+		{"1.2kg/m³", 1200 * GramPerCubicMetre},
+	}
+
+	fails := []struct {
+		in  string
+		err string
+	}{
+		{
+			"10Tg/m³",
+			"maximum value is 9.223Gg/m³",
+		},
+		{
+			"10Eg/m3",
+			"unknown unit prefix; valid prefixes for \"g/m3\" are p,n,u,µ,m,k,M,G or T",
+		},
+		{
+			"10",
+			"no unit provided; need g/m³ or g/m3",
+		},
+		{
+			"10g",
+			"unknown unit provided; need g/m³ or g/m3",
+		},
+		{
+			"RPM",
+			"does not contain number or unit g/m³ or g/m3",
+		},
+	}
+
+	for i, tt := range succeeds {
+		var got MassConcentration
+		if err := got.Set(tt.in); err != nil {
+			t.Errorf("#%d: MassConcentration.Set(%s) got unexpected error: %v", i, tt.in, err)
+		}
+		if got != tt.expected {
+			t.Errorf("#%d: MassConcentration.Set(%s) expected: %v(%d) but got: %v(%d)", i, tt.in, tt.expected, tt.expected, got, got)
+		}
+	}
+
+	for i, tt := range fails {
+		var got MassConcentration
+		if err := got.Set(tt.in); err == nil || err.Error() != tt.err {
+			t.Errorf("#%d: MassConcentration.Set(%s) \nexpected: %s\ngot:      %s", i, tt.in, tt.err, err)
+		}
+	}
+}
+
+func TestMassConcentration_RoundTrip(t *testing.T) {
+	x := 35 * MicroGramPerCubicMetre
+	var y MassConcentration
+	if err := y.Set(x.String()); err != nil {
+		t.Fatalf("MassConcentration.Set(stringer) failed: %v", err)
+	}
+	if x != y {
+		t.Fatalf("MassConcentration expected %s to equal %s", x, y)
+	}
+}
+
+func TestSoundPressureLevel_Set(t *testing.T) {
+	succeeds := []struct {
+		in       string
+		expected SoundPressureLevel
+	}{
+		{"0dB", 0},
+		{"65dB", 65 * Decibel},
+		{"65.5dB", 65500 * MilliDecibel},
+		{"-3dB", -3 * Decibel},
+		{"0.001dB", MilliDecibel},
+	}
+
+	fails := []struct {
+		in  string
+		err string
+	}{
+		{
+			"10000000000dB",
+			"maximum value is 9223372036.855dB",
+		},
+		{
+			"-10000000000dB",
+			"minimum value is -9223372036.855dB",
+		},
+		{
+			"1mdB",
+			"unknown unit provided; need dB",
+		},
+		{
+			"10",
+			"no unit provided; need dB",
+		},
+		{
+			"dB",
+			"not a number",
+		},
+		{
+			"RPM",
+			"does not contain number or unit dB",
+		},
+	}
+
+	for i, tt := range succeeds {
+		var got SoundPressureLevel
+		if err := got.Set(tt.in); err != nil {
+			t.Errorf("#%d: SoundPressureLevel.Set(%s) got unexpected error: %v", i, tt.in, err)
+		}
+		if got != tt.expected {
+			t.Errorf("#%d: SoundPressureLevel.Set(%s) expected: %v(%d) but got: %v(%d)", i, tt.in, tt.expected, tt.expected, got, got)
+		}
+	}
+
+	for i, tt := range fails {
+		var got SoundPressureLevel
+		if err := got.Set(tt.in); err == nil || err.Error() != tt.err {
+			t.Errorf("#%d: SoundPressureLevel.Set(%s) \nexpected: %s\ngot:      %s", i, tt.in, tt.err, err)
+		}
+	}
+}
+
+func TestSoundPressureLevel_RoundTrip(t *testing.T) {
+	x := 425 * Decibel / 10
+	var y SoundPressureLevel
+	if err := y.Set(x.String()); err != nil {
+		t.Fatalf("SoundPressureLevel.Set(stringer) failed: %v", err)
+	}
+	if x != y {
+		t.Fatalf("SoundPressureLevel expected %s to equal %s", x, y)
+	}
+}
+
+func TestAcceleration_Set(t *testing.T) {
+	succeeds := []struct {
+		in       string
+		expected Acceleration
+	}{
+		{"1nm/s²", 1 * NanoMetrePerSecondSquared},
+		{"1um/s2", 1 * MicroMetrePerSecondSquared},
+		{"1mm/s²", 1 * MilliMetrePerSecondSquared},
+		{"1m/s²", 1 * MetrePerSecondSquared},
+		{"9.80665m/s2", StandardGravity},
+		{"-1.5m/s²", -1500 * MilliMetrePerSecondSquared},
+		{"1km/s²", 1 * KiloMetrePerSecondSquared},
+	}
+
+	fails := []struct {
+		in  string
+		err string
+	}{
+		{
+			"10Tm/s²",
+			"maximum value is 9.223Gm/s²",
+		},
+		{
+			"-10Tm/s²",
+			"minimum value is -9.223Gm/s²",
+		},
+		{
+			"10Em/s2",
+			"unknown unit prefix; valid prefixes for \"m/s2\" are p,n,u,µ,m,k,M,G or T",
+		},
+		{
+			"10",
+			"no unit provided; need m/s² or m/s2",
+		},
+		{
+			"10m/s",
+			"unknown unit provided; need m/s² or m/s2",
+		},
+		{
+			"RPM",
+			"does not contain number or unit m/s² or m/s2",
+		},
+	}
+
+	for i, tt := range succeeds {
+		var got Acceleration
+		if err := got.Set(tt.in); err != nil {
+			t.Errorf("#%d: Acceleration.Set(%s) got unexpected error: %v", i, tt.in, err)
+		}
+		if got != tt.expected {
+			t.Errorf("#%d: Acceleration.Set(%s) expected: %v(%d) but got: %v(%d)", i, tt.in, tt.expected, tt.expected, got, got)
+		}
+	}
+
+	for i, tt := range fails {
+		var got Acceleration
+		if err := got.Set(tt.in); err == nil || err.Error() != tt.err {
+			t.Errorf("#%d: Acceleration.Set(%s) \nexpected: %s\ngot:      %s", i, tt.in, tt.err, err)
+		}
+	}
+}
+
+func TestAcceleration_RoundTrip(t *testing.T) {
+	x := 123 * MetrePerSecondSquared
+	var y Acceleration
+	if err := y.Set(x.String()); err != nil {
+		t.Fatalf("Acceleration.Set(stringer) failed: %v", err)
+	}
+	if x != y {
+		t.Fatalf("Acceleration expected %s to equal %s", x, y)
+	}
+}
+
+func TestAngularVelocity_Set(t *testing.T) {
+	succeeds := []struct {
+		in       string
+		expected AngularVelocity
+	}{
+		{"1nrad/s", 1 * NanoRadianPerSecond},
+		{"1mrad/s", 1 * MilliRadianPerSecond},
+		{"1rad/s", 1 * RadianPerSecond},
+		{"-2.5rad/s", -2500 * MilliRadianPerSecond},
+		{"1°/s", DegreePerSecond},
+		{"1deg/s", DegreePerSecond},
+		{"250dps", 250 * DegreePerSecond},
+		{"2k°/s", 2000 * DegreePerSecond},
+	}
+
+	fails := []struct {
+		in  string
+		err string
+	}{
+		{
+			"10Trad/s",
+			"maximum value is 9.223Grad/s",
+		},
+		{
+			"-10Trad/s",
+			"minimum value is -9.223Grad/s",
+		},
+		{
+			"10Erad/s",
+			"unknown unit prefix; valid prefixes for \"rad/s\" are p,n,u,µ,m,k,M,G or T",
+		},
+		{
+			"10",
+			"no unit provided; need rad/s, °/s, deg/s or dps",
+		},
+		{
+			"10rpm",
+			"unknown unit provided; need rad/s, °/s, deg/s or dps",
+		},
+		{
+			"RPM",
+			"does not contain number or unit rad/s, °/s, deg/s or dps",
+		},
+	}
+
+	for i, tt := range succeeds {
+		var got AngularVelocity
+		if err := got.Set(tt.in); err != nil {
+			t.Errorf("#%d: AngularVelocity.Set(%s) got unexpected error: %v", i, tt.in, err)
+		}
+		if got != tt.expected {
+			t.Errorf("#%d: AngularVelocity.Set(%s) expected: %v(%d) but got: %v(%d)", i, tt.in, tt.expected, tt.expected, got, got)
+		}
+	}
+
+	for i, tt := range fails {
+		var got AngularVelocity
+		if err := got.Set(tt.in); err == nil || err.Error() != tt.err {
+			t.Errorf("#%d: AngularVelocity.Set(%s) \nexpected: %s\ngot:      %s", i, tt.in, tt.err, err)
+		}
+	}
+}
+
+func TestAngularVelocity_RoundTrip(t *testing.T) {
+	x := 123 * RadianPerSecond
+	var y AngularVelocity
+	if err := y.Set(x.String()); err != nil {
+		t.Fatalf("AngularVelocity.Set(stringer) failed: %v", err)
+	}
+	if x != y {
+		t.Fatalf("AngularVelocity expected %s to equal %s", x, y)
+	}
+}
+
 // Benchmarks
 
 func BenchmarkDecimal(b *testing.B) {
@@ -0,0 +1,226 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package sensor defines a generic measurement abstraction over sensors.
+//
+// Sensor drivers expose different APIs, each returning its own struct.
+// Sensor lets tools read any sensor uniformly as a list of named quantities
+// using the unit types of package physic.
+//
+// Drivers implementing physic.SenseEnv can be adapted with FromEnv(). Other
+// drivers implement Sensor directly.
+package sensor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+// Quantity is the name of a measured physical quantity.
+type Quantity string
+
+// Well known quantities and the type used for their Value.
+const (
+	Temperature         Quantity = "temperature"           // physic.Temperature
+	Pressure            Quantity = "pressure"              // physic.Pressure
+	Humidity            Quantity = "humidity"              // physic.RelativeHumidity
+	Voltage             Quantity = "voltage"               // physic.ElectricPotential
+	Current             Quantity = "current"               // physic.ElectricCurrent
+	Power               Quantity = "power"                 // physic.Power
+	LuminousFlux        Quantity = "luminous_flux"         // physic.LuminousFlux
+	MagneticFluxDensity Quantity = "magnetic_flux_density" // physic.MagneticFluxDensity
+	SpectralIrradiance  Quantity = "spectral_irradiance"   // Number in µW/cm²
+	Illuminance         Quantity = physic.EnvIlluminance   // physic.Illuminance
+	CO2                 Quantity = physic.EnvCO2           // physic.Concentration
+	TVOC                Quantity = physic.EnvTVOC          // physic.Concentration
+	SoundLevel          Quantity = physic.EnvSoundLevel    // physic.SoundPressureLevel
+	PM1                 Quantity = physic.EnvPM1           // physic.MassConcentration
+	PM2_5               Quantity = physic.EnvPM2_5         // physic.MassConcentration
+	PM10                Quantity = physic.EnvPM10          // physic.MassConcentration
+	Acceleration        Quantity = "acceleration"          // physic.Acceleration
+	AngularVelocity     Quantity = "angular_velocity"      // physic.AngularVelocity
+	Raw                 Quantity = "raw"                   // Number, unitless raw reading
+)
+
+// Value is a measured value.
+//
+// It is one of the physic unit types, or Number for quantities without a
+// matching physic type.
+type Value interface {
+	fmt.Stringer
+}
+
+// Number is a value in a unit not supported by package physic. The unit is
+// documented along the Quantity.
+type Number float64
+
+func (n Number) String() string {
+	return fmt.Sprintf("%g", float64(n))
+}
+
+// Measurement is a single value read from a sensor.
+type Measurement struct {
+	Quantity Quantity
+	// Channel differentiates multiple values of the same quantity measured by a
+	// sensor, e.g. "x", "y" and "z" or "shunt" and "bus". It is empty when the
+	// sensor measures a single value of this quantity.
+	Channel string
+	Value   Value
+	// Precision is the smallest significant step of Value, of the same type.
+	// It is nil when unknown.
+	//
+	// Precision is not accuracy; see physic.SenseEnv.Precision().
+	Precision Value
+	// Time is when the value was read.
+	Time time.Time
+}
+
+func (m *Measurement) String() string {
+	n := string(m.Quantity)
+	if m.Channel != "" {
+		n += "[" + m.Channel + "]"
+	}
+	return n + "=" + m.Value.String()
+}
+
+// Float returns the value as a float64 in its SI base unit, e.g. Kelvin for
+// a temperature or Ampere for a current. Humidity is returned in %rH, a
+// concentration as a ratio, a sound level in dB and Number as is.
+//
+// It returns false if the type of the value is not supported.
+func Float(v Value) (float64, bool) {
+	switch v := v.(type) {
+	case physic.Acceleration:
+		return float64(v) / float64(physic.MetrePerSecondSquared), true
+	case physic.Angle:
+		return float64(v) / float64(physic.Radian), true
+	case physic.AngularVelocity:
+		return float64(v) / float64(physic.RadianPerSecond), true
+	case physic.Concentration:
+		return float64(v) / float64(100*physic.Percent), true
+	case physic.Distance:
+		return float64(v) / float64(physic.Metre), true
+	case physic.ElectricCurrent:
+		return float64(v) / float64(physic.Ampere), true
+	case physic.ElectricPotential:
+		return float64(v) / float64(physic.Volt), true
+	case physic.ElectricResistance:
+		return float64(v) / float64(physic.Ohm), true
+	case physic.ElectricalCapacitance:
+		return float64(v) / float64(physic.Farad), true
+	case physic.Energy:
+		return float64(v) / float64(physic.Joule), true
+	case physic.Force:
+		return float64(v) / float64(physic.Newton), true
+	case physic.Frequency:
+		return float64(v) / float64(physic.Hertz), true
+	case physic.Illuminance:
+		return float64(v) / float64(physic.Lux), true
+	case physic.Irradiance:
+		return float64(v) / float64(physic.WattPerSquareMetre), true
+	case physic.LuminousFlux:
+		return float64(v) / float64(physic.Lumen), true
+	case physic.LuminousIntensity:
+		return float64(v) / float64(physic.Candela), true
+	case physic.MagneticFluxDensity:
+		return float64(v) / float64(physic.Tesla), true
+	case physic.Mass:
+		return float64(v) / float64(physic.KiloGram), true
+	case physic.MassConcentration:
+		return float64(v) / float64(physic.KiloGramPerCubicMetre), true
+	case physic.Power:
+		return float64(v) / float64(physic.Watt), true
+	case physic.Pressure:
+		return float64(v) / float64(physic.Pascal), true
+	case physic.RelativeHumidity:
+		return float64(v) / float64(physic.PercentRH), true
+	case physic.SoundPressureLevel:
+		return float64(v) / float64(physic.Decibel), true
+	case physic.Speed:
+		return float64(v) / float64(physic.MetrePerSecond), true
+	case physic.Temperature:
+		return float64(v) / float64(physic.Kelvin), true
+	case Number:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// Sensor is a sensor that can be read generically.
+type Sensor interface {
+	conn.Resource
+	// Measure reads all the quantities measured by the sensor.
+	Measure() ([]Measurement, error)
+}
+
+// FromEnv adapts an environmental sensor.
+//
+// The quantities reported are the ones for which the sensor reports a
+// non-zero precision. When s implements physic.SenseEnvExtra, the ones in
+// physic.EnvExtra.Extra are also reported; their name is used as the
+// Quantity.
+func FromEnv(s physic.SenseEnv) Sensor {
+	a := &envSensor{s: s}
+	if x, ok := s.(physic.SenseEnvExtra); ok {
+		a.x = x
+		x.PrecisionExtra(&a.p)
+	} else {
+		s.Precision(&a.p.Env)
+	}
+	return a
+}
+
+//
+
+type envSensor struct {
+	s physic.SenseEnv
+	x physic.SenseEnvExtra // Set when s implements it.
+	p physic.EnvExtra
+}
+
+func (e *envSensor) String() string {
+	return e.s.String()
+}
+
+func (e *envSensor) Halt() error {
+	return e.s.Halt()
+}
+
+func (e *envSensor) Measure() ([]Measurement, error) {
+	var env physic.EnvExtra
+	if e.x != nil {
+		if err := e.x.SenseExtra(&env); err != nil {
+			return nil, err
+		}
+	} else if err := e.s.Sense(&env.Env); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var out []Measurement
+	if e.p.Temperature != 0 {
+		out = append(out, Measurement{Quantity: Temperature, Value: env.Temperature, Precision: e.p.Temperature, Time: now})
+	}
+	if e.p.Pressure != 0 {
+		out = append(out, Measurement{Quantity: Pressure, Value: env.Pressure, Precision: e.p.Pressure, Time: now})
+	}
+	if e.p.Humidity != 0 {
+		out = append(out, Measurement{Quantity: Humidity, Value: env.Humidity, Precision: e.p.Humidity, Time: now})
+	}
+	names := make([]string, 0, len(env.Extra))
+	for n := range env.Extra {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		out = append(out, Measurement{Quantity: Quantity(n), Value: env.Extra[n], Precision: e.p.Extra[n], Time: now})
+	}
+	return out, nil
+}
+
+var _ Sensor = &envSensor{}
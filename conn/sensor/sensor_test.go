@@ -0,0 +1,159 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sensor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+func TestFloat(t *testing.T) {
+	data := []struct {
+		v        Value
+		expected float64
+	}{
+		{physic.Radian, 1},
+		{physic.MilliMetre, 0.001},
+		{2 * physic.Ampere, 2},
+		{physic.MilliVolt, 0.001},
+		{physic.KiloOhm, 1000},
+		{physic.Farad, 1},
+		{physic.Joule, 1},
+		{physic.Newton, 1},
+		{physic.KiloHertz, 1000},
+		{physic.Lumen, 1},
+		{physic.Candela, 1},
+		{physic.MilliTesla, 0.001},
+		{physic.Gram, 0.001},
+		{physic.Watt, 1},
+		{physic.KiloPascal, 1000},
+		{50 * physic.PercentRH, 50},
+		{physic.MetrePerSecond, 1},
+		{physic.ZeroCelsius, 273.15},
+		{physic.StandardGravity, 9.80665},
+		{physic.RadianPerSecond, 1},
+		{400 * physic.PartPerMillion, 0.0004},
+		{physic.KiloLux, 1000},
+		{physic.MicroWattPerSquareCentiMetre, 0.01},
+		{12 * physic.MicroGramPerCubicMetre, 12e-9},
+		{65 * physic.Decibel, 65},
+		{Number(1.5), 1.5},
+	}
+	for i, line := range data {
+		f, ok := Float(line.v)
+		if !ok {
+			t.Fatalf("#%d: %T not supported", i, line.v)
+		}
+		if d := f - line.expected; d > 1e-9 || d < -1e-9 {
+			t.Fatalf("#%d: %g != %g", i, f, line.expected)
+		}
+	}
+	if _, ok := Float(time.Second); ok {
+		t.Fatal("unsupported type")
+	}
+}
+
+func TestMeasurement_String(t *testing.T) {
+	m := Measurement{Quantity: Voltage, Channel: "bus", Value: 5 * physic.Volt}
+	if s := m.String(); s != "voltage[bus]=5V" {
+		t.Fatal(s)
+	}
+	m = Measurement{Quantity: Raw, Value: Number(12)}
+	if s := m.String(); s != "raw=12" {
+		t.Fatal(s)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	e := &fakeEnv{e: physic.Env{Temperature: physic.ZeroCelsius, Humidity: 10 * physic.PercentRH}, p: physic.Env{Temperature: physic.MilliKelvin, Humidity: physic.MilliRH}}
+	s := FromEnv(e)
+	if s.String() != "fake" {
+		t.Fatal(s)
+	}
+	m, err := s.Measure()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m[0].Quantity != Temperature || m[0].Value != physic.ZeroCelsius || m[0].Precision != physic.MilliKelvin || m[1].Quantity != Humidity || m[1].Time.IsZero() {
+		t.Fatal(m)
+	}
+	e.err = errors.New("fail")
+	if _, err := s.Measure(); err == nil {
+		t.Fatal("expected failure")
+	}
+	if err := s.Halt(); err != nil || !e.halted {
+		t.Fatal(err)
+	}
+}
+
+func TestFromEnv_extra(t *testing.T) {
+	e := &fakeEnvExtra{p: physic.EnvExtra{Extra: map[string]fmt.Stringer{physic.EnvCO2: physic.PartPerMillion}}}
+	e.e.Set(physic.EnvTVOC, 12*physic.PartPerBillion)
+	e.e.Set(physic.EnvCO2, 400*physic.PartPerMillion)
+	m, err := FromEnv(e).Measure()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m[0].Quantity != CO2 || m[0].Precision != physic.PartPerMillion || m[1].Quantity != TVOC || m[1].Precision != nil {
+		t.Fatal(m)
+	}
+	if s := m[0].String(); s != "co2=400ppm" {
+		t.Fatal(s)
+	}
+}
+
+//
+
+type fakeEnv struct {
+	e      physic.Env
+	p      physic.Env
+	err    error
+	halted bool
+}
+
+func (f *fakeEnv) String() string {
+	return "fake"
+}
+
+func (f *fakeEnv) Halt() error {
+	f.halted = true
+	return nil
+}
+
+func (f *fakeEnv) Sense(e *physic.Env) error {
+	*e = f.e
+	return f.err
+}
+
+func (f *fakeEnv) SenseContinuous(interval time.Duration) (<-chan physic.Env, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeEnv) Precision(e *physic.Env) {
+	*e = f.p
+}
+
+type fakeEnvExtra struct {
+	fakeEnv
+	e physic.EnvExtra
+	p physic.EnvExtra
+}
+
+func (f *fakeEnvExtra) SenseExtra(e *physic.EnvExtra) error {
+	*e = f.e
+	return f.err
+}
+
+func (f *fakeEnvExtra) SenseContinuousExtra(interval time.Duration) (<-chan physic.EnvExtra, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeEnvExtra) PrecisionExtra(e *physic.EnvExtra) {
+	*e = f.p
+}
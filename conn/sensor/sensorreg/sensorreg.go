@@ -0,0 +1,120 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package sensorreg defines a registry of the sensors configured on the host
+// so tools can enumerate and read them uniformly.
+//
+// Unlike i2creg or spireg, the registry contains opened sensor instances,
+// usually registered by the application after constructing them.
+package sensorreg
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+// Ref references a registered sensor.
+type Ref struct {
+	// Name of the sensor. It must be unique.
+	Name string
+	// Sensor is the registered sensor.
+	Sensor sensor.Sensor
+}
+
+// Reading is the result of reading a sensor with ReadAll().
+type Reading struct {
+	Name         string
+	Measurements []sensor.Measurement
+	Err          error
+}
+
+// Register registers a sensor.
+//
+// Registering the same name twice is an error. Use RegisterEnv() for a
+// physic.SenseEnv.
+func Register(name string, s sensor.Sensor) error {
+	if len(name) == 0 {
+		return errors.New("sensorreg: can't register a sensor with no name")
+	}
+	if s == nil {
+		return errors.New("sensorreg: can't register sensor " + strconv.Quote(name) + " with nil Sensor")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := byName[name]; ok {
+		return errors.New("sensorreg: can't register sensor " + strconv.Quote(name) + " twice")
+	}
+	byName[name] = &Ref{Name: name, Sensor: s}
+	return nil
+}
+
+// RegisterEnv registers an environmental sensor via sensor.FromEnv().
+func RegisterEnv(name string, s physic.SenseEnv) error {
+	if s == nil {
+		return errors.New("sensorreg: can't register sensor " + strconv.Quote(name) + " with nil Sensor")
+	}
+	return Register(name, sensor.FromEnv(s))
+}
+
+// Unregister removes a previously registered sensor.
+//
+// The sensor is not halted.
+func Unregister(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := byName[name]; !ok {
+		return errors.New("sensorreg: can't unregister unknown sensor name " + strconv.Quote(name))
+	}
+	delete(byName, name)
+	return nil
+}
+
+// ByName returns the sensor registered under name, or nil if none.
+func ByName(name string) sensor.Sensor {
+	mu.Lock()
+	defer mu.Unlock()
+	if r := byName[name]; r != nil {
+		return r.Sensor
+	}
+	return nil
+}
+
+// All returns a copy of all the registered sensors, sorted by name.
+func All() []*Ref {
+	mu.Lock()
+	defer mu.Unlock()
+	out := make([]*Ref, 0, len(byName))
+	for _, r := range byName {
+		c := *r
+		out = append(out, &c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ReadAll reads all the registered sensors sequentially, sorted by name.
+//
+// A failure to read a sensor is reported in its Reading and doesn't stop the
+// other sensors from being read.
+func ReadAll() []Reading {
+	refs := All()
+	out := make([]Reading, len(refs))
+	for i, r := range refs {
+		out[i].Name = r.Name
+		out[i].Measurements, out[i].Err = r.Sensor.Measure()
+	}
+	return out
+}
+
+//
+
+var (
+	mu     sync.Mutex
+	byName = map[string]*Ref{}
+)
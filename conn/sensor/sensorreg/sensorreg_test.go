@@ -0,0 +1,120 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sensorreg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+func TestRegister(t *testing.T) {
+	defer reset()
+	if err := Register("", &fakeSensor{}); err == nil {
+		t.Fatal("no name")
+	}
+	if err := Register("a", nil); err == nil {
+		t.Fatal("nil sensor")
+	}
+	if err := RegisterEnv("a", nil); err == nil {
+		t.Fatal("nil sensor")
+	}
+	b := &fakeSensor{v: physic.Volt}
+	if err := Register("b", b); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register("b", b); err == nil {
+		t.Fatal("twice")
+	}
+	a := &fakeSensor{err: errors.New("fail")}
+	if err := Register("a", a); err != nil {
+		t.Fatal(err)
+	}
+	if s := ByName("b"); s != b {
+		t.Fatal(s)
+	}
+	if s := ByName("c"); s != nil {
+		t.Fatal(s)
+	}
+	if all := All(); len(all) != 2 || all[0].Name != "a" || all[1].Sensor != b {
+		t.Fatal(all)
+	}
+	r := ReadAll()
+	if len(r) != 2 || r[0].Err == nil || r[1].Err != nil || r[1].Measurements[0].Value != physic.Volt {
+		t.Fatal(r)
+	}
+	if err := Unregister("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unregister("a"); err == nil {
+		t.Fatal("unknown")
+	}
+}
+
+func TestRegisterEnv(t *testing.T) {
+	defer reset()
+	if err := RegisterEnv("env", &fakeEnv{}); err != nil {
+		t.Fatal(err)
+	}
+	r := ReadAll()
+	if len(r) != 1 || r[0].Err != nil || len(r[0].Measurements) != 1 || r[0].Measurements[0].Value != physic.ZeroCelsius {
+		t.Fatal(r)
+	}
+}
+
+//
+
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	byName = map[string]*Ref{}
+}
+
+type fakeSensor struct {
+	v   physic.ElectricPotential
+	err error
+}
+
+func (f *fakeSensor) String() string {
+	return "fake"
+}
+
+func (f *fakeSensor) Halt() error {
+	return nil
+}
+
+func (f *fakeSensor) Measure() ([]sensor.Measurement, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []sensor.Measurement{{Quantity: sensor.Voltage, Value: f.v, Time: time.Now()}}, nil
+}
+
+type fakeEnv struct {
+}
+
+func (f *fakeEnv) String() string {
+	return "env"
+}
+
+func (f *fakeEnv) Halt() error {
+	return nil
+}
+
+func (f *fakeEnv) Sense(e *physic.Env) error {
+	e.Temperature = physic.ZeroCelsius
+	return nil
+}
+
+func (f *fakeEnv) SenseContinuous(interval time.Duration) (<-chan physic.Env, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeEnv) Precision(e *physic.Env) {
+	e.Temperature = physic.MilliKelvin
+}
@@ -22,8 +22,10 @@
 package spi
 
 import (
+	"context"
 	"io"
 	"strconv"
+	"time"
 
 	"github.com/meandrewdev/periph/conn"
 	"github.com/meandrewdev/periph/conn/gpio"
@@ -56,6 +58,15 @@ const (
 	// LSBFirst requests the words to be encoded in little endian instead of the
 	// default big endian.
 	LSBFirst = 0x10
+	// TxDual and TxQuad declare that the device can receive on 2 or 4 data
+	// lines. Each packet then selects the number of lines with
+	// Packet.TxLanes.
+	TxDual Mode = 0x20
+	TxQuad Mode = 0x40
+	// RxDual and RxQuad declare that the device can send on 2 or 4 data lines.
+	// Each packet then selects the number of lines with Packet.RxLanes.
+	RxDual Mode = 0x80
+	RxQuad Mode = 0x100
 )
 
 func (m Mode) String() string {
@@ -83,6 +94,22 @@ func (m Mode) String() string {
 		s += "|LSBFirst"
 	}
 	m &^= LSBFirst
+	if m&TxDual != 0 {
+		s += "|TxDual"
+	}
+	m &^= TxDual
+	if m&TxQuad != 0 {
+		s += "|TxQuad"
+	}
+	m &^= TxQuad
+	if m&RxDual != 0 {
+		s += "|RxDual"
+	}
+	m &^= RxDual
+	if m&RxQuad != 0 {
+		s += "|RxQuad"
+	}
+	m &^= RxQuad
 	if m != 0 {
 		s += "|0x"
 		s += strconv.FormatUint(uint64(m), 16)
@@ -91,6 +118,9 @@ func (m Mode) String() string {
 }
 
 // Packet represents one packet when sending multiple packets as a transaction.
+//
+// The delays are rounded up to the resolution of the driver, which may return
+// an error when a value is out of its range.
 type Packet struct {
 	// W and R are the output and input data. When HalfDuplex is specified to
 	// Connect, only one of the two can be set.
@@ -107,6 +137,29 @@ type Packet struct {
 	//
 	// KeepCS is ignored when NoCS was specified to Connect.
 	KeepCS bool
+	// Speed overrides the clock speed specified to Connect for this packet.
+	// It is still limited by Port.LimitSpeed(). 0 means the default.
+	Speed physic.Frequency
+	// DelayBefore is the idle time before the first clock of this packet.
+	//
+	// When the packet starts a transaction, i.e. it is the first one or the
+	// previous packet has KeepCS false, the delay is observed with CS
+	// asserted; this is the CS setup time.
+	DelayBefore time.Duration
+	// DelayAfter is the idle time after the last clock of this packet.
+	//
+	// When the packet ends a transaction, i.e. KeepCS is false, the delay is
+	// observed before CS is deasserted; this is the CS hold time.
+	DelayAfter time.Duration
+	// WordDelay is the idle time between each word of this packet.
+	WordDelay time.Duration
+	// TxLanes and RxLanes are the number of data lines used to send W and
+	// receive R: 1, 2 or 4. 0 means 1.
+	//
+	// Using 2 lines requires TxDual or TxQuad (respectively RxDual or RxQuad)
+	// to be specified to Connect, and 4 lines requires TxQuad (RxQuad). A
+	// packet using more than one line cannot have both W and R set.
+	TxLanes, RxLanes uint8
 }
 
 // Conn defines the interface a concrete SPI driver must implement.
@@ -131,6 +184,31 @@ type Conn interface {
 	TxPackets(p []Packet) error
 }
 
+// TxPacketsContexter is an optional interface implemented by a Conn that
+// supports aborting a transaction when a context is done.
+type TxPacketsContexter interface {
+	// TxPacketsContext does multiple operations like Conn.TxPackets() but
+	// returns early with ctx.Err() when ctx is done.
+	//
+	// Whether an on-going transaction can be interrupted is implementation
+	// specific; at worst the context is only checked before starting.
+	TxPacketsContext(ctx context.Context, p []Packet) error
+}
+
+// TxPacketsContext does multiple operations over c, honoring ctx.
+//
+// If c implements TxPacketsContexter, it is used. Otherwise ctx is checked
+// before calling c.TxPackets(), which cannot be interrupted once started.
+func TxPacketsContext(ctx context.Context, c Conn, p []Packet) error {
+	if t, ok := c.(TxPacketsContexter); ok {
+		return t.TxPacketsContext(ctx, p)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.TxPackets(p)
+}
+
 // Port is the interface to be provided to device drivers.
 //
 // The device driver, that is the driver for the peripheral connected over
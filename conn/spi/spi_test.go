@@ -5,11 +5,15 @@
 package spi
 
 import (
+	"context"
+	"errors"
 	"testing"
+
+	"github.com/meandrewdev/periph/conn"
 )
 
 func TestMode_String(t *testing.T) {
-	if s := Mode(^int(0)).String(); s != "Mode3|HalfDuplex|NoCS|LSBFirst|0xffffffffffffffe0" {
+	if s := Mode(^int(0)).String(); s != "Mode3|HalfDuplex|NoCS|LSBFirst|TxDual|TxQuad|RxDual|RxQuad|0xfffffffffffffe00" {
 		t.Fatal(s)
 	}
 	if s := Mode0.String(); s != "Mode0" {
@@ -21,4 +25,49 @@ func TestMode_String(t *testing.T) {
 	if s := Mode2.String(); s != "Mode2" {
 		t.Fatal(s)
 	}
+	if s := (Mode0 | TxQuad | RxDual).String(); s != "Mode0|TxQuad|RxDual" {
+		t.Fatal(s)
+	}
+}
+
+func TestTxPacketsContext(t *testing.T) {
+	c := &fakeConn{}
+	if err := TxPacketsContext(context.Background(), c, []Packet{{W: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if c.count != 1 {
+		t.Fatal(c.count)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := TxPacketsContext(ctx, c, []Packet{{W: []byte{1}}}); err != context.Canceled {
+		t.Fatal(err)
+	}
+	if c.count != 1 {
+		t.Fatal("TxPackets() shouldn't have been called")
+	}
+	if err := TxPacketsContext(ctx, &fakeConnContext{}, nil); err != errCtx {
+		t.Fatal(err)
+	}
+}
+
+//
+
+var errCtx = errors.New("ctx")
+
+type fakeConn struct {
+	count int
+}
+
+func (f *fakeConn) String() string             { return "fake" }
+func (f *fakeConn) Duplex() conn.Duplex        { return conn.Full }
+func (f *fakeConn) Tx(w, r []byte) error       { return nil }
+func (f *fakeConn) TxPackets(p []Packet) error { f.count++; return nil }
+
+type fakeConnContext struct {
+	fakeConn
+}
+
+func (f *fakeConnContext) TxPacketsContext(ctx context.Context, p []Packet) error {
+	return errCtx
 }
@@ -0,0 +1,149 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package spitest
+
+import (
+	"sync"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/conntest"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/i2c/i2ctest"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/spi"
+)
+
+// Sim implements spi.PortCloser and simulates a device as a register map.
+//
+// Unlike Playback, the transactions are not compared to a transcript; see
+// i2ctest.Sim.
+//
+// The first byte of each transaction is the register address. When ReadBit is
+// set in it, the registers starting at the address with ReadBit cleared are
+// read into the following bytes. Otherwise the following bytes are written as
+// described in i2ctest.SimDevice.Tx.
+type Sim struct {
+	sync.Mutex
+	Dev *i2ctest.SimDevice
+	// ReadBit is the bit of the register address that denotes a read, e.g.
+	// 0x80.
+	ReadBit     byte
+	Initialized bool
+}
+
+func (s *Sim) String() string {
+	return "sim"
+}
+
+// Close implements spi.PortCloser.
+func (s *Sim) Close() error {
+	return nil
+}
+
+// LimitSpeed implements spi.PortCloser.
+func (s *Sim) LimitSpeed(f physic.Frequency) error {
+	return nil
+}
+
+// Connect implements spi.PortCloser.
+func (s *Sim) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.Initialized {
+		return nil, conntest.Errorf("spitest: Connect cannot be called twice")
+	}
+	s.Initialized = true
+	return &simConn{s}, nil
+}
+
+// CLK implements spi.Pins.
+func (s *Sim) CLK() gpio.PinOut {
+	return gpio.INVALID
+}
+
+// MOSI implements spi.Pins.
+func (s *Sim) MOSI() gpio.PinOut {
+	return gpio.INVALID
+}
+
+// MISO implements spi.Pins.
+func (s *Sim) MISO() gpio.PinIn {
+	return gpio.INVALID
+}
+
+// CS implements spi.Pins.
+func (s *Sim) CS() gpio.PinOut {
+	return gpio.INVALID
+}
+
+//
+
+func (s *Sim) tx(w, r []byte) error {
+	if len(r) != 0 && len(r) != len(w) {
+		return conntest.Errorf("spitest: both buffers must have the same length")
+	}
+	if len(w) == 0 {
+		return nil
+	}
+	for i := range r {
+		r[i] = 0
+	}
+	if w[0]&s.ReadBit != 0 {
+		if len(r) == 0 {
+			return nil
+		}
+		return s.Dev.Tx([]byte{w[0] &^ s.ReadBit}, r[1:])
+	}
+	return s.Dev.Tx(w, nil)
+}
+
+type simConn struct {
+	s *Sim
+}
+
+func (s *simConn) String() string {
+	return s.s.String()
+}
+
+func (s *simConn) Duplex() conn.Duplex {
+	return conn.Full
+}
+
+func (s *simConn) Tx(w, r []byte) error {
+	s.s.Lock()
+	defer s.s.Unlock()
+	return s.s.tx(w, r)
+}
+
+func (s *simConn) TxPackets(packets []spi.Packet) error {
+	s.s.Lock()
+	defer s.s.Unlock()
+	for _, p := range packets {
+		if err := s.s.tx(p.W, p.R); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *simConn) CLK() gpio.PinOut {
+	return s.s.CLK()
+}
+
+func (s *simConn) MOSI() gpio.PinOut {
+	return s.s.MOSI()
+}
+
+func (s *simConn) MISO() gpio.PinIn {
+	return s.s.MISO()
+}
+
+func (s *simConn) CS() gpio.PinOut {
+	return s.s.CS()
+}
+
+var _ spi.PortCloser = &Sim{}
+var _ spi.Pins = &Sim{}
+var _ spi.Conn = &simConn{}
@@ -0,0 +1,66 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package spitest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/i2c/i2ctest"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/spi"
+)
+
+func TestSim(t *testing.T) {
+	dev := &i2ctest.SimDevice{
+		Regs: map[uint8]*i2ctest.SimReg{
+			0x10: {Value: []byte{0x60}, ReadOnly: true},
+			0x11: {Value: []byte{0}},
+			0x12: {Value: []byte{0}},
+		},
+		AutoIncrement: true,
+		PairedWrite:   true,
+	}
+	s := Sim{Dev: dev, ReadBit: 0x80}
+	c, err := s.Connect(physic.MegaHertz, spi.Mode0, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Connect(physic.MegaHertz, spi.Mode0, 8); err == nil {
+		t.Fatal("Connect twice")
+	}
+	if str := c.String(); str != "sim" {
+		t.Fatal(str)
+	}
+	if err := c.Tx([]byte{0x11, 1, 0x12, 2}, nil); err != nil {
+		t.Fatal(err)
+	}
+	r := make([]byte, 4)
+	if err := c.Tx([]byte{0x90, 0, 0, 0}, r); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r, []byte{0, 0x60, 1, 2}) {
+		t.Fatal(r)
+	}
+	p := []spi.Packet{
+		{W: []byte{0x11, 3}},
+		{W: []byte{0x91, 0}, R: make([]byte, 2)},
+	}
+	if err := c.TxPackets(p); err != nil {
+		t.Fatal(err)
+	}
+	if p[1].R[1] != 3 {
+		t.Fatal(p[1].R)
+	}
+	if err := c.Tx([]byte{0x90, 0}, r); err == nil {
+		t.Fatal("buffers length mismatch")
+	}
+	if err := c.Tx([]byte{0xA0, 0}, r[:2]); err == nil {
+		t.Fatal("no register 0x20")
+	}
+	if c.(spi.Pins).CS() == nil || s.LimitSpeed(physic.MegaHertz) != nil || s.Close() != nil {
+		t.Fatal("unexpected")
+	}
+}
@@ -78,10 +78,15 @@ func (r *recordRawConn) TxPackets(p []spi.Packet) error {
 // Record implements spi.PortCloser that records everything written to it.
 //
 // This can then be used to feed to Playback to do "replay" based unit tests.
+//
+// Each packet sent with TxPackets() is recorded as one IO in Ops. The packets
+// are also appended to Packets with their timing and lane widths, so a test
+// can verify them.
 type Record struct {
 	sync.Mutex
 	Port        spi.PortCloser // Port can be nil if only writes are being recorded.
 	Ops         []conntest.IO
+	Packets     []spi.Packet
 	Initialized bool
 }
 
@@ -118,9 +123,9 @@ func (r *Record) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn,
 		if err != nil {
 			return nil, err
 		}
-		return &recordConn{r, c}, nil
+		return &recordConn{r, c, mode}, nil
 	}
-	return &recordConn{r, nil}, nil
+	return &recordConn{r, nil, mode}, nil
 }
 
 // CLK implements spi.Pins.
@@ -183,8 +188,9 @@ func (r *Record) txInternal(c spi.Conn, w, read []byte) error {
 //
 
 type recordConn struct {
-	r *Record
-	c spi.Conn
+	r     *Record
+	c     spi.Conn
+	lanes spi.Mode
 }
 
 func (r *recordConn) String() string {
@@ -202,9 +208,34 @@ func (r *recordConn) Tx(w, read []byte) error {
 	return r.r.txInternal(r.c, w, read)
 }
 
-// TxPackets is not yet implemented.
 func (r *recordConn) TxPackets(p []spi.Packet) error {
-	return conntest.Errorf("spitest: TxPackets is not implemented")
+	if len(p) == 0 {
+		return conntest.Errorf("spitest: empty packets")
+	}
+	for i := range p {
+		if err := checkLanes(&p[i], r.lanes); err != nil {
+			return err
+		}
+	}
+	r.r.Lock()
+	defer r.r.Unlock()
+	if r.c == nil {
+		for i := range p {
+			if len(p[i].R) != 0 {
+				return conntest.Errorf("spitest: read unsupported when no port is connected")
+			}
+		}
+	} else if err := r.c.TxPackets(p); err != nil {
+		return err
+	}
+	for i := range p {
+		c := p[i]
+		c.W = copyBuf(p[i].W)
+		c.R = copyBuf(p[i].R)
+		r.r.Ops = append(r.r.Ops, conntest.IO{W: c.W, R: c.R})
+		r.r.Packets = append(r.r.Packets, c)
+	}
+	return nil
 }
 
 // CLK implements spi.Pins.
@@ -262,7 +293,7 @@ func (p *Playback) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Con
 		return nil, conntest.Errorf("spitest: Connect cannot be called twice")
 	}
 	p.Initialized = true
-	return &playbackConn{p}, nil
+	return &playbackConn{p, mode}, nil
 }
 
 // CLK implements spi.Pins.
@@ -286,7 +317,8 @@ func (p *Playback) CS() gpio.PinOut {
 }
 
 type playbackConn struct {
-	p *Playback
+	p     *Playback
+	lanes spi.Mode
 }
 
 func (p *playbackConn) String() string {
@@ -301,8 +333,25 @@ func (p *playbackConn) Tx(w, r []byte) error {
 	return p.p.Tx(w, r)
 }
 
+// TxPackets plays back one IO per packet.
+//
+// The lane widths of each packet are verified against the mode specified to
+// Connect().
 func (p *playbackConn) TxPackets(packets []spi.Packet) error {
-	return conntest.Errorf("spitest: TxPackets is not implemented")
+	if len(packets) == 0 {
+		return conntest.Errorf("spitest: empty packets")
+	}
+	for i := range packets {
+		if err := checkLanes(&packets[i], p.lanes); err != nil {
+			return err
+		}
+	}
+	for i := range packets {
+		if err := p.p.Tx(packets[i].W, packets[i].R); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (p *playbackConn) CLK() gpio.PinOut {
@@ -323,6 +372,45 @@ func (p *playbackConn) CS() gpio.PinOut {
 
 //
 
+// checkLanes verifies that the number of data lines used by a packet was
+// enabled at Connect(), as documented in spi.Packet.
+func checkLanes(p *spi.Packet, mode spi.Mode) error {
+	if !validLanes(p.TxLanes, mode&spi.TxDual != 0, mode&spi.TxQuad != 0) {
+		return conntest.Errorf("spitest: %d tx lanes not supported with mode %v", p.TxLanes, mode)
+	}
+	if !validLanes(p.RxLanes, mode&spi.RxDual != 0, mode&spi.RxQuad != 0) {
+		return conntest.Errorf("spitest: %d rx lanes not supported with mode %v", p.RxLanes, mode)
+	}
+	if (p.TxLanes > 1 || p.RxLanes > 1) && len(p.W) != 0 && len(p.R) != 0 {
+		return conntest.Errorf("spitest: can only specify one of w or r when using multiple lanes")
+	}
+	return nil
+}
+
+func validLanes(lanes uint8, dual, quad bool) bool {
+	switch lanes {
+	case 0, 1:
+		return true
+	case 2:
+		return dual || quad
+	case 4:
+		return quad
+	default:
+		return false
+	}
+}
+
+func copyBuf(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
+
+//
+
 // Log logs all operations done on an spi.PortCloser.
 type Log struct {
 	spi.PortCloser
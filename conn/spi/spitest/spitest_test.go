@@ -12,6 +12,7 @@ import (
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/meandrewdev/periph/conn"
 	"github.com/meandrewdev/periph/conn/conntest"
@@ -35,7 +36,7 @@ func TestRecordRaw(t *testing.T) {
 		t.Fatal("Can't call Connect twice")
 	}
 	if err := c.TxPackets(nil); err == nil {
-		t.Fatal("not yet implemented")
+		t.Fatal("empty packets")
 	}
 	if v := c.String(); v != "recordraw" {
 		t.Fatal(v)
@@ -79,7 +80,7 @@ func TestRecord_empty(t *testing.T) {
 		t.Fatal("Port is nil")
 	}
 	if err := c.TxPackets(nil); err == nil {
-		t.Fatal("not yet implemented")
+		t.Fatal("empty packets")
 	}
 	if d := c.Duplex(); d != conn.DuplexUnknown {
 		t.Fatal(d)
@@ -149,7 +150,7 @@ func TestPlayback(t *testing.T) {
 		t.Fatal("Can't call Connect twice")
 	}
 	if err := c.TxPackets(nil); err == nil {
-		t.Fatal("not yet implemented")
+		t.Fatal("empty packets")
 	}
 	if n := c.(spi.Pins).CLK().Name(); n != "CLK" {
 		t.Fatal(n)
@@ -276,6 +277,73 @@ func TestRecord_Playback(t *testing.T) {
 	}
 }
 
+func TestRecord_Playback_TxPackets(t *testing.T) {
+	r := Record{
+		Port: &Playback{
+			Playback: conntest.Playback{
+				Ops: []conntest.IO{
+					{W: []byte{0x03, 0x00}},
+					{R: []byte{1, 2}},
+				},
+				D: conn.Full,
+			},
+		},
+	}
+	c, err := r.Connect(0, spi.Mode0|spi.RxQuad, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := make([]byte, 2)
+	p := []spi.Packet{
+		{W: []byte{0x03, 0x00}, KeepCS: true, DelayBefore: time.Microsecond},
+		{R: read, Speed: physic.MegaHertz, RxLanes: 4},
+	}
+	if err := c.TxPackets(p); err != nil {
+		t.Fatal(err)
+	}
+	// A packet using multiple lanes cannot both write and read, and the lanes
+	// must have been enabled at Connect().
+	if err := c.TxPackets([]spi.Packet{{W: []byte{0}, R: read, RxLanes: 4}}); err == nil {
+		t.Fatal("w and r with 4 lanes")
+	}
+	if err := c.TxPackets([]spi.Packet{{W: []byte{0}, TxLanes: 2}}); err == nil {
+		t.Fatal("tx lanes not enabled")
+	}
+	if err := c.TxPackets([]spi.Packet{{R: read, RxLanes: 3}}); err == nil {
+		t.Fatal("invalid lanes")
+	}
+	if !bytes.Equal(read, []byte{1, 2}) {
+		t.Fatal(read)
+	}
+	if len(r.Ops) != 2 || !bytes.Equal(r.Ops[1].R, []byte{1, 2}) {
+		t.Fatal(r.Ops)
+	}
+	if len(r.Packets) != 2 || !r.Packets[0].KeepCS || r.Packets[0].DelayBefore != time.Microsecond || r.Packets[1].Speed != physic.MegaHertz || r.Packets[1].RxLanes != 4 {
+		t.Fatal(r.Packets)
+	}
+	// The recording is not affected by later modifications of the buffers.
+	read[0] = 0
+	if r.Packets[1].R[0] != 1 {
+		t.Fatal(r.Packets[1].R)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a port, only writes can be recorded.
+	r = Record{}
+	c, err = r.Connect(0, spi.Mode0, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.TxPackets([]spi.Packet{{W: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.TxPackets([]spi.Packet{{R: []byte{1}}}); err == nil {
+		t.Fatal("Port is nil")
+	}
+}
+
 type connectFail struct {
 	Playback
 }
@@ -313,7 +381,7 @@ func TestLog_Playback(t *testing.T) {
 		t.Fatal(err)
 	}
 	if err := c.TxPackets(nil); err == nil {
-		t.Fatal("not yet implemented")
+		t.Fatal("empty packets")
 	}
 	if d := c.Duplex(); d != conn.Full {
 		t.Fatal(d)
@@ -0,0 +1,25 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package spitest
+
+import (
+	"io"
+
+	"github.com/meandrewdev/periph/conn/conntest"
+)
+
+// WriteOps writes ops as a transcript of kind "spi".
+//
+// See conntest.TranscriptVersion for the file format.
+func WriteOps(w io.Writer, ops []conntest.IO) error {
+	return conntest.WriteOpsKind(w, "spi", ops)
+}
+
+// ReadOps reads a transcript of kind "spi" written by WriteOps.
+//
+// The result can be used as Playback.Ops.
+func ReadOps(r io.Reader) ([]conntest.IO, error) {
+	return conntest.ReadOpsKind(r, "spi")
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package spitest
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/conntest"
+)
+
+func TestWriteOps_ReadOps(t *testing.T) {
+	ops := []conntest.IO{{W: []byte{0x80, 0}, R: []byte{0, 0x60}}}
+	var b bytes.Buffer
+	if err := WriteOps(&b, ops); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadOps(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, ops) {
+		t.Fatalf("%#v", got)
+	}
+	b.Reset()
+	if err := conntest.WriteOps(&b, ops); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadOps(&b); err == nil {
+		t.Fatal("wrong kind")
+	}
+}
@@ -8,8 +8,8 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/meandrewdev/periph/experimental/conn/uart"
-	"github.com/meandrewdev/periph/experimental/conn/uart/uartreg"
+	"github.com/meandrewdev/periph/conn/uart"
+	"github.com/meandrewdev/periph/conn/uart/uartreg"
 	"github.com/meandrewdev/periph/host"
 )
 
@@ -0,0 +1,50 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package uart
+
+import (
+	"testing"
+)
+
+func TestFlow_String(t *testing.T) {
+	data := []struct {
+		f        Flow
+		expected string
+	}{
+		{NoFlow, "None"},
+		{RTSCTS, "RTS/CTS"},
+		{MakeXOnXOffFlow('a', 'b'), "XOn(a)/XOff(b)"},
+		{Flow(1), "Flow(1)"},
+	}
+	for i, line := range data {
+		if s := line.f.String(); s != line.expected {
+			t.Fatalf("#%d: %q != %q", i, s, line.expected)
+		}
+	}
+}
+
+func TestFlow_XOnXOff(t *testing.T) {
+	if xon, xoff, ok := XOnXOff.XOnXOff(); !ok || xon != 0x11 || xoff != 0x13 {
+		t.Fatal(xon, xoff, ok)
+	}
+	if xon, xoff, ok := MakeXOnXOffFlow('a', 'b').XOnXOff(); !ok || xon != 'a' || xoff != 'b' {
+		t.Fatal(xon, xoff, ok)
+	}
+	if _, _, ok := RTSCTS.XOnXOff(); ok {
+		t.Fatal("RTSCTS is not XOnXOff")
+	}
+}
+
+func TestModem_String(t *testing.T) {
+	if s := Modem(0).String(); s != "0" {
+		t.Fatal(s)
+	}
+	if s := (ModemDTR | ModemRI).String(); s != "DTR|RI" {
+		t.Fatal(s)
+	}
+	if s := Modem(0xFF).String(); s != "DTR|RTS|CTS|DSR|DCD|RI|0xc0" {
+		t.Fatal(s)
+	}
+}
@@ -11,8 +11,8 @@ import (
 	"strings"
 
 	"github.com/meandrewdev/periph/conn/physic"
-	"github.com/meandrewdev/periph/experimental/conn/uart"
-	"github.com/meandrewdev/periph/experimental/conn/uart/uartreg"
+	"github.com/meandrewdev/periph/conn/uart"
+	"github.com/meandrewdev/periph/conn/uart/uartreg"
 	"github.com/meandrewdev/periph/host"
 )
 
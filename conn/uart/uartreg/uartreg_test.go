@@ -8,11 +8,12 @@ import (
 	"errors"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/meandrewdev/periph/conn"
 	"github.com/meandrewdev/periph/conn/gpio"
 	"github.com/meandrewdev/periph/conn/physic"
-	"github.com/meandrewdev/periph/experimental/conn/uart"
+	"github.com/meandrewdev/periph/conn/uart"
 )
 
 func TestOpen(t *testing.T) {
@@ -166,7 +167,7 @@ func (f *fakePort) LimitSpeed(freq physic.Frequency) error {
 	return errors.New("not implemented")
 }
 
-func (f *fakePort) Connect(freq physic.Frequency, stopBit uart.Stop, parity uart.Parity, flow uart.Flow, bits int) (conn.Conn, error) {
+func (f *fakePort) Connect(freq physic.Frequency, stopBit uart.Stop, parity uart.Parity, flow uart.Flow, bits int) (uart.Conn, error) {
 	return &f.conn, nil
 }
 
@@ -175,7 +176,7 @@ func (f *fakePort) TX() gpio.PinOut  { return f.conn.TX() }
 func (f *fakePort) RTS() gpio.PinOut { return f.conn.RTS() }
 func (f *fakePort) CTS() gpio.PinIn  { return f.conn.CTS() }
 
-// fakeConn implements conn.Conn.
+// fakeConn implements uart.Conn.
 type fakeConn struct {
 }
 
@@ -191,6 +192,20 @@ func (f *fakeConn) Duplex() conn.Duplex {
 	return conn.Full
 }
 
+func (f *fakeConn) Read(b []byte) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (f *fakeConn) Write(b []byte) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (f *fakeConn) SetReadTimeout(d time.Duration) error { return nil }
+func (f *fakeConn) SetFlow(flow uart.Flow) error         { return nil }
+func (f *fakeConn) Break(d time.Duration) error          { return nil }
+func (f *fakeConn) Modem() (uart.Modem, error)           { return 0, nil }
+func (f *fakeConn) SetModem(mask, m uart.Modem) error    { return nil }
+
 func (f *fakeConn) RX() gpio.PinIn   { return gpio.INVALID }
 func (f *fakeConn) TX() gpio.PinOut  { return gpio.INVALID }
 func (f *fakeConn) RTS() gpio.PinOut { return gpio.INVALID }
@@ -208,5 +223,5 @@ func reset() {
 
 var _ uart.PortCloser = &fakePort{}
 var _ uart.Pins = &fakePort{}
-var _ conn.Conn = &fakeConn{}
+var _ uart.Conn = &fakeConn{}
 var _ uart.Pins = &fakeConn{}
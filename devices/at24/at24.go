@@ -0,0 +1,242 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package at24
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/conn/mmr"
+)
+
+// Opts holds the configuration options.
+//
+// Slave Address
+//
+// The base address is 0x50 plus the value of the A0, A1 and A2 pins. Devices
+// that occupy multiple addresses must use a base address aligned on the
+// number of addresses they use.
+type Opts struct {
+	// Addr is the I²C address of the first memory block, between 0x50 and
+	// 0x57.
+	Addr uint16
+	// Size is the memory size in bytes.
+	Size int
+	// PageSize is the size of the page write buffer in bytes.
+	PageSize int
+	// AddrBytes is the number of bytes used to address the memory, 1 or 2.
+	AddrBytes int
+	// WP is the pin connected to the write protect pin of the device, if any.
+	// It is driven high except while writing.
+	WP gpio.PinOut
+}
+
+// Common devices. Set Addr according to the A0-A2 pins.
+var (
+	AT24C01  = Opts{Addr: 0x50, Size: 128, PageSize: 8, AddrBytes: 1}
+	AT24C02  = Opts{Addr: 0x50, Size: 256, PageSize: 8, AddrBytes: 1}
+	AT24C04  = Opts{Addr: 0x50, Size: 512, PageSize: 16, AddrBytes: 1}
+	AT24C08  = Opts{Addr: 0x50, Size: 1024, PageSize: 16, AddrBytes: 1}
+	AT24C16  = Opts{Addr: 0x50, Size: 2048, PageSize: 16, AddrBytes: 1}
+	AT24C32  = Opts{Addr: 0x50, Size: 4096, PageSize: 32, AddrBytes: 2}
+	AT24C64  = Opts{Addr: 0x50, Size: 8192, PageSize: 32, AddrBytes: 2}
+	AT24C128 = Opts{Addr: 0x50, Size: 16384, PageSize: 64, AddrBytes: 2}
+	AT24C256 = Opts{Addr: 0x50, Size: 32768, PageSize: 64, AddrBytes: 2}
+	AT24C512 = Opts{Addr: 0x50, Size: 65536, PageSize: 128, AddrBytes: 2}
+	AT24CM01 = Opts{Addr: 0x50, Size: 131072, PageSize: 256, AddrBytes: 2}
+	AT24CM02 = Opts{Addr: 0x50, Size: 262144, PageSize: 256, AddrBytes: 2}
+)
+
+// DefaultOpts is the 24C32, as used on Raspberry Pi HATs.
+var DefaultOpts = AT24C32
+
+// New opens a handle to a 24Cxx EEPROM.
+//
+// The device is not accessed until the first read or write.
+func New(b i2c.Bus, opts *Opts) (*Dev, error) {
+	if opts.AddrBytes != 1 && opts.AddrBytes != 2 {
+		return nil, fmt.Errorf("at24: invalid address bytes %d", opts.AddrBytes)
+	}
+	if opts.Size <= 0 || opts.PageSize <= 0 || opts.PageSize&(opts.PageSize-1) != 0 {
+		return nil, errors.New("at24: invalid size")
+	}
+	blockSize := 1 << uint(8*opts.AddrBytes)
+	blocks := (opts.Size + blockSize - 1) / blockSize
+	if blocks > 8 || blocks&(blocks-1) != 0 {
+		return nil, fmt.Errorf("at24: unsupported size %d", opts.Size)
+	}
+	if opts.Addr < 0x50 || opts.Addr > 0x57 || int(opts.Addr)&(blocks-1) != 0 {
+		return nil, fmt.Errorf("at24: invalid address 0x%x for a device using %d addresses", opts.Addr, blocks)
+	}
+	d := &Dev{opts: *opts, blockSize: blockSize, c: make([]i2c.Dev, blocks)}
+	for i := range d.c {
+		d.c[i] = i2c.Dev{Bus: b, Addr: opts.Addr + uint16(i)}
+	}
+	if opts.WP != nil {
+		if err := opts.WP.Out(gpio.High); err != nil {
+			return nil, fmt.Errorf("at24: %v", err)
+		}
+	}
+	return d, nil
+}
+
+// Dev is a handle to a 24Cxx EEPROM.
+type Dev struct {
+	opts      Opts
+	blockSize int
+	c         []i2c.Dev // One per I²C address used.
+
+	mu sync.Mutex
+}
+
+// String implements conn.Resource.
+func (d *Dev) String() string {
+	return fmt.Sprintf("AT24{%s}", &d.c[0])
+}
+
+// Halt implements conn.Resource.
+//
+// It is a noop.
+func (d *Dev) Halt() error {
+	return nil
+}
+
+// Size returns the memory size in bytes.
+func (d *Dev) Size() int64 {
+	return int64(d.opts.Size)
+}
+
+// ReadAt implements io.ReaderAt.
+//
+// It returns io.EOF when reading past the end of the memory.
+func (d *Dev) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("at24: negative offset")
+	}
+	if off >= d.Size() {
+		return 0, io.EOF
+	}
+	var err error
+	if l := d.Size() - off; int64(len(b)) > l {
+		b = b[:l]
+		err = io.EOF
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for n := 0; n < len(b); {
+		a := int(off) + n
+		// Sequential reads wrap around at the end of the block.
+		l := d.blockSize - a%d.blockSize
+		if l > maxRead {
+			l = maxRead
+		}
+		if l > len(b)-n {
+			l = len(b) - n
+		}
+		if err := d.read(a, b[n:n+l]); err != nil {
+			return n, fmt.Errorf("at24: %v", err)
+		}
+		n += l
+	}
+	return len(b), err
+}
+
+// WriteAt implements io.WriterAt.
+//
+// The data is written one page at a time, waiting for each write cycle to
+// complete.
+func (d *Dev) WriteAt(b []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(b)) > d.Size() {
+		return 0, errors.New("at24: write out of range")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.opts.WP != nil {
+		if err := d.opts.WP.Out(gpio.Low); err != nil {
+			return 0, fmt.Errorf("at24: %v", err)
+		}
+		defer d.opts.WP.Out(gpio.High)
+	}
+	for n := 0; n < len(b); {
+		a := int(off) + n
+		// Page writes wrap around at the end of the page.
+		l := d.opts.PageSize - a%d.opts.PageSize
+		if l > len(b)-n {
+			l = len(b) - n
+		}
+		if err := d.write(a, b[n:n+l]); err != nil {
+			return n, fmt.Errorf("at24: %v", err)
+		}
+		if err := d.wait(a); err != nil {
+			return n, err
+		}
+		n += l
+	}
+	return len(b), nil
+}
+
+//
+
+// maxRead is the maximum number of bytes read in a single transaction. Linux'
+// i2c-dev limits messages to 8192 bytes and some drivers are more limited.
+const maxRead = 4096
+
+// writeTimeout is the maximum duration of a write cycle; datasheets specify
+// 5ms or 10ms.
+const writeTimeout = 20 * time.Millisecond
+
+// pollInterval is the interval between acknowledge polls while a write cycle
+// is in progress.
+var pollInterval = 100 * time.Microsecond
+
+// read reads b at memory address a. It must not cross a block boundary.
+func (d *Dev) read(a int, b []byte) error {
+	c := &d.c[a/d.blockSize]
+	if d.opts.AddrBytes == 1 {
+		m := mmr.Dev8{Conn: c, Order: binary.BigEndian}
+		return m.ReadStruct(uint8(a), b)
+	}
+	m := mmr.Dev16{Conn: c, Order: binary.BigEndian}
+	return m.ReadStruct(uint16(a), b)
+}
+
+// write writes b at memory address a. It must not cross a page boundary.
+func (d *Dev) write(a int, b []byte) error {
+	c := &d.c[a/d.blockSize]
+	if d.opts.AddrBytes == 1 {
+		m := mmr.Dev8{Conn: c, Order: binary.BigEndian}
+		return m.WriteStruct(uint8(a), b)
+	}
+	m := mmr.Dev16{Conn: c, Order: binary.BigEndian}
+	return m.WriteStruct(uint16(a), b)
+}
+
+// wait polls the device until it acknowledges its address, which means the
+// write cycle is completed.
+func (d *Dev) wait(a int) error {
+	c := &d.c[a/d.blockSize]
+	var b [1]byte
+	for start := time.Now(); ; {
+		// A current address read doesn't modify the memory.
+		if err := c.Tx(nil, b[:]); err == nil {
+			return nil
+		}
+		if time.Since(start) > writeTimeout {
+			return errors.New("at24: timed out waiting for the write cycle to complete")
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+var _ conn.Resource = &Dev{}
+var _ io.ReaderAt = &Dev{}
+var _ io.WriterAt = &Dev{}
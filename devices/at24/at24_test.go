@@ -0,0 +1,244 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package at24
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/gpio/gpiotest"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+func TestNew(t *testing.T) {
+	b := newSimEEPROM(&DefaultOpts)
+	d, err := New(b, &DefaultOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := d.String(); s != "AT24{simeeprom(80)}" {
+		t.Fatal(s)
+	}
+	if s := d.Size(); s != 4096 {
+		t.Fatal(s)
+	}
+	if err := d.Halt(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNew_err(t *testing.T) {
+	data := []Opts{
+		{Addr: 0x50, Size: 256, PageSize: 8, AddrBytes: 3},
+		{Addr: 0x50, Size: 0, PageSize: 8, AddrBytes: 1},
+		{Addr: 0x50, Size: 256, PageSize: 6, AddrBytes: 1},
+		{Addr: 0x50, Size: 4096, PageSize: 16, AddrBytes: 1},
+		{Addr: 0x48, Size: 256, PageSize: 8, AddrBytes: 1},
+		// 24C16 uses all 8 addresses.
+		{Addr: 0x51, Size: 2048, PageSize: 16, AddrBytes: 1},
+	}
+	for i, line := range data {
+		if _, err := New(newSimEEPROM(&DefaultOpts), &line); err == nil {
+			t.Fatal(i)
+		}
+	}
+}
+
+func TestDev_ReadWrite(t *testing.T) {
+	o := AT24C32
+	o.Addr = 0x52
+	b := newSimEEPROM(&o)
+	d, err := New(b, &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(i + 1)
+	}
+	if n, err := d.WriteAt(data, 20); n != len(data) || err != nil {
+		t.Fatal(n, err)
+	}
+	// 12+32+32+24 bytes.
+	if b.pageWrites != 4 {
+		t.Fatal(b.pageWrites)
+	}
+	got := make([]byte, len(data)+2)
+	if n, err := d.ReadAt(got, 19); n != len(got) || err != nil {
+		t.Fatal(n, err)
+	}
+	if got[0] != 0xFF || got[len(got)-1] != 0xFF || !bytes.Equal(got[1:len(got)-1], data) {
+		t.Fatal(got)
+	}
+
+	if n, err := d.ReadAt(got, d.Size()-2); n != 2 || err != io.EOF {
+		t.Fatal(n, err)
+	}
+	if n, err := d.ReadAt(got, d.Size()); n != 0 || err != io.EOF {
+		t.Fatal(n, err)
+	}
+	if _, err := d.ReadAt(got, -1); err == nil {
+		t.Fatal("negative offset")
+	}
+	if _, err := d.WriteAt(got, d.Size()-1); err == nil {
+		t.Fatal("out of range")
+	}
+}
+
+func TestDev_MultiAddress(t *testing.T) {
+	b := newSimEEPROM(&AT24C16)
+	d, err := New(b, &AT24C16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Crosses the boundary between the first and second I²C address.
+	data := []byte("hello, multi-address world")
+	if _, err := d.WriteAt(data, 250); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.mem[250:250+len(data)], data) {
+		t.Fatal(b.mem[250 : 250+len(data)])
+	}
+	if b.addrs[0x50] == 0 || b.addrs[0x51] == 0 {
+		t.Fatal(b.addrs)
+	}
+	got := make([]byte, 2048)
+	if _, err := d.ReadAt(got, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, b.mem) {
+		t.Fatal("mismatch")
+	}
+	for a := uint16(0x50); a < 0x58; a++ {
+		if b.addrs[a] == 0 {
+			t.Fatalf("0x%x not used", a)
+		}
+	}
+}
+
+func TestDev_WriteProtect(t *testing.T) {
+	p := &gpiotest.Pin{N: "WP"}
+	o := AT24C02
+	o.WP = p
+	b := newSimEEPROM(&o)
+	b.wp = p
+	d, err := New(b, &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.L != gpio.High {
+		t.Fatal("expected protected")
+	}
+	if _, err := d.WriteAt([]byte{1, 2}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if p.L != gpio.High {
+		t.Fatal("expected protected")
+	}
+	if b.mem[0] != 1 || b.mem[1] != 2 {
+		t.Fatal(b.mem[:2])
+	}
+}
+
+func TestDev_WriteTimeout(t *testing.T) {
+	b := newSimEEPROM(&DefaultOpts)
+	d, err := New(b, &DefaultOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.cycle = 1 << 30
+	if _, err := d.WriteAt([]byte{1}, 0); err == nil {
+		t.Fatal("expected timeout")
+	}
+	b.busy = 0
+	b.err = errors.New("bus error")
+	if _, err := d.ReadAt([]byte{1}, 0); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+//
+
+func init() {
+	pollInterval = 0
+}
+
+// simEEPROM simulates a 24Cxx EEPROM on an I²C bus.
+type simEEPROM struct {
+	mem        []byte
+	opts       Opts
+	wp         *gpiotest.Pin
+	ptr        int
+	cycle      int // number of polls a write cycle lasts
+	busy       int // number of polls to NACK
+	pageWrites int
+	addrs      map[uint16]int
+	err        error
+}
+
+func newSimEEPROM(o *Opts) *simEEPROM {
+	s := &simEEPROM{mem: make([]byte, o.Size), opts: *o, cycle: 2, addrs: map[uint16]int{}}
+	for i := range s.mem {
+		s.mem[i] = 0xFF
+	}
+	return s
+}
+
+func (s *simEEPROM) String() string {
+	return "simeeprom"
+}
+
+func (s *simEEPROM) SetSpeed(f physic.Frequency) error {
+	return nil
+}
+
+func (s *simEEPROM) Tx(addr uint16, w, r []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	blockSize := 1 << uint(8*s.opts.AddrBytes)
+	blk := int(addr) - int(s.opts.Addr)
+	if blk < 0 || blk*blockSize >= len(s.mem) {
+		return errors.New("nack")
+	}
+	if s.busy != 0 {
+		s.busy--
+		return errors.New("nack")
+	}
+	s.addrs[addr]++
+	if len(w) != 0 {
+		if len(w) < s.opts.AddrBytes {
+			return errors.New("short address")
+		}
+		a := 0
+		for _, b := range w[:s.opts.AddrBytes] {
+			a = a<<8 | int(b)
+		}
+		s.ptr = blk*blockSize + a
+		if data := w[s.opts.AddrBytes:]; len(data) != 0 {
+			if len(r) != 0 {
+				return errors.New("unexpected read after write")
+			}
+			if s.wp != nil && s.wp.L == gpio.High {
+				// Data is ignored.
+				return nil
+			}
+			base := s.ptr &^ (s.opts.PageSize - 1)
+			for i, b := range data {
+				s.mem[base+(s.ptr+i)%s.opts.PageSize] = b
+			}
+			s.pageWrites++
+			s.busy = s.cycle
+		}
+	}
+	for i := range r {
+		r[i] = s.mem[s.ptr]
+		s.ptr = (s.ptr + 1) % len(s.mem)
+	}
+	return nil
+}
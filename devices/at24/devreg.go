@@ -0,0 +1,65 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package at24
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/devices/devreg"
+)
+
+// jsonOpts is the board description options.
+type jsonOpts struct {
+	// Model is the device, e.g. "24c32". It defaults to DefaultOpts.
+	Model string      `json:"model"`
+	Addr  uint16      `json:"addr"`
+	WP    *devreg.Pin `json:"wp"`
+}
+
+var models = map[string]*Opts{
+	"24c01":  &AT24C01,
+	"24c02":  &AT24C02,
+	"24c04":  &AT24C04,
+	"24c08":  &AT24C08,
+	"24c16":  &AT24C16,
+	"24c32":  &AT24C32,
+	"24c64":  &AT24C64,
+	"24c128": &AT24C128,
+	"24c256": &AT24C256,
+	"24c512": &AT24C512,
+	"24cm01": &AT24CM01,
+	"24cm02": &AT24CM02,
+}
+
+func openI2C(b i2c.Bus, raw json.RawMessage) (conn.Resource, error) {
+	var j jsonOpts
+	if err := devreg.DecodeOpts(raw, &j); err != nil {
+		return nil, err
+	}
+	o := DefaultOpts
+	if j.Model != "" {
+		m := models[strings.TrimPrefix(strings.ToLower(j.Model), "at")]
+		if m == nil {
+			return nil, errors.New("at24: unknown model " + strconv.Quote(j.Model))
+		}
+		o = *m
+	}
+	if j.Addr != 0 {
+		o.Addr = j.Addr
+	}
+	if j.WP != nil {
+		o.WP = j.WP
+	}
+	return New(b, &o)
+}
+
+func init() {
+	devreg.MustRegister(&devreg.Ref{Name: "at24", I2C: openI2C})
+}
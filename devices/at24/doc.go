@@ -0,0 +1,24 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package at24 controls an I²C serial EEPROM of the 24Cxx family, like the
+// Microchip AT24Cxx and 24LCxx.
+//
+// Dev implements io.ReaderAt and io.WriterAt. Writes are split on page
+// boundaries and each page write waits for the internal write cycle to
+// complete by polling the device until it acknowledges its address.
+//
+// Multi-address chips
+//
+// Devices with 8 bits memory addressing and more than 256 bytes (24C04,
+// 24C08, 24C16) and devices with 16 bits memory addressing and more than 64kB
+// (24CM01, 24CM02) use the low bits of the I²C address as the high bits of
+// the memory address. They occupy multiple consecutive I²C addresses.
+//
+// Datasheet
+//
+// http://ww1.microchip.com/downloads/en/DeviceDoc/AT24C32D-AT24C64D-Data-Sheet-DS20006092A.pdf
+//
+// http://ww1.microchip.com/downloads/en/DeviceDoc/20001711J.pdf
+package at24
@@ -402,6 +402,48 @@ func TestI2CSenseBME280_success(t *testing.T) {
 	}
 }
 
+func TestI2CSenseBME280_sim(t *testing.T) {
+	bus, sim := newSimBME280()
+	dev, err := NewI2C(bus, 0x76, &DefaultOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := dev.String(); s != "BME280{sim(118)}" {
+		t.Fatal(s)
+	}
+	if v := sim.Regs[0xF2].Value[0]; v != byte(DefaultOpts.Humidity) {
+		t.Fatalf("ctrl_hum 0x%x", v)
+	}
+	for i := 0; i < 2; i++ {
+		e := physic.Env{}
+		if err := dev.Sense(&e); err != nil {
+			t.Fatal(err)
+		}
+		if expected := 23720*physic.MilliCelsius + physic.ZeroCelsius; e.Temperature != expected {
+			t.Fatalf("temperature %s(%d) != %s(%d)", expected, expected, e.Temperature, e.Temperature)
+		}
+		if expected := 100942695312500 * physic.NanoPascal; e.Pressure != expected {
+			t.Fatalf("pressure %s(%d) != %s(%d)", expected, expected, e.Pressure, e.Pressure)
+		}
+		if expected := 6530560 * physic.TenthMicroRH; e.Humidity != expected {
+			t.Fatalf("humidity %s(%d) != %s(%d)", expected, expected, e.Humidity, e.Humidity)
+		}
+	}
+	if sim.measurements != 2 {
+		t.Fatal(sim.measurements)
+	}
+	// Back to sleep after the forced measurement.
+	if m := mode(sim.Regs[0xF4].Value[0] & 3); m != sleep {
+		t.Fatal(m)
+	}
+	if err := dev.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if v := sim.Regs[0xF5].Value[0]; v != byte(s1s)<<5 {
+		t.Fatalf("config 0x%x", v)
+	}
+}
+
 func TestI2CSense280_idle_fail(t *testing.T) {
 	bus := i2ctest.Playback{
 		Ops: []i2ctest.IO{
@@ -1017,6 +1059,66 @@ func (c *calibration280) compensateHumidityFloat(raw, tFine int32) float64 {
 	return float64(h)
 }
 
+// simBME280 simulates the forced measurements of a BME280.
+type simBME280 struct {
+	i2ctest.SimDevice
+	// busy is the number of status reads during which the measurement is in
+	// progress.
+	busy         int
+	measurements int
+}
+
+func newSimBME280() (*i2ctest.Sim, *simBME280) {
+	d := &simBME280{
+		SimDevice: i2ctest.SimDevice{
+			Regs:          map[uint8]*i2ctest.SimReg{},
+			AutoIncrement: true,
+			PairedWrite:   true,
+		},
+	}
+	// Same calibration and measurement as TestI2CSenseBME280_success.
+	tph := []byte{0x10, 0x6e, 0x6c, 0x66, 0x32, 0x0, 0x5d, 0x95, 0xb8, 0xd5, 0xd0, 0xb, 0x77, 0x1e, 0x9d, 0xff, 0xf9, 0xff, 0xac, 0x26, 0xa, 0xd8, 0xbd, 0x10, 0x0, 0x4b}
+	h := []byte{0x6e, 0x1, 0x0, 0x13, 0x5, 0x0, 0x1e}
+	for i, v := range tph {
+		d.Regs[0x88+uint8(i)] = &i2ctest.SimReg{Value: []byte{v}, ReadOnly: true}
+	}
+	for i, v := range h {
+		d.Regs[0xE1+uint8(i)] = &i2ctest.SimReg{Value: []byte{v}, ReadOnly: true}
+	}
+	for i := uint8(0xF7); i <= 0xFE; i++ {
+		d.Regs[i] = &i2ctest.SimReg{Value: []byte{0}, ReadOnly: true}
+	}
+	d.Regs[0xD0] = &i2ctest.SimReg{Value: []byte{0x60}, ReadOnly: true}
+	d.Regs[0xF2] = &i2ctest.SimReg{Value: []byte{0}}
+	d.Regs[0xF3] = &i2ctest.SimReg{Value: []byte{0}, ReadOnly: true, OnRead: d.status}
+	d.Regs[0xF4] = &i2ctest.SimReg{Value: []byte{0}, OnWrite: d.ctrlMeas}
+	d.Regs[0xF5] = &i2ctest.SimReg{Value: []byte{0}}
+	return &i2ctest.Sim{Devices: map[uint16]*i2ctest.SimDevice{0x76: &d.SimDevice}}, d
+}
+
+// ctrlMeas starts a measurement in forced mode.
+func (d *simBME280) ctrlMeas() {
+	if mode(d.Regs[0xF4].Value[0]&3) == forced {
+		d.busy = 1
+	}
+}
+
+// status reports the measurement in progress then completes it.
+func (d *simBME280) status() {
+	if d.busy == 0 {
+		d.Regs[0xF3].Value[0] = 0
+		return
+	}
+	d.Regs[0xF3].Value[0] = 8
+	if d.busy--; d.busy == 0 {
+		for i, v := range []byte{0x4a, 0x52, 0xc0, 0x80, 0x96, 0xc0, 0x7a, 0x76} {
+			d.Regs[0xF7+uint8(i)].Value[0] = v
+		}
+		d.Regs[0xF4].Value[0] &^= 3
+		d.measurements++
+	}
+}
+
 type spiFail struct {
 	spitest.Playback
 }
@@ -0,0 +1,52 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package bmxx80
+
+import (
+	"encoding/json"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/conn/spi"
+	"github.com/meandrewdev/periph/devices/devreg"
+)
+
+// jsonOpts is the board description options.
+//
+// The fields of Opts are specified as their numerical values and default to
+// DefaultOpts.
+type jsonOpts struct {
+	// Addr is only used on I²C. It defaults to 0x76.
+	Addr uint16 `json:"addr"`
+	Opts
+}
+
+func parseOpts(raw json.RawMessage) (*jsonOpts, error) {
+	j := &jsonOpts{Addr: 0x76, Opts: DefaultOpts}
+	if err := devreg.DecodeOpts(raw, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func init() {
+	devreg.MustRegister(&devreg.Ref{
+		Name: "bmxx80",
+		I2C: func(b i2c.Bus, raw json.RawMessage) (conn.Resource, error) {
+			j, err := parseOpts(raw)
+			if err != nil {
+				return nil, err
+			}
+			return NewI2C(b, j.Addr, &j.Opts)
+		},
+		SPI: func(p spi.Port, raw json.RawMessage) (conn.Resource, error) {
+			j, err := parseOpts(raw)
+			if err != nil {
+				return nil, err
+			}
+			return NewSPI(p, &j.Opts)
+		},
+	})
+}
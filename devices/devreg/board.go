@@ -0,0 +1,252 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devreg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio/gpioreg"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/conn/i2c/i2creg"
+	"github.com/meandrewdev/periph/conn/onewire"
+	"github.com/meandrewdev/periph/conn/onewire/onewirereg"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/spi"
+	"github.com/meandrewdev/periph/conn/spi/spireg"
+)
+
+// Config is a board description.
+type Config struct {
+	// Aliases maps GPIO alias names to the pin names they point to. They are
+	// registered with gpioreg.RegisterAlias().
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Buses maps local bus names to the bus to open.
+	Buses map[string]BusConfig `json:"buses,omitempty"`
+	// Devices are the devices to open, in order.
+	Devices []DeviceConfig `json:"devices,omitempty"`
+}
+
+// BusConfig describes a bus to open.
+type BusConfig struct {
+	// Type is one of "i2c", "spi" or "onewire".
+	Type string `json:"type"`
+	// Name is the name, alias or number of the bus in its registry, e.g.
+	// i2creg. Leave empty to use the first available bus.
+	Name string `json:"name,omitempty"`
+	// Speed is the bus speed, e.g. "400kHz". It is not supported on 1-wire.
+	Speed string `json:"speed,omitempty"`
+}
+
+// DeviceConfig describes a device to open.
+type DeviceConfig struct {
+	// Name is the name of the device in Board.Devices.
+	Name string `json:"name"`
+	// Driver is the name of the registered device driver.
+	Driver string `json:"driver"`
+	// Bus is the local name of the bus the device is connected to, as declared
+	// in Config.Buses. Leave empty for devices only connected via GPIO pins.
+	Bus string `json:"bus,omitempty"`
+	// Opts are the driver specific options.
+	Opts json.RawMessage `json:"opts,omitempty"`
+}
+
+// Board is the set of buses and devices opened from a board description.
+type Board struct {
+	// Devices are the opened devices by name.
+	Devices map[string]conn.Resource
+
+	order   []string
+	buses   []io.Closer
+	aliases []string
+}
+
+// Load decodes a JSON board description from r and opens it.
+//
+// Unknown fields are rejected to catch typos.
+func Load(r io.Reader) (*Board, error) {
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	c := &Config{}
+	if err := d.Decode(c); err != nil {
+		return nil, fmt.Errorf("devreg: %v", err)
+	}
+	return Open(c)
+}
+
+// Open registers the GPIO aliases, opens the buses and then the devices
+// described by c.
+//
+// On failure, everything opened so far is closed.
+func Open(c *Config) (*Board, error) {
+	b := &Board{Devices: map[string]conn.Resource{}}
+	if err := b.open(c); err != nil {
+		_ = b.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// Close halts the devices in reverse order, closes the buses and unregisters
+// the GPIO aliases.
+//
+// It returns the first error encountered.
+func (b *Board) Close() error {
+	var err error
+	for i := len(b.order) - 1; i >= 0; i-- {
+		if err2 := b.Devices[b.order[i]].Halt(); err == nil {
+			err = err2
+		}
+	}
+	for i := len(b.buses) - 1; i >= 0; i-- {
+		if err2 := b.buses[i].Close(); err == nil {
+			err = err2
+		}
+	}
+	for _, a := range b.aliases {
+		if err2 := gpioreg.Unregister(a); err == nil {
+			err = err2
+		}
+	}
+	b.Devices = map[string]conn.Resource{}
+	b.order = nil
+	b.buses = nil
+	b.aliases = nil
+	return err
+}
+
+//
+
+func (b *Board) open(c *Config) error {
+	for _, alias := range sortedKeys(c.Aliases) {
+		if err := gpioreg.RegisterAlias(alias, c.Aliases[alias]); err != nil {
+			return err
+		}
+		b.aliases = append(b.aliases, alias)
+	}
+
+	buses := map[string]interface{}{}
+	names := make([]string, 0, len(c.Buses))
+	for n := range c.Buses {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		bus, err := b.openBus(c.Buses[n])
+		if err != nil {
+			return fmt.Errorf("devreg: bus %s: %v", strconv.Quote(n), err)
+		}
+		buses[n] = bus
+	}
+
+	for _, d := range c.Devices {
+		if len(d.Name) == 0 {
+			return errors.New("devreg: device with no name")
+		}
+		if _, ok := b.Devices[d.Name]; ok {
+			return errors.New("devreg: device " + strconv.Quote(d.Name) + " declared twice")
+		}
+		dev, err := openDevice(&d, buses)
+		if err != nil {
+			return fmt.Errorf("devreg: device %s: %v", strconv.Quote(d.Name), err)
+		}
+		b.Devices[d.Name] = dev
+		b.order = append(b.order, d.Name)
+	}
+	return nil
+}
+
+func (b *Board) openBus(c BusConfig) (interface{}, error) {
+	var hz physic.Frequency
+	if len(c.Speed) != 0 {
+		if err := hz.Set(c.Speed); err != nil {
+			return nil, err
+		}
+	}
+	switch c.Type {
+	case "i2c":
+		bus, err := i2creg.Open(c.Name)
+		if err != nil {
+			return nil, err
+		}
+		b.buses = append(b.buses, bus)
+		if hz != 0 {
+			if err := bus.SetSpeed(hz); err != nil {
+				return nil, err
+			}
+		}
+		return bus, nil
+	case "spi":
+		p, err := spireg.Open(c.Name)
+		if err != nil {
+			return nil, err
+		}
+		b.buses = append(b.buses, p)
+		if hz != 0 {
+			if err := p.LimitSpeed(hz); err != nil {
+				return nil, err
+			}
+		}
+		return p, nil
+	case "onewire":
+		if hz != 0 {
+			return nil, errors.New("speed is not supported on 1-wire")
+		}
+		bus, err := onewirereg.Open(c.Name)
+		if err != nil {
+			return nil, err
+		}
+		b.buses = append(b.buses, bus)
+		return bus, nil
+	default:
+		return nil, errors.New("unknown bus type " + strconv.Quote(c.Type))
+	}
+}
+
+func openDevice(d *DeviceConfig, buses map[string]interface{}) (conn.Resource, error) {
+	r := get(d.Driver)
+	if r == nil {
+		return nil, errors.New("unknown driver " + strconv.Quote(d.Driver) + "; is its package imported?")
+	}
+	if len(d.Bus) == 0 {
+		if r.GPIO == nil {
+			return nil, errors.New("driver " + strconv.Quote(d.Driver) + " requires a bus")
+		}
+		return r.GPIO(d.Opts)
+	}
+	bus, ok := buses[d.Bus]
+	if !ok {
+		return nil, errors.New("unknown bus " + strconv.Quote(d.Bus))
+	}
+	switch bus := bus.(type) {
+	case i2c.Bus:
+		if r.I2C != nil {
+			return r.I2C(bus, d.Opts)
+		}
+	case spi.Port:
+		if r.SPI != nil {
+			return r.SPI(bus, d.Opts)
+		}
+	case onewire.Bus:
+		if r.OneWire != nil {
+			return r.OneWire(bus, d.Opts)
+		}
+	}
+	return nil, errors.New("driver " + strconv.Quote(d.Driver) + " doesn't support the bus type of " + strconv.Quote(d.Bus))
+}
+
+func sortedKeys(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
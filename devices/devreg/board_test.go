@@ -0,0 +1,151 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devreg
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio/gpioreg"
+	"github.com/meandrewdev/periph/conn/gpio/gpiotest"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/conn/i2c/i2creg"
+	"github.com/meandrewdev/periph/conn/i2c/i2ctest"
+)
+
+func TestLoad(t *testing.T) {
+	defer reset()
+	defer registerBus(t)()
+	p := &gpiotest.Pin{N: "DEVREG2", Num: 2}
+	if err := gpioreg.Register(p); err != nil {
+		t.Fatal(err)
+	}
+	defer gpioreg.Unregister("DEVREG2")
+	MustRegister(&Ref{Name: "fake", I2C: openFake})
+	MustRegister(&Ref{Name: "gpioonly", GPIO: func(raw json.RawMessage) (conn.Resource, error) {
+		return &fakeDev{name: "gpioonly"}, nil
+	}})
+
+	const cfg = `{
+		"aliases": {"FAKE_IRQ": "DEVREG2"},
+		"buses": {"main": {"type": "i2c", "name": "devregbus", "speed": "400kHz"}},
+		"devices": [
+			{"name": "a", "driver": "fake", "bus": "main", "opts": {"addr": 16, "irq": "FAKE_IRQ"}},
+			{"name": "b", "driver": "gpioonly"}
+		]
+	}`
+	b, err := Load(strings.NewReader(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := b.Devices["a"].(*fakeDev)
+	if a.name != "devregbus(16)" || a.irq.Name() != "FAKE_IRQ" {
+		t.Fatal(a.name, a.irq)
+	}
+	if gpioreg.ByName("FAKE_IRQ") == nil {
+		t.Fatal("alias not registered")
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !a.halted || len(b.Devices) != 0 {
+		t.Fatal("expected halted")
+	}
+	if gpioreg.ByName("FAKE_IRQ") != nil {
+		t.Fatal("alias not unregistered")
+	}
+}
+
+func TestLoad_err(t *testing.T) {
+	defer reset()
+	defer registerBus(t)()
+	MustRegister(&Ref{Name: "fake", I2C: openFake})
+	data := []string{
+		`{"unknown": 1}`,
+		`{"buses": {"x": {"type": "uart"}}}`,
+		`{"buses": {"x": {"type": "i2c", "name": "devregbus", "speed": "fast"}}}`,
+		`{"buses": {"x": {"type": "i2c", "name": "unknown"}}}`,
+		`{"buses": {"x": {"type": "onewire", "speed": "1kHz"}}}`,
+		`{"devices": [{"driver": "fake"}]}`,
+		`{"devices": [{"name": "a", "driver": "unknown"}]}`,
+		`{"devices": [{"name": "a", "driver": "fake"}]}`,
+		`{"devices": [{"name": "a", "driver": "fake", "bus": "x"}]}`,
+		`{"buses": {"x": {"type": "i2c", "name": "devregbus"}},
+			"devices": [{"name": "a", "driver": "fake", "bus": "x", "opts": {"fail": true}}]}`,
+		`{"buses": {"x": {"type": "i2c", "name": "devregbus"}},
+			"devices": [{"name": "a", "driver": "fake", "bus": "x"}, {"name": "a", "driver": "fake", "bus": "x"}]}`,
+	}
+	for i, line := range data {
+		if _, err := Load(strings.NewReader(line)); err == nil {
+			t.Fatalf("#%d: expected failure", i)
+		}
+	}
+	if l := len(closed); l == 0 {
+		t.Fatal("expected buses to be closed on failure")
+	}
+}
+
+//
+
+// closed records the fake buses closed.
+var closed []string
+
+type fakeBus struct {
+	i2ctest.Record
+}
+
+func (f *fakeBus) String() string {
+	return "devregbus"
+}
+
+func (f *fakeBus) Close() error {
+	closed = append(closed, "devregbus")
+	return nil
+}
+
+func registerBus(t *testing.T) func() {
+	if err := i2creg.Register("devregbus", nil, -1, func() (i2c.BusCloser, error) { return &fakeBus{}, nil }); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if err := i2creg.Unregister("devregbus"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+type fakeDev struct {
+	name   string
+	irq    *Pin
+	halted bool
+}
+
+func (f *fakeDev) String() string {
+	return f.name
+}
+
+func (f *fakeDev) Halt() error {
+	f.halted = true
+	return nil
+}
+
+func openFake(b i2c.Bus, raw json.RawMessage) (conn.Resource, error) {
+	var o struct {
+		Addr uint16 `json:"addr"`
+		IRQ  *Pin   `json:"irq"`
+		Fail bool   `json:"fail"`
+	}
+	if err := json.Unmarshal(raw, &o); err != nil {
+		return nil, err
+	}
+	if o.Fail {
+		return nil, errors.New("failed")
+	}
+	d := &i2c.Dev{Bus: b, Addr: o.Addr}
+	return &fakeDev{name: d.String(), irq: o.IRQ}, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package devreg defines a device driver registry and a declarative board
+// description to instantiate devices.
+//
+// Device drivers register themselves in their package init() function, so the
+// driver package must be imported, usually as a blank import, for its name to
+// be usable in a board description.
+//
+// A board description is a JSON document declaring GPIO aliases, the buses to
+// open and the devices connected on them:
+//
+//   {
+//     "aliases": {"EEPROM_WP": "GPIO17"},
+//     "buses": {
+//       "hat": {"type": "i2c", "name": "1", "speed": "400kHz"},
+//       "flash": {"type": "spi", "name": "SPI0.0", "speed": "10MHz"}
+//     },
+//     "devices": [
+//       {"name": "id", "driver": "at24", "bus": "hat", "opts": {"model": "24c32", "wp": "EEPROM_WP"}},
+//       {"name": "env", "driver": "bmxx80", "bus": "hat", "opts": {"addr": 118}},
+//       {"name": "rom", "driver": "spiflash", "bus": "flash"}
+//     ]
+//   }
+//
+// Only JSON is supported. A YAML description must be converted to JSON first,
+// as periph doesn't depend on a YAML parser.
+//
+// The host drivers must be initialized, e.g. with host.Init(), before calling
+// Load() or Open().
+package devreg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/gpio/gpioreg"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/conn/onewire"
+	"github.com/meandrewdev/periph/conn/spi"
+)
+
+// Ref references a device driver.
+//
+// At least one of the openers must be set. The opener used is selected by the
+// type of the bus the device is connected to in the board description.
+type Ref struct {
+	// Name of the driver, as used in a board description.
+	Name string
+	// I2C opens a device connected on an I²C bus.
+	I2C func(b i2c.Bus, opts json.RawMessage) (conn.Resource, error)
+	// SPI opens a device connected on a SPI port.
+	SPI func(p spi.Port, opts json.RawMessage) (conn.Resource, error)
+	// OneWire opens a device connected on a 1-wire bus.
+	OneWire func(b onewire.Bus, opts json.RawMessage) (conn.Resource, error)
+	// GPIO opens a device connected only via GPIO pins, specified in its
+	// options.
+	GPIO func(opts json.RawMessage) (conn.Resource, error)
+}
+
+// Pin is a GPIO pin referenced by name in the options of a device.
+//
+// It is decoded from a JSON string with gpioreg.ByName(), so GPIO aliases
+// declared in the board description can be used.
+type Pin struct {
+	gpio.PinIO
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Pin) UnmarshalText(b []byte) error {
+	if p.PinIO = gpioreg.ByName(string(b)); p.PinIO == nil {
+		return errors.New("devreg: unknown pin " + strconv.Quote(string(b)))
+	}
+	return nil
+}
+
+// All returns a copy of all the registered device drivers, sorted by name.
+func All() []*Ref {
+	mu.Lock()
+	defer mu.Unlock()
+	out := make([]*Ref, 0, len(byName))
+	for _, r := range byName {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Register registers a device driver.
+//
+// Registering the same driver name twice is an error.
+func Register(r *Ref) error {
+	if len(r.Name) == 0 {
+		return errors.New("devreg: can't register a driver with no name")
+	}
+	if r.I2C == nil && r.SPI == nil && r.OneWire == nil && r.GPIO == nil {
+		return errors.New("devreg: can't register driver " + strconv.Quote(r.Name) + " with no opener")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := byName[r.Name]; ok {
+		return errors.New("devreg: can't register driver " + strconv.Quote(r.Name) + " twice")
+	}
+	c := *r
+	byName[r.Name] = &c
+	return nil
+}
+
+// DecodeOpts decodes the options of a device into v.
+//
+// Empty options leave v untouched, so v can be initialized with the default
+// values. Unknown fields are rejected to catch typos, like Load() does.
+func DecodeOpts(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		return fmt.Errorf("devreg: invalid opts: %v", err)
+	}
+	return nil
+}
+
+// MustRegister calls Register() and panics if registration fails.
+//
+// This is the function to call in a driver's package init() function.
+func MustRegister(r *Ref) {
+	if err := Register(r); err != nil {
+		panic(err)
+	}
+}
+
+// Unregister removes a previously registered device driver.
+func Unregister(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := byName[name]; !ok {
+		return errors.New("devreg: can't unregister unknown driver name " + strconv.Quote(name))
+	}
+	delete(byName, name)
+	return nil
+}
+
+//
+
+var (
+	mu     sync.Mutex
+	byName = map[string]*Ref{}
+)
+
+func get(name string) *Ref {
+	mu.Lock()
+	defer mu.Unlock()
+	return byName[name]
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devreg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio/gpioreg"
+	"github.com/meandrewdev/periph/conn/gpio/gpiotest"
+	"github.com/meandrewdev/periph/conn/i2c"
+)
+
+func TestRegister(t *testing.T) {
+	defer reset()
+	if err := Register(&Ref{}); err == nil {
+		t.Fatal("no name")
+	}
+	if err := Register(&Ref{Name: "a"}); err == nil {
+		t.Fatal("no opener")
+	}
+	r := &Ref{Name: "a", I2C: func(b i2c.Bus, opts json.RawMessage) (conn.Resource, error) { return nil, nil }}
+	if err := Register(r); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(r); err == nil {
+		t.Fatal("twice")
+	}
+	r = &Ref{Name: "0", GPIO: func(opts json.RawMessage) (conn.Resource, error) { return nil, nil }}
+	MustRegister(r)
+	if a := All(); len(a) != 2 || a[0].Name != "0" || a[1].Name != "a" {
+		t.Fatal(a)
+	}
+	if err := Unregister("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unregister("a"); err == nil {
+		t.Fatal("unknown")
+	}
+	if a := All(); len(a) != 1 {
+		t.Fatal(a)
+	}
+}
+
+func TestMustRegister_panic(t *testing.T) {
+	defer reset()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	MustRegister(&Ref{Name: "a"})
+}
+
+func TestPin(t *testing.T) {
+	p := &gpiotest.Pin{N: "DEVREG1", Num: 1}
+	if err := gpioreg.Register(p); err != nil {
+		t.Fatal(err)
+	}
+	defer gpioreg.Unregister("DEVREG1")
+	var v struct {
+		P *Pin `json:"p"`
+	}
+	if err := json.Unmarshal([]byte(`{"p": "DEVREG1"}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.P.PinIO != p {
+		t.Fatal(v.P)
+	}
+	if err := json.Unmarshal([]byte(`{"p": "DEVREG_UNKNOWN"}`), &v); err == nil {
+		t.Fatal("unknown pin")
+	}
+}
+
+func TestDecodeOpts(t *testing.T) {
+	type opts struct {
+		Addr uint16 `json:"addr"`
+	}
+	o := opts{Addr: 0x76}
+	if err := DecodeOpts(nil, &o); err != nil || o.Addr != 0x76 {
+		t.Fatal(o, err)
+	}
+	if err := DecodeOpts(json.RawMessage(`{"addr": 119}`), &o); err != nil || o.Addr != 119 {
+		t.Fatal(o, err)
+	}
+	err := DecodeOpts(json.RawMessage(`{"adr": 118}`), &o)
+	if err == nil || err.Error() != `devreg: invalid opts: json: unknown field "adr"` {
+		t.Fatal(err)
+	}
+}
+
+//
+
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	byName = map[string]*Ref{}
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package ds18b20
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/onewire"
+	"github.com/meandrewdev/periph/devices/devreg"
+)
+
+// jsonOpts is the board description options.
+type jsonOpts struct {
+	// Addr is the 64 bits 1-wire address of the device.
+	Addr onewire.Address `json:"addr"`
+	// Resolution is in bits, between 9 and 12. It defaults to 10.
+	Resolution int `json:"resolution"`
+}
+
+func openOneWire(b onewire.Bus, raw json.RawMessage) (conn.Resource, error) {
+	j := jsonOpts{Resolution: 10}
+	if err := devreg.DecodeOpts(raw, &j); err != nil {
+		return nil, err
+	}
+	if j.Addr == 0 {
+		return nil, errors.New("ds18b20: addr is required")
+	}
+	return New(b, j.Addr, j.Resolution)
+}
+
+func init() {
+	devreg.MustRegister(&devreg.Ref{Name: "ds18b20", OneWire: openOneWire})
+}
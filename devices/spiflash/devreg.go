@@ -0,0 +1,41 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package spiflash
+
+import (
+	"encoding/json"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/spi"
+	"github.com/meandrewdev/periph/devices/devreg"
+)
+
+// jsonOpts is the board description options.
+type jsonOpts struct {
+	// Speed is the SPI clock speed, e.g. "20MHz". It defaults to
+	// DefaultOpts.Speed.
+	Speed string `json:"speed"`
+	// Params overrides the parameters discovered via SFDP.
+	Params *Params `json:"params"`
+}
+
+func openSPI(p spi.Port, raw json.RawMessage) (conn.Resource, error) {
+	var j jsonOpts
+	if err := devreg.DecodeOpts(raw, &j); err != nil {
+		return nil, err
+	}
+	o := DefaultOpts
+	if j.Speed != "" {
+		if err := o.Speed.Set(j.Speed); err != nil {
+			return nil, err
+		}
+	}
+	o.Params = j.Params
+	return New(p, &o)
+}
+
+func init() {
+	devreg.MustRegister(&devreg.Ref{Name: "spiflash", SPI: openSPI})
+}
@@ -0,0 +1,24 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package spiflash drives a serial NOR flash memory connected on a SPI port.
+//
+// The geometry of the device is discovered with the Serial Flash Discoverable
+// Parameters (SFDP) tables, so most devices from the common vendors
+// (Winbond, Macronix, Micron, GigaDevice, ISSI, etc) are supported without
+// device specific code.
+//
+// Dev implements io.ReaderAt and io.WriterAt. As with any NOR flash, writing
+// can only clear bits; the area must be erased first with Erase().
+//
+// Datasheet
+//
+// JESD216 Serial Flash Discoverable Parameters
+//
+// https://www.jedec.org/standards-documents/docs/jesd216b
+//
+// Example of a device:
+//
+// https://www.winbond.com/resource-files/w25q128jv%20revf%2003272018%20plus.pdf
+package spiflash
@@ -0,0 +1,140 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package spiflash
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Params describes the geometry of a flash device.
+type Params struct {
+	// Size is the size of the memory array in bytes.
+	Size int64
+	// PageSize is the maximum number of bytes programmed at once.
+	PageSize int
+	// Erases are the supported erase operations, sorted by increasing size.
+	Erases []EraseType
+	// AddrBytes is the number of address bytes, 3 or 4.
+	AddrBytes int
+	// Enter4ByteWREN is set when the write enable latch must be set before
+	// entering the 4 bytes address mode.
+	Enter4ByteWREN bool
+}
+
+// EraseType is an erase operation of a block of Size bytes.
+type EraseType struct {
+	Size   int
+	Opcode byte
+}
+
+//
+
+// sfdpSignature is "SFDP" in little endian.
+const sfdpSignature = 0x50444653
+
+// errNoSFDP is returned when the device does not support SFDP.
+var errNoSFDP = errors.New("spiflash: SFDP is not supported")
+
+// parseSFDP reads the SFDP headers and the basic flash parameter table with
+// read.
+//
+// See JESD216 section 6.
+func parseSFDP(read func(addr uint32, b []byte) error) (Params, error) {
+	var hdr [16]byte
+	if err := read(0, hdr[:]); err != nil {
+		return Params{}, err
+	}
+	if binary.LittleEndian.Uint32(hdr[:]) != sfdpSignature {
+		return Params{}, errNoSFDP
+	}
+	// The first parameter header is always the basic flash parameter table,
+	// whose ID is 0xFF00.
+	ph := hdr[8:]
+	if ph[0] != 0x00 || ph[7] != 0xFF {
+		return Params{}, fmt.Errorf("spiflash: unexpected first SFDP parameter table 0x%02X%02X", ph[7], ph[0])
+	}
+	l := int(ph[3])
+	if l < 9 {
+		return Params{}, fmt.Errorf("spiflash: SFDP basic flash parameter table is too short: %d DWORDs", l)
+	}
+	ptr := uint32(ph[4]) | uint32(ph[5])<<8 | uint32(ph[6])<<16
+	tbl := make([]byte, 4*l)
+	if err := read(ptr, tbl); err != nil {
+		return Params{}, err
+	}
+	d := make([]uint32, l)
+	for i := range d {
+		d[i] = binary.LittleEndian.Uint32(tbl[4*i:])
+	}
+	return parseBFPT(d)
+}
+
+// parseBFPT decodes the basic flash parameter table.
+//
+// See JESD216 section 6.4.
+func parseBFPT(d []uint32) (Params, error) {
+	p := Params{PageSize: 256, AddrBytes: 3}
+
+	// 2nd DWORD: density.
+	if d[1]&0x80000000 == 0 {
+		p.Size = (int64(d[1]) + 1) / 8
+	} else {
+		n := d[1] & 0x7FFFFFFF
+		if n < 3 || n > 62 {
+			return Params{}, fmt.Errorf("spiflash: invalid SFDP density 2^%d bits", n)
+		}
+		p.Size = int64(1) << (n - 3)
+	}
+	if p.Size == 0 {
+		return Params{}, errors.New("spiflash: invalid SFDP density")
+	}
+
+	// 1st DWORD: address bytes.
+	switch (d[0] >> 17) & 3 {
+	case 0:
+		// 3 bytes only.
+	case 1:
+		// 3 or 4 bytes; use 4 bytes only when needed.
+		if p.Size > 1<<24 {
+			p.AddrBytes = 4
+		}
+	case 2:
+		p.AddrBytes = 4
+	default:
+		return Params{}, errors.New("spiflash: invalid SFDP address bytes")
+	}
+
+	// 8th and 9th DWORDs: erase types.
+	for _, v := range d[7:9] {
+		for i := 0; i < 2; i++ {
+			if n := byte(v >> (16 * uint(i))); n != 0 && n < 31 {
+				p.Erases = append(p.Erases, EraseType{Size: 1 << n, Opcode: byte(v >> (16*uint(i) + 8))})
+			}
+		}
+	}
+	if len(p.Erases) == 0 && d[0]&3 == 1 {
+		// Only the legacy 4kB erase is described.
+		p.Erases = append(p.Erases, EraseType{Size: 4096, Opcode: byte(d[0] >> 8)})
+	}
+	sort.Slice(p.Erases, func(i, j int) bool { return p.Erases[i].Size < p.Erases[j].Size })
+
+	// 11th DWORD: page size, JESD216A and later.
+	if len(d) >= 11 {
+		if n := (d[10] >> 4) & 0xF; n != 0 {
+			p.PageSize = 1 << n
+		}
+	}
+
+	// 16th DWORD: how to enter the 4 bytes address mode, JESD216B and later.
+	if len(d) >= 16 && p.AddrBytes == 4 {
+		if v := d[15] >> 24; v&1 == 0 && v&2 != 0 {
+			p.Enter4ByteWREN = true
+		}
+	}
+	return p, nil
+}
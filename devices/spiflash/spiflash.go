@@ -0,0 +1,419 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package spiflash
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/spi"
+)
+
+// Opts contains the options for New.
+type Opts struct {
+	// Speed is the SPI clock speed.
+	Speed physic.Frequency
+	// Params overrides the parameters discovered via SFDP. It is required for
+	// devices that do not support SFDP.
+	Params *Params
+}
+
+// DefaultOpts is the recommended default options.
+var DefaultOpts = Opts{
+	Speed: 10 * physic.MegaHertz,
+}
+
+// JEDECID is the identification returned by the device.
+type JEDECID struct {
+	Manufacturer byte
+	Device       uint16
+}
+
+func (j JEDECID) String() string {
+	return fmt.Sprintf("%02X%04X", j.Manufacturer, j.Device)
+}
+
+// Status is the status register 1 of the device.
+type Status uint8
+
+// Bits of the status register common to most devices.
+const (
+	Busy         Status = 1 << 0 // WIP: program, erase or status write in progress
+	WriteEnabled Status = 1 << 1 // WEL: write enable latch
+	BP0          Status = 1 << 2 // Block protect bits
+	BP1          Status = 1 << 3
+	BP2          Status = 1 << 4
+	SRP0         Status = 1 << 7 // Status register protect; honors the WP pin
+)
+
+// New opens a handle to a SPI NOR flash.
+//
+// The JEDEC ID is read and the geometry is discovered via SFDP unless
+// opts.Params is set.
+func New(p spi.Port, opts *Opts) (*Dev, error) {
+	c, err := p.Connect(opts.Speed, spi.Mode0, 8)
+	if err != nil {
+		return nil, fmt.Errorf("spiflash: %v", err)
+	}
+	d := &Dev{c: c, maxTx: 4096}
+	if l, ok := c.(conn.Limits); ok {
+		if n := l.MaxTxSize(); n != 0 {
+			d.maxTx = n
+		}
+	}
+	var id [4]byte
+	if err := d.c.Tx([]byte{cmdReadID, 0, 0, 0}, id[:]); err != nil {
+		return nil, fmt.Errorf("spiflash: %v", err)
+	}
+	d.id = JEDECID{Manufacturer: id[1], Device: uint16(id[2])<<8 | uint16(id[3])}
+	if d.id.Manufacturer == 0 || d.id.Manufacturer == 0xFF {
+		return nil, fmt.Errorf("spiflash: no device detected; got JEDEC ID %s", d.id)
+	}
+	if opts.Params != nil {
+		d.params = *opts.Params
+	} else if d.params, err = parseSFDP(d.readSFDP); err != nil {
+		if err == errNoSFDP {
+			return nil, fmt.Errorf("spiflash: device %s does not support SFDP; specify Opts.Params", d.id)
+		}
+		return nil, err
+	}
+	if d.params.AddrBytes != 3 && d.params.AddrBytes != 4 {
+		return nil, fmt.Errorf("spiflash: invalid address bytes %d", d.params.AddrBytes)
+	}
+	if d.params.PageSize <= 0 || d.params.Size <= 0 {
+		return nil, errors.New("spiflash: invalid size")
+	}
+	if d.params.AddrBytes == 4 {
+		if d.params.Enter4ByteWREN {
+			if err := d.writeEnable(); err != nil {
+				return nil, err
+			}
+		}
+		if err := d.c.Tx([]byte{cmdEnter4Byte}, nil); err != nil {
+			return nil, fmt.Errorf("spiflash: %v", err)
+		}
+	}
+	return d, nil
+}
+
+// Dev is a handle to a SPI NOR flash.
+type Dev struct {
+	c      spi.Conn
+	maxTx  int
+	id     JEDECID
+	params Params
+
+	mu sync.Mutex
+}
+
+func (d *Dev) String() string {
+	return fmt.Sprintf("SPIFlash{%s}", d.c)
+}
+
+// Halt implements conn.Resource.
+//
+// It is a no-op; an on-going program or erase operation cannot be
+// interrupted.
+func (d *Dev) Halt() error {
+	return nil
+}
+
+// ID returns the JEDEC ID of the device.
+func (d *Dev) ID() JEDECID {
+	return d.id
+}
+
+// Params returns the geometry of the device.
+func (d *Dev) Params() Params {
+	return d.params
+}
+
+// Size returns the size of the memory array in bytes.
+func (d *Dev) Size() int64 {
+	return d.params.Size
+}
+
+// ReadAt implements io.ReaderAt.
+func (d *Dev) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("spiflash: negative offset")
+	}
+	if off >= d.params.Size {
+		return 0, io.EOF
+	}
+	n := len(b)
+	if l := d.params.Size - off; int64(n) > l {
+		n = int(l)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	chunk := d.maxTx - 1 - d.params.AddrBytes
+	for i := 0; i < n; i += chunk {
+		end := i + chunk
+		if end > n {
+			end = n
+		}
+		p := []spi.Packet{
+			{W: d.cmd(cmdRead, off+int64(i)), KeepCS: true},
+			{R: b[i:end]},
+		}
+		if err := d.c.TxPackets(p); err != nil {
+			return i, fmt.Errorf("spiflash: %v", err)
+		}
+	}
+	if n != len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt implements io.WriterAt.
+//
+// The data is programmed page by page. Programming can only clear bits, so the
+// area must be erased first.
+func (d *Dev) WriteAt(b []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(b)) > d.params.Size {
+		return 0, fmt.Errorf("spiflash: write of %d bytes at %d is out of range", len(b), off)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := 0; i < len(b); {
+		// Do not cross a page boundary, the address would wrap around.
+		addr := off + int64(i)
+		n := d.params.PageSize - int(addr%int64(d.params.PageSize))
+		if l := len(b) - i; n > l {
+			n = l
+		}
+		if max := d.maxTx - 1 - d.params.AddrBytes; n > max {
+			n = max
+		}
+		if err := d.writeEnable(); err != nil {
+			return i, err
+		}
+		p := []spi.Packet{
+			{W: d.cmd(cmdPageProgram, addr), KeepCS: true},
+			{W: b[i : i+n]},
+		}
+		if err := d.c.TxPackets(p); err != nil {
+			return i, fmt.Errorf("spiflash: %v", err)
+		}
+		if err := d.wait(pageProgramTimeout); err != nil {
+			return i, err
+		}
+		i += n
+	}
+	return len(b), nil
+}
+
+// Erase erases the area of length bytes starting at off, setting all the
+// bytes to 0xFF.
+//
+// off and length must be aligned on the smallest erase size. The largest
+// erase operations are used when possible.
+func (d *Dev) Erase(off, length int64) error {
+	if len(d.params.Erases) == 0 {
+		return errors.New("spiflash: no erase operation known")
+	}
+	min := int64(d.params.Erases[0].Size)
+	if off < 0 || length < 0 || off+length > d.params.Size || off%min != 0 || length%min != 0 {
+		return fmt.Errorf("spiflash: erase of %d bytes at %d must be in range and aligned on %d bytes", length, off, min)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for end := off + length; off < end; {
+		e := d.params.Erases[0]
+		for _, t := range d.params.Erases[1:] {
+			if s := int64(t.Size); off%s == 0 && off+s <= end {
+				e = t
+			}
+		}
+		if err := d.writeEnable(); err != nil {
+			return err
+		}
+		if err := d.c.Tx(d.cmd(e.Opcode, off), nil); err != nil {
+			return fmt.Errorf("spiflash: %v", err)
+		}
+		if err := d.wait(eraseTimeout); err != nil {
+			return err
+		}
+		off += int64(e.Size)
+	}
+	return nil
+}
+
+// EraseChip erases the whole memory array.
+//
+// It can take minutes on large devices.
+func (d *Dev) EraseChip() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.writeEnable(); err != nil {
+		return err
+	}
+	if err := d.c.Tx([]byte{cmdChipErase}, nil); err != nil {
+		return fmt.Errorf("spiflash: %v", err)
+	}
+	return d.wait(chipEraseTimeout)
+}
+
+// Status reads the status register.
+func (d *Dev) Status() (Status, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.status()
+}
+
+// SetStatus writes the status register.
+//
+// Busy and WriteEnabled are read only.
+func (d *Dev) SetStatus(s Status) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.setStatus(s)
+}
+
+// SetWriteProtect sets or clears the block protect bits BP0 to BP2.
+//
+// When set, the whole memory array is protected on most devices and program
+// and erase operations are ignored.
+func (d *Dev) SetWriteProtect(on bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s, err := d.status()
+	if err != nil {
+		return err
+	}
+	s &^= Busy | WriteEnabled
+	if on {
+		s |= BP0 | BP1 | BP2
+	} else {
+		s &^= BP0 | BP1 | BP2
+	}
+	return d.setStatus(s)
+}
+
+//
+
+const (
+	cmdWriteStatus = 0x01
+	cmdPageProgram = 0x02
+	cmdRead        = 0x03
+	cmdWriteDis    = 0x04
+	cmdReadStatus  = 0x05
+	cmdWriteEnable = 0x06
+	cmdReadSFDP    = 0x5A
+	cmdReadID      = 0x9F
+	cmdEnter4Byte  = 0xB7
+	cmdChipErase   = 0xC7
+)
+
+// Maximum durations of the operations. They are generous, as they vary
+// widely across devices.
+const (
+	pageProgramTimeout = 100 * time.Millisecond
+	statusTimeout      = 100 * time.Millisecond
+	eraseTimeout       = 10 * time.Second
+	chipEraseTimeout   = 10 * time.Minute
+)
+
+// pollInterval is the interval between status reads while busy.
+var pollInterval = 100 * time.Microsecond
+
+// cmd returns a command followed by an address.
+func (d *Dev) cmd(c byte, addr int64) []byte {
+	if d.params.AddrBytes == 4 {
+		return []byte{c, byte(addr >> 24), byte(addr >> 16), byte(addr >> 8), byte(addr)}
+	}
+	return []byte{c, byte(addr >> 16), byte(addr >> 8), byte(addr)}
+}
+
+// readSFDP reads the SFDP tables; the address is always 3 bytes followed by
+// 8 dummy cycles.
+func (d *Dev) readSFDP(addr uint32, b []byte) error {
+	p := []spi.Packet{
+		{W: []byte{cmdReadSFDP, byte(addr >> 16), byte(addr >> 8), byte(addr), 0}, KeepCS: true},
+		{R: b},
+	}
+	if err := d.c.TxPackets(p); err != nil {
+		return fmt.Errorf("spiflash: %v", err)
+	}
+	return nil
+}
+
+func (d *Dev) status() (Status, error) {
+	var r [2]byte
+	if err := d.c.Tx([]byte{cmdReadStatus, 0}, r[:]); err != nil {
+		return 0, fmt.Errorf("spiflash: %v", err)
+	}
+	return Status(r[1]), nil
+}
+
+func (d *Dev) setStatus(s Status) error {
+	if err := d.writeEnable(); err != nil {
+		return err
+	}
+	if err := d.c.Tx([]byte{cmdWriteStatus, byte(s)}, nil); err != nil {
+		return fmt.Errorf("spiflash: %v", err)
+	}
+	if err := d.wait(statusTimeout); err != nil {
+		return err
+	}
+	v, err := d.status()
+	if err != nil {
+		return err
+	}
+	if v&^(Busy|WriteEnabled) != s&^(Busy|WriteEnabled) {
+		return fmt.Errorf("spiflash: status register is 0x%02X after writing 0x%02X; is it protected?", byte(v), byte(s))
+	}
+	return nil
+}
+
+// writeEnable sets the write enable latch, which is required before each
+// program, erase or status write operation.
+func (d *Dev) writeEnable() error {
+	if err := d.c.Tx([]byte{cmdWriteEnable}, nil); err != nil {
+		return fmt.Errorf("spiflash: %v", err)
+	}
+	s, err := d.status()
+	if err != nil {
+		return err
+	}
+	if s&WriteEnabled == 0 {
+		return errors.New("spiflash: failed to enable write")
+	}
+	return nil
+}
+
+// wait polls the status register until the device is not busy anymore.
+func (d *Dev) wait(timeout time.Duration) error {
+	for start := time.Now(); ; {
+		s, err := d.status()
+		if err != nil {
+			return err
+		}
+		if s&Busy == 0 {
+			if s&WriteEnabled != 0 {
+				// The operation was ignored, generally because the area is
+				// protected.
+				_ = d.c.Tx([]byte{cmdWriteDis}, nil)
+				return errors.New("spiflash: operation ignored by the device; is it write protected?")
+			}
+			return nil
+		}
+		if time.Since(start) > timeout {
+			return fmt.Errorf("spiflash: device still busy after %s", timeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+var _ conn.Resource = &Dev{}
+var _ io.ReaderAt = &Dev{}
+var _ io.WriterAt = &Dev{}
@@ -0,0 +1,514 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package spiflash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/spi"
+	"github.com/meandrewdev/periph/conn/spi/spitest"
+)
+
+func TestNew(t *testing.T) {
+	f := newSimFlash(1 << 20)
+	r := spitest.Record{Port: f}
+	d, err := New(&r, &DefaultOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := d.String(); s != "SPIFlash{record}" {
+		t.Fatal(s)
+	}
+	if id := d.ID(); id.String() != "EF4014" {
+		t.Fatal(id)
+	}
+	expected := Params{
+		Size:      1 << 20,
+		PageSize:  256,
+		Erases:    []EraseType{{4096, 0x20}, {65536, 0xD8}},
+		AddrBytes: 3,
+	}
+	if p := d.Params(); !reflect.DeepEqual(p, expected) {
+		t.Fatalf("%#v", p)
+	}
+	if s := d.Size(); s != 1<<20 {
+		t.Fatal(s)
+	}
+	if !bytes.Equal(r.Ops[0].W, []byte{cmdReadID, 0, 0, 0}) || !bytes.Equal(r.Ops[1].W, []byte{cmdReadSFDP, 0, 0, 0, 0}) {
+		t.Fatal(r.Ops)
+	}
+	if err := d.Halt(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNew_noSFDP(t *testing.T) {
+	f := newSimFlash(1 << 20)
+	f.sfdp = nil
+	if _, err := New(f, &DefaultOpts); err == nil {
+		t.Fatal("SFDP is not supported")
+	}
+	opts := Opts{Params: &Params{Size: 1 << 20, PageSize: 256, Erases: []EraseType{{4096, 0x20}}, AddrBytes: 3}}
+	d, err := New(f, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Erase(0, 4096); err != nil {
+		t.Fatal(err)
+	}
+
+	f = newSimFlash(1 << 20)
+	f.id = [3]byte{0xFF, 0xFF, 0xFF}
+	if _, err := New(f, &DefaultOpts); err == nil {
+		t.Fatal("no device")
+	}
+}
+
+func TestNew_4ByteAddress(t *testing.T) {
+	f := newSimFlash(1 << 20)
+	opts := Opts{Params: &Params{Size: 1 << 20, PageSize: 256, Erases: []EraseType{{4096, 0x20}}, AddrBytes: 4}}
+	d, err := New(f, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.addr4 {
+		t.Fatal("expected 4 bytes address mode")
+	}
+	if err := d.Erase(0x1000, 0x1000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.WriteAt([]byte{1, 2, 3}, 0x1001); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 4)
+	if _, err := d.ReadAt(b, 0x1000); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, []byte{0xFF, 1, 2, 3}) {
+		t.Fatal(b)
+	}
+}
+
+func TestNew_4ByteAddressWREN(t *testing.T) {
+	f := newSimFlash(1 << 20)
+	f.enter4WREN = true
+	opts := Opts{Params: &Params{Size: 1 << 20, PageSize: 256, Erases: []EraseType{{4096, 0x20}}, AddrBytes: 4}}
+	if _, err := New(f, &opts); err != nil {
+		t.Fatal(err)
+	}
+	if f.addr4 {
+		t.Fatal("4 bytes address mode requires write enable")
+	}
+	f = newSimFlash(1 << 20)
+	f.enter4WREN = true
+	opts.Params.Enter4ByteWREN = true
+	if _, err := New(f, &opts); err != nil {
+		t.Fatal(err)
+	}
+	if !f.addr4 {
+		t.Fatal("expected 4 bytes address mode")
+	}
+}
+
+func TestDev_ReadWriteErase(t *testing.T) {
+	f := newSimFlash(1 << 20)
+	// Force chunking.
+	f.maxTx = 64
+	d, err := New(f, &DefaultOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Erasing 64kB+4kB at 60kB uses a 4kB and a 64kB erase.
+	for i := range f.mem {
+		f.mem[i] = 0
+	}
+	if err := d.Erase(60*1024, 68*1024); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0x20, 0xD8}; !bytes.Equal(f.erases, expected) {
+		t.Fatal(f.erases)
+	}
+	if f.mem[60*1024-1] != 0 || f.mem[60*1024] != 0xFF || f.mem[128*1024-1] != 0xFF || f.mem[128*1024] != 0 {
+		t.Fatal("unexpected erase")
+	}
+
+	// Program across page boundaries.
+	data := make([]byte, 600)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	if n, err := d.WriteAt(data, 64*1024+200); n != len(data) || err != nil {
+		t.Fatal(n, err)
+	}
+	got := make([]byte, len(data)+2)
+	if n, err := d.ReadAt(got, 64*1024+199); n != len(got) || err != nil {
+		t.Fatal(n, err)
+	}
+	if got[0] != 0xFF || got[len(got)-1] != 0xFF || !bytes.Equal(got[1:len(got)-1], data) {
+		t.Fatal(got)
+	}
+
+	// Reading past the end.
+	if n, err := d.ReadAt(got, d.Size()-2); n != 2 || err != io.EOF {
+		t.Fatal(n, err)
+	}
+	if n, err := d.ReadAt(got, d.Size()); n != 0 || err != io.EOF {
+		t.Fatal(n, err)
+	}
+	if _, err := d.ReadAt(got, -1); err == nil {
+		t.Fatal("negative offset")
+	}
+	if _, err := d.WriteAt(got, d.Size()-2); err == nil {
+		t.Fatal("out of range")
+	}
+	if err := d.Erase(1, 4096); err == nil {
+		t.Fatal("unaligned")
+	}
+	if err := d.Erase(d.Size(), 4096); err == nil {
+		t.Fatal("out of range")
+	}
+
+	if err := d.EraseChip(); err != nil {
+		t.Fatal(err)
+	}
+	if f.mem[0] != 0xFF {
+		t.Fatal("chip not erased")
+	}
+}
+
+func TestDev_WriteProtect(t *testing.T) {
+	f := newSimFlash(1 << 20)
+	d, err := New(f, &DefaultOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetWriteProtect(true); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := d.Status(); err != nil || s != BP0|BP1|BP2 {
+		t.Fatal(s, err)
+	}
+	if _, err := d.WriteAt([]byte{0}, 0); err == nil {
+		t.Fatal("protected")
+	}
+	if err := d.Erase(0, 4096); err == nil {
+		t.Fatal("protected")
+	}
+	if err := d.SetWriteProtect(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.WriteAt([]byte{0}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if f.mem[0] != 0 {
+		t.Fatal(f.mem[0])
+	}
+
+	// The status register is locked.
+	f.locked = true
+	if err := d.SetStatus(SRP0); err == nil {
+		t.Fatal("status register is locked")
+	}
+}
+
+func TestParseBFPT(t *testing.T) {
+	d := make([]uint32, 9)
+	// 3 or 4 bytes addressing, legacy 4kB erase only, 256Mbits.
+	d[0] = 1<<17 | 0x20<<8 | 1
+	d[1] = 0x80000000 | 28
+	p, err := parseBFPT(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Params{Size: 32 << 20, PageSize: 256, Erases: []EraseType{{4096, 0x20}}, AddrBytes: 4}
+	if !reflect.DeepEqual(p, expected) {
+		t.Fatalf("%#v", p)
+	}
+	// 4 bytes addressing only.
+	d[0] = 2 << 17
+	d[1] = 1<<20 - 1
+	d[8] = 0xDC10
+	if p, err = parseBFPT(d); err != nil {
+		t.Fatal(err)
+	}
+	expected = Params{Size: 1 << 17, PageSize: 256, Erases: []EraseType{{65536, 0xDC}}, AddrBytes: 4}
+	if !reflect.DeepEqual(p, expected) {
+		t.Fatalf("%#v", p)
+	}
+	// JESD216B: write enable is required before entering 4 bytes addressing.
+	d16 := append(append([]uint32{}, d...), make([]uint32, 7)...)
+	d16[15] = 2 << 24
+	if p, err = parseBFPT(d16); err != nil {
+		t.Fatal(err)
+	}
+	expected.Enter4ByteWREN = true
+	if !reflect.DeepEqual(p, expected) {
+		t.Fatalf("%#v", p)
+	}
+	for i, v := range []uint32{0x80000000 | 2, 0x80000000 | 63} {
+		d[1] = v
+		if _, err := parseBFPT(d); err == nil {
+			t.Fatal(i)
+		}
+	}
+	d[1] = 0
+	d[0] = 3 << 17
+	if _, err := parseBFPT(d); err == nil {
+		t.Fatal("invalid address bytes")
+	}
+}
+
+func TestParseSFDP_invalid(t *testing.T) {
+	sfdp := newSimFlash(1 << 20).sfdp
+	read := func(b []byte) func(addr uint32, d []byte) error {
+		return func(addr uint32, d []byte) error {
+			copy(d, b[addr:])
+			return nil
+		}
+	}
+	b := append([]byte{}, sfdp...)
+	b[8] = 1
+	if _, err := parseSFDP(read(b)); err == nil {
+		t.Fatal("not the BFPT")
+	}
+	b = append([]byte{}, sfdp...)
+	b[11] = 8
+	if _, err := parseSFDP(read(b)); err == nil {
+		t.Fatal("BFPT too short")
+	}
+}
+
+//
+
+func init() {
+	pollInterval = 0
+}
+
+// simFlash simulates a 3 bytes address SPI NOR flash with 4kB and 64kB
+// erases.
+type simFlash struct {
+	mem    []byte
+	sfdp   []byte
+	id     [3]byte
+	status Status
+	locked bool // status register is locked
+	addr4  bool
+	maxTx  int
+	busy   int    // number of status reads returning Busy
+	erases []byte // erase opcodes executed
+	txn    []byte // bytes written in the current transaction
+
+	// enter4WREN requires the write enable latch to enter the 4 bytes address
+	// mode.
+	enter4WREN bool
+}
+
+func newSimFlash(size int) *simFlash {
+	f := &simFlash{mem: make([]byte, size), id: [3]byte{0xEF, 0x40, 0x14}}
+	// SFDP header, one parameter header pointing to a 16 DWORDs BFPT at 0x30.
+	f.sfdp = make([]byte, 0x30+16*4)
+	copy(f.sfdp, []byte{'S', 'F', 'D', 'P', 0x06, 0x01, 0x00, 0xFF, 0x00, 0x06, 0x01, 16, 0x30, 0x00, 0x00, 0xFF})
+	bfpt := make([]uint32, 16)
+	bfpt[0] = 0x20<<8 | 1
+	bfpt[1] = uint32(size*8 - 1)
+	bfpt[7] = 0xD8<<24 | 16<<16 | 0x20<<8 | 12
+	bfpt[10] = 8 << 4
+	for i, v := range bfpt {
+		binary.LittleEndian.PutUint32(f.sfdp[0x30+4*i:], v)
+	}
+	return f
+}
+
+func (f *simFlash) String() string {
+	return "simflash"
+}
+
+func (f *simFlash) Close() error {
+	return nil
+}
+
+func (f *simFlash) LimitSpeed(freq physic.Frequency) error {
+	return nil
+}
+
+func (f *simFlash) Connect(freq physic.Frequency, mode spi.Mode, bits int) (spi.Conn, error) {
+	return f, nil
+}
+
+func (f *simFlash) Duplex() conn.Duplex {
+	return conn.Full
+}
+
+func (f *simFlash) MaxTxSize() int {
+	return f.maxTx
+}
+
+func (f *simFlash) Tx(w, r []byte) error {
+	f.xfer(w, r)
+	f.end()
+	return nil
+}
+
+func (f *simFlash) TxPackets(p []spi.Packet) error {
+	for i := range p {
+		if f.maxTx != 0 && len(p[i].W)+len(p[i].R) > f.maxTx {
+			return io.ErrShortBuffer
+		}
+		f.xfer(p[i].W, p[i].R)
+		if !p[i].KeepCS {
+			f.end()
+		}
+	}
+	return nil
+}
+
+func (f *simFlash) CLK() gpio.PinOut {
+	return gpio.INVALID
+}
+
+func (f *simFlash) MOSI() gpio.PinOut {
+	return gpio.INVALID
+}
+
+func (f *simFlash) MISO() gpio.PinIn {
+	return gpio.INVALID
+}
+
+func (f *simFlash) CS() gpio.PinOut {
+	return gpio.INVALID
+}
+
+func (f *simFlash) xfer(w, r []byte) {
+	n := len(w)
+	if len(r) > n {
+		n = len(r)
+	}
+	for i := 0; i < n; i++ {
+		pos := len(f.txn)
+		var b byte
+		if i < len(w) {
+			b = w[i]
+		}
+		f.txn = append(f.txn, b)
+		if i < len(r) {
+			r[i] = f.out(pos)
+		}
+	}
+}
+
+func (f *simFlash) addrLen() int {
+	if f.addr4 {
+		return 4
+	}
+	return 3
+}
+
+func (f *simFlash) addr() int {
+	a := 0
+	for _, b := range f.txn[1 : 1+f.addrLen()] {
+		a = a<<8 | int(b)
+	}
+	return a
+}
+
+// out returns the byte sent at position pos of the transaction.
+func (f *simFlash) out(pos int) byte {
+	if pos == 0 {
+		return 0xFF
+	}
+	switch f.txn[0] {
+	case cmdReadID:
+		if pos <= 3 {
+			return f.id[pos-1]
+		}
+	case cmdReadStatus:
+		if f.busy != 0 {
+			f.busy--
+			return byte(f.status | Busy)
+		}
+		return byte(f.status)
+	case cmdReadSFDP:
+		if pos >= 5 {
+			a := int(f.txn[1])<<16 | int(f.txn[2])<<8 | int(f.txn[3]) + pos - 5
+			if a < len(f.sfdp) {
+				return f.sfdp[a]
+			}
+		}
+	case cmdRead:
+		if l := f.addrLen(); pos > l {
+			return f.mem[(f.addr()+pos-1-l)%len(f.mem)]
+		}
+	}
+	return 0xFF
+}
+
+// end executes the transaction when CS is deasserted.
+func (f *simFlash) end() {
+	txn := f.txn
+	if len(txn) == 0 {
+		return
+	}
+	defer func() {
+		f.txn = nil
+	}()
+	we := f.status&WriteEnabled != 0
+	protected := f.status&(BP0|BP1|BP2) != 0
+	switch txn[0] {
+	case cmdWriteEnable:
+		f.status |= WriteEnabled
+	case cmdWriteDis:
+		f.status &^= WriteEnabled
+	case cmdEnter4Byte:
+		if !f.enter4WREN || we {
+			f.addr4 = true
+			f.status &^= WriteEnabled
+		}
+	case cmdWriteStatus:
+		if we && !f.locked && len(txn) == 2 {
+			f.status = Status(txn[1]) &^ (Busy | WriteEnabled)
+			f.busy = 1
+		}
+	case cmdPageProgram:
+		if l := f.addrLen(); we && !protected && len(txn) > 1+l {
+			// The address wraps around within the page.
+			a := f.addr()
+			base := a &^ 255
+			for i, b := range txn[1+l:] {
+				f.mem[base+(a+i)&255] &= b
+			}
+			f.status &^= WriteEnabled
+			f.busy = 2
+		}
+	case 0x20, 0xD8:
+		if l := f.addrLen(); we && !protected && len(txn) == 1+l {
+			size := 4096
+			if txn[0] == 0xD8 {
+				size = 65536
+			}
+			a := f.addr() &^ (size - 1)
+			for i := a; i < a+size; i++ {
+				f.mem[i] = 0xFF
+			}
+			f.erases = append(f.erases, txn[0])
+			f.status &^= WriteEnabled
+			f.busy = 3
+		}
+	case cmdChipErase:
+		if we && !protected {
+			for i := range f.mem {
+				f.mem[i] = 0xFF
+			}
+			f.status &^= WriteEnabled
+			f.busy = 3
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package analog
+
+import (
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+// Sensor adapts an analog input as a sensor.Sensor.
+//
+// The electrical tension is reported as a sensor.Voltage when the pin's
+// Range() reports the tension it represents. The raw measurement is always
+// reported as sensor.Raw.
+func Sensor(p PinADC) sensor.Sensor {
+	s := &adcSensor{p: p}
+	min, max := p.Range()
+	if max.V != min.V {
+		s.hasV = true
+		if d := int64(max.Raw) - int64(min.Raw); d > 0 {
+			s.prec = (max.V - min.V) / physic.ElectricPotential(d)
+		}
+	}
+	return s
+}
+
+//
+
+type adcSensor struct {
+	p    PinADC
+	hasV bool
+	prec physic.ElectricPotential
+}
+
+func (a *adcSensor) String() string {
+	return a.p.String()
+}
+
+func (a *adcSensor) Halt() error {
+	return a.p.Halt()
+}
+
+func (a *adcSensor) Measure() ([]sensor.Measurement, error) {
+	s, err := a.p.Read()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := make([]sensor.Measurement, 0, 2)
+	if a.hasV {
+		m := sensor.Measurement{Quantity: sensor.Voltage, Value: s.V, Time: now}
+		if a.prec != 0 {
+			m.Precision = a.prec
+		}
+		out = append(out, m)
+	}
+	return append(out, sensor.Measurement{Quantity: sensor.Raw, Value: sensor.Number(s.Raw), Precision: sensor.Number(1), Time: now}), nil
+}
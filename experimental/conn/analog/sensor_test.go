@@ -0,0 +1,53 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package analog
+
+import (
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+func TestSensor(t *testing.T) {
+	s := Sensor(&fakeADC{s: Sample{V: physic.Volt, Raw: 1000}})
+	if s.String() != "INVALID" {
+		t.Fatal(s)
+	}
+	m, err := s.Measure()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m[0].Quantity != sensor.Voltage || m[0].Value != physic.Volt || m[0].Precision != physic.MilliVolt {
+		t.Fatal(m)
+	}
+	if m[1].Quantity != sensor.Raw || m[1].Value != sensor.Number(1000) {
+		t.Fatal(m)
+	}
+
+	// INVALID fails on all access.
+	s = Sensor(&INVALID)
+	if _, err := s.Measure(); err == nil {
+		t.Fatal("expected failure")
+	}
+	if err := s.Halt(); err == nil {
+		t.Fatal("expected failure")
+	}
+}
+
+//
+
+type fakeADC struct {
+	invalidPin
+	s Sample
+}
+
+func (f *fakeADC) Range() (Sample, Sample) {
+	return Sample{}, Sample{V: 4096 * physic.MilliVolt, Raw: 4096}
+}
+
+func (f *fakeADC) Read() (Sample, error) {
+	return f.s, nil
+}
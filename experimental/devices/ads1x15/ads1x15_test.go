@@ -5,6 +5,7 @@
 package ads1x15
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
@@ -252,3 +253,102 @@ func TestPinADC_ReadContinous(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSim(t *testing.T) {
+	s, dev := newSimADS1115()
+	dev.inputs = [4]physic.ElectricPotential{1 * physic.Volt, 2500 * physic.MilliVolt, 0, 500 * physic.MilliVolt}
+	d, err := NewADS1115(s, &DefaultOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []struct {
+		c          Channel
+		maxVoltage physic.ElectricPotential
+		expected   physic.ElectricPotential
+	}{
+		{Channel0, 4 * physic.Volt, 1 * physic.Volt},
+		{Channel1, 4 * physic.Volt, 2500 * physic.MilliVolt},
+		{Channel1, 1 * physic.Volt, 1024 * physic.MilliVolt},
+		{Channel2, 200 * physic.MilliVolt, 0},
+		{Channel0Minus1, 2 * physic.Volt, -1500 * physic.MilliVolt},
+		{Channel0Minus3, 512 * physic.MilliVolt, 500 * physic.MilliVolt},
+		{Channel2Minus3, 1 * physic.Volt, -500 * physic.MilliVolt},
+	}
+	for i, line := range data {
+		p, err := d.PinForChannel(line.c, line.maxVoltage, 800*physic.Hertz, SaveEnergy)
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		reading, err := p.Read()
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		// Allow one LSB of error.
+		_, max := p.Range()
+		if diff := reading.V - line.expected; diff > max.V>>15 || diff < -max.V>>15 {
+			t.Fatalf("#%d: found %s, expected %s", i, reading.V, line.expected)
+		}
+	}
+	if dev.conversions != len(data) {
+		t.Fatal(dev.conversions)
+	}
+}
+
+//
+
+// simADS1115 simulates the single shot conversions of the inputs.
+type simADS1115 struct {
+	i2ctest.SimDevice
+	inputs      [4]physic.ElectricPotential
+	conversions int
+}
+
+func newSimADS1115() (*i2ctest.Sim, *simADS1115) {
+	d := &simADS1115{
+		SimDevice: i2ctest.SimDevice{
+			Regs: map[uint8]*i2ctest.SimReg{
+				ads1x15PointerConversion:    {Value: []byte{0, 0}, ReadOnly: true},
+				ads1x15PointerConfig:        {Value: []byte{0x85, 0x83}},
+				ads1x15PointerLowThreshold:  {Value: []byte{0x80, 0x00}},
+				ads1x15PointerHighThreshold: {Value: []byte{0x7F, 0xFF}},
+			},
+		},
+	}
+	d.Regs[ads1x15PointerConfig].OnWrite = d.convert
+	return &i2ctest.Sim{Devices: map[uint16]*i2ctest.SimDevice{DefaultOpts.I2cAddress: &d.SimDevice}}, d
+}
+
+// convert does a conversion when a single shot is requested.
+func (d *simADS1115) convert() {
+	config := d.Regs[ads1x15PointerConfig].Uint16()
+	if config&ads1x15ConfigOsSingle == 0 {
+		return
+	}
+	var v physic.ElectricPotential
+	switch mux := Channel(config>>ads1x15ConfigMuxOffset) & 7; mux {
+	case Channel0Minus1:
+		v = d.inputs[0] - d.inputs[1]
+	case Channel0Minus3:
+		v = d.inputs[0] - d.inputs[3]
+	case Channel1Minus3:
+		v = d.inputs[1] - d.inputs[3]
+	case Channel2Minus3:
+		v = d.inputs[2] - d.inputs[3]
+	default:
+		v = d.inputs[mux-Channel0]
+	}
+	var fs physic.ElectricPotential
+	for gain, conf := range gainConfig {
+		if conf == config&0x0E00 {
+			fs = gainVoltage[gain]
+		}
+	}
+	raw := int64(v) * (1 << 15) / int64(fs)
+	if raw > math.MaxInt16 {
+		raw = math.MaxInt16
+	} else if raw < math.MinInt16 {
+		raw = math.MinInt16
+	}
+	d.Regs[ads1x15PointerConversion].SetUint16(uint16(int16(raw)))
+	d.conversions++
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package as7262
+
+import (
+	"math"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+// SenseEnv returns the sensor as a physic.SenseEnvExtra that reads the
+// spectrum with the given led drive and sense time; see Sense().
+//
+// The temperature of the sensor is reported as the temperature. Each band is
+// reported in physic.EnvExtra.Extra as a physic.Irradiance named after its
+// wavelength, e.g. "irradiance_450nm".
+//
+// Halt() on the returned object stops SenseContinuous() and any pending
+// operation.
+func (d *Dev) SenseEnv(ledDrive physic.ElectricCurrent, senseTime time.Duration) physic.SenseEnvExtra {
+	return &envSensor{d: d, ledDrive: ledDrive, senseTime: senseTime}
+}
+
+//
+
+// bandWavelengths are the nominal center of the bands, as reported by Sense().
+var bandWavelengths = []physic.Distance{450 * physic.NanoMetre, 500 * physic.NanoMetre, 550 * physic.NanoMetre, 570 * physic.NanoMetre, 600 * physic.NanoMetre, 650 * physic.NanoMetre}
+
+// bandName returns the physic.EnvExtra.Extra name of the band.
+func bandName(w physic.Distance) string {
+	return "irradiance_" + w.String()
+}
+
+type envSensor struct {
+	d         *Dev
+	ledDrive  physic.ElectricCurrent
+	senseTime time.Duration
+	loop      physic.SenseLoop
+}
+
+func (e *envSensor) String() string {
+	return e.d.String()
+}
+
+// Halt implements conn.Resource.
+func (e *envSensor) Halt() error {
+	// Cancel the pending Sense() so the goroutine doesn't have to wait for it.
+	return e.loop.Stop(e.d.Halt)
+}
+
+// Sense implements physic.SenseEnv.
+func (e *envSensor) Sense(env *physic.Env) error {
+	var x physic.EnvExtra
+	if err := e.SenseExtra(&x); err != nil {
+		return err
+	}
+	env.Temperature = x.Temperature
+	return nil
+}
+
+// SenseContinuous implements physic.SenseEnv.
+//
+// Calling it again stops the previous sensing and closes its channel.
+func (e *envSensor) SenseContinuous(interval time.Duration) (<-chan physic.Env, error) {
+	return e.loop.StartEnv(e, interval, e.Sense), nil
+}
+
+// Precision implements physic.SenseEnv.
+func (e *envSensor) Precision(env *physic.Env) {
+	env.Temperature = physic.Kelvin
+}
+
+// SenseExtra implements physic.SenseEnvExtra.
+func (e *envSensor) SenseExtra(env *physic.EnvExtra) error {
+	sp, err := e.d.Sense(e.ledDrive, e.senseTime)
+	if err != nil {
+		return err
+	}
+	env.Temperature = sp.SensorTemperature
+	for _, b := range sp.Bands {
+		// The calibrated value is in µW/cm².
+		v := physic.Irradiance(math.Round(b.Value * float64(physic.MicroWattPerSquareCentiMetre)))
+		env.Set(bandName(b.Wavelength), v)
+	}
+	return nil
+}
+
+// SenseContinuousExtra implements physic.SenseEnvExtra.
+func (e *envSensor) SenseContinuousExtra(interval time.Duration) (<-chan physic.EnvExtra, error) {
+	return e.loop.StartExtra(e, interval, e.SenseExtra), nil
+}
+
+// PrecisionExtra implements physic.SenseEnvExtra.
+//
+// The precision of the bands is the value of one count at a gain of 16x,
+// approximately 45 counts/μW/cm².
+func (e *envSensor) PrecisionExtra(env *physic.EnvExtra) {
+	e.Precision(&env.Env)
+	for _, w := range bandWavelengths {
+		env.Set(bandName(w), physic.MicroWattPerSquareCentiMetre/45)
+	}
+}
+
+var _ physic.SenseEnvExtra = &envSensor{}
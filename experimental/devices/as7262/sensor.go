@@ -0,0 +1,51 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package as7262
+
+import (
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+// Sensor returns a sensor.Sensor that reads the spectrum with the given led
+// drive and sense time; see Sense().
+//
+// Each band is reported as a sensor.SpectralIrradiance on a channel named
+// after its wavelength, e.g. "450nm". The temperature of the sensor is also
+// reported.
+func (d *Dev) Sensor(ledDrive physic.ElectricCurrent, senseTime time.Duration) sensor.Sensor {
+	return &spectrumSensor{d: d, ledDrive: ledDrive, senseTime: senseTime}
+}
+
+//
+
+type spectrumSensor struct {
+	d         *Dev
+	ledDrive  physic.ElectricCurrent
+	senseTime time.Duration
+}
+
+func (s *spectrumSensor) String() string {
+	return s.d.String()
+}
+
+func (s *spectrumSensor) Halt() error {
+	return s.d.Halt()
+}
+
+func (s *spectrumSensor) Measure() ([]sensor.Measurement, error) {
+	sp, err := s.d.Sense(s.ledDrive, s.senseTime)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := make([]sensor.Measurement, 0, len(sp.Bands)+1)
+	for _, b := range sp.Bands {
+		out = append(out, sensor.Measurement{Quantity: sensor.SpectralIrradiance, Channel: b.Wavelength.String(), Value: sensor.Number(b.Value), Time: now})
+	}
+	return append(out, sensor.Measurement{Quantity: sensor.Temperature, Value: sp.SensorTemperature, Precision: physic.Kelvin, Time: now}), nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package bh1750
+
+import (
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+// SenseEnv returns the sensor as a physic.SenseEnvExtra. The illuminance is
+// reported in physic.EnvExtra.Extra as physic.EnvIlluminance.
+//
+// Halt() on the returned object stops SenseContinuous() and powers down the
+// device.
+func (d *Dev) SenseEnv() physic.SenseEnvExtra {
+	return &envSensor{d: d}
+}
+
+//
+
+type envSensor struct {
+	d    *Dev
+	loop physic.SenseLoop
+}
+
+func (e *envSensor) String() string {
+	return e.d.String()
+}
+
+// Halt implements conn.Resource.
+func (e *envSensor) Halt() error {
+	if err := e.loop.Stop(nil); err != nil {
+		return err
+	}
+	return e.d.Halt()
+}
+
+// Sense implements physic.SenseEnv.
+//
+// None of the quantities in physic.Env is measured; use SenseExtra().
+func (e *envSensor) Sense(env *physic.Env) error {
+	var x physic.EnvExtra
+	return e.SenseExtra(&x)
+}
+
+// SenseContinuous implements physic.SenseEnv.
+//
+// Calling it again stops the previous sensing and closes its channel.
+func (e *envSensor) SenseContinuous(interval time.Duration) (<-chan physic.Env, error) {
+	return e.loop.StartEnv(e, interval, e.Sense), nil
+}
+
+// Precision implements physic.SenseEnv.
+func (e *envSensor) Precision(env *physic.Env) {
+}
+
+// SenseExtra implements physic.SenseEnvExtra.
+func (e *envSensor) SenseExtra(env *physic.EnvExtra) error {
+	v, err := e.d.Sense()
+	if err != nil {
+		return err
+	}
+	// Sense() returns lux as a LuminousFlux; both are stored as nano units.
+	env.Set(physic.EnvIlluminance, physic.Illuminance(v))
+	return nil
+}
+
+// SenseContinuousExtra implements physic.SenseEnvExtra.
+func (e *envSensor) SenseContinuousExtra(interval time.Duration) (<-chan physic.EnvExtra, error) {
+	return e.loop.StartExtra(e, interval, e.SenseExtra), nil
+}
+
+// PrecisionExtra implements physic.SenseEnvExtra.
+//
+// It is the value of one count; the effective resolution depends on the
+// Resolution used.
+func (e *envSensor) PrecisionExtra(env *physic.EnvExtra) {
+	env.Set(physic.EnvIlluminance, physic.Lux*10/12)
+}
+
+var _ physic.SenseEnvExtra = &envSensor{}
@@ -0,0 +1,28 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package bh1750
+
+import (
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+func (d *Dev) String() string {
+	return "BH1750{" + d.dev.String() + "}"
+}
+
+// Measure implements sensor.Sensor.
+func (d *Dev) Measure() ([]sensor.Measurement, error) {
+	v, err := d.Sense()
+	if err != nil {
+		return nil, err
+	}
+	// Sense() returns lux as a LuminousFlux; both are stored as nano units.
+	return []sensor.Measurement{{Quantity: sensor.Illuminance, Value: physic.Illuminance(v), Time: time.Now()}}, nil
+}
+
+var _ sensor.Sensor = &Dev{}
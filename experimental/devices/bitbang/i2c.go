@@ -9,6 +9,7 @@
 package bitbang
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"runtime"
@@ -76,6 +77,17 @@ func (i *I2C) Close() error {
 
 // Tx implements i2c.Bus.
 func (i *I2C) Tx(addr uint16, w, r []byte) error {
+	return i.TxContext(context.Background(), addr, w, r)
+}
+
+// TxContext implements i2c.TxContexter.
+//
+// ctx is checked between each byte. When ctx is done, a stop condition is
+// sent and ctx.Err() is returned.
+func (i *I2C) TxContext(ctx context.Context, addr uint16, w, r []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	i.mu.Lock()
 	defer i.mu.Unlock()
 	runtime.LockOSThread()
@@ -104,6 +116,9 @@ func (i *I2C) Tx(addr uint16, w, r []byte) error {
 		}
 	}
 	for _, b := range w {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		ack, err := i.writeByte(b)
 		if err != nil {
 			return err
@@ -113,6 +128,9 @@ func (i *I2C) Tx(addr uint16, w, r []byte) error {
 		}
 	}
 	for x := range r {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		var err error
 		r[x], err = i.readByte()
 		if err != nil {
@@ -122,6 +140,62 @@ func (i *I2C) Tx(addr uint16, w, r []byte) error {
 	return nil
 }
 
+// Transfer implements i2c.Messenger.
+//
+// All the flags are supported. SkipAddr is not supported.
+func (i *I2C) Transfer(msgs []i2c.Msg) error {
+	for j := range msgs {
+		if err := checkMsg(&msgs[j]); err != nil {
+			return err
+		}
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	started := false
+	defer func() {
+		if started {
+			i.stop()
+		}
+	}()
+	for j := range msgs {
+		m := &msgs[j]
+		if !started || m.Flags&i2c.NoStart == 0 {
+			if started {
+				i.restart()
+			} else {
+				i.start()
+				started = true
+			}
+			if err := i.writeAddr(m); err != nil {
+				return err
+			}
+		}
+		if m.Flags&i2c.Read != 0 {
+			if err := i.readMsg(m); err != nil {
+				return err
+			}
+		} else {
+			for _, b := range m.Buf {
+				ack, err := i.writeByte(b)
+				if err != nil {
+					return err
+				}
+				if err := checkAck(ack, m); err != nil {
+					return err
+				}
+			}
+		}
+		if m.Flags&i2c.Stop != 0 && j != len(msgs)-1 {
+			i.stop()
+			started = false
+		}
+	}
+	return nil
+}
+
 // SetSpeed implements i2c.Bus.
 func (i *I2C) SetSpeed(f physic.Frequency) error {
 	i.mu.Lock()
@@ -156,6 +230,20 @@ func (i *I2C) start() {
 	_ = i.scl.Out(gpio.Low)
 }
 
+// restart sends a repeated start condition.
+//
+// Expects SCL low. Ends with SDA and SCL low.
+//
+// Lasts 3/2 cycle.
+func (i *I2C) restart() {
+	// Page 9, section 3.1.4 START and STOP conditions
+	_ = i.sda.Out(gpio.High)
+	i.sleepHalfCycle()
+	_ = i.scl.Out(gpio.High)
+	i.sleepHalfCycle()
+	i.start()
+}
+
 // "When CLK is a high level and DIO changes from low level to high level, data
 // input ends."
 //
@@ -163,6 +251,7 @@ func (i *I2C) start() {
 func (i *I2C) stop() {
 	// Page 9, section 3.1.4 START and STOP conditions
 	_ = i.scl.Out(gpio.Low)
+	_ = i.sda.Out(gpio.Low)
 	i.sleepHalfCycle()
 	_ = i.scl.Out(gpio.High)
 	i.sleepHalfCycle()
@@ -219,6 +308,94 @@ func (i *I2C) writeByte(b byte) (bool, error) {
 	return ack, nil
 }
 
+// writeAddr writes the address and R/W bit of a message.
+//
+// A 10 bits address is sent as 0b11110xx0 followed by the 8 lower bits; for a
+// read, a repeated start and 0b11110xx1 follow.
+func (i *I2C) writeAddr(m *i2c.Msg) error {
+	rw := byte(0)
+	if m.Flags&i2c.Read != 0 {
+		rw = 1
+	}
+	if m.Flags&i2c.RevDirAddr != 0 {
+		rw ^= 1
+	}
+	if m.Flags&i2c.TenBit == 0 {
+		// Page 13, section 3.1.10 The slave address and R/W bit
+		ack, err := i.writeByte(byte(m.Addr<<1) | rw)
+		if err != nil {
+			return err
+		}
+		return checkAck(ack, m)
+	}
+	// Page 15, section 3.1.11 10-bit addressing
+	hi := byte(0xF0 | (m.Addr>>7)&0x06)
+	for _, b := range []byte{hi, byte(m.Addr)} {
+		ack, err := i.writeByte(b)
+		if err != nil {
+			return err
+		}
+		if err := checkAck(ack, m); err != nil {
+			return err
+		}
+	}
+	if rw == 0 {
+		return nil
+	}
+	i.restart()
+	ack, err := i.writeByte(hi | 1)
+	if err != nil {
+		return err
+	}
+	return checkAck(ack, m)
+}
+
+// readMsg reads the bytes of a message.
+//
+// The last byte is not acknowledged, as required to let the device release
+// SDA.
+func (i *I2C) readMsg(m *i2c.Msg) error {
+	n := len(m.Buf)
+	for x := 0; x < n; x++ {
+		b, err := i.readBits()
+		if err != nil {
+			return err
+		}
+		m.Buf[x] = b
+		if x == 0 && m.Flags&i2c.RecvLen != 0 {
+			if 1+int(b) > len(m.Buf) {
+				return fmt.Errorf("bitbang-i2c: received length %d is too long", b)
+			}
+			n = 1 + int(b)
+		}
+		if m.Flags&i2c.NoReadAck == 0 {
+			if err := i.writeAck(x != n-1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkAck returns an error on NACK unless the message ignores it.
+func checkAck(ack bool, m *i2c.Msg) error {
+	if !ack && m.Flags&i2c.IgnoreNAK == 0 {
+		return errors.New("bitbang-i2c: got NACK")
+	}
+	return nil
+}
+
+// checkMsg verifies the address of a message.
+func checkMsg(m *i2c.Msg) error {
+	if (m.Flags&i2c.TenBit != 0 && m.Addr >= 0x400) || (m.Flags&i2c.TenBit == 0 && m.Addr >= 0x80) {
+		return errors.New("bitbang-i2c: invalid address")
+	}
+	if m.Flags&i2c.RecvLen != 0 && (m.Flags&i2c.Read == 0 || len(m.Buf) == 0) {
+		return errors.New("bitbang-i2c: RecvLen requires a read buffer")
+	}
+	return nil
+}
+
 // readByte reads 8 bits and an ACK.
 //
 // Expects SDA and SCL low.
@@ -227,6 +404,25 @@ func (i *I2C) writeByte(b byte) (bool, error) {
 //
 // Lasts 9 cycles.
 func (i *I2C) readByte() (byte, error) {
+	b, err := i.readBits()
+	if err != nil {
+		return 0, err
+	}
+	if err := i.sda.Out(gpio.Low); err != nil {
+		return 0, err
+	}
+	i.sleepHalfCycle()
+	_ = i.scl.Out(gpio.High)
+	i.sleepHalfCycle()
+	return b, nil
+}
+
+// readBits reads 8 bits.
+//
+// Expects SCL low. Ends with SCL low.
+//
+// Lasts 8 cycles.
+func (i *I2C) readBits() (byte, error) {
 	var b byte
 	if err := i.sda.In(gpio.PullUp, gpio.NoEdge); err != nil {
 		return b, err
@@ -241,13 +437,23 @@ func (i *I2C) readByte() (byte, error) {
 		}
 		_ = i.scl.Out(gpio.Low)
 	}
-	if err := i.sda.Out(gpio.Low); err != nil {
-		return 0, err
+	return b, nil
+}
+
+// writeAck sends ACK (SDA low) or NACK (SDA high) after a byte read.
+//
+// Expects SCL low. Ends with SDA low and SCL low.
+//
+// Lasts 1 cycle.
+func (i *I2C) writeAck(ack bool) error {
+	if err := i.sda.Out(gpio.Level(!ack)); err != nil {
+		return err
 	}
 	i.sleepHalfCycle()
 	_ = i.scl.Out(gpio.High)
 	i.sleepHalfCycle()
-	return b, nil
+	_ = i.scl.Out(gpio.Low)
+	return i.sda.Out(gpio.Low)
 }
 
 // sleep does a busy loop to act as fast as possible.
@@ -256,3 +462,5 @@ func (i *I2C) sleepHalfCycle() {
 }
 
 var _ i2c.Bus = &I2C{}
+var _ i2c.TxContexter = &I2C{}
+var _ i2c.Messenger = &I2C{}
@@ -0,0 +1,53 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package bitbang
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/gpio/gpiotest"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+func TestI2C_Transfer(t *testing.T) {
+	// Nothing is connected; SDA is pulled up so the device never acknowledges
+	// and all the bytes read are 0xFF.
+	b, err := New(&gpiotest.Pin{N: "SCL"}, &gpiotest.Pin{N: "SDA", Num: 1}, physic.GigaHertz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Transfer([]i2c.Msg{{Addr: 0x10, Buf: []byte{1}}}); err == nil {
+		t.Fatal("expected NACK")
+	}
+	r := make([]byte, 2)
+	msgs := []i2c.Msg{
+		{Addr: 0x10, Flags: i2c.IgnoreNAK, Buf: []byte{1}},
+		{Addr: 0x3FF, Flags: i2c.TenBit | i2c.Read | i2c.IgnoreNAK | i2c.Stop, Buf: r},
+		{Addr: 0x10, Flags: i2c.IgnoreNAK | i2c.RevDirAddr, Buf: []byte{2}},
+		{Flags: i2c.NoStart | i2c.IgnoreNAK, Buf: []byte{3}},
+	}
+	if err := b.Transfer(msgs); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r, []byte{0xFF, 0xFF}) {
+		t.Fatal(r)
+	}
+	r = make([]byte, 33)
+	if err := b.Transfer([]i2c.Msg{{Addr: 0x10, Flags: i2c.Read | i2c.RecvLen | i2c.IgnoreNAK | i2c.NoReadAck, Buf: r}}); err == nil {
+		t.Fatal("expected invalid length")
+	}
+	data := []i2c.Msg{
+		{Addr: 0x80},
+		{Addr: 0x400, Flags: i2c.TenBit},
+		{Addr: 0x10, Flags: i2c.RecvLen},
+	}
+	for i, m := range data {
+		if b.Transfer([]i2c.Msg{m}) == nil {
+			t.Fatal(i)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Specification
+//
+// IEEE 1149.1 Standard Test Access Port and Boundary-Scan Architecture.
+// https://www.xjtag.com/about-jtag/jtag-a-technical-overview/
+
+package bitbang
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/jtag"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/host/cpu"
+)
+
+// NewJTAG returns a jtag.Adapter that drives a JTAG chain over 4 pins.
+//
+// The TAP controllers are reset to jtag.TestLogicReset.
+func NewJTAG(tck, tms, tdi gpio.PinOut, tdo gpio.PinIn) (*JTAG, error) {
+	j := &JTAG{tck: tck, tms: tms, tdi: tdi, tdo: tdo}
+	if err := j.tdo.In(gpio.PullUp, gpio.NoEdge); err != nil {
+		return nil, fmt.Errorf("bitbang-jtag: failed to initialize TDO: %v", err)
+	}
+	if err := j.tdi.Out(gpio.Low); err != nil {
+		return nil, fmt.Errorf("bitbang-jtag: failed to initialize TDI: %v", err)
+	}
+	if err := j.tck.Out(gpio.Low); err != nil {
+		return nil, fmt.Errorf("bitbang-jtag: failed to idle clock: %v", err)
+	}
+	if err := j.Reset(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// JTAG represents a JTAG adapter implemented as bit-banging on 4 GPIO pins.
+//
+// TMS and TDI are set while TCK is low and TDO is sampled right before the
+// rising edge of TCK.
+type JTAG struct {
+	// Immutable.
+	tck gpio.PinOut
+	tms gpio.PinOut
+	tdi gpio.PinOut
+	tdo gpio.PinIn
+
+	mu        sync.Mutex
+	state     jtag.State
+	halfCycle time.Duration
+}
+
+func (j *JTAG) String() string {
+	return fmt.Sprintf("bitbang/jtag(%s, %s, %s, %s)", j.tck, j.tms, j.tdi, j.tdo)
+}
+
+// Halt implements conn.Resource.
+//
+// It has no effect.
+func (j *JTAG) Halt() error {
+	return nil
+}
+
+// SetSpeed implements jtag.Adapter.
+//
+// By default, TCK is toggled as fast as possible.
+func (j *JTAG) SetSpeed(f physic.Frequency) error {
+	if f <= 0 {
+		return errors.New("bitbang-jtag: invalid frequency")
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.halfCycle = f.Period() / 2
+	return nil
+}
+
+// State implements jtag.Adapter.
+func (j *JTAG) State() jtag.State {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.state
+}
+
+// Reset implements jtag.Adapter.
+func (j *JTAG) Reset() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.clockTMS(0x1F, 5); err != nil {
+		return err
+	}
+	j.state = jtag.TestLogicReset
+	return nil
+}
+
+// GoTo implements jtag.Adapter.
+func (j *JTAG) GoTo(s jtag.State) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.goTo(s)
+}
+
+// RunTest implements jtag.Adapter.
+func (j *JTAG) RunTest(n int) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.state.IsStable() {
+		return fmt.Errorf("bitbang-jtag: can't stay in state %s", j.state)
+	}
+	tms := gpio.Level(j.state == jtag.TestLogicReset)
+	for i := 0; i < n; i++ {
+		if _, err := j.clock(tms, gpio.Low); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScanIR implements jtag.Adapter.
+func (j *JTAG) ScanIR(w, r []byte, bits int, end jtag.State) error {
+	return j.scan(jtag.ShiftIR, w, r, bits, end)
+}
+
+// ScanDR implements jtag.Adapter.
+func (j *JTAG) ScanDR(w, r []byte, bits int, end jtag.State) error {
+	return j.scan(jtag.ShiftDR, w, r, bits, end)
+}
+
+// TCK implements jtag.Pins.
+func (j *JTAG) TCK() gpio.PinOut {
+	return j.tck
+}
+
+// TMS implements jtag.Pins.
+func (j *JTAG) TMS() gpio.PinOut {
+	return j.tms
+}
+
+// TDI implements jtag.Pins.
+func (j *JTAG) TDI() gpio.PinOut {
+	return j.tdi
+}
+
+// TDO implements jtag.Pins.
+func (j *JTAG) TDO() gpio.PinIn {
+	return j.tdo
+}
+
+//
+
+func (j *JTAG) scan(shift jtag.State, w, r []byte, bits int, end jtag.State) error {
+	if bits <= 0 {
+		return errors.New("bitbang-jtag: invalid number of bits")
+	}
+	if (w != nil && len(w)*8 < bits) || (r != nil && len(r)*8 < bits) {
+		return fmt.Errorf("bitbang-jtag: buffers too short for %d bits", bits)
+	}
+	if !end.IsStable() {
+		return fmt.Errorf("bitbang-jtag: invalid end state %s", end)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.goTo(shift); err != nil {
+		return err
+	}
+	for i := 0; i < bits; i++ {
+		tdi := gpio.Low
+		if w != nil {
+			tdi = w[i/8]&(1<<uint(i%8)) != 0
+		}
+		// The last bit is shifted while leaving the shift state.
+		tdo, err := j.clock(i == bits-1, tdi)
+		if err != nil {
+			return err
+		}
+		if r != nil {
+			if tdo {
+				r[i/8] |= 1 << uint(i%8)
+			} else {
+				r[i/8] &^= 1 << uint(i%8)
+			}
+		}
+	}
+	j.state = j.state.Next(gpio.High)
+	return j.goTo(end)
+}
+
+func (j *JTAG) goTo(s jtag.State) error {
+	tms, n := jtag.Path(j.state, s)
+	if err := j.clockTMS(tms, n); err != nil {
+		return err
+	}
+	j.state = s
+	return nil
+}
+
+// clockTMS clocks n TMS bits, least significant bit first.
+func (j *JTAG) clockTMS(tms uint32, n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := j.clock(tms&(1<<uint(i)) != 0, gpio.Low); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clock sets TMS and TDI, samples TDO and toggles TCK.
+func (j *JTAG) clock(tms, tdi gpio.Level) (gpio.Level, error) {
+	if err := j.tms.Out(tms); err != nil {
+		return gpio.Low, fmt.Errorf("bitbang-jtag: failed to set TMS: %v", err)
+	}
+	if err := j.tdi.Out(tdi); err != nil {
+		return gpio.Low, fmt.Errorf("bitbang-jtag: failed to set TDI: %v", err)
+	}
+	cpu.Nanospin(j.halfCycle)
+	tdo := j.tdo.Read()
+	if err := j.tck.Out(gpio.High); err != nil {
+		return gpio.Low, fmt.Errorf("bitbang-jtag: failed to assert clock: %v", err)
+	}
+	cpu.Nanospin(j.halfCycle)
+	if err := j.tck.Out(gpio.Low); err != nil {
+		return gpio.Low, fmt.Errorf("bitbang-jtag: failed to idle clock: %v", err)
+	}
+	return tdo, nil
+}
+
+var _ jtag.Adapter = &JTAG{}
+var _ jtag.Pins = &JTAG{}
@@ -0,0 +1,150 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package bitbang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/jtag"
+	"github.com/meandrewdev/periph/conn/jtag/jtagtest"
+	"github.com/meandrewdev/periph/conn/jtag/svf"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+func TestJTAG(t *testing.T) {
+	c, j := newChain(t)
+	if s := j.String(); s != "bitbang/jtag(TCK(0), TMS(1), TDI(2), TDO(3))" {
+		t.Fatal(s)
+	}
+	if j.TCK() != c.TCK() || j.TMS() != c.TMS() || j.TDI() != c.TDI() || j.TDO() != c.TDO() {
+		t.Fatal("unexpected pins")
+	}
+	if err := j.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.SetSpeed(0); err == nil {
+		t.Fatal("invalid speed")
+	}
+	if err := j.SetSpeed(physic.GigaHertz); err != nil {
+		t.Fatal(err)
+	}
+	if s := j.State(); s != jtag.TestLogicReset || c.State != jtag.TestLogicReset {
+		t.Fatal(s, c.State)
+	}
+	for _, s := range []jtag.State{jtag.ShiftDR, jtag.PauseIR, jtag.UpdateDR, jtag.RunTestIdle} {
+		if err := j.GoTo(s); err != nil {
+			t.Fatal(err)
+		}
+		if j.State() != s || c.State != s {
+			t.Fatal(j.State(), c.State)
+		}
+	}
+	n := c.Clocks
+	if err := j.RunTest(10); err != nil {
+		t.Fatal(err)
+	}
+	if c.Clocks != n+10 || c.State != jtag.RunTestIdle {
+		t.Fatal(c.Clocks, c.State)
+	}
+	if err := j.GoTo(jtag.ShiftDR); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.RunTest(1); err == nil {
+		t.Fatal("ShiftDR is not stable")
+	}
+}
+
+func TestJTAG_Scan(t *testing.T) {
+	c, j := newChain(t)
+	// Select the user register of the device closest to TDI, the other in
+	// BYPASS.
+	if err := j.ScanIR([]byte{0x2F, 0x00}, nil, 12, jtag.RunTestIdle); err != nil {
+		t.Fatal(err)
+	}
+	if c.Devices[0].IR != 0xF || c.Devices[1].IR != 2 {
+		t.Fatal(c.Devices)
+	}
+	r := make([]byte, 2)
+	if err := j.ScanDR([]byte{0x4A, 0x01}, r, 9, jtag.PauseDR); err != nil {
+		t.Fatal(err)
+	}
+	// The old content 0x3C is shifted out after the bypass bit.
+	if r[0] != 0x3C<<1 || r[1] != 0 {
+		t.Fatal(r)
+	}
+	// The register is only updated when going through UpdateDR.
+	if c.Devices[1].DR != 0x3C || c.State != jtag.PauseDR {
+		t.Fatal(c.Devices[1].DR, c.State)
+	}
+	if err := j.GoTo(jtag.RunTestIdle); err != nil {
+		t.Fatal(err)
+	}
+	if c.Devices[1].DR != 0xA5 {
+		t.Fatal(c.Devices[1].DR)
+	}
+	if err := j.ScanDR(nil, nil, 0, jtag.RunTestIdle); err == nil {
+		t.Fatal("invalid bits")
+	}
+	if err := j.ScanDR(nil, r, 17, jtag.RunTestIdle); err == nil {
+		t.Fatal("buffer too short")
+	}
+	if err := j.ScanDR(nil, nil, 1, jtag.ShiftDR); err == nil {
+		t.Fatal("invalid end state")
+	}
+}
+
+func TestJTAG_ScanChain(t *testing.T) {
+	c, j := newChain(t)
+	ids, err := jtag.ScanChain(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 0 || ids[1] != jtag.IDCode(c.Devices[1].IDCode) {
+		t.Fatal(ids)
+	}
+}
+
+func TestJTAG_SVF(t *testing.T) {
+	c, j := newChain(t)
+	in := `
+! Put the first device in BYPASS and write to the second one.
+TRST ABSENT;
+ENDIR IDLE;
+ENDDR IDLE;
+STATE RESET;
+HIR 4 TDI (F) TDO (1) MASK (3);
+HDR 1 TDI (0);
+SIR 8 TDI (02) TDO (01) MASK (03);
+SDR 8 TDI (5A) TDO (3C);
+SDR 8 TDI (00) TDO (5A);
+RUNTEST 10 TCK;
+`
+	if err := svf.Play(j, strings.NewReader(in)); err != nil {
+		t.Fatal(err)
+	}
+	if c.Devices[1].DR != 0 || c.State != jtag.RunTestIdle {
+		t.Fatal(c.Devices[1].DR, c.State)
+	}
+	if err := svf.Play(j, strings.NewReader("SDR 32 TDI (0) TDO (1);")); err == nil {
+		t.Fatal("expected TDO mismatch")
+	}
+}
+
+//
+
+func newChain(t *testing.T) (*jtagtest.Chain, *JTAG) {
+	c := &jtagtest.Chain{
+		Devices: []jtagtest.Device{
+			{IRLen: 4},
+			{IDCode: 0x4BA00477, IDCodeInstr: 0xE, IRLen: 8, DRLen: 8, DR: 0x3C},
+		},
+	}
+	j, err := NewJTAG(c.TCK(), c.TMS(), c.TDI(), c.TDO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, j
+}
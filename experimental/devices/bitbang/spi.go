@@ -12,6 +12,7 @@
 package bitbang
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -162,7 +163,18 @@ func (s *spiConn) Duplex() conn.Duplex {
 // BUG(maruel): Implement mode (HalfDuplex and LSBFirst remain to be done).
 // BUG(maruel): Implement bits.
 // BUG(maruel): Test if read works.
-func (s *spiConn) Tx(w, r []byte) (err error) {
+func (s *spiConn) Tx(w, r []byte) error {
+	return s.TxContext(context.Background(), w, r)
+}
+
+// TxContext implements conn.TxContexter.
+//
+// ctx is checked between each byte. When ctx is done, chip-select is
+// unasserted and ctx.Err() is returned.
+func (s *spiConn) TxContext(ctx context.Context, w, r []byte) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
 	if len(r) != 0 && len(w) != len(r) {
 		return errors.New("bitbang-spi: write and read buffers must be the same length")
 	}
@@ -173,49 +185,62 @@ func (s *spiConn) Tx(w, r []byte) (err error) {
 	if err = s.assertCS(); err != nil {
 		return fmt.Errorf("bitbang-spi: failed to assert chip-select: %v", err)
 	}
-
-	for i := uint(0); i < uint(len(w)*8); i++ {
-		if err = s.sdo.Out(w[i/8]&(1<<(i%8)) != 0); err != nil {
-			return fmt.Errorf("bitbang-spi: failed to send bit %d of word %d: %v", i%8, i/8, err)
+	if err = s.tx(ctx, w, r, 0); err != nil {
+		if err == ctx.Err() {
+			_ = s.unassertCS()
 		}
+		return err
+	}
+	if err = s.unassertCS(); err != nil {
+		return fmt.Errorf("bitbang-spi: failed to unassert chip-select: %v", err)
+	}
+	return nil
+}
 
-		s.sleepHalfCycle()
-		if err = s.sck.Out(!s.clockIdle); err != nil {
-			return fmt.Errorf("bitbang-spi: failed to assert clock: %v", err)
+// TxPackets implements spi.Conn.
+//
+// The delays are busy loops. If the last packet has KeepCS true, CS stays
+// asserted. A packet with only R set clocks out zeros. On error, CS is
+// unasserted.
+func (s *spiConn) TxPackets(p []spi.Packet) error {
+	for i := range p {
+		if len(p[i].W) != 0 && len(p[i].R) != 0 && len(p[i].W) != len(p[i].R) {
+			return errors.New("bitbang-spi: write and read buffers must be the same length")
 		}
-		s.sleepHalfCycle()
-
-		if s.readAfterClockPulse {
-			if err = s.sck.Out(s.clockIdle); err != nil {
-				return fmt.Errorf("bitbang-spi: failed to idle clock: %v", err)
-			}
-			s.sleepHalfCycle()
+		if p[i].Speed < 0 || p[i].DelayBefore < 0 || p[i].DelayAfter < 0 || p[i].WordDelay < 0 {
+			return errors.New("bitbang-spi: invalid packet timing")
 		}
+	}
 
-		if len(r) != 0 {
-			if s.sdi.Read() == gpio.High {
-				r[i/8] |= 1 << (i % 8)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer func(h time.Duration) {
+		s.halfCycle = h
+	}(s.halfCycle)
+
+	ctx := context.Background()
+	for i := range p {
+		if i == 0 || !p[i-1].KeepCS {
+			if err := s.assertCS(); err != nil {
+				return fmt.Errorf("bitbang-spi: failed to assert chip-select: %v", err)
 			}
 		}
-
-		if !s.readAfterClockPulse {
-			if err = s.sck.Out(s.clockIdle); err != nil {
-				return fmt.Errorf("bitbang-spi: failed to idle clock: %v", err)
+		s.halfCycle = s.halfCycleFor(p[i].Speed)
+		cpu.Nanospin(p[i].DelayBefore)
+		if err := s.tx(ctx, p[i].W, p[i].R, p[i].WordDelay); err != nil {
+			_ = s.unassertCS()
+			return err
+		}
+		cpu.Nanospin(p[i].DelayAfter)
+		if !p[i].KeepCS {
+			if err := s.unassertCS(); err != nil {
+				return fmt.Errorf("bitbang-spi: failed to unassert chip-select: %v", err)
 			}
 		}
 	}
-	if err = s.unassertCS(); err != nil {
-		return fmt.Errorf("bitbang-spi: failed to unassert chip-select: %v", err)
-	}
-
 	return nil
 }
 
-// TxPackets implements spi.Conn.
-func (s *spiConn) TxPackets(p []spi.Packet) error {
-	return errors.New("bitbang-spi: not implemented")
-}
-
 // Write implements io.Writer.
 func (s *spiConn) Write(d []byte) (int, error) {
 	if err := s.Tx(d, nil); err != nil {
@@ -251,6 +276,76 @@ func (s *spiConn) sleepHalfCycle() {
 	cpu.Nanospin(s.halfCycle)
 }
 
+// halfCycleFor returns the half clock cycle for a packet speed, still limited
+// by LimitSpeed().
+func (s *spiConn) halfCycleFor(speed physic.Frequency) time.Duration {
+	f := s.freqDev
+	if speed != 0 {
+		f = speed
+	}
+	if s.freqPort != 0 && (f == 0 || s.freqPort < f) {
+		f = s.freqPort
+	}
+	if f == 0 {
+		return s.halfCycle
+	}
+	return f.Period() / 2
+}
+
+// tx clocks the bits of w and r, idling wordDelay between each byte.
+//
+// ctx is checked between each byte.
+func (s *spiConn) tx(ctx context.Context, w, r []byte, wordDelay time.Duration) error {
+	// When w is empty, zeros are clocked out.
+	n := len(w)
+	if len(r) > n {
+		n = len(r)
+	}
+	for i := uint(0); i < uint(n*8); i++ {
+		if i%8 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if i != 0 {
+				cpu.Nanospin(wordDelay)
+			}
+		}
+		var b byte
+		if len(w) != 0 {
+			b = w[i/8]
+		}
+		if err := s.sdo.Out(b&(1<<(i%8)) != 0); err != nil {
+			return fmt.Errorf("bitbang-spi: failed to send bit %d of word %d: %v", i%8, i/8, err)
+		}
+
+		s.sleepHalfCycle()
+		if err := s.sck.Out(!s.clockIdle); err != nil {
+			return fmt.Errorf("bitbang-spi: failed to assert clock: %v", err)
+		}
+		s.sleepHalfCycle()
+
+		if s.readAfterClockPulse {
+			if err := s.sck.Out(s.clockIdle); err != nil {
+				return fmt.Errorf("bitbang-spi: failed to idle clock: %v", err)
+			}
+			s.sleepHalfCycle()
+		}
+
+		if len(r) != 0 {
+			if s.sdi.Read() == gpio.High {
+				r[i/8] |= 1 << (i % 8)
+			}
+		}
+
+		if !s.readAfterClockPulse {
+			if err := s.sck.Out(s.clockIdle); err != nil {
+				return fmt.Errorf("bitbang-spi: failed to idle clock: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
 func (s *spiConn) assertCS() error {
 	if s.csn == nil || s.mode&spi.NoCS == spi.NoCS {
 		return nil
@@ -295,5 +390,6 @@ func (s *spiConn) initializePins() error {
 	return nil
 }
 
+var _ conn.TxContexter = &spiConn{}
 var _ spi.Conn = &spiConn{}
 var _ spi.PortCloser = &SPI{}
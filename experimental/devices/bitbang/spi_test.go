@@ -0,0 +1,126 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package bitbang
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/gpio/gpiotest"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/spi"
+)
+
+func TestSPI_TxPackets(t *testing.T) {
+	cs := &levelsPin{Pin: gpiotest.Pin{N: "CS"}}
+	// MISO is pulled up so all the bytes read are 0xFF.
+	miso := &gpiotest.Pin{N: "MISO", L: gpio.High}
+	s, err := NewSPI(&gpiotest.Pin{N: "CLK"}, &gpiotest.Pin{N: "MOSI"}, miso, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.Connect(physic.GigaHertz, spi.Mode0, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs.levels = nil
+	r := make([]byte, 2)
+	p := []spi.Packet{
+		{W: []byte{1}, KeepCS: true, DelayBefore: time.Microsecond},
+		{W: []byte{0, 0}, R: r, Speed: physic.MegaHertz, WordDelay: time.Microsecond, DelayAfter: time.Microsecond},
+		{W: []byte{2}},
+	}
+	if err := c.TxPackets(p); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r, []byte{0xFF, 0xFF}) {
+		t.Fatal(r)
+	}
+	// CS is asserted once for the first two packets, then for the last one.
+	if expected := []gpio.Level{gpio.Low, gpio.High, gpio.Low, gpio.High}; !reflect.DeepEqual(cs.levels, expected) {
+		t.Fatal(cs.levels)
+	}
+
+	// The transaction is left hanging.
+	cs.levels = nil
+	if err := c.TxPackets([]spi.Packet{{W: []byte{1}, KeepCS: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []gpio.Level{gpio.Low}; !reflect.DeepEqual(cs.levels, expected) {
+		t.Fatal(cs.levels)
+	}
+
+	// Read only packet.
+	r = make([]byte, 1)
+	if err := c.TxPackets([]spi.Packet{{R: r}}); err != nil {
+		t.Fatal(err)
+	}
+	if r[0] != 0xFF {
+		t.Fatal(r)
+	}
+
+	data := [][]spi.Packet{
+		{{W: []byte{1}, R: []byte{1, 2}}},
+		{{W: []byte{1}, Speed: -1}},
+		{{W: []byte{1}, DelayBefore: -1}},
+	}
+	for i, p := range data {
+		if err := c.TxPackets(p); err == nil {
+			t.Fatal(i)
+		}
+	}
+}
+
+func TestSPI_TxPackets_Error(t *testing.T) {
+	cs := &levelsPin{Pin: gpiotest.Pin{N: "CS"}}
+	mosi := &failPin{Pin: gpiotest.Pin{N: "MOSI"}}
+	s, err := NewSPI(&gpiotest.Pin{N: "CLK"}, mosi, &gpiotest.Pin{N: "MISO"}, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.Connect(physic.GigaHertz, spi.Mode0, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs.levels = nil
+	mosi.fail = true
+	if err := c.TxPackets([]spi.Packet{{W: []byte{1}, KeepCS: true}}); err == nil {
+		t.Fatal("MOSI failed")
+	}
+	// CS is unasserted on failure.
+	if expected := []gpio.Level{gpio.Low, gpio.High}; !reflect.DeepEqual(cs.levels, expected) {
+		t.Fatal(cs.levels)
+	}
+}
+
+//
+
+// levelsPin records the levels set.
+type levelsPin struct {
+	gpiotest.Pin
+	levels []gpio.Level
+}
+
+func (l *levelsPin) Out(level gpio.Level) error {
+	l.levels = append(l.levels, level)
+	return l.Pin.Out(level)
+}
+
+// failPin fails on Out when fail is true.
+type failPin struct {
+	gpiotest.Pin
+	fail bool
+}
+
+func (f *failPin) Out(level gpio.Level) error {
+	if f.fail {
+		return errors.New("failed")
+	}
+	return f.Pin.Out(level)
+}
@@ -7,6 +7,7 @@ package ccs811
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/meandrewdev/periph/conn/i2c/i2ctest"
 	"github.com/meandrewdev/periph/conn/physic"
@@ -212,3 +213,103 @@ func TestReset(t *testing.T) {
 	}
 	dev.Reset()
 }
+
+func TestSensor(t *testing.T) {
+	bus := i2ctest.Playback{
+		Ops: []i2ctest.IO{
+			{Addr: 0x5A, W: []byte{0xf4}, R: nil},
+			{Addr: 0x5A, W: []byte{measurementModeReg, 0x10}, R: nil},
+			{Addr: 0x5A, W: []byte{algoResultsReg}, R: []byte{0x1, 0x90, 0x0, 0x19, 0x98, 0x0, 0x0, 0x0}},
+			{Addr: 0x5A, W: []byte{algoResultsReg}, R: []byte{0x1, 0x90, 0x0, 0x19, 0x99, 0x5, 0x0, 0x0}},
+		},
+	}
+	opts := DefaultOpts
+	opts.Addr = 0x5A
+	dev, err := New(&bus, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := dev.Sensor()
+	m, err := s.Measure()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m[0].Value != 400*physic.PartPerMillion || m[1].Value != 25*physic.PartPerBillion {
+		t.Fatal(m)
+	}
+	if _, err := s.Measure(); err == nil {
+		t.Fatal("expected sensor error")
+	}
+	// Halt doesn't change the measurement mode.
+	if err := s.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSenseEnv(t *testing.T) {
+	values := []byte{0x1, 0x90, 0x0, 0x19, 0x98, 0x0, 0x0, 0x0}
+	bus := i2ctest.Playback{
+		Ops: []i2ctest.IO{
+			{Addr: 0x5A, W: []byte{0xf4}, R: nil},
+			{Addr: 0x5A, W: []byte{measurementModeReg, 0x10}, R: nil},
+			{Addr: 0x5A, W: []byte{algoResultsReg}, R: values},
+			{Addr: 0x5A, W: []byte{algoResultsReg}, R: []byte{0x1, 0x90, 0x0, 0x19, 0x99, 0x5, 0x0, 0x0}},
+			{Addr: 0x5A, W: []byte{algoResultsReg}, R: values},
+			{Addr: 0x5A, W: []byte{algoResultsReg}, R: values},
+		},
+	}
+	opts := DefaultOpts
+	opts.Addr = 0x5A
+	dev, err := New(&bus, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := dev.SenseEnv()
+	p := physic.EnvExtra{}
+	s.PrecisionExtra(&p)
+	if p.Extra[physic.EnvCO2] != physic.PartPerMillion || p.Extra[physic.EnvTVOC] != physic.PartPerBillion {
+		t.Fatal(p.Extra)
+	}
+	e := physic.EnvExtra{}
+	if err := s.SenseExtra(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Extra[physic.EnvCO2] != 400*physic.PartPerMillion || e.Extra[physic.EnvTVOC] != 25*physic.PartPerBillion {
+		t.Fatal(e.Extra)
+	}
+	if err := s.SenseExtra(&e); err == nil {
+		t.Fatal("expected sensor error")
+	}
+
+	c1, err := s.SenseContinuousExtra(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := <-c1; e.Extra[physic.EnvCO2] != 400*physic.PartPerMillion {
+		t.Fatal(e.Extra)
+	}
+	// Calling SenseContinuous restarts the sensing. Env is comparable.
+	c2, err := s.SenseContinuous(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-c1; ok {
+		t.Fatal("expected the first channel to be closed")
+	}
+	if e := <-c2; e != (physic.Env{}) {
+		t.Fatal(e)
+	}
+	// Halt doesn't change the measurement mode.
+	if err := s.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-c2; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
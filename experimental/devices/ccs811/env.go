@@ -0,0 +1,84 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package ccs811
+
+import (
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+// SenseEnv returns the sensor as a physic.SenseEnvExtra. The equivalent CO₂
+// and the total volatile organic compounds are reported in
+// physic.EnvExtra.Extra as physic.EnvCO2 and physic.EnvTVOC.
+//
+// The measurement mode must have been set with SetMeasurementModeRegister().
+// Halt() on the returned object stops SenseContinuous() but doesn't change the
+// measurement mode.
+func (d *Dev) SenseEnv() physic.SenseEnvExtra {
+	return &envSensor{d: d}
+}
+
+//
+
+type envSensor struct {
+	d    *Dev
+	loop physic.SenseLoop
+}
+
+func (e *envSensor) String() string {
+	return e.d.String()
+}
+
+// Halt implements conn.Resource.
+func (e *envSensor) Halt() error {
+	return e.loop.Stop(nil)
+}
+
+// Sense implements physic.SenseEnv.
+//
+// None of the quantities in physic.Env is measured; use SenseExtra().
+func (e *envSensor) Sense(env *physic.Env) error {
+	var x physic.EnvExtra
+	return e.SenseExtra(&x)
+}
+
+// SenseContinuous implements physic.SenseEnv.
+//
+// Calling it again stops the previous sensing and closes its channel.
+func (e *envSensor) SenseContinuous(interval time.Duration) (<-chan physic.Env, error) {
+	return e.loop.StartEnv(e, interval, e.Sense), nil
+}
+
+// Precision implements physic.SenseEnv.
+func (e *envSensor) Precision(env *physic.Env) {
+}
+
+// SenseExtra implements physic.SenseEnvExtra.
+func (e *envSensor) SenseExtra(env *physic.EnvExtra) error {
+	var v SensorValues
+	if err := e.d.Sense(&v); err != nil {
+		return err
+	}
+	if v.Error != nil {
+		return v.Error
+	}
+	env.Set(physic.EnvCO2, physic.Concentration(v.ECO2)*physic.PartPerMillion)
+	env.Set(physic.EnvTVOC, physic.Concentration(v.VOC)*physic.PartPerBillion)
+	return nil
+}
+
+// SenseContinuousExtra implements physic.SenseEnvExtra.
+func (e *envSensor) SenseContinuousExtra(interval time.Duration) (<-chan physic.EnvExtra, error) {
+	return e.loop.StartExtra(e, interval, e.SenseExtra), nil
+}
+
+// PrecisionExtra implements physic.SenseEnvExtra.
+func (e *envSensor) PrecisionExtra(env *physic.EnvExtra) {
+	env.Set(physic.EnvCO2, physic.PartPerMillion)
+	env.Set(physic.EnvTVOC, physic.PartPerBillion)
+}
+
+var _ physic.SenseEnvExtra = &envSensor{}
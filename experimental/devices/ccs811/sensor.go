@@ -0,0 +1,51 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package ccs811
+
+import (
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+// Sensor returns a sensor.Sensor that reports the equivalent CO₂ and the
+// total volatile organic compounds.
+//
+// The measurement mode must have been set with SetMeasurementModeRegister().
+// Halt() on the returned object is a no-op; it doesn't change the measurement
+// mode.
+func (d *Dev) Sensor() sensor.Sensor {
+	return &gasSensor{d: d}
+}
+
+//
+
+type gasSensor struct {
+	d *Dev
+}
+
+func (g *gasSensor) String() string {
+	return g.d.String()
+}
+
+func (g *gasSensor) Halt() error {
+	return nil
+}
+
+func (g *gasSensor) Measure() ([]sensor.Measurement, error) {
+	var v SensorValues
+	if err := g.d.Sense(&v); err != nil {
+		return nil, err
+	}
+	if v.Error != nil {
+		return nil, v.Error
+	}
+	now := time.Now()
+	return []sensor.Measurement{
+		{Quantity: sensor.CO2, Value: physic.Concentration(v.ECO2) * physic.PartPerMillion, Precision: physic.PartPerMillion, Time: now},
+		{Quantity: sensor.TVOC, Value: physic.Concentration(v.VOC) * physic.PartPerBillion, Precision: physic.PartPerBillion, Time: now},
+	}, nil
+}
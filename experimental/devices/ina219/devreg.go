@@ -0,0 +1,51 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package ina219
+
+import (
+	"encoding/json"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/devices/devreg"
+)
+
+// jsonOpts is the board description options.
+type jsonOpts struct {
+	// Addr defaults to DefaultOpts.Address.
+	Addr int `json:"addr"`
+	// SenseResistor is the shunt resistor value, e.g. "100mOhm". It defaults
+	// to DefaultOpts.SenseResistor.
+	SenseResistor string `json:"sense_resistor"`
+	// MaxCurrent is the maximum current expected, e.g. "3.2A". It defaults to
+	// DefaultOpts.MaxCurrent.
+	MaxCurrent string `json:"max_current"`
+}
+
+func openI2C(b i2c.Bus, raw json.RawMessage) (conn.Resource, error) {
+	var j jsonOpts
+	if err := devreg.DecodeOpts(raw, &j); err != nil {
+		return nil, err
+	}
+	o := DefaultOpts
+	if j.Addr != 0 {
+		o.Address = j.Addr
+	}
+	if j.SenseResistor != "" {
+		if err := o.SenseResistor.Set(j.SenseResistor); err != nil {
+			return nil, err
+		}
+	}
+	if j.MaxCurrent != "" {
+		if err := o.MaxCurrent.Set(j.MaxCurrent); err != nil {
+			return nil, err
+		}
+	}
+	return New(b, &o)
+}
+
+func init() {
+	devreg.MustRegister(&devreg.Ref{Name: "ina219", I2C: openI2C})
+}
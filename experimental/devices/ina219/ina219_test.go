@@ -14,6 +14,7 @@ import (
 	"github.com/meandrewdev/periph/conn/i2c/i2ctest"
 	"github.com/meandrewdev/periph/conn/mmr"
 	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
 )
 
 func TestNew(t *testing.T) {
@@ -391,3 +392,124 @@ func TestPowerStringer(t *testing.T) {
 		t.Errorf("wanted %s\n, but got: %s", want, got)
 	}
 }
+
+func TestSim(t *testing.T) {
+	sim, dev := newSimINA219(100 * physic.MilliOhm)
+	d, err := New(sim, &DefaultOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := dev.Regs[configRegister].Uint16(); v != 0x1FFF {
+		t.Fatalf("config 0x%x", v)
+	}
+	if v := dev.Regs[calibrationRegister].Uint16(); v != 4194 {
+		t.Fatalf("calibration %d", v)
+	}
+
+	dev.bus = 12 * physic.Volt
+	dev.current = physic.Ampere
+	pm, err := d.Sense()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pm.Voltage != 12*physic.Volt || pm.Shunt != 100*physic.MilliVolt {
+		t.Fatal(pm)
+	}
+	if diff := pm.Current - physic.Ampere; diff < -physic.MilliAmpere || diff > physic.MilliAmpere {
+		t.Fatal(pm)
+	}
+	if diff := pm.Power - 12*physic.Watt; diff < -10*physic.MilliWatt || diff > 10*physic.MilliWatt {
+		t.Fatal(pm)
+	}
+
+	// The shunt voltage exceeds the 320mV range.
+	dev.current = 4 * physic.Ampere
+	if _, err := d.Sense(); err != errRegisterOverflow {
+		t.Fatal(err)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	sim, dev := newSimINA219(100 * physic.MilliOhm)
+	d, err := New(sim, &DefaultOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := d.String(); s != "INA219{sim(64)}" {
+		t.Fatal(s)
+	}
+	dev.bus = 5 * physic.Volt
+	dev.current = 500 * physic.MilliAmpere
+	m, err := d.Measure()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 4 {
+		t.Fatal(m)
+	}
+	if m[0].Quantity != sensor.Voltage || m[0].Channel != "shunt" || m[0].Value != 50*physic.MilliVolt {
+		t.Fatal(m[0])
+	}
+	if m[1].Channel != "bus" || m[1].Value != 5*physic.Volt || m[1].Precision != 4*physic.MilliVolt {
+		t.Fatal(m[1])
+	}
+	if m[2].Quantity != sensor.Current || m[3].Quantity != sensor.Power || m[3].Time.IsZero() {
+		t.Fatal(m[2:])
+	}
+	if err := d.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	dev.current = 4 * physic.Ampere
+	if _, err := d.Measure(); err == nil {
+		t.Fatal("expected overflow")
+	}
+}
+
+//
+
+// simINA219 simulates the ADC and the current and power calculations.
+type simINA219 struct {
+	i2ctest.SimDevice
+	shunt   physic.ElectricResistance
+	bus     physic.ElectricPotential
+	current physic.ElectricCurrent
+}
+
+func newSimINA219(shunt physic.ElectricResistance) (*i2ctest.Sim, *simINA219) {
+	d := &simINA219{
+		SimDevice: i2ctest.SimDevice{
+			Regs: map[uint8]*i2ctest.SimReg{
+				configRegister:       {Value: []byte{0x39, 0x9F}},
+				shuntVoltageRegister: {Value: []byte{0, 0}, ReadOnly: true},
+				busVoltageRegister:   {Value: []byte{0, 0}, ReadOnly: true},
+				powerRegister:        {Value: []byte{0, 0}, ReadOnly: true},
+				currentRegister:      {Value: []byte{0, 0}, ReadOnly: true},
+				calibrationRegister:  {Value: []byte{0, 0}},
+			},
+		},
+		shunt: shunt,
+	}
+	for _, r := range []uint8{shuntVoltageRegister, busVoltageRegister, powerRegister, currentRegister} {
+		d.Regs[r].OnRead = d.convert
+	}
+	return &i2ctest.Sim{Devices: map[uint16]*i2ctest.SimDevice{0x40: &d.SimDevice}}, d
+}
+
+// convert updates the result registers as described on page 12 of the
+// datasheet.
+func (d *simINA219) convert() {
+	// 10µV per bit; convert via nV to not overflow.
+	vShunt := int64(d.current) * int64(d.shunt) / int64(physic.Volt)
+	shunt := vShunt / int64(10*physic.MicroVolt)
+	bus := uint16(d.bus/(4*physic.MilliVolt)) << 3
+	if shunt > 32000 || shunt < -32000 {
+		// Math overflow.
+		bus |= 1
+	}
+	current := shunt * int64(d.Regs[calibrationRegister].Uint16()) / 4096
+	power := current * int64(bus>>3) / 5000
+	d.Regs[shuntVoltageRegister].SetUint16(uint16(shunt))
+	d.Regs[busVoltageRegister].SetUint16(bus | 2)
+	d.Regs[currentRegister].SetUint16(uint16(current))
+	d.Regs[powerRegister].SetUint16(uint16(power))
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package ina219
+
+import (
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+// String implements conn.Resource.
+func (d *Dev) String() string {
+	return "INA219{" + d.m.Conn.String() + "}"
+}
+
+// Halt implements conn.Resource.
+//
+// It is a noop.
+func (d *Dev) Halt() error {
+	return nil
+}
+
+// Measure implements sensor.Sensor.
+//
+// The shunt and bus voltages are reported on the "shunt" and "bus" channels.
+func (d *Dev) Measure() ([]sensor.Measurement, error) {
+	pm, err := d.Sense()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return []sensor.Measurement{
+		{Quantity: sensor.Voltage, Channel: "shunt", Value: pm.Shunt, Precision: 10 * physic.MicroVolt, Time: now},
+		{Quantity: sensor.Voltage, Channel: "bus", Value: pm.Voltage, Precision: 4 * physic.MilliVolt, Time: now},
+		{Quantity: sensor.Current, Value: pm.Current, Precision: d.currentLSB, Time: now},
+		{Quantity: sensor.Power, Value: pm.Power, Precision: d.powerLSB, Time: now},
+	}, nil
+}
+
+var _ sensor.Sensor = &Dev{}
@@ -0,0 +1,27 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package mcp9808
+
+import (
+	"encoding/json"
+
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/i2c"
+	"github.com/meandrewdev/periph/devices/devreg"
+)
+
+// openI2C decodes the board description options as Opts, starting from
+// DefaultOpts.
+func openI2C(b i2c.Bus, raw json.RawMessage) (conn.Resource, error) {
+	o := DefaultOpts
+	if err := devreg.DecodeOpts(raw, &o); err != nil {
+		return nil, err
+	}
+	return New(b, &o)
+}
+
+func init() {
+	devreg.MustRegister(&devreg.Ref{Name: "mcp9808", I2C: openI2C})
+}
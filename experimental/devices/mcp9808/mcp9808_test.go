@@ -956,3 +956,111 @@ func Test_alertTemperatureToBits(t *testing.T) {
 		}
 	}
 }
+
+func TestSim(t *testing.T) {
+	sim, dev := newSimMCP9808()
+	dev.ambient = physic.ZeroCelsius + 25*physic.Kelvin
+	d, err := New(sim, &DefaultOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := dev.Regs[resolutionConfig].Value[0]; v != 0x03 {
+		t.Fatalf("resolution 0x%x", v)
+	}
+	if temp, err := d.SenseTemp(); err != nil || temp != dev.ambient {
+		t.Fatal(temp, err)
+	}
+
+	lower := physic.ZeroCelsius
+	upper := physic.ZeroCelsius + 20*physic.Kelvin
+	crit := physic.ZeroCelsius + 30*physic.Kelvin
+	data := []struct {
+		ambient physic.Temperature
+		alerts  []Alert
+	}{
+		{physic.ZeroCelsius + 10*physic.Kelvin, nil},
+		{physic.ZeroCelsius - 5*physic.Kelvin, []Alert{{"lower", lower}}},
+		{physic.ZeroCelsius + 25*physic.Kelvin, []Alert{{"upper", upper}}},
+		{physic.ZeroCelsius + 35*physic.Kelvin, []Alert{{"critical", crit}, {"upper", upper}}},
+	}
+	for i, line := range data {
+		dev.ambient = line.ambient
+		temp, alerts, err := d.SenseWithAlerts(lower, upper, crit)
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if temp != line.ambient {
+			t.Fatal(i, temp)
+		}
+		if !reflect.DeepEqual(alerts, line.alerts) {
+			t.Fatal(i, alerts)
+		}
+	}
+
+	if err := d.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if v := dev.Regs[configuration].Uint16(); v != 0x0100 {
+		t.Fatalf("config 0x%x", v)
+	}
+	// SenseTemp() takes the device out of shut down, so the new temperature is
+	// converted.
+	dev.ambient = physic.ZeroCelsius
+	if temp, err := d.SenseTemp(); err != nil || temp != dev.ambient {
+		t.Fatal(temp, err)
+	}
+	if v := dev.Regs[configuration].Uint16(); v != 0 {
+		t.Fatalf("config 0x%x", v)
+	}
+}
+
+//
+
+// simMCP9808 simulates the temperature conversion and the alert comparators.
+type simMCP9808 struct {
+	i2ctest.SimDevice
+	ambient physic.Temperature
+}
+
+func newSimMCP9808() (*i2ctest.Sim, *simMCP9808) {
+	d := &simMCP9808{
+		SimDevice: i2ctest.SimDevice{
+			Regs: map[uint8]*i2ctest.SimReg{
+				configuration:    {Value: []byte{0x01, 0x00}},
+				upperAlert:       {Value: []byte{0, 0}},
+				lowerAlert:       {Value: []byte{0, 0}},
+				critAlert:        {Value: []byte{0, 0}},
+				temperature:      {Value: []byte{0, 0}, ReadOnly: true},
+				manifactureID:    {Value: []byte{0x00, 0x54}, ReadOnly: true},
+				deviceID:         {Value: []byte{0x04, 0x00}, ReadOnly: true},
+				resolutionConfig: {Value: []byte{0x03}},
+			},
+		},
+	}
+	d.Regs[temperature].OnRead = d.convert
+	return &i2ctest.Sim{Devices: map[uint16]*i2ctest.SimDevice{0x18: &d.SimDevice}}, d
+}
+
+// convert updates the ambient temperature register unless in shutdown.
+func (d *simMCP9808) convert() {
+	if d.Regs[configuration].Uint16()&0x0100 != 0 {
+		return
+	}
+	// 1/16°C per bit, 13 bits two's complement.
+	v := int((d.ambient - physic.ZeroCelsius) / (62500 * physic.MicroKelvin))
+	bits := uint16(v) & 0x1FFF
+	// The alert registers use the same format.
+	limit := func(r byte) int {
+		return int(int16(d.Regs[r].Uint16()<<3) >> 3)
+	}
+	if v >= limit(critAlert) {
+		bits |= 0x8000
+	}
+	if v > limit(upperAlert) {
+		bits |= 0x4000
+	}
+	if v < limit(lowerAlert) {
+		bits |= 0x2000
+	}
+	d.Regs[temperature].SetUint16(bits)
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package mpu9250
+
+import (
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+// Sensor returns a sensor.Sensor that reads the accelerometer, the gyroscope
+// and the die temperature.
+//
+// The acceleration and the angular velocity are reported on the "x", "y" and
+// "z" channels, scaled with the full-scale ranges read from the device. The
+// device must have been initialized with Init().
+//
+// Halt() on the returned object is a no-op.
+func (m *MPU9250) Sensor() sensor.Sensor {
+	return &motionSensor{m: m}
+}
+
+//
+
+type motionSensor struct {
+	m *MPU9250
+}
+
+func (s *motionSensor) String() string {
+	return "MPU9250"
+}
+
+func (s *motionSensor) Halt() error {
+	return nil
+}
+
+func (s *motionSensor) Measure() ([]sensor.Measurement, error) {
+	ar, err := s.m.GetAccelRange()
+	if err != nil {
+		return nil, err
+	}
+	gr, err := s.m.GetGyroRange()
+	if err != nil {
+		return nil, err
+	}
+	acc, rot, err := s.m.GetMotion6()
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.m.GetTemperature()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	// The full scale is ±2g<<AFS_SEL and ±250°/s<<FS_SEL over 16 bits.
+	aLSB := physic.Acceleration(2<<ar) * physic.StandardGravity / 32768
+	gLSB := physic.AngularVelocity(250<<gr) * physic.DegreePerSecond / 32768
+	accel := func(v int16) physic.Acceleration {
+		return physic.Acceleration(int64(v) * int64(2<<ar) * int64(physic.StandardGravity) / 32768)
+	}
+	gyro := func(v int16) physic.AngularVelocity {
+		return physic.AngularVelocity(int64(v) * int64(250<<gr) * int64(physic.DegreePerSecond) / 32768)
+	}
+	return []sensor.Measurement{
+		{Quantity: sensor.Acceleration, Channel: "x", Value: accel(acc.X), Precision: aLSB, Time: now},
+		{Quantity: sensor.Acceleration, Channel: "y", Value: accel(acc.Y), Precision: aLSB, Time: now},
+		{Quantity: sensor.Acceleration, Channel: "z", Value: accel(acc.Z), Precision: aLSB, Time: now},
+		{Quantity: sensor.AngularVelocity, Channel: "x", Value: gyro(rot.X), Precision: gLSB, Time: now},
+		{Quantity: sensor.AngularVelocity, Channel: "y", Value: gyro(rot.Y), Precision: gLSB, Time: now},
+		{Quantity: sensor.AngularVelocity, Channel: "z", Value: gyro(rot.Z), Precision: gLSB, Time: now},
+		{Quantity: sensor.Temperature, Value: temperature(t), Precision: physic.Kelvin * 100 / 33387, Time: now},
+	}, nil
+}
+
+// temperature converts TEMP_OUT; the sensitivity is 333.87 LSB/°C and the
+// room temperature offset is 0 at 21°C.
+func temperature(v uint16) physic.Temperature {
+	return physic.ZeroCelsius + 21*physic.Kelvin + physic.Temperature(int64(int16(v))*int64(physic.Kelvin)*100/33387)
+}
+
+var _ sensor.Sensor = &motionSensor{}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package mpu9250
+
+import (
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+	"github.com/meandrewdev/periph/experimental/devices/mpu9250/reg"
+)
+
+func TestSensor(t *testing.T) {
+	f := fakeTransport{}
+	f[reg.MPU9250_ACCEL_CONFIG] = 1 << 3 // ±4g
+	f[reg.MPU9250_GYRO_CONFIG] = 2 << 3  // ±1000°/s
+	f.set(reg.MPU9250_ACCEL_XOUT_H, 8192)
+	f.set(reg.MPU9250_ACCEL_YOUT_H, -8192)
+	f.set(reg.MPU9250_GYRO_XOUT_H, 16384)
+	f.set(reg.MPU9250_TEMP_OUT_H, 334)
+	m, err := New(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := m.Sensor()
+	got, err := s.Measure()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		q sensor.Quantity
+		c string
+		v sensor.Value
+	}{
+		{sensor.Acceleration, "x", physic.StandardGravity},
+		{sensor.Acceleration, "y", -physic.StandardGravity},
+		{sensor.Acceleration, "z", physic.Acceleration(0)},
+		{sensor.AngularVelocity, "x", 500 * physic.DegreePerSecond},
+		{sensor.AngularVelocity, "y", physic.AngularVelocity(0)},
+		{sensor.AngularVelocity, "z", physic.AngularVelocity(0)},
+		{sensor.Temperature, "", physic.ZeroCelsius + 22*physic.Kelvin + 389373*physic.NanoKelvin},
+	}
+	if len(got) != len(expected) {
+		t.Fatal(got)
+	}
+	for i, e := range expected {
+		if got[i].Quantity != e.q || got[i].Channel != e.c || got[i].Value != e.v {
+			t.Fatal(i, got[i].String())
+		}
+	}
+	if got[0].Precision != physic.StandardGravity/8192 {
+		t.Fatal(got[0].Precision)
+	}
+	if err := s.Halt(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//
+
+// fakeTransport implements Proto over a register map.
+type fakeTransport map[byte]byte
+
+func (f fakeTransport) set(hi byte, v int16) {
+	f[hi] = byte(uint16(v) >> 8)
+	f[hi+1] = byte(v)
+}
+
+func (f fakeTransport) writeMaskedReg(address byte, mask byte, value byte) error {
+	f[address] = f[address]&^mask | value&mask
+	return nil
+}
+
+func (f fakeTransport) readMaskedReg(address byte, mask byte) (byte, error) {
+	return f[address] & mask, nil
+}
+
+func (f fakeTransport) readByte(address byte) (byte, error) {
+	return f[address], nil
+}
+
+func (f fakeTransport) writeByte(address byte, value byte) error {
+	f[address] = value
+	return nil
+}
+
+func (f fakeTransport) readUint16(address ...byte) (uint16, error) {
+	return uint16(f[address[0]])<<8 | uint16(f[address[1]]), nil
+}
+
+func (f fakeTransport) writeMagReg(address byte, value byte) error {
+	return nil
+}
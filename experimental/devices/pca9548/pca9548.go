@@ -96,14 +96,36 @@ func (d *Dev) tx(port uint8, address uint16, w, r []byte) error {
 	}
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	// Change active port if needed.
+	if err := d.selectPort(port); err != nil {
+		return err
+	}
+	return d.c.Tx(address, w, r)
+}
+
+// transfer is like tx but for multi-message transactions.
+func (d *Dev) transfer(port uint8, msgs []i2c.Msg) error {
+	for i := range msgs {
+		if msgs[i].Addr == d.address {
+			return errors.New("device address conflicts with multiplexer address")
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.selectPort(port); err != nil {
+		return err
+	}
+	return i2c.Transfer(d.c, msgs)
+}
+
+// selectPort changes the active port if needed.
+func (d *Dev) selectPort(port uint8) error {
 	if port != d.activePort {
 		if err := d.c.Tx(d.address, []byte{1 << port}, nil); err != nil {
 			return errors.New("failed to change active port on multiplexer: " + err.Error())
 		}
 		d.activePort = port
 	}
-	return d.c.Tx(address, w, r)
+	return nil
 }
 
 // newOpener is a helper for creating an opener func.
@@ -146,6 +168,19 @@ func (p *port) Tx(addr uint16, w, r []byte) error {
 	return p.mux.tx(p.number, addr, w, r)
 }
 
+// Transfer implements i2c.Messenger.
+//
+// If the master bus doesn't implement i2c.Messenger, only transactions that
+// can be done with Tx() are supported.
+func (p *port) Transfer(msgs []i2c.Msg) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.mux == nil {
+		return errors.New(p.String() + " has been closed")
+	}
+	return p.mux.transfer(p.number, msgs)
+}
+
 // Close closes a port.
 func (p *port) Close() error {
 	p.mu.Lock()
@@ -156,3 +191,4 @@ func (p *port) Close() error {
 
 var _ conn.Resource = &Dev{}
 var _ i2c.Bus = &port{}
+var _ i2c.Messenger = &port{}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/meandrewdev/periph/conn/i2c"
 	"github.com/meandrewdev/periph/conn/i2c/i2creg"
 	"github.com/meandrewdev/periph/conn/i2c/i2ctest"
 	"github.com/meandrewdev/periph/conn/physic"
@@ -283,6 +284,37 @@ func Test_port_Tx(t *testing.T) {
 
 }
 
+func Test_port_Transfer(t *testing.T) {
+	msgs := []i2c.Msg{
+		{Addr: 0x30, Buf: []byte{0xAA}},
+		{Addr: 0x31, Flags: i2c.Read, Buf: []byte{0xBB}},
+	}
+	bus := &i2ctest.Playback{
+		Ops: []i2ctest.IO{
+			{Addr: 0x70, W: []byte{0x40}},
+			{Msgs: msgs},
+		},
+	}
+	p := &port{number: 6, mux: &Dev{address: 0x70, activePort: 0xFF, c: bus}}
+	r := make([]byte, 1)
+	if err := p.Transfer([]i2c.Msg{msgs[0], {Addr: 0x31, Flags: i2c.Read, Buf: r}}); err != nil {
+		t.Fatal(err)
+	}
+	if r[0] != 0xBB {
+		t.Fatal(r)
+	}
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Transfer([]i2c.Msg{{Addr: 0x70}}) == nil {
+		t.Fatal("expected address conflict")
+	}
+	p.Close()
+	if p.Transfer(msgs) == nil {
+		t.Fatal("expected error on closed port")
+	}
+}
+
 func Test_port_Close(t *testing.T) {
 
 	p := &port{number: 6}
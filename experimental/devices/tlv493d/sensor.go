@@ -0,0 +1,32 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package tlv493d
+
+import (
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
+)
+
+// Measure implements sensor.Sensor.
+//
+// It reads a sample with HighPrecisionWithTemperature. The magnetic flux
+// density is reported on the "x", "y" and "z" channels.
+func (d *Dev) Measure() ([]sensor.Measurement, error) {
+	s, err := d.Read(HighPrecisionWithTemperature)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return []sensor.Measurement{
+		{Quantity: sensor.MagneticFluxDensity, Channel: "x", Value: s.Bx, Precision: magneticFluxScaling, Time: now},
+		{Quantity: sensor.MagneticFluxDensity, Channel: "y", Value: s.By, Precision: magneticFluxScaling, Time: now},
+		{Quantity: sensor.MagneticFluxDensity, Channel: "z", Value: s.Bz, Precision: magneticFluxScaling, Time: now},
+		{Quantity: sensor.Temperature, Value: s.Temperature, Precision: physic.Temperature(temperatureScaling), Time: now},
+	}, nil
+}
+
+var _ sensor.Sensor = &Dev{}
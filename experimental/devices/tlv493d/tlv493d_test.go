@@ -9,6 +9,7 @@ import (
 
 	"github.com/meandrewdev/periph/conn/i2c/i2ctest"
 	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/sensor"
 )
 
 func TestDev_String(t *testing.T) {
@@ -129,6 +130,44 @@ func TestTLV493D_Read(t *testing.T) {
 	}
 }
 
+func TestTLV493D_Measure(t *testing.T) {
+	b := i2ctest.Playback{
+		Ops: []i2ctest.IO{
+			// Recovery
+			{Addr: 0x5e, W: []byte{0xff}, R: []byte{}},
+			// Reset
+			{Addr: 0x5e, W: []byte{0x0}, R: []byte{}},
+			// Read configuration
+			{Addr: 0x5e, W: []byte{0x0}, R: []byte{0xfd, 0x2d, 0x79, 0x14, 0xab, 0x22, 0x51, 0x81, 0x4, 0x60}},
+			// Configure
+			{Addr: 0x5e, W: []byte{0x0, 0x81, 0x4, 0x60}, R: []byte{}},
+			// Read measurements
+			{Addr: 0x5e, W: []byte{0x0}, R: []byte{0xfd, 0x2d, 0x79, 0x18, 0xbb, 0x31, 0x51}},
+		},
+	}
+	defer b.Close()
+
+	opts := DefaultOpts
+	opts.Mode = LowPowerMode
+	d, err := New(&b, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := d.Measure()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 4 {
+		t.Fatal(m)
+	}
+	if m[1].Quantity != sensor.MagneticFluxDensity || m[1].Channel != "y" || m[1].Value != 71638*physic.MicroTesla {
+		t.Fatal(m[1])
+	}
+	if m[3].Quantity != sensor.Temperature || m[3].Value != 294850*physic.MilliKelvin {
+		t.Fatal(m[3])
+	}
+}
+
 func TestTLV493D_ReadContinous(t *testing.T) {
 	t.Skip("this test has a race condition")
 	b := i2ctest.Playback{
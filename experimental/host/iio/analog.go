@@ -0,0 +1,179 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package iio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/pin"
+	"github.com/meandrewdev/periph/experimental/conn/analog"
+)
+
+// ADC returns the channel as an analog.PinADC.
+//
+// The channel must be a voltage input channel.
+func (c *Channel) ADC() (analog.PinADC, error) {
+	if c.typ != "voltage" || c.output || c.file == "" {
+		return nil, fmt.Errorf("iio: %s is not an ADC channel", c)
+	}
+	return &adcPin{c: c}, nil
+}
+
+// DAC returns the channel as an analog.PinDAC.
+//
+// The channel must be a voltage output channel.
+func (c *Channel) DAC() (analog.PinDAC, error) {
+	if c.typ != "voltage" || !c.output || c.file != "raw" {
+		return nil, fmt.Errorf("iio: %s is not a DAC channel", c)
+	}
+	return &dacPin{c: c}, nil
+}
+
+//
+
+// toVoltage converts a raw value to an electrical tension.
+func (c *Channel) toVoltage(raw float64) physic.ElectricPotential {
+	return physic.ElectricPotential(math.Round(c.convert(raw) * float64(physic.MilliVolt)))
+}
+
+// voltageRange returns the range of the channel if known.
+func (c *Channel) voltageRange() (analog.Sample, analog.Sample) {
+	if c.scanIndex == -1 || c.file != "raw" {
+		return analog.Sample{}, analog.Sample{}
+	}
+	var min, max int64
+	if c.scanFormat.signed {
+		min = -1 << (c.scanFormat.bits - 1)
+		max = 1<<(c.scanFormat.bits-1) - 1
+	} else {
+		max = 1<<c.scanFormat.bits - 1
+	}
+	if min < math.MinInt32 || max > math.MaxInt32 {
+		return analog.Sample{}, analog.Sample{}
+	}
+	return analog.Sample{Raw: int32(min), V: c.toVoltage(float64(min))}, analog.Sample{Raw: int32(max), V: c.toVoltage(float64(max))}
+}
+
+type adcPin struct {
+	c *Channel
+}
+
+func (p *adcPin) String() string {
+	return p.c.String()
+}
+
+func (p *adcPin) Halt() error {
+	return nil
+}
+
+func (p *adcPin) Name() string {
+	return p.c.String()
+}
+
+func (p *adcPin) Number() int {
+	return p.c.index
+}
+
+func (p *adcPin) Function() string {
+	return string(p.Func())
+}
+
+// Func implements pin.PinFunc.
+func (p *adcPin) Func() pin.Func {
+	return analog.ADC
+}
+
+// SupportedFuncs implements pin.PinFunc.
+func (p *adcPin) SupportedFuncs() []pin.Func {
+	return []pin.Func{analog.ADC}
+}
+
+// SetFunc implements pin.PinFunc.
+func (p *adcPin) SetFunc(f pin.Func) error {
+	if f == analog.ADC {
+		return nil
+	}
+	return errors.New("iio: pin function cannot be changed")
+}
+
+// Range implements analog.PinADC.
+//
+// The range is only known for channels supporting buffered capture, as the
+// number of bits is only reported for these.
+func (p *adcPin) Range() (analog.Sample, analog.Sample) {
+	return p.c.voltageRange()
+}
+
+// Read implements analog.PinADC.
+func (p *adcPin) Read() (analog.Sample, error) {
+	v, err := p.c.read()
+	if err != nil {
+		return analog.Sample{}, err
+	}
+	return analog.Sample{Raw: int32(v), V: p.c.toVoltage(v)}, nil
+}
+
+type dacPin struct {
+	c *Channel
+}
+
+func (p *dacPin) String() string {
+	return p.c.String()
+}
+
+func (p *dacPin) Halt() error {
+	return nil
+}
+
+func (p *dacPin) Name() string {
+	return p.c.String()
+}
+
+func (p *dacPin) Number() int {
+	return p.c.index
+}
+
+func (p *dacPin) Function() string {
+	return string(p.Func())
+}
+
+// Func implements pin.PinFunc.
+func (p *dacPin) Func() pin.Func {
+	return analog.DAC
+}
+
+// SupportedFuncs implements pin.PinFunc.
+func (p *dacPin) SupportedFuncs() []pin.Func {
+	return []pin.Func{analog.DAC}
+}
+
+// SetFunc implements pin.PinFunc.
+func (p *dacPin) SetFunc(f pin.Func) error {
+	if f == analog.DAC {
+		return nil
+	}
+	return errors.New("iio: pin function cannot be changed")
+}
+
+// Range implements analog.PinDAC.
+//
+// The range of output channels is not reported by the kernel.
+func (p *dacPin) Range() (analog.Sample, analog.Sample) {
+	return analog.Sample{}, analog.Sample{}
+}
+
+// Out implements analog.PinDAC.
+func (p *dacPin) Out(v int32) error {
+	return writeString(p.c.dev.root+p.c.Name()+"_raw", strconv.Itoa(int(v)))
+}
+
+var _ analog.PinADC = &adcPin{}
+var _ analog.PinDAC = &dacPin{}
+var _ pin.PinFunc = &adcPin{}
+var _ pin.PinFunc = &dacPin{}
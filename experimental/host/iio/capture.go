@@ -0,0 +1,223 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package iio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Capture starts a buffered capture of the channels chs.
+//
+// trigger is the name of the trigger that starts each scan, one of Triggers.
+// If empty, the current trigger of the device is kept; devices with their own
+// FIFO don't need one. length is the number of scans the kernel buffers.
+//
+// Only one capture can be running per device. The capture must be stopped
+// with Capture.Halt() or Device.Halt().
+func (d *Device) Capture(chs []*Channel, trigger string, length int) (*Capture, error) {
+	if len(chs) == 0 {
+		return nil, errors.New("iio: no channel to capture")
+	}
+	if length <= 0 {
+		return nil, errors.New("iio: length must be positive")
+	}
+	for _, c := range chs {
+		if c.dev != d {
+			return nil, fmt.Errorf("iio: %s is not a channel of %s", c, d)
+		}
+		if c.scanIndex == -1 {
+			return nil, fmt.Errorf("iio: %s doesn't support buffered capture", c)
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.capture != nil {
+		return nil, fmt.Errorf("iio: %s is already capturing", d)
+	}
+	c := &Capture{d: d, chs: chs, length: length}
+	c.layout()
+	// The buffer must be disabled to change the scan elements.
+	if err := writeString(d.root+"buffer/enable", "0"); err != nil {
+		return nil, err
+	}
+	for _, ch := range d.channels {
+		if ch.scanIndex == -1 {
+			continue
+		}
+		v := "0"
+		if c.index(ch) != -1 {
+			v = "1"
+		}
+		if err := writeString(d.root+"scan_elements/"+ch.Name()+"_en", v); err != nil {
+			_ = c.disable()
+			return nil, err
+		}
+	}
+	if trigger != "" {
+		if err := writeString(d.root+"trigger/current_trigger", trigger); err != nil {
+			_ = c.disable()
+			return nil, err
+		}
+	}
+	if err := writeString(d.root+"buffer/length", strconv.Itoa(length)); err != nil {
+		_ = c.disable()
+		return nil, err
+	}
+	if err := writeString(d.root+"buffer/enable", "1"); err != nil {
+		_ = c.disable()
+		return nil, err
+	}
+	f, err := os.OpenFile(devRoot+d.String(), os.O_RDONLY, 0)
+	if err != nil {
+		_ = c.disable()
+		if os.IsPermission(err) {
+			return nil, fmt.Errorf("iio: need more access, try as root or setup udev rules: %v", err)
+		}
+		return nil, fmt.Errorf("iio: %v", err)
+	}
+	c.f = f
+	d.capture = c
+	return c, nil
+}
+
+// Capture is a buffered capture in progress, started with Device.Capture().
+type Capture struct {
+	d      *Device
+	chs    []*Channel
+	length int
+
+	// Layout of a scan.
+	offsets []int // Offset of each channel of chs in a scan.
+	size    int   // Size of a scan in bytes.
+
+	mu  sync.Mutex
+	f   io.ReadCloser
+	buf []byte
+}
+
+// String implements conn.Resource.
+func (c *Capture) String() string {
+	return c.d.String()
+}
+
+// Halt implements conn.Resource.
+//
+// It stops the capture and disables the scan elements.
+func (c *Capture) Halt() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.f == nil {
+		return nil
+	}
+	err := c.f.Close()
+	c.f = nil
+	if err2 := c.disable(); err == nil {
+		err = err2
+	}
+	c.d.mu.Lock()
+	c.d.capture = nil
+	c.d.mu.Unlock()
+	return err
+}
+
+// disable disables the buffer and the scan elements of the channels.
+//
+// It returns the first error but always tries all the writes, so it can
+// unwind a partial setup.
+func (c *Capture) disable() error {
+	err := writeString(c.d.root+"buffer/enable", "0")
+	for _, ch := range c.chs {
+		if err2 := writeString(c.d.root+"scan_elements/"+ch.Name()+"_en", "0"); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// Channels returns the channels captured, in the order of the values in a
+// scan returned by Read().
+func (c *Capture) Channels() []*Channel {
+	return c.chs
+}
+
+// Read blocks until at least one scan is available and returns the scans
+// read, up to the length passed to Device.Capture().
+//
+// Each scan contains the raw value of each channel, in the order passed to
+// Device.Capture(). Use Channel.Scale() and Channel.Offset() to convert them.
+func (c *Capture) Read() ([][]int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.f == nil {
+		return nil, errors.New("iio: capture is halted")
+	}
+	if c.buf == nil {
+		c.buf = make([]byte, c.size*c.length)
+	}
+	n, err := c.f.Read(c.buf)
+	if err != nil {
+		return nil, fmt.Errorf("iio: %v", err)
+	}
+	if n%c.size != 0 {
+		return nil, fmt.Errorf("iio: read %d bytes, which is not a multiple of the scan size %d", n, c.size)
+	}
+	out := make([][]int64, n/c.size)
+	for i := range out {
+		scan := c.buf[i*c.size : (i+1)*c.size]
+		out[i] = make([]int64, len(c.chs))
+		for j, ch := range c.chs {
+			out[i][j] = ch.scanFormat.decode(scan[c.offsets[j] : c.offsets[j]+ch.scanFormat.size()])
+		}
+	}
+	return out, nil
+}
+
+//
+
+// layout calculates the offset of each channel in a scan.
+//
+// The values are ordered by scan index and each one is aligned to its size.
+// The scan is padded to the size of its largest value.
+func (c *Capture) layout() {
+	sorted := make([]*Channel, len(c.chs))
+	copy(sorted, c.chs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].scanIndex < sorted[j].scanIndex
+	})
+	c.offsets = make([]int, len(c.chs))
+	offset := 0
+	largest := 1
+	for _, ch := range sorted {
+		s := ch.scanFormat.size()
+		if offset%s != 0 {
+			offset += s - offset%s
+		}
+		c.offsets[c.index(ch)] = offset
+		offset += s
+		if s > largest {
+			largest = s
+		}
+	}
+	if offset%largest != 0 {
+		offset += largest - offset%largest
+	}
+	c.size = offset
+}
+
+// index returns the index of ch in c.chs, or -1.
+func (c *Capture) index(ch *Channel) int {
+	for i, o := range c.chs {
+		if o == ch {
+			return i
+		}
+	}
+	return -1
+}
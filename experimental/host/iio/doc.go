@@ -0,0 +1,28 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package iio implements a host driver for the Linux Industrial I/O
+// subsystem.
+//
+// Many boards have kernel drivers for their built-in ADCs and for sensors
+// connected via I²C or SPI. These devices are exposed in
+// /sys/bus/iio/devices. This package enumerates them and exposes:
+//
+// - voltage channels as analog.PinADC and analog.PinDAC, with the scale and
+// offset reported by the kernel applied.
+//
+// - temperature, pressure and relative humidity channels as physic.SenseEnv.
+//
+// - buffered capture of multiple channels via the character device
+// /dev/iio:deviceN, optionally driven by a trigger.
+//
+// Accessing the devices only requires read and write access to the sysfs
+// files, which can be granted to a non-root user via udev rules.
+//
+// Datasheet
+//
+// https://www.kernel.org/doc/html/latest/driver-api/iio/index.html
+//
+// https://www.kernel.org/doc/Documentation/ABI/testing/sysfs-bus-iio
+package iio
@@ -0,0 +1,132 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package iio
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+// Env returns the temperature, pressure and relative humidity channels of the
+// device as a physic.SenseEnv.
+//
+// When the device has multiple channels of the same type, the one with the
+// lowest index is used.
+func (d *Device) Env() (physic.SenseEnv, error) {
+	e := &envSensor{d: d}
+	for _, c := range d.channels {
+		if c.output || c.file == "" {
+			continue
+		}
+		switch c.typ {
+		case "temp":
+			if e.temp == nil {
+				e.temp = c
+			}
+		case "pressure":
+			if e.pressure == nil {
+				e.pressure = c
+			}
+		case "humidityrelative":
+			if e.humidity == nil {
+				e.humidity = c
+			}
+		}
+	}
+	if e.temp == nil && e.pressure == nil && e.humidity == nil {
+		return nil, fmt.Errorf("iio: %s has no environmental channel", d)
+	}
+	return e, nil
+}
+
+//
+
+type envSensor struct {
+	d        *Device
+	temp     *Channel // milli degree Celsius
+	pressure *Channel // kilopascal
+	humidity *Channel // milli percent
+
+	loop physic.SenseLoop
+}
+
+func (e *envSensor) String() string {
+	return e.d.String() + "(" + e.d.name + ")"
+}
+
+// Halt stops a continuous sense that was started with SenseContinuous.
+func (e *envSensor) Halt() error {
+	return e.loop.Stop(nil)
+}
+
+// Sense implements physic.SenseEnv.
+func (e *envSensor) Sense(env *physic.Env) error {
+	if e.temp != nil {
+		v, err := e.temp.Read()
+		if err != nil {
+			return err
+		}
+		env.Temperature = toTemperature(v) + physic.ZeroCelsius
+	}
+	if e.pressure != nil {
+		v, err := e.pressure.Read()
+		if err != nil {
+			return err
+		}
+		env.Pressure = toPressure(v)
+	}
+	if e.humidity != nil {
+		v, err := e.humidity.Read()
+		if err != nil {
+			return err
+		}
+		env.Humidity = toHumidity(v)
+	}
+	return nil
+}
+
+// SenseContinuous implements physic.SenseEnv.
+//
+// Calling it again stops the previous sensing and closes its channel.
+func (e *envSensor) SenseContinuous(interval time.Duration) (<-chan physic.Env, error) {
+	return e.loop.StartEnv(e, interval, e.Sense), nil
+}
+
+// Precision implements physic.SenseEnv.
+//
+// It is the value of one unit of the raw value. For channels reporting a
+// processed value, it is one unit as reported by the kernel, e.g. 1kPa for
+// pressure, which may be pessimistic.
+func (e *envSensor) Precision(env *physic.Env) {
+	if e.temp != nil {
+		env.Temperature = toTemperature(e.temp.scale)
+	}
+	if e.pressure != nil {
+		env.Pressure = toPressure(e.pressure.scale)
+	}
+	if e.humidity != nil {
+		env.Humidity = toHumidity(e.humidity.scale)
+	}
+}
+
+// toTemperature converts milli degree Celsius to a temperature difference.
+func toTemperature(v float64) physic.Temperature {
+	return physic.Temperature(math.Round(v * float64(physic.MilliKelvin)))
+}
+
+// toPressure converts kilopascal.
+func toPressure(v float64) physic.Pressure {
+	return physic.Pressure(math.Round(v * float64(physic.KiloPascal)))
+}
+
+// toHumidity converts milli percent.
+func toHumidity(v float64) physic.RelativeHumidity {
+	return physic.RelativeHumidity(math.Round(v * float64(physic.PercentRH) / 1000))
+}
+
+var _ physic.SenseEnv = &envSensor{}
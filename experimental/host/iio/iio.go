@@ -0,0 +1,540 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package iio
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/meandrewdev/periph"
+)
+
+// Devices is all the IIO devices discovered on this host, sorted by number.
+var Devices []*Device
+
+// Triggers is the name of all the IIO triggers discovered on this host, as
+// found in /sys/bus/iio/devices/triggerN/name.
+//
+// One can be passed to Device.Capture() to drive a buffered capture.
+var Triggers []string
+
+// DeviceByName returns the first device with the name name, e.g. "ads1015",
+// or the device named "iio:deviceN".
+func DeviceByName(name string) (*Device, error) {
+	for _, d := range Devices {
+		if d.name == name || d.String() == name {
+			return d, nil
+		}
+	}
+	return nil, errors.New("iio: invalid device name " + strconv.Quote(name))
+}
+
+// Device is an IIO device, as found in /sys/bus/iio/devices/iio:deviceN.
+type Device struct {
+	number   int
+	name     string
+	root     string // /sys/bus/iio/devices/iio:deviceN/
+	channels []*Channel
+
+	mu      sync.Mutex
+	capture *Capture
+}
+
+// String returns "iio:deviceN".
+func (d *Device) String() string {
+	return "iio:device" + strconv.Itoa(d.number)
+}
+
+// Name returns the name of the device as reported by its kernel driver, e.g.
+// "ads1015".
+func (d *Device) Name() string {
+	return d.name
+}
+
+// Number returns N in iio:deviceN.
+func (d *Device) Number() int {
+	return d.number
+}
+
+// Halt implements conn.Resource.
+//
+// It stops the buffered capture, if any.
+func (d *Device) Halt() error {
+	d.mu.Lock()
+	c := d.capture
+	d.mu.Unlock()
+	if c != nil {
+		return c.Halt()
+	}
+	return nil
+}
+
+// Channels returns the channels of the device.
+//
+// Input channels are listed before output channels, then they are sorted by
+// type and index.
+func (d *Device) Channels() []*Channel {
+	return d.channels
+}
+
+// Channel returns the channel with the sysfs name name, e.g. "in_voltage0" or
+// "in_temp".
+func (d *Device) Channel(name string) (*Channel, error) {
+	for _, c := range d.channels {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("iio: %s has no channel %q", d, name)
+}
+
+// Channel is a channel of an IIO device, e.g. "in_voltage0".
+//
+// The scale and the offset are read when the device is discovered.
+type Channel struct {
+	dev    *Device
+	id     string // e.g. "voltage0", "accel_x" or "voltage0-voltage1".
+	typ    string // e.g. "voltage", "temp" or "accel".
+	index  int    // -1 if the channel is not indexed.
+	output bool
+	file   string // "raw" or "input"; empty if it can only be captured.
+	scale  float64
+	offset float64
+
+	scanIndex  int // -1 if the channel can't be captured.
+	scanFormat scanFormat
+}
+
+// String returns the device and the name of the channel, e.g.
+// "iio:device0/in_voltage0".
+func (c *Channel) String() string {
+	return c.dev.String() + "/" + c.Name()
+}
+
+// Name returns the sysfs name of the channel, e.g. "in_voltage0".
+func (c *Channel) Name() string {
+	if c.output {
+		return "out_" + c.id
+	}
+	return "in_" + c.id
+}
+
+// Device returns the device this channel is part of.
+func (c *Channel) Device() *Device {
+	return c.dev
+}
+
+// Type returns the type of the channel, e.g. "voltage", "temp", "pressure",
+// "humidityrelative", "accel" or "timestamp".
+func (c *Channel) Type() string {
+	return c.typ
+}
+
+// Index returns the index of the channel, or -1 if it is not indexed.
+func (c *Channel) Index() int {
+	return c.index
+}
+
+// Output returns true for an output channel, e.g. a DAC.
+func (c *Channel) Output() bool {
+	return c.output
+}
+
+// Scale returns the value of one unit of the raw value.
+//
+// It is 1 for channels that report a processed value.
+func (c *Channel) Scale() float64 {
+	return c.scale
+}
+
+// Offset returns the offset added to the raw value before the scale is
+// applied.
+func (c *Channel) Offset() float64 {
+	return c.offset
+}
+
+// ReadRaw reads the current raw value of the channel.
+//
+// For channels reporting a processed value, it is the processed value
+// truncated to an integer.
+func (c *Channel) ReadRaw() (int64, error) {
+	v, err := c.read()
+	if err != nil {
+		return 0, err
+	}
+	return int64(v), nil
+}
+
+// Read reads the current value of the channel with the offset and scale
+// applied.
+//
+// The unit depends on the type, as documented by the kernel: millivolt for
+// voltage, milli degree Celsius for temp, kilopascal for pressure and milli
+// percent for humidityrelative.
+func (c *Channel) Read() (float64, error) {
+	v, err := c.read()
+	if err != nil {
+		return 0, err
+	}
+	return c.convert(v), nil
+}
+
+//
+
+// convert applies the offset and scale to a raw value.
+func (c *Channel) convert(raw float64) float64 {
+	return (raw + c.offset) * c.scale
+}
+
+func (c *Channel) read() (float64, error) {
+	if c.file == "" {
+		return 0, fmt.Errorf("iio: %s can only be captured", c)
+	}
+	s, err := readString(c.dev.root + c.Name() + "_" + c.file)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("iio: %s: %v", c, err)
+	}
+	return v, nil
+}
+
+// scanFormat is the format of a channel in a scan, e.g. "le:s12/16>>4".
+type scanFormat struct {
+	bigEndian bool
+	signed    bool
+	bits      uint // Number of significant bits.
+	storage   uint // Number of bits used to store the value.
+	shift     uint // Number of bits to shift right.
+}
+
+func parseScanFormat(s string) (scanFormat, error) {
+	f := scanFormat{}
+	// [be|le]:[s|u]bits/storagebits[Xrepeat]>>shift
+	var sign byte
+	var endian string
+	if i := strings.IndexByte(s, 'X'); i != -1 {
+		// Repeated elements are not supported.
+		return f, fmt.Errorf("iio: unsupported scan format %q", s)
+	}
+	if _, err := fmt.Sscanf(s, "%2s:%c%d/%d>>%d", &endian, &sign, &f.bits, &f.storage, &f.shift); err != nil {
+		return f, fmt.Errorf("iio: invalid scan format %q: %v", s, err)
+	}
+	switch endian {
+	case "be":
+		f.bigEndian = true
+	case "le":
+	default:
+		return f, fmt.Errorf("iio: invalid scan format %q", s)
+	}
+	switch sign {
+	case 's':
+		f.signed = true
+	case 'u':
+	default:
+		return f, fmt.Errorf("iio: invalid scan format %q", s)
+	}
+	if f.storage != 8 && f.storage != 16 && f.storage != 32 && f.storage != 64 || f.bits == 0 || f.bits+f.shift > f.storage {
+		return f, fmt.Errorf("iio: invalid scan format %q", s)
+	}
+	return f, nil
+}
+
+// size returns the number of bytes used by the value in a scan.
+func (f *scanFormat) size() int {
+	return int(f.storage / 8)
+}
+
+// decode decodes the value stored in b, which must be f.size() long.
+func (f *scanFormat) decode(b []byte) int64 {
+	var v uint64
+	for i := range b {
+		if f.bigEndian {
+			v = v<<8 | uint64(b[i])
+		} else {
+			v |= uint64(b[i]) << (8 * uint(i))
+		}
+	}
+	v >>= f.shift
+	if f.bits < 64 {
+		v &= 1<<f.bits - 1
+		if f.signed && v&(1<<(f.bits-1)) != 0 {
+			return int64(v) - 1<<f.bits
+		}
+	}
+	return int64(v)
+}
+
+// sysfsRoot is the directory containing the IIO devices and triggers.
+var sysfsRoot = "/sys/bus/iio/devices/"
+
+// devRoot is the directory containing the IIO character devices.
+var devRoot = "/dev/"
+
+func readString(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("iio: %v", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func writeString(path, v string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return fmt.Errorf("iio: %v", err)
+	}
+	_, err = f.Write([]byte(v))
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return fmt.Errorf("iio: %v", err)
+	}
+	return nil
+}
+
+// readFloat reads a float in path, or returns def if the file doesn't exist.
+func readFloat(path string, def float64) (float64, error) {
+	s, err := readString(path)
+	if err != nil {
+		if _, err2 := os.Stat(path); os.IsNotExist(err2) {
+			return def, nil
+		}
+		return 0, err
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("iio: %s: %v", path, err)
+	}
+	return v, nil
+}
+
+// parseDevice parses the device at root, e.g.
+// /sys/bus/iio/devices/iio:device0/.
+func parseDevice(root string) (*Device, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(root), "iio:device"))
+	if err != nil {
+		return nil, fmt.Errorf("iio: invalid device %s: %v", root, err)
+	}
+	d := &Device{number: n, root: root}
+	if d.name, err = readString(root + "name"); err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("iio: %v", err)
+	}
+	seen := map[string]*Channel{}
+	for _, e := range entries {
+		output, id, file := parseChannelFile(e.Name())
+		if id == "" {
+			continue
+		}
+		key := e.Name()[:len(e.Name())-len(file)-1]
+		if c, ok := seen[key]; ok {
+			// Prefer the processed value when both are present.
+			if file == "input" {
+				c.file = file
+			}
+			continue
+		}
+		c := &Channel{dev: d, id: id, output: output, file: file, scanIndex: -1}
+		c.typ, c.index = parseChannelID(id)
+		seen[key] = c
+		d.channels = append(d.channels, c)
+	}
+	// Channels that can only be captured, like the timestamp.
+	if entries, err := ioutil.ReadDir(root + "scan_elements"); err == nil {
+		for _, e := range entries {
+			name := e.Name()
+			if !strings.HasPrefix(name, "in_") || !strings.HasSuffix(name, "_index") {
+				continue
+			}
+			key := name[:len(name)-len("_index")]
+			if _, ok := seen[key]; !ok {
+				c := &Channel{dev: d, id: key[len("in_"):], scanIndex: -1}
+				c.typ, c.index = parseChannelID(c.id)
+				seen[key] = c
+				d.channels = append(d.channels, c)
+			}
+		}
+	}
+	for _, c := range d.channels {
+		if err := c.parseAttributes(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(d.channels, func(i, j int) bool {
+		a, b := d.channels[i], d.channels[j]
+		if a.output != b.output {
+			return b.output
+		}
+		if a.typ != b.typ {
+			return a.typ < b.typ
+		}
+		if a.index != b.index {
+			return a.index < b.index
+		}
+		return a.id < b.id
+	})
+	return d, nil
+}
+
+// parseAttributes reads the scale, the offset and the scan element
+// description of the channel.
+func (c *Channel) parseAttributes() error {
+	prefix := "in_"
+	if c.output {
+		prefix = "out_"
+	}
+	c.scale = 1
+	if c.file == "raw" {
+		// The attribute may be specific to the channel or shared by all the
+		// channels of the same type.
+		var err error
+		for _, n := range []string{c.id, c.typ} {
+			if c.scale, err = readFloat(c.dev.root+prefix+n+"_scale", 0); err != nil {
+				return err
+			} else if c.scale != 0 {
+				break
+			}
+		}
+		if c.scale == 0 {
+			c.scale = 1
+		}
+		for _, n := range []string{c.id, c.typ} {
+			if c.offset, err = readFloat(c.dev.root+prefix+n+"_offset", 0); err != nil {
+				return err
+			} else if c.offset != 0 {
+				break
+			}
+		}
+	}
+	if c.output {
+		return nil
+	}
+	base := c.dev.root + "scan_elements/" + prefix + c.id
+	s, err := readString(base + "_index")
+	if err != nil {
+		// Not supported by the device.
+		return nil
+	}
+	if c.scanIndex, err = strconv.Atoi(s); err != nil {
+		return fmt.Errorf("iio: %s: %v", c, err)
+	}
+	if s, err = readString(base + "_type"); err != nil {
+		return err
+	}
+	c.scanFormat, err = parseScanFormat(s)
+	return err
+}
+
+// parseChannelFile parses a sysfs attribute name like "in_voltage0_raw".
+//
+// Returns an empty id if the file is not the value of a channel.
+func parseChannelFile(name string) (bool, string, string) {
+	output := false
+	switch {
+	case strings.HasPrefix(name, "in_"):
+		name = name[len("in_"):]
+	case strings.HasPrefix(name, "out_"):
+		name = name[len("out_"):]
+		output = true
+	default:
+		return false, "", ""
+	}
+	for _, file := range []string{"raw", "input"} {
+		if strings.HasSuffix(name, "_"+file) {
+			return output, name[:len(name)-len(file)-1], file
+		}
+	}
+	return false, "", ""
+}
+
+// parseChannelID parses a channel id like "voltage0" into its type and index.
+func parseChannelID(id string) (string, int) {
+	i := 0
+	for i < len(id) && id[i] >= 'a' && id[i] <= 'z' {
+		i++
+	}
+	typ := id[:i]
+	j := i
+	for j < len(id) && id[j] >= '0' && id[j] <= '9' {
+		j++
+	}
+	if j == i {
+		return typ, -1
+	}
+	n, _ := strconv.Atoi(id[i:j])
+	return typ, n
+}
+
+// driver implements periph.Driver.
+type driver struct {
+}
+
+func (d *driver) String() string {
+	return "iio"
+}
+
+func (d *driver) Prerequisites() []string {
+	return nil
+}
+
+func (d *driver) After() []string {
+	return nil
+}
+
+// Init enumerates the IIO devices and triggers.
+func (d *driver) Init() (bool, error) {
+	items, err := filepath.Glob(sysfsRoot + "iio:device*")
+	if err != nil {
+		return true, err
+	}
+	if len(items) == 0 {
+		return false, errors.New("iio: no device found")
+	}
+	for _, item := range items {
+		dev, err := parseDevice(item + "/")
+		if err != nil {
+			return true, err
+		}
+		Devices = append(Devices, dev)
+	}
+	sort.Slice(Devices, func(i, j int) bool {
+		return Devices[i].number < Devices[j].number
+	})
+	triggers, err := filepath.Glob(sysfsRoot + "trigger*")
+	if err != nil {
+		return true, err
+	}
+	sort.Strings(triggers)
+	for _, t := range triggers {
+		name, err := readString(t + "/name")
+		if err != nil {
+			return true, err
+		}
+		Triggers = append(Triggers, name)
+	}
+	return true, nil
+}
+
+func init() {
+	if isLinux {
+		periph.MustRegister(&drv)
+	}
+}
+
+var drv driver
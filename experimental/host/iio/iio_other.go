@@ -2,8 +2,8 @@
 // Use of this source code is governed under the Apache License, Version 2.0
 // that can be found in the LICENSE file.
 
-// +build windows
+// +build !linux
 
-package serial
+package iio
 
-const isWindows = true
+const isLinux = false
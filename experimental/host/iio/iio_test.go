@@ -0,0 +1,401 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package iio
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/pin"
+	"github.com/meandrewdev/periph/experimental/conn/analog"
+)
+
+func TestDriver(t *testing.T) {
+	defer reset()
+	fakeSysfs(t)
+	if ok, err := drv.Init(); !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	if len(Devices) != 2 {
+		t.Fatal(Devices)
+	}
+	if !reflect.DeepEqual(Triggers, []string{"trig0"}) {
+		t.Fatal(Triggers)
+	}
+	d, err := DeviceByName("ads1015")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := d.String(); s != "iio:device0" {
+		t.Fatal(s)
+	}
+	if d2, err := DeviceByName("iio:device1"); err != nil || d2.Name() != "bme280" {
+		t.Fatal(d2, err)
+	}
+	if _, err := DeviceByName("foo"); err == nil {
+		t.Fatal("invalid name")
+	}
+	var names []string
+	for _, c := range d.Channels() {
+		names = append(names, c.Name())
+	}
+	expected := []string{"in_timestamp", "in_voltage0", "in_voltage1", "out_voltage0"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatal(names)
+	}
+	c, err := d.Channel("in_voltage1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Type() != "voltage" || c.Index() != 1 || c.Output() || c.Scale() != 2 || c.Offset() != 0 || c.Device() != d {
+		t.Fatal(c)
+	}
+	if _, err := d.Channel("in_accel_x"); err == nil {
+		t.Fatal("invalid channel")
+	}
+}
+
+func TestADC(t *testing.T) {
+	defer reset()
+	d := fakeDevice(t)
+	c, _ := d.Channel("in_voltage0")
+	p, err := c.ADC()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := p.String(); s != "iio:device0/in_voltage0" {
+		t.Fatal(s)
+	}
+	s, err := p.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (1000 + 10) * 0.5mV
+	if s.Raw != 1000 || s.V != 505*physic.MilliVolt {
+		t.Fatal(s)
+	}
+	min, max := p.Range()
+	if min.Raw != -2048 || max.Raw != 2047 || max.V != 1028500*physic.MicroVolt {
+		t.Fatal(min, max)
+	}
+	if _, err := c.DAC(); err == nil {
+		t.Fatal("not a DAC")
+	}
+	ts, _ := d.Channel("in_timestamp")
+	if _, err := ts.ADC(); err == nil {
+		t.Fatal("not an ADC")
+	}
+	if _, err := ts.Read(); err == nil {
+		t.Fatal("can only be captured")
+	}
+}
+
+func TestDAC(t *testing.T) {
+	defer reset()
+	d := fakeDevice(t)
+	c, _ := d.Channel("out_voltage0")
+	p, err := c.DAC()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := p.(pin.PinFunc).Func(); f != analog.DAC {
+		t.Fatal(f)
+	}
+	if err := p.Out(42); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, d.root+"out_voltage0_raw"); s != "42" {
+		t.Fatal(s)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	defer reset()
+	root := fakeSysfs(t)
+	d, err := parseDevice(root + "iio:device1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := d.Env()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := physic.Env{}
+	if err := s.Sense(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Temperature != 21500*physic.MilliKelvin+physic.ZeroCelsius {
+		t.Fatal(e.Temperature)
+	}
+	if e.Pressure != 101325*physic.Pascal {
+		t.Fatal(e.Pressure)
+	}
+	if e.Humidity != 45*physic.PercentRH {
+		t.Fatal(e.Humidity)
+	}
+	s.Precision(&e)
+	if e.Temperature != physic.MilliKelvin || e.Pressure != physic.KiloPascal {
+		t.Fatal(e)
+	}
+
+	c1, err := s.SenseContinuous(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := <-c1; e.Pressure != 101325*physic.Pascal {
+		t.Fatal(e.Pressure)
+	}
+	// Calling again restarts the sensing.
+	c2, err := s.SenseContinuous(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-c1; ok {
+		t.Fatal("expected the first channel to be closed")
+	}
+	// Halt unblocks the goroutine waiting to send.
+	if err := s.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-c2; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if a, err := fakeDevice(t).Env(); err == nil {
+		t.Fatal(a)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	defer reset()
+	d := fakeDevice(t)
+	v0, _ := d.Channel("in_voltage0")
+	v1, _ := d.Channel("in_voltage1")
+	ts, _ := d.Channel("in_timestamp")
+	out, _ := d.Channel("out_voltage0")
+	if _, err := d.Capture(nil, "", 1); err == nil {
+		t.Fatal("no channel")
+	}
+	if _, err := d.Capture([]*Channel{out}, "", 1); err == nil {
+		t.Fatal("can't capture an output")
+	}
+	// Two scans of voltage1, voltage0 and the timestamp. voltage0 is at index
+	// 0, voltage1 at index 1, they are followed by 4 bytes of padding and the
+	// timestamp.
+	scans := []byte{
+		0x10, 0x00, 0xF0, 0xFF, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0,
+		0x20, 0x00, 0x30, 0x00, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0,
+	}
+	if err := ioutil.WriteFile(devRoot+"iio:device0", scans, 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := d.Capture([]*Channel{v1, v0, ts}, "trig0", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Capture([]*Channel{v0}, "", 1); err == nil {
+		t.Fatal("already capturing")
+	}
+	if s := readFile(t, d.root+"buffer/enable"); s != "1" {
+		t.Fatal(s)
+	}
+	if s := readFile(t, d.root+"trigger/current_trigger"); s != "trig0" {
+		t.Fatal(s)
+	}
+	if s := readFile(t, d.root+"scan_elements/in_voltage1_en"); s != "1" {
+		t.Fatal(s)
+	}
+	got, err := c.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Values are shifted right by 4 and sign extended from 12 bits.
+	expected := [][]int64{{-1, 1, 1}, {3, 2, 2}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatal(got)
+	}
+	if err := d.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, d.root+"buffer/enable"); s != "0" {
+		t.Fatal(s)
+	}
+	if s := readFile(t, d.root+"scan_elements/in_voltage1_en"); s != "0" {
+		t.Fatal(s)
+	}
+	if _, err := c.Read(); err == nil {
+		t.Fatal("halted")
+	}
+}
+
+func TestCapture_unwind(t *testing.T) {
+	defer reset()
+	d := fakeDevice(t)
+	v0, _ := d.Channel("in_voltage0")
+	v1, _ := d.Channel("in_voltage1")
+	// The character device doesn't exist, so the capture fails once the scan
+	// elements and the buffer are enabled.
+	if _, err := d.Capture([]*Channel{v0, v1}, "trig0", 4); err == nil {
+		t.Fatal("no character device")
+	}
+	if s := readFile(t, d.root+"buffer/enable"); s != "0" {
+		t.Fatal(s)
+	}
+	for _, n := range []string{"in_voltage0_en", "in_voltage1_en"} {
+		if s := readFile(t, d.root+"scan_elements/"+n); s != "0" {
+			t.Fatal(n, s)
+		}
+	}
+	// The device is not left capturing.
+	if err := ioutil.WriteFile(devRoot+"iio:device0", nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := d.Capture([]*Channel{v0}, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Halt(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseScanFormat(t *testing.T) {
+	data := []struct {
+		in       string
+		b        []byte
+		expected int64
+	}{
+		{"le:s12/16>>4", []byte{0xF0, 0xFF}, -1},
+		{"be:u12/16>>4", []byte{0x12, 0x30}, 0x123},
+		{"le:u8/8>>0", []byte{0xFF}, 255},
+		{"le:s64/64>>0", []byte{0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, -2},
+		{"be:s24/32>>8", []byte{0x80, 0x00, 0x00, 0x00}, -8388608},
+	}
+	for i, line := range data {
+		f, err := parseScanFormat(line.in)
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if len(line.b) != f.size() {
+			t.Fatal(i, f.size())
+		}
+		if v := f.decode(line.b); v != line.expected {
+			t.Fatal(i, v)
+		}
+	}
+	for _, s := range []string{"", "xx:s12/16>>4", "le:x12/16>>4", "le:s12/12>>0", "le:s12/16>>8", "le:s12/16X2>>4"} {
+		if _, err := parseScanFormat(s); err == nil {
+			t.Fatal(s)
+		}
+	}
+}
+
+func TestParseChannelFile(t *testing.T) {
+	data := []struct {
+		in     string
+		output bool
+		id     string
+		file   string
+		typ    string
+		index  int
+	}{
+		{"in_voltage0_raw", false, "voltage0", "raw", "voltage", 0},
+		{"out_voltage12_raw", true, "voltage12", "raw", "voltage", 12},
+		{"in_temp_input", false, "temp", "input", "temp", -1},
+		{"in_accel_x_raw", false, "accel_x", "raw", "accel", -1},
+		{"in_voltage0-voltage1_raw", false, "voltage0-voltage1", "raw", "voltage", 0},
+		{"in_voltage_scale", false, "", "", "", -1},
+		{"name", false, "", "", "", -1},
+	}
+	for i, line := range data {
+		output, id, file := parseChannelFile(line.in)
+		if output != line.output || id != line.id || file != line.file {
+			t.Fatal(i, output, id, file)
+		}
+		if id == "" {
+			continue
+		}
+		if typ, index := parseChannelID(id); typ != line.typ || index != line.index {
+			t.Fatal(i, typ, index)
+		}
+	}
+}
+
+//
+
+func reset() {
+	Devices = nil
+	Triggers = nil
+	sysfsRoot = "/sys/bus/iio/devices/"
+	devRoot = "/dev/"
+}
+
+// fakeSysfs creates an ADC with a DAC output at iio:device0, an
+// environmental sensor at iio:device1 and a trigger in a temporary directory.
+func fakeSysfs(t *testing.T) string {
+	tmp := t.TempDir()
+	sysfsRoot = filepath.Join(tmp, "sys") + "/"
+	devRoot = filepath.Join(tmp, "dev") + "/"
+	files := map[string]string{
+		"iio:device0/name":                             "ads1015\n",
+		"iio:device0/in_voltage0_raw":                  "1000\n",
+		"iio:device0/in_voltage0_scale":                "0.5\n",
+		"iio:device0/in_voltage0_offset":               "10\n",
+		"iio:device0/in_voltage1_raw":                  "3\n",
+		"iio:device0/in_voltage_scale":                 "2\n",
+		"iio:device0/out_voltage0_raw":                 "0\n",
+		"iio:device0/buffer/enable":                    "0\n",
+		"iio:device0/buffer/length":                    "0\n",
+		"iio:device0/trigger/current_trigger":          "\n",
+		"iio:device0/scan_elements/in_voltage0_en":     "0\n",
+		"iio:device0/scan_elements/in_voltage0_index":  "0\n",
+		"iio:device0/scan_elements/in_voltage0_type":   "le:s12/16>>4\n",
+		"iio:device0/scan_elements/in_voltage1_en":     "0\n",
+		"iio:device0/scan_elements/in_voltage1_index":  "1\n",
+		"iio:device0/scan_elements/in_voltage1_type":   "le:s12/16>>4\n",
+		"iio:device0/scan_elements/in_timestamp_en":    "0\n",
+		"iio:device0/scan_elements/in_timestamp_index": "2\n",
+		"iio:device0/scan_elements/in_timestamp_type":  "le:s64/64>>0\n",
+		"iio:device1/name":                             "bme280\n",
+		"iio:device1/in_temp_input":                    "21500\n",
+		"iio:device1/in_pressure_input":                "101.325\n",
+		"iio:device1/in_humidityrelative_input":        "45000\n",
+		"trigger0/name":                                "trig0\n",
+	}
+	for name, v := range files {
+		p := filepath.Join(sysfsRoot, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(v), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(devRoot, 0700); err != nil {
+		t.Fatal(err)
+	}
+	return sysfsRoot
+}
+
+// fakeDevice returns the ADC created by fakeSysfs.
+func fakeDevice(t *testing.T) *Device {
+	d, err := parseDevice(fakeSysfs(t) + "iio:device0/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func readFile(t *testing.T, path string) string {
+	s, err := readString(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
@@ -26,7 +26,7 @@ func (d driverGPIO) Prerequisites() []string {
 }
 
 func (d driverGPIO) After() []string {
-	return []string{"sysfs-gpio"}
+	return []string{"sysfs-gpio", "sysfs-gpiochip"}
 }
 
 func (d *driverGPIO) Init() (bool, error) {
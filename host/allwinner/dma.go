@@ -3,8 +3,8 @@
 // that can be found in the LICENSE file.
 
 // Unlike the bcm283x, the allwinner CPUs do not have a "clear bit" and "set
-// bit" registers, they only have the data register. Also, allwinner CPUs do
-// not support linked lists of DMA buffers. On the other hand, the Allwinner DMA
+// bit" registers, they only have the data register. Also, the R8 does not
+// support linked lists of DMA buffers. On the other hand, the Allwinner DMA
 // controller supports 8 bits transfers instead of 32-128 bits that the bcm283x
 // DMA controller supports.
 //
@@ -18,10 +18,13 @@ package allwinner
 import (
 	"errors"
 	"fmt"
-	"log"
+	"math"
 	"os"
+	"time"
 
 	"github.com/meandrewdev/periph"
+	"github.com/meandrewdev/periph/conn/gpio/gpiostream"
+	"github.com/meandrewdev/periph/conn/physic"
 	"github.com/meandrewdev/periph/host/pmem"
 )
 
@@ -53,6 +56,172 @@ func (d *dmaMap) getDedicated() int {
 	return -1
 }
 
+// transfer copies l bytes from srcAddr to dstAddr with a dedicated DMA
+// channel and waits for its completion.
+//
+// The source and destination wait the same number of clock cycles.
+func (d *dmaMap) transfer(srcAddr, dstAddr, l uint32, srcIO, dstIO bool, wait uint8, timeout time.Duration) error {
+	n := d.getDedicated()
+	if n == -1 {
+		return errors.New("no DMA channel available")
+	}
+	d.irqEn &^= 3 << uint(2*n+16)
+	d.irqPendStas = 3 << uint(2*n+16)
+	ch := &d.dedicated[n]
+	defer func() {
+		_ = ch.release()
+	}()
+	// The block sizes are left to 0, which means 1 byte.
+	param := ddmaR8Param(wait)<<16 | ddmaR8Param(wait)
+	if err := ch.set(srcAddr, dstAddr, l, srcIO, dstIO, ddmaDstDrqSDRAM|ddmaSrcDrqSDRAM, param); err != nil {
+		return err
+	}
+	return waitDMA(func() bool { return ch.cfg&ddmaBusy == 0 }, timeout)
+}
+
+// dmaA64Map represents the DMA memory mapped CPU registers on the A64.
+//
+// Unlike the R8, the DMA controller has no normal and dedicated channels;
+// each channel fetches a linked list of descriptors from memory.
+//
+// A64: Page 198-209.
+type dmaA64Map struct {
+	irqEn     dmaA64Irq                  // 0x00 DMA_IRQ_EN_REG
+	reserved0 [3]uint32                  // 0x04
+	irqPend   dmaA64PendingIrq           // 0x10 DMA_IRQ_PEND_REG
+	reserved1 [5]uint32                  // 0x14
+	autoGate  uint32                     // 0x28 DMA_AUTO_GATE_REG
+	reserved2 uint32                     // 0x2C
+	status    uint32                     // 0x30 DMA_STA_REG; one bit per busy channel
+	reserved3 [(0x100 - 0x34) / 4]uint32 //
+	channels  [8]dmaA64Channel           // 0x100 DMA_EN_REG ...
+}
+
+// getChannel returns a channel that is neither enabled nor busy, or -1.
+func (d *dmaA64Map) getChannel() int {
+	for i := len(d.channels) - 1; i >= 0; i-- {
+		if d.channels[i].enable == 0 && d.status&(1<<uint(i)) == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// transfer copies l bytes from srcAddr to dstAddr with a single descriptor and
+// waits for its completion.
+func (d *dmaA64Map) transfer(srcAddr, dstAddr, l uint32, srcIO, dstIO bool, wait uint8, timeout time.Duration) error {
+	n := d.getChannel()
+	if n == -1 {
+		return errors.New("no DMA channel available")
+	}
+	buf, err := drvDMA.dmaBufAllocator(4096)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	var desc *dmaA64Desc
+	if err := buf.AsPOD(&desc); err != nil {
+		return err
+	}
+	// All these have value 0. This statement only exist for documentation.
+	cfg := dmaA64DstWidth8 | dmaA64DstBurst1 | dmaA64DstLinear | dmaA64SrcWidth8 | dmaA64SrcBurst1 | dmaA64SrcLinear
+	cfg |= dmaA64DstDrqSDRAM | dmaA64SrcDrqSDRAM
+	if srcIO {
+		cfg |= dmaA64SrcIOMode
+	}
+	if dstIO {
+		cfg |= dmaA64DstIOMode
+	}
+	*desc = dmaA64Desc{
+		cfg:         cfg,
+		srcAddr:     srcAddr,
+		dstAddr:     dstAddr,
+		byteCounter: l,
+		param:       uint32(wait),
+		next:        dmaA64LastDesc,
+	}
+	// The interrupts are left disabled and the pending bits are cleared by
+	// writing 1 to them.
+	d.irqEn &^= 7 << uint(4*n)
+	d.irqPend = 7 << uint(4*n)
+	ch := &d.channels[n]
+	ch.descAddr = uint32(buf.PhysAddr())
+	ch.enable = 1
+	defer func() {
+		ch.enable = 0
+	}()
+	busy := false
+	return waitDMA(func() bool { return d.transferDone(n, &busy) }, timeout)
+}
+
+// transferDone returns true once channel n completed its transfer.
+//
+// The package and queue end pending bits are set when the descriptor was
+// processed. DMA_STA_REG can't be used alone since the channel may still be
+// reported idle right after it is enabled, so it is only used once the
+// channel was seen busy. This covers the kernel DMA driver clearing the
+// pending bits of all channels in its interrupt handler.
+func (d *dmaA64Map) transferDone(n int, busy *bool) bool {
+	if d.irqPend&((dma0PackageEndIrqPend|dma0QueueEndIrqPend)<<uint(4*n)) != 0 {
+		return true
+	}
+	if d.status&(1<<uint(n)) != 0 {
+		*busy = true
+		return false
+	}
+	return *busy
+}
+
+// dmaA64Channel is the control registers of one DMA channel.
+type dmaA64Channel struct {
+	enable    uint32    // 0x00 DMA_EN_REG
+	pause     uint32    // 0x04 DMA_PAU_REG
+	descAddr  uint32    // 0x08 DMA_DESC_ADDR_REG
+	cfg       dmaA64Cfg // 0x0C DMA_CFG_REG (read only)
+	curSrc    uint32    // 0x10 DMA_CUR_SRC_REG
+	curDst    uint32    // 0x14 DMA_CUR_DEST_REG
+	bcntLeft  uint32    // 0x18 DMA_BCNT_LEFT_REG
+	param     uint32    // 0x1C DMA_PARA_REG (read only)
+	reserved0 [2]uint32 //
+	mode      uint32    // 0x28 DMA_MODE_REG
+	fdescAddr uint32    // 0x2C DMA_FDESC_ADDR_REG
+	pkgNum    uint32    // 0x30 DMA_PKG_NUM_REG
+	reserved1 [3]uint32 //
+}
+
+// dmaA64Desc is a DMA descriptor, as read by the DMA controller from memory.
+//
+// It must be 4 bytes aligned.
+type dmaA64Desc struct {
+	cfg         dmaA64Cfg // Loaded in DMA_CFG_REG
+	srcAddr     uint32    //
+	dstAddr     uint32    //
+	byteCounter uint32    // 25 bits
+	param       uint32    // Loaded in DMA_PARA_REG; 7:0 WAIT_DATA, number of clock cycles to wait between each transfer
+	next        uint32    // Physical address of the next descriptor or dmaA64LastDesc
+}
+
+// dmaA64LastDesc is the value of dmaA64Desc.next to mark the end of the list.
+const dmaA64LastDesc = 0xFFFFF800
+
+// dmaPoll is the interval at which waitDMA polls the DMA controller.
+const dmaPoll = 50 * time.Microsecond
+
+// waitDMA polls until done returns true or the timeout expires.
+//
+// It sleeps between each poll so it doesn't hog a CPU for the duration of
+// the transfer.
+func waitDMA(done func() bool, timeout time.Duration) error {
+	start := time.Now()
+	for !done() {
+		if time.Since(start) > timeout {
+			return errors.New("timed out waiting for DMA transfer")
+		}
+		time.Sleep(dmaPoll)
+	}
+	return nil
+}
+
 // dmaNormalGroup is the control registers for the first block of 8 DMA
 // controllers.
 //
@@ -102,13 +271,11 @@ func (d *dmaDedicatedGroup) isAvailable() bool {
 	return d.cfg == 0 && d.srcAddr == 0 && d.dstAddr == 0 && d.byteCounter == 0 && d.param == 0
 }
 
-func (d *dmaDedicatedGroup) set(srcAddr, dstAddr, l uint32, srcIO, dstIO bool, src ddmaR8Cfg) {
+func (d *dmaDedicatedGroup) set(srcAddr, dstAddr, l uint32, srcIO, dstIO bool, src ddmaR8Cfg, param ddmaR8Param) error {
 	d.srcAddr = srcAddr
 	d.dstAddr = dstAddr
 	d.byteCounter = l
-	// TODO(maruel): Slow down the clock by another 2*250x
-	//d.param = ddmaR8Param(250 | 250<<16)
-	d.param = ddmaR8Param(1<<24 | 1<<8 | 1)
+	d.param = param
 	// All these have value 0. This statement only exist for documentation.
 	cfg := ddmaDstWidth8 | ddmaDstBurst1 | ddmaDstLinear | ddmaSrcWidth8 | ddmaSrcLinear | ddmaSrcBurst1
 	cfg |= src | ddmaBCRemain
@@ -121,8 +288,9 @@ func (d *dmaDedicatedGroup) set(srcAddr, dstAddr, l uint32, srcIO, dstIO bool, s
 	for i := 0; d.cfg&ddmaLoad != 0 && i < 100000; i++ {
 	}
 	if d.cfg&ddmaLoad != 0 {
-		log.Printf("failed to load DDMA: %# v\n", d)
+		return errors.New("failed to load DDMA")
 	}
+	return nil
 }
 
 func (d *dmaDedicatedGroup) release() error {
@@ -328,6 +496,39 @@ const (
 // R8: Page 134.
 type ddmaR8Param uint32
 
+const (
+	// 31:27 reserved
+	dmaA64DstWidth64  dmaA64Cfg = 3 << 25 // DMA_DEST_DATA_WIDTH
+	dmaA64DstWidth32  dmaA64Cfg = 2 << 25 //
+	dmaA64DstWidth16  dmaA64Cfg = 1 << 25 //
+	dmaA64DstWidth8   dmaA64Cfg = 0 << 25 //
+	dmaA64DstBurst16  dmaA64Cfg = 3 << 22 // DMA_DEST_BST_LEN
+	dmaA64DstBurst8   dmaA64Cfg = 2 << 22 //
+	dmaA64DstBurst4   dmaA64Cfg = 1 << 22 //
+	dmaA64DstBurst1   dmaA64Cfg = 0 << 22 //
+	dmaA64DstIOMode   dmaA64Cfg = 1 << 21 // DMA_ADDR_MODE; Non incrementing
+	dmaA64DstLinear   dmaA64Cfg = 0 << 21 // Normal incrementing position
+	dmaA64DstDrqSRAM  dmaA64Cfg = 0 << 16 // DMA_DEST_DRQ_TYPE
+	dmaA64DstDrqSDRAM dmaA64Cfg = 1 << 16 //
+	// 15:11 reserved
+	dmaA64SrcWidth64  dmaA64Cfg = 3 << 9 // DMA_SRC_DATA_WIDTH
+	dmaA64SrcWidth32  dmaA64Cfg = 2 << 9 //
+	dmaA64SrcWidth16  dmaA64Cfg = 1 << 9 //
+	dmaA64SrcWidth8   dmaA64Cfg = 0 << 9 //
+	dmaA64SrcBurst16  dmaA64Cfg = 3 << 6 // DMA_SRC_BST_LEN
+	dmaA64SrcBurst8   dmaA64Cfg = 2 << 6 //
+	dmaA64SrcBurst4   dmaA64Cfg = 1 << 6 //
+	dmaA64SrcBurst1   dmaA64Cfg = 0 << 6 //
+	dmaA64SrcIOMode   dmaA64Cfg = 1 << 5 // DMA_SRC_ADDR_MODE; Non incrementing
+	dmaA64SrcLinear   dmaA64Cfg = 0 << 5 // Normal incrementing position
+	dmaA64SrcDrqSRAM  dmaA64Cfg = 0 << 0 // DMA_SRC_DRQ_TYPE
+	dmaA64SrcDrqSDRAM dmaA64Cfg = 1 << 0 //
+)
+
+// DMA_CFG_REG
+// A64: Page 205-207.
+type dmaA64Cfg uint32
+
 // smokeTest allocates two physical pages, ask the DMA controller to copy the
 // data from one page to another (with a small offset) and make sure the
 // content is as expected.
@@ -338,46 +539,220 @@ func smokeTest() error {
 	const size = 4096  // 4kb
 	const holeSize = 1 // Minimum DMA alignment.
 
-	alloc := func(s int) (pmem.Mem, error) {
-		return pmem.Alloc(s)
+	copyMem := func(pDst, pSrc uint64) error {
+		return dmaTransfer(uint32(pSrc), uint32(pDst)+holeSize, 4096-2*holeSize, false, false, 0, time.Second)
 	}
 
-	copyMem := func(pDst, pSrc uint64) error {
-		n := drvDMA.dmaMemory.getDedicated()
-		if n == -1 {
-			return errors.New("no channel available")
+	return pmem.TestCopy(size, holeSize, drvDMA.dmaBufAllocator, copyMem)
+}
+
+// dmaTransfer copies l bytes from srcAddr to dstAddr and waits for its
+// completion.
+//
+// srcIO and dstIO disable the address increment, to read from or write to a
+// register. wait is the number of clock cycles to wait between each byte.
+//
+// The transfers are not gated by a DRQ, so they run as fast as the bus and the
+// wait cycles permit.
+func dmaTransfer(srcAddr, dstAddr, l uint32, srcIO, dstIO bool, wait uint8, timeout time.Duration) error {
+	if drvDMA.dmaA64Memory != nil {
+		return drvDMA.dmaA64Memory.transfer(srcAddr, dstAddr, l, srcIO, dstIO, wait, timeout)
+	}
+	if drvDMA.dmaMemory != nil {
+		return drvDMA.dmaMemory.transfer(srcAddr, dstAddr, l, srcIO, dstIO, wait, timeout)
+	}
+	return errors.New("subsystem allwinner-dma not initialized")
+}
+
+// dmaReadStream streams input from a pin.
+//
+// Each sample is a byte read from the byte of the Pn_DAT register that
+// contains the pin.
+func dmaReadStream(p *Pin, b *gpiostream.BitStream) error {
+	skip, wait, err := overSamples(b)
+	if err != nil {
+		return err
+	}
+	l := len(b.Bits) * 8 * skip
+	if l > dmaMaxBytes {
+		return fmt.Errorf("stream is too long(%d bytes)", l)
+	}
+	buf, err := drvDMA.dmaBufAllocator((l + 0xFFF) &^ 0xFFF)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	if err := dmaTransfer(p.dataAddr(), uint32(buf.PhysAddr()), uint32(l), true, false, wait, dmaTimeout(l, wait)); err != nil {
+		return err
+	}
+	bytesToBits(b.Bits, buf.Bytes()[:l], uint(p.offset%8), skip, !b.LSBF)
+	return nil
+}
+
+// dmaWriteStream streams data to a pin, which must be configured as an
+// output.
+//
+// Each sample is a byte written to the byte of the Pn_DAT register that
+// contains the pin. The 7 other pins sharing this byte are written the level
+// they had when the stream started, so it fails if any of them is an output.
+//
+// Memory usage is one byte per sample, multiplied by the oversampling ratio
+// and rounded up to the nearest 4Kb.
+func dmaWriteStream(p *Pin, s gpiostream.Stream) error {
+	if s.Duration() == 0 {
+		return nil
+	}
+	if n := outputNeighbour(p); n != -1 {
+		return fmt.Errorf("P%c%d shares the data register byte and is an output", 'A'+p.group, n)
+	}
+	skip, wait, err := overSamples(s)
+	if err != nil {
+		return err
+	}
+	n, err := streamLen(s)
+	if err != nil {
+		return err
+	}
+	l := n * skip
+	if l > dmaMaxBytes {
+		return fmt.Errorf("stream is too long(%d bytes)", l)
+	}
+	buf, err := drvDMA.dmaBufAllocator((l + 0xFFF) &^ 0xFFF)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	shift := 8 * uint(p.offset/8)
+	mask := byte(1 << uint(p.offset%8))
+	clear := byte(drvGPIO.gpioMemory.groups[p.group].data>>shift) &^ mask
+	if err := raster8(s, skip, buf.Bytes()[:l], clear, clear|mask); err != nil {
+		return err
+	}
+	return dmaTransfer(uint32(buf.PhysAddr()), p.dataAddr(), uint32(l), false, true, wait, dmaTimeout(l, wait))
+}
+
+// outputNeighbour returns the offset of another pin configured as an output in
+// the byte of the Pn_DAT register that contains p, or -1.
+func outputNeighbour(p *Pin) int {
+	cfg := drvGPIO.gpioMemory.groups[p.group].cfg[p.offset/8]
+	for i := 0; i < 8; i++ {
+		n := int(p.offset&^7) + i
+		if n != int(p.offset) && function((cfg>>uint(4*i))&7) == out {
+			return n
 		}
-		drvDMA.dmaMemory.irqEn &^= 3 << uint(2*n+16)
-		drvDMA.dmaMemory.irqPendStas = 3 << uint(2*n+16)
-		ch := &drvDMA.dmaMemory.dedicated[n]
-		defer func() {
-			_ = ch.release()
-		}()
-		ch.set(uint32(pSrc), uint32(pDst)+holeSize, 4096-2*holeSize, false, false, ddmaDstDrqSDRAM|ddmaSrcDrqSDRAM)
-
-		for ch.cfg&ddmaBusy != 0 {
+	}
+	return -1
+}
+
+// overSamples returns the number of DMA transfers per sample of the stream and
+// the number of wait cycles to insert between each transfer.
+//
+// The time taken by a transfer is interpolated from the values measured by
+// calibrate().
+func overSamples(s gpiostream.Stream) (int, uint8, error) {
+	desired := s.Frequency()
+	if desired <= 0 {
+		return 0, 0, fmt.Errorf("invalid frequency(%s)", desired)
+	}
+	if drvDMA.sampleNs[0] <= 0 || drvDMA.sampleNs[1] <= drvDMA.sampleNs[0] {
+		if drvDMA.calibrateErr != nil {
+			return 0, 0, fmt.Errorf("DMA transfer time is not calibrated: %v", drvDMA.calibrateErr)
 		}
-		return nil
+		return 0, 0, errors.New("DMA transfer time is not calibrated")
+	}
+	// In nanoseconds.
+	period := 1e9 * float64(physic.Hertz) / float64(desired)
+	if period < 0.9*drvDMA.sampleNs[0] {
+		return 0, 0, fmt.Errorf("frequency is too high(%s)", desired)
+	}
+	skip := int(math.Ceil(period / drvDMA.sampleNs[1]))
+	w := math.Round((period/float64(skip) - drvDMA.sampleNs[0]) * dmaMaxWait / (drvDMA.sampleNs[1] - drvDMA.sampleNs[0]))
+	if w < 0 {
+		w = 0
+	} else if w > dmaMaxWait {
+		w = dmaMaxWait
+	}
+	wait := uint8(w)
+	actual := float64(skip) * sampleNs(wait)
+	errorPercent := 100 * (actual - period) / period
+	if errorPercent < -10 || errorPercent > 10 {
+		return 0, 0, fmt.Errorf("actual resolution differs more than 10%%(%s vs %s)", desired, physic.Frequency(1e9*float64(physic.Hertz)/actual))
 	}
+	return skip, wait, nil
+}
+
+// sampleNs returns the time in nanoseconds of a single byte transfer with wait
+// clock cycles.
+func sampleNs(wait uint8) float64 {
+	return drvDMA.sampleNs[0] + (drvDMA.sampleNs[1]-drvDMA.sampleNs[0])*float64(wait)/dmaMaxWait
+}
 
-	return pmem.TestCopy(size, holeSize, alloc, copyMem)
+// dmaTimeout returns a generous timeout for the transfer of l bytes.
+func dmaTimeout(l int, wait uint8) time.Duration {
+	return time.Duration(2*float64(l)*sampleNs(wait)) + 100*time.Millisecond
+}
+
+// calibrate measures the time taken by byte transfers from a GPIO register to
+// memory, without wait cycle and with the maximum number of wait cycles.
+//
+// The DMA controller is not paced by a timer, so this is what is used to
+// determine the resolution of streams. It is measured once with the wall
+// clock, so scheduling jitter at load time and bus contention while streaming
+// affect the actual timing of the streams.
+func calibrate() error {
+	const samples = 32768
+	buf, err := drvDMA.dmaBufAllocator(samples)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	// PA_DAT
+	reg := drvGPIO.gpioBaseAddr + 0x10
+	for i, wait := range []uint8{0, dmaMaxWait} {
+		start := time.Now()
+		if err := dmaTransfer(reg, uint32(buf.PhysAddr()), samples, true, false, wait, time.Second); err != nil {
+			return err
+		}
+		drvDMA.sampleNs[i] = float64(time.Since(start)) / samples
+	}
+	if drvDMA.sampleNs[1] <= drvDMA.sampleNs[0] {
+		return fmt.Errorf("DMA wait cycles have no effect(%.1fns vs %.1fns)", drvDMA.sampleNs[0], drvDMA.sampleNs[1])
+	}
+	return nil
 }
 
+const (
+	// dmaMaxWait is the maximum number of wait clock cycles between two
+	// transfers.
+	dmaMaxWait = 255
+	// dmaMaxBytes is the maximum number of bytes in a single transfer.
+	dmaMaxBytes = 1<<24 - 1
+)
+
 // driverDMA implements periph.Driver.
 //
-// It implements much more than the DMA controller, it also exposes the clocks,
-// the PWM and PCM controllers.
+// It implements much more than the DMA controller, it also exposes the clocks
+// and the PCM controller. The PWM controller is handled by driverPWM.
 type driverDMA struct {
-	// dmaMemory is the memory map of the CPU DMA registers.
+	// dmaMemory is the memory map of the CPU DMA registers on the R8.
 	dmaMemory *dmaMap
-	// pwmMemory is the memory map of the CPU PWM registers.
-	pwmMemory *pwmMap
+	// dmaA64Memory is the memory map of the CPU DMA registers on the A64.
+	dmaA64Memory *dmaA64Map
 	// spiMemory is the memory mapping for the spi CPU registers.
 	spiMemory *spiMap
 	// clockMemory is the memory mapping for the clock CPU registers.
 	clockMemory *clockMap
 	// timerMemory is the memory mapping for the timer CPU registers.
 	timerMemory *timerMap
+	// sampleNs is the time in nanoseconds of a byte transfer between a GPIO
+	// register and memory, with 0 and dmaMaxWait wait clock cycles.
+	sampleNs [2]float64
+	// calibrateErr is the error returned by calibrate(), if any. It is
+	// reported by the streams instead of failing the driver.
+	calibrateErr error
+
+	// dmaBufAllocator is overridden for unit testing.
+	dmaBufAllocator func(s int) (pmem.Mem, error)
 }
 
 func (d *driverDMA) String() string {
@@ -393,10 +768,11 @@ func (d *driverDMA) After() []string {
 }
 
 func (d *driverDMA) Init() (bool, error) {
+	d.dmaBufAllocator = func(s int) (pmem.Mem, error) {
+		return pmem.Alloc(s)
+	}
 	// dmaBaseAddr is the physical base address of the DMA registers.
 	var dmaBaseAddr uint32
-	// pwmBaseAddr is the physical base address of the PWM registers.
-	var pwmBaseAddr uint32
 	// spiBaseAddr is the physical base address of the clock registers.
 	var spiBaseAddr uint32
 	// clockBaseAddr is the physical base address of the clock registers.
@@ -406,8 +782,6 @@ func (d *driverDMA) Init() (bool, error) {
 	if IsA64() {
 		// Page 198.
 		dmaBaseAddr = 0x1C02000
-		// Page 194.
-		pwmBaseAddr = 0x1C21400
 		// Page 161.
 		timerBaseAddr = 0x1C20C00
 		// Page 81.
@@ -417,8 +791,6 @@ func (d *driverDMA) Init() (bool, error) {
 	} else if IsR8() {
 		// Page 124.
 		dmaBaseAddr = 0x1C02000
-		// Page 83.
-		pwmBaseAddr = 0x1C20C00 + 0x200
 		// Page 85.
 		timerBaseAddr = 0x1C20C00
 		// Page 57.
@@ -429,23 +801,24 @@ func (d *driverDMA) Init() (bool, error) {
 		// H3
 		// Page 194.
 		//dmaBaseAddr = 0x1C02000
-		// Page 187.
-		//pwmBaseAddr = 0x1C21400
 		// Page 154.
 		//timerBaseAddr = 0x1C20C00
 		return false, errors.New("unsupported CPU architecture")
 	}
 
-	if err := pmem.MapAsPOD(uint64(dmaBaseAddr), &d.dmaMemory); err != nil {
+	var err error
+	if IsA64() {
+		err = pmem.MapAsPOD(uint64(dmaBaseAddr), &d.dmaA64Memory)
+	} else {
+		err = pmem.MapAsPOD(uint64(dmaBaseAddr), &d.dmaMemory)
+	}
+	if err != nil {
 		if os.IsPermission(err) {
 			return true, fmt.Errorf("need more access, try as root: %v", err)
 		}
 		return true, err
 	}
 
-	if err := pmem.MapAsPOD(uint64(pwmBaseAddr), &d.pwmMemory); err != nil {
-		return true, err
-	}
 	if err := pmem.MapAsPOD(uint64(timerBaseAddr), &d.timerMemory); err != nil {
 		return true, err
 	}
@@ -456,7 +829,11 @@ func (d *driverDMA) Init() (bool, error) {
 		return true, err
 	}
 
-	return true, smokeTest()
+	if err := smokeTest(); err != nil {
+		return true, err
+	}
+	d.calibrateErr = calibrate()
+	return true, nil
 }
 
 func (d *driverDMA) Close() error {
@@ -466,7 +843,11 @@ func (d *driverDMA) Close() error {
 
 func init() {
 	if false && isArm {
-		// TODO(maruel): This is intense, wait to be sure it works.
+		// TODO(maruel): This is intense, wait to be sure it works. Neither the
+		// R8 nor the A64 DMA controller has a timer DRQ, so dmaReadStream() and
+		// dmaWriteStream() are only paced by wait cycles calibrated with the
+		// wall clock. They are not exposed as gpiostream.PinIn and
+		// gpiostream.PinOut until the transfers are paced by hardware.
 		periph.MustRegister(&drvDMA)
 	}
 }
@@ -0,0 +1,264 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package allwinner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/gpio/gpiostream"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/host/pmem"
+)
+
+func TestOverSamples(t *testing.T) {
+	defer resetDMA()
+	if _, _, err := overSamples(&gpiostream.BitStream{Freq: physic.MegaHertz}); err == nil {
+		t.Fatal("not calibrated")
+	}
+	drvDMA.calibrateErr = errors.New("wait cycles have no effect")
+	if _, _, err := overSamples(&gpiostream.BitStream{Freq: physic.MegaHertz}); err == nil || err.Error() != "DMA transfer time is not calibrated: wait cycles have no effect" {
+		t.Fatal(err)
+	}
+	// Each wait cycle adds 5ns.
+	drvDMA.sampleNs = [2]float64{100, 1375}
+	data := []struct {
+		freq physic.Frequency
+		skip int
+		wait uint8
+	}{
+		{10 * physic.MegaHertz, 1, 0},
+		{physic.MegaHertz, 1, 180},
+		{500 * physic.KiloHertz, 2, 180},
+		{physic.KiloHertz, 728, 255},
+	}
+	for i, line := range data {
+		skip, wait, err := overSamples(&gpiostream.BitStream{Freq: line.freq})
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if skip != line.skip || wait != line.wait {
+			t.Fatal(i, skip, wait)
+		}
+	}
+	for _, f := range []physic.Frequency{0, 20 * physic.MegaHertz} {
+		if _, _, err := overSamples(&gpiostream.BitStream{Freq: f}); err == nil {
+			t.Fatal(f)
+		}
+	}
+}
+
+func TestDMATransfer(t *testing.T) {
+	defer resetDMA()
+	if err := dmaTransfer(0x1000, 0x2000, 1, false, false, 0, time.Second); err == nil {
+		t.Fatal("not initialized")
+	}
+	// Clearing the pending bits sets them in the fake registers, so the
+	// transfer completes right away.
+	bufs := fakeDMA(nil)
+	if err := dmaTransfer(0x1000, 0x2000, 42, true, false, 10, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	desc := getDesc(t, bufs)
+	expected := dmaA64Desc{
+		cfg:         dmaA64SrcIOMode | dmaA64SrcDrqSDRAM | dmaA64DstDrqSDRAM,
+		srcAddr:     0x1000,
+		dstAddr:     0x2000,
+		byteCounter: 42,
+		param:       10,
+		next:        dmaA64LastDesc,
+	}
+	if *desc != expected {
+		t.Fatalf("%#v", desc)
+	}
+	ch := &drvDMA.dmaA64Memory.channels[7]
+	if ch.descAddr != uint32((*bufs)[0].phys) || ch.enable != 0 {
+		t.Fatal(ch.descAddr, ch.enable)
+	}
+	drvDMA.dmaA64Memory.status = 0xFF
+	if err := dmaTransfer(0x1000, 0x2000, 1, false, false, 0, time.Second); err == nil {
+		t.Fatal("no channel available")
+	}
+
+	// The fake R8 controller never clears the load bit.
+	drvDMA.dmaA64Memory = nil
+	drvDMA.dmaMemory = &dmaMap{}
+	if err := dmaTransfer(0x1000, 0x2000, 1, false, false, 0, time.Second); err == nil {
+		t.Fatal("failed to load")
+	}
+
+	if err := waitDMA(func() bool { return false }, time.Millisecond); err == nil {
+		t.Fatal("timeout")
+	}
+}
+
+func TestDMAA64TransferDone(t *testing.T) {
+	d := &dmaA64Map{}
+	busy := false
+	// The channel may not be reported busy right after it is enabled.
+	if d.transferDone(3, &busy) {
+		t.Fatal("not started yet")
+	}
+	d.status = 1 << 3
+	for i := 0; i < 5; i++ {
+		if d.transferDone(3, &busy) {
+			t.Fatal(i, "busy")
+		}
+	}
+	d.status = 0
+	if !d.transferDone(3, &busy) {
+		t.Fatal("busy then idle")
+	}
+	busy = false
+	d.irqPend = dma0QueueEndIrqPend << 12
+	if !d.transferDone(3, &busy) {
+		t.Fatal("queue end")
+	}
+	d.irqPend = dma0QueueEndIrqPend << 8
+	if d.transferDone(3, &busy) {
+		t.Fatal("other channel")
+	}
+}
+
+func TestDMAWriteStream(t *testing.T) {
+	defer resetDMA()
+	bufs := fakeDMA(nil)
+	p := &Pin{group: 1, offset: 10, name: "PB10", available: true}
+	if err := p.Out(gpio.Low); err != nil {
+		t.Fatal(err)
+	}
+	// PB8 and PB9 are inputs, their data bits are kept as is.
+	drvGPIO.gpioMemory.groups[1].data = 0x700
+	b := &gpiostream.BitStream{Bits: []byte{0x05}, Freq: physic.MegaHertz, LSBF: true}
+	if err := dmaWriteStream(p, b); err != nil {
+		t.Fatal(err)
+	}
+	if len(*bufs) != 2 {
+		t.Fatal(len(*bufs))
+	}
+	expected := []byte{7, 3, 7, 3, 3, 3, 3, 3, 0}
+	if s := (*bufs)[0].Bytes()[:len(expected)]; string(s) != string(expected) {
+		t.Fatal(s)
+	}
+	desc := getDesc(t, bufs)
+	if desc.srcAddr != uint32((*bufs)[0].phys) || desc.dstAddr != 0x1C20800+0x24+0x10+1 || desc.byteCounter != 8 || desc.param != 180 {
+		t.Fatalf("%#v", desc)
+	}
+	if desc.cfg&dmaA64DstIOMode == 0 {
+		t.Fatal(desc.cfg)
+	}
+
+	// Empty stream.
+	if err := dmaWriteStream(p, &gpiostream.BitStream{Freq: physic.MegaHertz}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*bufs) != 2 {
+		t.Fatal(len(*bufs))
+	}
+	if err := dmaWriteStream(p, &gpiostream.Program{Parts: []gpiostream.Stream{b}, Loops: -1}); err == nil {
+		t.Fatal("infinite Program")
+	}
+	// PB15 is an output in the same byte; writing the byte would revert it.
+	pb15 := &Pin{group: 1, offset: 15, name: "PB15", available: true}
+	if err := pb15.Out(gpio.High); err != nil {
+		t.Fatal(err)
+	}
+	if err := dmaWriteStream(p, b); err == nil || err.Error() != "PB15 shares the data register byte and is an output" {
+		t.Fatal(err)
+	}
+	if len(*bufs) != 2 {
+		t.Fatal(len(*bufs))
+	}
+	// PB16 is in the next byte.
+	if err := pb15.In(gpio.PullNoChange, gpio.NoEdge); err != nil {
+		t.Fatal(err)
+	}
+	pb16 := &Pin{group: 1, offset: 16, name: "PB16", available: true}
+	if err := pb16.Out(gpio.High); err != nil {
+		t.Fatal(err)
+	}
+	if err := dmaWriteStream(p, b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDMAReadStream(t *testing.T) {
+	defer resetDMA()
+	bufs := fakeDMA(func(b []byte) {
+		for i := range b {
+			b[i] = byte(i)
+		}
+	})
+	p := &Pin{group: 1, offset: 10, name: "PB10", available: true}
+	b := &gpiostream.BitStream{Bits: make([]byte, 1), Freq: 500 * physic.KiloHertz, LSBF: true}
+	if err := dmaReadStream(p, b); err != nil {
+		t.Fatal(err)
+	}
+	// Each other sample is read, it is the bit 2 of the index of the byte.
+	if b.Bits[0] != 0xCC {
+		t.Fatalf("0x%02x", b.Bits[0])
+	}
+	desc := getDesc(t, bufs)
+	if desc.srcAddr != 0x1C20800+0x24+0x10+1 || desc.dstAddr != uint32((*bufs)[0].phys) || desc.byteCounter != 16 {
+		t.Fatalf("%#v", desc)
+	}
+	if desc.cfg&dmaA64SrcIOMode == 0 {
+		t.Fatal(desc.cfg)
+	}
+	if err := dmaReadStream(p, &gpiostream.BitStream{Bits: make([]byte, 1), Freq: 20 * physic.MegaHertz}); err == nil {
+		t.Fatal("frequency is too high")
+	}
+}
+
+//
+
+type fakeMem struct {
+	pmem.Slice
+	phys uint64
+}
+
+func (f *fakeMem) Close() error {
+	return nil
+}
+
+func (f *fakeMem) PhysAddr() uint64 {
+	return f.phys
+}
+
+// fakeDMA sets up fake GPIO and A64 DMA registers and records the buffers
+// allocated. init is called on each buffer allocated if not nil.
+func fakeDMA(init func(b []byte)) *[]*fakeMem {
+	drvGPIO.gpioMemory = &gpioMap{}
+	drvGPIO.gpioBaseAddr = 0x1C20800
+	drvDMA.dmaA64Memory = &dmaA64Map{}
+	drvDMA.sampleNs = [2]float64{100, 1375}
+	bufs := &[]*fakeMem{}
+	drvDMA.dmaBufAllocator = func(s int) (pmem.Mem, error) {
+		m := &fakeMem{Slice: make(pmem.Slice, s), phys: uint64(0x10000 * (len(*bufs) + 1))}
+		if init != nil {
+			init(m.Slice)
+		}
+		*bufs = append(*bufs, m)
+		return m, nil
+	}
+	return bufs
+}
+
+// getDesc returns the last descriptor written in the DMA buffers.
+func getDesc(t *testing.T, bufs *[]*fakeMem) *dmaA64Desc {
+	var desc *dmaA64Desc
+	if err := (*bufs)[len(*bufs)-1].AsPOD(&desc); err != nil {
+		t.Fatal(err)
+	}
+	return desc
+}
+
+func resetDMA() {
+	drvGPIO.gpioMemory = nil
+	drvGPIO.gpioBaseAddr = 0
+	drvDMA = driverDMA{}
+}
@@ -56,13 +56,17 @@ type Pin struct {
 	defaultPull gpio.Pull // default pull at startup
 
 	// Immutable after driver initialization.
-	altFunc     [5]pin.Func // alternate functions
-	sysfsPin    *sysfs.Pin  // Set to the corresponding sysfs.Pin, if any.
-	available   bool        // Set when the pin is available on this CPU architecture.
-	supportEdge bool        // Set when the pin supports interrupt based edge detection.
+	altFunc     [5]pin.Func     // alternate functions
+	sysfsPin    *sysfs.Pin      // Set to the corresponding sysfs.Pin, if any.
+	gpioLine    *sysfs.GPIOLine // Set to the corresponding sysfs.GPIOLine, if any.
+	available   bool            // Set when the pin is available on this CPU architecture.
+	supportEdge bool            // Set when the pin supports interrupt based edge detection.
 
 	// Mutable.
-	usingEdge bool // Set when edge detection is enabled.
+	edgePin  sysfs.EdgePin    // Set when edge detection is enabled.
+	usingPWM bool             // Set when the PWM channel of the pin is enabled.
+	pwmDuty  gpio.Duty        // Duty actually output by the PWM channel.
+	pwmFreq  physic.Frequency // Frequency actually output by the PWM channel.
 }
 
 // String implements conn.Resource.
@@ -74,14 +78,15 @@ func (p *Pin) String() string {
 
 // Halt implements conn.Resource.
 //
-// It stops edge detection if enabled.
+// It stops edge detection and PWM if enabled.
 func (p *Pin) Halt() error {
-	if p.usingEdge {
-		if err := p.sysfsPin.Halt(); err != nil {
+	if p.edgePin != nil {
+		if err := p.edgePin.Halt(); err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = false
+		p.edgePin = nil
 	}
+	p.haltPWM()
 	return nil
 }
 
@@ -232,9 +237,10 @@ func (p *Pin) SetFunc(f pin.Func) error {
 //
 // Not all pins support edge detection on Allwinner processors!
 //
-// Edge detection requires opening a gpio sysfs file handle. The pin will be
-// exported at /sys/class/gpio/gpio*/. Note that the pin will not be unexported
-// at shutdown.
+// Edge detection requires requesting the line from the GPIO character device
+// /dev/gpiochipN, or if not available, opening a gpio sysfs file handle. With
+// sysfs, the pin will be exported at /sys/class/gpio/gpio*/. Note that the pin
+// will not be unexported at shutdown.
 func (p *Pin) In(pull gpio.Pull, edge gpio.Edge) error {
 	if !p.available {
 		// We do not want the error message about uninitialized system.
@@ -243,11 +249,12 @@ func (p *Pin) In(pull gpio.Pull, edge gpio.Edge) error {
 	if edge != gpio.NoEdge && !p.supportEdge {
 		return p.wrap(errors.New("edge detection is not supported on this pin"))
 	}
-	if p.usingEdge && edge == gpio.NoEdge {
-		if err := p.sysfsPin.Halt(); err != nil {
+	p.haltPWM()
+	if p.edgePin != nil && edge == gpio.NoEdge {
+		if err := p.edgePin.Halt(); err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = false
+		p.edgePin = nil
 	}
 	if drvGPIO.gpioMemory == nil {
 		if p.sysfsPin == nil {
@@ -259,7 +266,9 @@ func (p *Pin) In(pull gpio.Pull, edge gpio.Edge) error {
 		if err := p.sysfsPin.In(pull, edge); err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = edge != gpio.NoEdge
+		if edge != gpio.NoEdge {
+			p.edgePin = p.sysfsPin
+		}
 		return nil
 	}
 	p.setFunction(in)
@@ -277,14 +286,12 @@ func (p *Pin) In(pull gpio.Pull, edge gpio.Edge) error {
 		}
 	}
 	if edge != gpio.NoEdge {
-		if p.sysfsPin == nil {
-			return p.wrap(fmt.Errorf("pin %d is not exported by sysfs", p.Number()))
-		}
 		// This resets pending edges.
-		if err := p.sysfsPin.In(gpio.PullNoChange, edge); err != nil {
+		e, err := sysfs.StartEdge(p.gpioLine, p.sysfsPin, pull, edge)
+		if err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = true
+		p.edgePin = e
 	}
 	return nil
 }
@@ -317,12 +324,26 @@ func (p *Pin) FastRead() gpio.Level {
 // It waits for an edge as previously set using In() or the expiration of a
 // timeout.
 func (p *Pin) WaitForEdge(timeout time.Duration) bool {
+	if p.edgePin != nil {
+		return p.edgePin.WaitForEdge(timeout)
+	}
 	if p.sysfsPin != nil {
 		return p.sysfsPin.WaitForEdge(timeout)
 	}
 	return false
 }
 
+// WaitForEvent implements gpio.PinEventer.
+//
+// The precision of the timestamp depends on whether the GPIO character device
+// is available; see sysfs.StartEdge.
+func (p *Pin) WaitForEvent(timeout time.Duration) (gpio.EdgeEvent, bool) {
+	if p.edgePin != nil {
+		return p.edgePin.WaitForEvent(timeout)
+	}
+	return gpio.EdgeEvent{}, false
+}
+
 // Pull implements gpio.PinIn.
 func (p *Pin) Pull() gpio.Pull {
 	if drvGPIO.gpioMemory == nil || !p.available {
@@ -438,11 +459,96 @@ func (p *Pin) FastOut(l gpio.Level) {
 }
 
 // PWM implements gpio.PinOut.
-func (p *Pin) PWM(gpio.Duty, physic.Frequency) error {
-	return p.wrap(errors.New("not available on this CPU architecture"))
+//
+// It outputs a periodic signal on supported pins without CPU usage. The
+// frequency and the duty are rounded to the resolution of the controller; use
+// PWMOutput() to retrieve the values actually output.
+//
+// The frequency must be between 0.005Hz and 12MHz. The resolution depends on
+// the prescaler selected, e.g. it is 16 bits at 367Hz and decreases as the
+// frequency increases, down to 8 bits at 94kHz.
+//
+// The A64 and the R8 have one PWM channel, PWM0, the A20 has two, PWM0 and
+// PWM1.
+//
+// This can only be used if the driver "allwinner-pwm" was loaded.
+func (p *Pin) PWM(duty gpio.Duty, freq physic.Frequency) error {
+	if !p.available {
+		// We do not want the error message about uninitialized system.
+		return p.wrap(errors.New("not available on this CPU architecture"))
+	}
+	if duty == 0 {
+		return p.Out(gpio.Low)
+	} else if duty == gpio.DutyMax {
+		return p.Out(gpio.High)
+	}
+	f, ch := p.pwmChannel()
+	if ch == -1 {
+		return p.wrap(errors.New("pin doesn't support PWM"))
+	}
+	if drvPWM.pwmMemory == nil {
+		return p.wrap(errors.New("subsystem allwinner-pwm not initialized"))
+	}
+	if ch >= drvPWM.channels {
+		return p.wrap(fmt.Errorf("PWM%d is not available on this CPU architecture", ch))
+	}
+	if err := p.Halt(); err != nil {
+		return err
+	}
+	d, actual, err := drvPWM.pwmMemory.set(ch, duty, freq)
+	if err != nil {
+		return p.wrap(err)
+	}
+	p.setFunction(f)
+	p.usingPWM = true
+	p.pwmDuty = d
+	p.pwmFreq = actual
+	return nil
 }
 
+// PWMOutput returns the duty and the frequency actually output after the
+// last call to PWM(), once rounded to the resolution of the controller.
 //
+// It returns zeros if the pin is not outputting a PWM signal.
+func (p *Pin) PWMOutput() (gpio.Duty, physic.Frequency) {
+	if !p.usingPWM {
+		return 0, 0
+	}
+	return p.pwmDuty, p.pwmFreq
+}
+
+//
+
+// dataAddr returns the physical address of the byte of the Pn_DAT register
+// that contains the pin.
+func (p *Pin) dataAddr() uint32 {
+	return drvGPIO.gpioBaseAddr + 0x24*uint32(p.group) + 0x10 + uint32(p.offset/8)
+}
+
+// pwmChannel returns the alternate function to use and the PWM channel
+// number of the pin, or -1 if the pin doesn't support PWM.
+func (p *Pin) pwmChannel() (function, int) {
+	for i, m := range p.altFunc {
+		switch m {
+		case "PWM0":
+			return alt1 + function(i), 0
+		case "PWM1":
+			return alt1 + function(i), 1
+		}
+	}
+	return disabled, -1
+}
+
+// haltPWM stops the PWM channel if it was used by this pin.
+func (p *Pin) haltPWM() {
+	if !p.usingPWM {
+		return
+	}
+	if _, ch := p.pwmChannel(); ch != -1 && drvPWM.pwmMemory != nil {
+		drvPWM.pwmMemory.disable(ch)
+	}
+	p.usingPWM = false
+}
 
 // drive returns the configured output current drive strength for this GPIO.
 //
@@ -891,8 +997,12 @@ func init() {
 // functions of each pin, and registers all the pins with gpio.
 func initPins() error {
 	functions := map[pin.Func]struct{}{}
+	// The GPIO character device is labeled after the physical address of the
+	// registers, with one line per pin of the groups PA to PI.
+	label := fmt.Sprintf("%x.pinctrl", drvGPIO.gpioBaseAddr)
 	for name, p := range cpupins {
 		num := strconv.Itoa(p.Number())
+		p.gpioLine = sysfs.GPIOLineByLabel(label, p.Number())
 		gpion := "GPIO" + num
 
 		// Unregister the pin if already registered. This happens with sysfs-gpio.
@@ -975,6 +1085,8 @@ type gpioMap struct {
 type driverGPIO struct {
 	// gpioMemory is the memory map of the CPU GPIO registers.
 	gpioMemory *gpioMap
+	// gpioBaseAddr is the physical base address of the GPIO registers.
+	gpioBaseAddr uint32
 }
 
 func (d *driverGPIO) String() string {
@@ -986,7 +1098,7 @@ func (d *driverGPIO) Prerequisites() []string {
 }
 
 func (d *driverGPIO) After() []string {
-	return []string{"sysfs-gpio"}
+	return []string{"sysfs-gpio", "sysfs-gpiochip"}
 }
 
 // Init does nothing if an allwinner processor is not detected. If one is
@@ -1016,9 +1128,8 @@ func (d *driverGPIO) Init() (bool, error) {
 		return false, errors.New("unknown Allwinner CPU model")
 	}
 
-	// gpioBaseAddr is the physical base address of the GPIO registers.
-	gpioBaseAddr := uint32(getBaseAddress())
-	if err := pmem.MapAsPOD(uint64(gpioBaseAddr), &d.gpioMemory); err != nil {
+	d.gpioBaseAddr = uint32(getBaseAddress())
+	if err := pmem.MapAsPOD(uint64(d.gpioBaseAddr), &d.gpioMemory); err != nil {
 		if os.IsPermission(err) {
 			return true, fmt.Errorf("need more access, try as root: %v", err)
 		}
@@ -1060,6 +1171,7 @@ var drvGPIO driverGPIO
 
 // Ensure that the various structs implement the interfaces they're supposed to.
 var _ gpio.PinIO = &Pin{}
+var _ gpio.PinEventer = &Pin{}
 var _ gpio.PinIn = &Pin{}
 var _ gpio.PinOut = &Pin{}
 var _ pin.PinFunc = &Pin{}
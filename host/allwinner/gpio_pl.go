@@ -35,11 +35,12 @@ type PinPL struct {
 	defaultPull gpio.Pull // default pull at startup
 
 	// Immutable after driver initialization.
-	sysfsPin  *sysfs.Pin // Set to the corresponding sysfs.Pin, if any.
-	available bool       // Set when the pin is available on this CPU architecture.
+	sysfsPin  *sysfs.Pin      // Set to the corresponding sysfs.Pin, if any.
+	gpioLine  *sysfs.GPIOLine // Set to the corresponding sysfs.GPIOLine, if any.
+	available bool            // Set when the pin is available on this CPU architecture.
 
 	// Mutable.
-	usingEdge bool // Set when edge detection is enabled.
+	edgePin sysfs.EdgePin // Set when edge detection is enabled.
 }
 
 // String implements conn.Resource.
@@ -53,11 +54,11 @@ func (p *PinPL) String() string {
 //
 // It stops edge detection if enabled.
 func (p *PinPL) Halt() error {
-	if p.usingEdge {
-		if err := p.sysfsPin.Halt(); err != nil {
+	if p.edgePin != nil {
+		if err := p.edgePin.Halt(); err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = false
+		p.edgePin = nil
 	}
 	return nil
 }
@@ -202,11 +203,11 @@ func (p *PinPL) In(pull gpio.Pull, edge gpio.Edge) error {
 		// We do not want the error message about uninitialized system.
 		return p.wrap(errors.New("not available on this CPU architecture"))
 	}
-	if p.usingEdge && edge == gpio.NoEdge {
-		if err := p.sysfsPin.Halt(); err != nil {
+	if p.edgePin != nil && edge == gpio.NoEdge {
+		if err := p.edgePin.Halt(); err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = false
+		p.edgePin = nil
 	}
 	if drvGPIOPL.gpioMemoryPL == nil {
 		if p.sysfsPin == nil {
@@ -218,7 +219,9 @@ func (p *PinPL) In(pull gpio.Pull, edge gpio.Edge) error {
 		if err := p.sysfsPin.In(pull, edge); err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = edge != gpio.NoEdge
+		if edge != gpio.NoEdge {
+			p.edgePin = p.sysfsPin
+		}
 		return nil
 	}
 	if !p.setFunction(in) {
@@ -238,14 +241,12 @@ func (p *PinPL) In(pull gpio.Pull, edge gpio.Edge) error {
 		}
 	}
 	if edge != gpio.NoEdge {
-		if p.sysfsPin == nil {
-			return p.wrap(fmt.Errorf("pin %d is not exported by sysfs", p.Number()))
-		}
 		// This resets pending edges.
-		if err := p.sysfsPin.In(gpio.PullNoChange, edge); err != nil {
+		e, err := sysfs.StartEdge(p.gpioLine, p.sysfsPin, pull, edge)
+		if err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = true
+		p.edgePin = e
 	}
 	return nil
 }
@@ -268,12 +269,26 @@ func (p *PinPL) FastRead() gpio.Level {
 
 // WaitForEdge implements gpio.PinIn.
 func (p *PinPL) WaitForEdge(timeout time.Duration) bool {
+	if p.edgePin != nil {
+		return p.edgePin.WaitForEdge(timeout)
+	}
 	if p.sysfsPin != nil {
 		return p.sysfsPin.WaitForEdge(timeout)
 	}
 	return false
 }
 
+// WaitForEvent implements gpio.PinEventer.
+//
+// The precision of the timestamp depends on whether the GPIO character device
+// is available; see sysfs.StartEdge.
+func (p *PinPL) WaitForEvent(timeout time.Duration) (gpio.EdgeEvent, bool) {
+	if p.edgePin != nil {
+		return p.edgePin.WaitForEvent(timeout)
+	}
+	return gpio.EdgeEvent{}, false
+}
+
 // Pull implements gpio.PinIn.
 func (p *PinPL) Pull() gpio.Pull {
 	if drvGPIOPL.gpioMemoryPL == nil {
@@ -472,7 +487,7 @@ func (d *driverGPIOPL) Prerequisites() []string {
 }
 
 func (d *driverGPIOPL) After() []string {
-	return []string{"sysfs-gpio"}
+	return []string{"sysfs-gpio", "sysfs-gpiochip"}
 }
 
 func (d *driverGPIOPL) Init() (bool, error) {
@@ -484,10 +499,12 @@ func (d *driverGPIOPL) Init() (bool, error) {
 	// Mark the right pins as available even if the memory map fails so they can
 	// callback to sysfs.Pins.
 	functions := map[pin.Func]struct{}{}
+	label := fmt.Sprintf("%x.pinctrl", getBaseAddressPL())
 	for i := range cpuPinsPL {
 		name := cpuPinsPL[i].Name()
 		num := strconv.Itoa(cpuPinsPL[i].Number())
 		cpuPinsPL[i].available = true
+		cpuPinsPL[i].gpioLine = sysfs.GPIOLineByLabel(label, int(cpuPinsPL[i].offset))
 		gpion := "GPIO" + num
 
 		// Unregister the pin if already registered. This happens with sysfs-gpio.
@@ -558,6 +575,7 @@ func init() {
 var drvGPIOPL driverGPIOPL
 
 var _ gpio.PinIO = &PinPL{}
+var _ gpio.PinEventer = &PinPL{}
 var _ gpio.PinIn = &PinPL{}
 var _ gpio.PinOut = &PinPL{}
 var _ pin.PinFunc = &PinPL{}
@@ -5,31 +5,38 @@
 package allwinner
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
+
+	"github.com/meandrewdev/periph"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/host/pmem"
 )
 
-const pwmClock = 24000000
+const pwmClock = 24 * physic.MegaHertz
 const pwmMaxPeriod = 0x10000
 
-// prescalers is the value for pwm0Prescale*
+// prescalers is the value for pwm0Prescale*, sorted by increasing divider.
 var prescalers = []struct {
-	freq   uint32
+	div    uint32
 	scaler pwmPrescale
 }{
-	// Base frequency (min freq is half that) / PWM clock at pwmMaxPeriod
-	{pwmClock, pwmPrescale1},             //  24MHz / 366Hz
-	{pwmClock / 120, pwmPrescale120},     // 200kHz / 3Hz
-	{pwmClock / 180, pwmPrescale180},     // 133kHz / 2Hz
-	{pwmClock / 240, pwmPrescale240},     // 100kHz / 1.5Hz
-	{pwmClock / 360, pwmPrescale360},     //  66kHz / 1.01Hz
-	{pwmClock / 480, pwmPrescale480},     //  50kHz / 0.7Hz
-	{pwmClock / 12000, pwmPrescale12000}, //   2kHz
-	{pwmClock / 24000, pwmPrescale24000}, //   1kHz
-	{pwmClock / 36000, pwmPrescale36000}, // 666 Hz
-	{pwmClock / 48000, pwmPrescale48000}, // 500 Hz
-	{pwmClock / 72000, pwmPrescale72000}, // 333 Hz / 0.005Hz
+	// Base frequency (max freq is half that) / PWM clock at pwmMaxPeriod
+	{1, pwmPrescale1},         //  24MHz / 366Hz
+	{120, pwmPrescale120},     // 200kHz / 3Hz
+	{180, pwmPrescale180},     // 133kHz / 2Hz
+	{240, pwmPrescale240},     // 100kHz / 1.5Hz
+	{360, pwmPrescale360},     //  66kHz / 1.01Hz
+	{480, pwmPrescale480},     //  50kHz / 0.7Hz
+	{12000, pwmPrescale12000}, //   2kHz / 0.03Hz
+	{24000, pwmPrescale24000}, //   1kHz / 0.015Hz
+	{36000, pwmPrescale36000}, // 666Hz / 0.01Hz
+	{48000, pwmPrescale48000}, // 500Hz / 0.0076Hz
+	{72000, pwmPrescale72000}, // 333Hz / 0.005Hz
 }
 
 const (
@@ -165,33 +172,143 @@ func toPeriod(total uint32, active uint16) pwmPeriod {
 	return pwmPeriod(total-1)<<16 | pwmPeriod(active)
 }
 
-// getBestPrescale finds the best prescaler.
+// getBestPrescale finds the prescaler with the best resolution for the
+// frequency freq.
 //
-// Cycles must be between 2 and 0x10000/2.
-func getBestPrescale(period time.Duration) pwmPrescale {
-	// TODO(maruel): Rewrite this function, it is incorrect.
+// It returns the prescaler and the number of cycles of the prescaled clock in
+// a period, which is between 2 and pwmMaxPeriod.
+func getBestPrescale(freq physic.Frequency) (pwmPrescale, uint32, error) {
+	if freq <= 0 {
+		return 0, 0, errors.New("frequency must be positive")
+	}
+	if m := pwmClock / 2; freq > m {
+		return 0, 0, fmt.Errorf("frequency must be at most %s", m)
+	}
+	// The smallest divider that can represent the period gives the best
+	// resolution.
 	for _, v := range prescalers {
-		p := time.Second / time.Duration(v.freq)
-		smallest := (period / pwmMaxPeriod)
-		largest := (period / 2)
-		if p > smallest && p < largest {
-			return v.scaler
+		base := pwmClock / physic.Frequency(v.div)
+		cycles := (base + freq/2) / freq
+		if cycles > pwmMaxPeriod {
+			continue
 		}
+		return v.scaler, uint32(cycles), nil
 	}
-	// Period is longer than 196s.
-	return pwmPrescale72000
+	return 0, 0, fmt.Errorf("frequency must be at least %s", pwmClock/72000/pwmMaxPeriod)
+}
+
+// pwmDivider returns the divider of the prescaler p.
+func pwmDivider(p pwmPrescale) uint32 {
+	for _, v := range prescalers {
+		if v.scaler == p {
+			return v.div
+		}
+	}
+	return 0
 }
 
 // pwmMap represents the PWM memory mapped CPU registers.
 //
-// The base frequency is 24Mhz.
-//
-// TODO(maruel): Some CPU have 2 PWMs.
+// The base frequency is 24Mhz. The A20 has 2 channels, the A64 and the R8
+// only have the first one.
 type pwmMap struct {
-	ctl    pwmCtl    // PWM_CTRL_REG
-	period pwmPeriod // PWM_CH0_PERIOD
+	ctl    pwmCtl       // PWM_CTRL_REG
+	period [2]pwmPeriod // PWM_CH0_PERIOD, PWM_CH1_PERIOD
 }
 
 func (p *pwmMap) String() string {
 	return fmt.Sprintf("pwmMap{%s, %v}", p.ctl, p.period)
 }
+
+// pwmChannelShift is the offset of the PWM1 control bits in PWM_CTRL_REG.
+const pwmChannelShift = 15
+
+// set configures and enables the channel ch.
+//
+// It returns the duty and the frequency actually output, after rounding to the
+// resolution of the controller.
+func (p *pwmMap) set(ch int, duty gpio.Duty, freq physic.Frequency) (gpio.Duty, physic.Frequency, error) {
+	scaler, cycles, err := getBestPrescale(freq)
+	if err != nil {
+		return 0, 0, err
+	}
+	active := (uint64(cycles)*uint64(duty) + uint64(gpio.DutyHalf)) / uint64(gpio.DutyMax)
+	if active > 0xFFFF {
+		active = 0xFFFF
+	}
+	shift := uint(ch * pwmChannelShift)
+	// The prescaler must be changed while the clock is gated.
+	ctl := p.ctl &^ (pwm0Mask << shift)
+	p.ctl = ctl | pwmCtl(scaler)<<shift
+	// The period register can only be written when it is not busy.
+	busy := pwmBusy << uint(ch)
+	for start := time.Now(); p.ctl&busy != 0; {
+		if time.Since(start) > 10*time.Millisecond {
+			return 0, 0, errors.New("timed out waiting for the period register")
+		}
+	}
+	p.period[ch] = toPeriod(cycles, uint16(active))
+	p.ctl = ctl | (pwm0SCLK|pwm0Polarity|pwm0Enable|pwmCtl(scaler))<<shift
+	d := gpio.Duty((active*uint64(gpio.DutyMax) + uint64(cycles)/2) / uint64(cycles))
+	return d, pwmClock / physic.Frequency(pwmDivider(scaler)) / physic.Frequency(cycles), nil
+}
+
+// disable stops the channel ch.
+func (p *pwmMap) disable(ch int) {
+	p.ctl &^= (pwm0SCLK | pwm0Enable) << uint(ch*pwmChannelShift)
+}
+
+// driverPWM implements periph.Driver.
+type driverPWM struct {
+	// pwmMemory is the memory map of the CPU PWM registers.
+	pwmMemory *pwmMap
+	// channels is the number of PWM channels on this CPU.
+	channels int
+}
+
+func (d *driverPWM) String() string {
+	return "allwinner-pwm"
+}
+
+func (d *driverPWM) Prerequisites() []string {
+	return []string{"allwinner-gpio"}
+}
+
+func (d *driverPWM) After() []string {
+	return nil
+}
+
+func (d *driverPWM) Init() (bool, error) {
+	// pwmBaseAddr is the physical base address of the PWM registers.
+	var pwmBaseAddr uint64
+	if IsA64() {
+		// Page 194.
+		pwmBaseAddr = 0x1C21400
+		d.channels = 1
+	} else if IsR8() {
+		// Page 83.
+		pwmBaseAddr = 0x1C20C00 + 0x200
+		d.channels = 1
+	} else if IsA20() {
+		// Page 100.
+		pwmBaseAddr = 0x1C20C00 + 0x200
+		d.channels = 2
+	} else {
+		return false, errors.New("unsupported CPU architecture")
+	}
+	if err := pmem.MapAsPOD(pwmBaseAddr, &d.pwmMemory); err != nil {
+		if os.IsPermission(err) {
+			return true, fmt.Errorf("need more access, try as root: %v", err)
+		}
+		return true, err
+	}
+	return true, nil
+}
+
+func init() {
+	if isArm {
+		periph.MustRegister(&drvPWM)
+	}
+}
+
+var drvPWM driverPWM
@@ -0,0 +1,138 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package allwinner
+
+import (
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/pin"
+)
+
+func TestGetBestPrescale(t *testing.T) {
+	data := []struct {
+		freq   physic.Frequency
+		scaler pwmPrescale
+		cycles uint32
+	}{
+		{12 * physic.MegaHertz, pwmPrescale1, 2},
+		{physic.KiloHertz, pwmPrescale1, 24000},
+		{367 * physic.Hertz, pwmPrescale1, 65395},
+		{100 * physic.Hertz, pwmPrescale120, 2000},
+		{physic.Hertz, pwmPrescale480, 50000},
+		{10 * physic.MilliHertz, pwmPrescale48000, 50000},
+		{6 * physic.MilliHertz, pwmPrescale72000, 55556},
+	}
+	for i, line := range data {
+		s, c, err := getBestPrescale(line.freq)
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if s != line.scaler || c != line.cycles {
+			t.Fatal(i, s, c)
+		}
+	}
+	for _, f := range []physic.Frequency{0, -physic.Hertz, 13 * physic.MegaHertz, physic.MilliHertz} {
+		if _, _, err := getBestPrescale(f); err == nil {
+			t.Fatal(f)
+		}
+	}
+}
+
+func TestPWMMap_set(t *testing.T) {
+	p := pwmMap{}
+	// 24MHz / 7kHz = 3428.57, rounded to 3429 cycles.
+	d, f, err := p.set(0, gpio.DutyMax/3, 7*physic.KiloHertz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ctl != pwm0SCLK|pwm0Polarity|pwm0Enable|pwm0Prescale1 {
+		t.Fatal(p.ctl)
+	}
+	if p.period[0] != 3428<<16|1143 {
+		t.Fatal(p.period[0])
+	}
+	if f != pwmClock/3429 {
+		t.Fatal(f)
+	}
+	if d != gpio.Duty((1143*uint64(gpio.DutyMax)+3429/2)/3429) {
+		t.Fatal(d)
+	}
+	// Channel 1 doesn't affect channel 0.
+	if _, _, err := p.set(1, gpio.DutyHalf, 100*physic.Hertz); err != nil {
+		t.Fatal(err)
+	}
+	if p.ctl != pwm0SCLK|pwm0Polarity|pwm0Enable|pwm0Prescale1|(pwm0SCLK|pwm0Polarity|pwm0Enable|pwm0Prescale120)<<pwmChannelShift {
+		t.Fatal(p.ctl)
+	}
+	if p.period[1] != 1999<<16|1000 {
+		t.Fatal(p.period[1])
+	}
+	p.disable(0)
+	if p.ctl != pwm0Polarity|pwm0Prescale1|(pwm0SCLK|pwm0Polarity|pwm0Enable|pwm0Prescale120)<<pwmChannelShift {
+		t.Fatal(p.ctl)
+	}
+	if _, _, err := p.set(0, gpio.DutyHalf, 0); err == nil {
+		t.Fatal("invalid frequency")
+	}
+	if s := p.String(); s != "pwmMap{PWM_CH0_ACT_STA|/1|Unknown(0x00380000), [1143/3429 1000/2000]}" {
+		t.Fatal(s)
+	}
+}
+
+func TestPin_PWM(t *testing.T) {
+	defer resetPWM()
+	drvGPIO.gpioMemory = &gpioMap{}
+	drvPWM.pwmMemory = &pwmMap{}
+	drvPWM.channels = 1
+	p := &Pin{group: 1, offset: 2, name: "PB2", altFunc: [5]pin.Func{"PWM0"}, available: true}
+	if err := p.PWM(gpio.DutyHalf, physic.KiloHertz); err != nil {
+		t.Fatal(err)
+	}
+	if f := p.Func(); f != "PWM0" {
+		t.Fatal(f)
+	}
+	if d, f := p.PWMOutput(); d != gpio.DutyHalf || f != physic.KiloHertz {
+		t.Fatal(d, f)
+	}
+	if drvPWM.pwmMemory.ctl&pwm0Enable == 0 {
+		t.Fatal(drvPWM.pwmMemory.ctl)
+	}
+	if err := p.Out(gpio.Low); err != nil {
+		t.Fatal(err)
+	}
+	if drvPWM.pwmMemory.ctl&pwm0Enable != 0 {
+		t.Fatal(drvPWM.pwmMemory.ctl)
+	}
+	if d, f := p.PWMOutput(); d != 0 || f != 0 {
+		t.Fatal(d, f)
+	}
+	if err := p.PWM(gpio.DutyHalf, 0); err == nil {
+		t.Fatal("invalid frequency")
+	}
+
+	// Channel not available on this CPU.
+	p1 := &Pin{group: 8, offset: 3, name: "PI3", altFunc: [5]pin.Func{"PWM1"}, available: true}
+	if err := p1.PWM(gpio.DutyHalf, physic.KiloHertz); err == nil {
+		t.Fatal("PWM1 is not available")
+	}
+	// No PWM on this pin.
+	p2 := &Pin{group: 1, offset: 3, name: "PB3", available: true}
+	if err := p2.PWM(gpio.DutyHalf, physic.KiloHertz); err == nil {
+		t.Fatal("no PWM")
+	}
+	drvPWM.pwmMemory = nil
+	if err := p.PWM(gpio.DutyHalf, physic.KiloHertz); err == nil {
+		t.Fatal("not initialized")
+	}
+}
+
+//
+
+func resetPWM() {
+	drvGPIO.gpioMemory = nil
+	drvPWM = driverPWM{}
+}
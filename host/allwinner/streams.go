@@ -0,0 +1,140 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package allwinner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/meandrewdev/periph/conn/gpio/gpiostream"
+)
+
+// getBit gets the bit at index in b.
+func getBit(b byte, index int, msb bool) byte {
+	var shift uint
+	if msb {
+		shift = uint(7 - index)
+	} else {
+		shift = uint(index)
+	}
+	return (b >> shift) & 1
+}
+
+// streamLen returns the number of samples in the stream at its frequency.
+//
+// All the parts of a Program must be at the same frequency and it must not
+// loop infinitely.
+func streamLen(s gpiostream.Stream) (int, error) {
+	switch x := s.(type) {
+	case *gpiostream.BitStream:
+		return len(x.Bits) * 8, nil
+	case *gpiostream.EdgeStream:
+		n := 0
+		for _, e := range x.Edges {
+			n += int(e)
+		}
+		return n, nil
+	case *gpiostream.Program:
+		if x.Loops < 0 {
+			return 0, errors.New("infinite Program is not supported")
+		}
+		if x.Loops == 0 {
+			return 0, nil
+		}
+		n := 0
+		for _, p := range x.Parts {
+			if p.Frequency() != x.Frequency() {
+				return 0, fmt.Errorf("Program parts at different frequencies is not supported(%s vs %s)", p.Frequency(), x.Frequency())
+			}
+			l, err := streamLen(p)
+			if err != nil {
+				return 0, err
+			}
+			n += l
+		}
+		return n * x.Loops, nil
+	default:
+		return 0, fmt.Errorf("unknown stream type %T", s)
+	}
+}
+
+// raster8 rasters the stream into a byte stream, repeating each sample skip
+// times. clear is the value used when the level is low, set when it is high.
+//
+// dst must be at least streamLen(s)*skip long.
+func raster8(s gpiostream.Stream, skip int, dst []byte, clear, set byte) error {
+	if skip < 1 {
+		return errors.New("skip must be positive")
+	}
+	n, err := streamLen(s)
+	if err != nil {
+		return err
+	}
+	if len(dst) < n*skip {
+		return fmt.Errorf("buffer is too short(%d bytes vs %d)", len(dst), n*skip)
+	}
+	raster8Inner(s, skip, dst, clear, set)
+	return nil
+}
+
+// raster8Inner rasters a stream that was validated by streamLen() and returns
+// the number of bytes written.
+func raster8Inner(s gpiostream.Stream, skip int, dst []byte, clear, set byte) int {
+	index := 0
+	fill := func(v byte, samples int) {
+		for i := 0; i < samples*skip; i++ {
+			dst[index] = v
+			index++
+		}
+	}
+	switch x := s.(type) {
+	case *gpiostream.BitStream:
+		for _, b := range x.Bits {
+			for j := 0; j < 8; j++ {
+				if getBit(b, j, !x.LSBF) != 0 {
+					fill(set, 1)
+				} else {
+					fill(clear, 1)
+				}
+			}
+		}
+	case *gpiostream.EdgeStream:
+		// The signal starts high and toggles after each edge.
+		v := set
+		for _, e := range x.Edges {
+			fill(v, int(e))
+			if v == set {
+				v = clear
+			} else {
+				v = set
+			}
+		}
+	case *gpiostream.Program:
+		for i := 0; i < x.Loops; i++ {
+			for _, p := range x.Parts {
+				index += raster8Inner(p, skip, dst[index:], clear, set)
+			}
+		}
+	}
+	return index
+}
+
+// bytesToBits extracts the bit at index of every skip bytes of src into the
+// bits of dst.
+func bytesToBits(dst, src []byte, index uint, skip int, msb bool) {
+	for i := range dst {
+		dst[i] = 0
+	}
+	for i := 0; i < len(dst)*8 && i*skip < len(src); i++ {
+		if (src[i*skip]>>index)&1 == 0 {
+			continue
+		}
+		if msb {
+			dst[i/8] |= 0x80 >> uint(i%8)
+		} else {
+			dst[i/8] |= 1 << uint(i%8)
+		}
+	}
+}
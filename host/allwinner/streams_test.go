@@ -0,0 +1,77 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package allwinner
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/gpio/gpiostream"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+func TestRaster8(t *testing.T) {
+	edges := &gpiostream.EdgeStream{Edges: []uint16{2, 1, 3}, Freq: physic.KiloHertz}
+	data := []struct {
+		s        gpiostream.Stream
+		skip     int
+		expected []byte
+	}{
+		{&gpiostream.BitStream{Bits: []byte{0x81}, Freq: physic.KiloHertz, LSBF: true}, 1, []byte{1, 0, 0, 0, 0, 0, 0, 1}},
+		{&gpiostream.BitStream{Bits: []byte{0x60}, Freq: physic.KiloHertz}, 2, []byte{0, 0, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{edges, 1, []byte{1, 1, 0, 1, 1, 1}},
+		{&gpiostream.EdgeStream{Edges: []uint16{0, 2, 1}, Freq: physic.KiloHertz}, 2, []byte{0, 0, 0, 0, 1, 1}},
+		{&gpiostream.Program{Parts: []gpiostream.Stream{edges}, Loops: 2}, 1, []byte{1, 1, 0, 1, 1, 1, 1, 1, 0, 1, 1, 1}},
+		{&gpiostream.Program{Parts: []gpiostream.Stream{edges}}, 1, []byte{}},
+	}
+	for i, line := range data {
+		n, err := streamLen(line.s)
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if n*line.skip != len(line.expected) {
+			t.Fatal(i, n)
+		}
+		dst := make([]byte, len(line.expected))
+		if err := raster8(line.s, line.skip, dst, 0, 1); err != nil {
+			t.Fatal(i, err)
+		}
+		if !bytes.Equal(dst, line.expected) {
+			t.Fatal(i, dst)
+		}
+	}
+	if err := raster8(edges, 0, make([]byte, 6), 0, 1); err == nil {
+		t.Fatal("invalid skip")
+	}
+	if err := raster8(edges, 1, make([]byte, 5), 0, 1); err == nil {
+		t.Fatal("buffer is too short")
+	}
+	if err := raster8(&gpiostream.Program{Parts: []gpiostream.Stream{edges}, Loops: -1}, 1, make([]byte, 6), 0, 1); err == nil {
+		t.Fatal("infinite Program")
+	}
+	mixed := &gpiostream.Program{
+		Parts: []gpiostream.Stream{edges, &gpiostream.EdgeStream{Edges: []uint16{1}, Freq: physic.Hertz}},
+		Loops: 1,
+	}
+	if _, err := streamLen(mixed); err == nil {
+		t.Fatal("mixed frequencies")
+	}
+	if _, err := streamLen(nil); err == nil {
+		t.Fatal("unknown type")
+	}
+}
+
+func TestBytesToBits(t *testing.T) {
+	src := []byte{0x10, 0, 0x10, 0x10, 0, 0x10, 0, 0, 0x10}
+	dst := []byte{0xFF, 0xFF}
+	bytesToBits(dst, src, 4, 1, false)
+	if !bytes.Equal(dst, []byte{0x2D, 0x01}) {
+		t.Fatal(dst)
+	}
+	bytesToBits(dst, src, 4, 2, true)
+	if !bytes.Equal(dst, []byte{0xC8, 0}) {
+		t.Fatal(dst)
+	}
+}
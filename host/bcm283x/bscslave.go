@@ -0,0 +1,234 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// bscslave means I²C target; the BSC/SPI slave block.
+
+package bcm283x
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/i2c"
+)
+
+// NewBSCSlave returns the I²C target of the BSC slave block, accessed via
+// direct register access.
+//
+// The pins GPIO18 (SDA) and GPIO19 (SCL) are used, or GPIO10 (SDA) and
+// GPIO11 (SCL) on the BCM2711.
+func NewBSCSlave() (*BSCSlave, error) {
+	if drvDMA.bscSlaveMemory == nil {
+		return nil, errors.New("bcm283x-bscslave: subsystem BSC slave not initialized; try running as root?")
+	}
+	return &BSCSlave{}, nil
+}
+
+// BSCSlave is the I²C target of the BSC slave block.
+//
+// It implements i2c.Target. The block supports a single address and has 16
+// bytes deep FIFOs which are polled:
+//
+// - The bytes written by the controller are reported to Write() once the
+// block is not receiving anymore.
+//
+// - The TX FIFO is filled ahead of time with Read(), on registration and
+// after each write and read. Only the first 16 bytes are guaranteed to be
+// available when the controller starts reading, the rest is sent as the FIFO
+// drains.
+type BSCSlave struct {
+	mu      sync.Mutex
+	h       i2c.TargetHandler
+	addr    uint16
+	rx      []byte
+	tx      []byte
+	reading bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func (b *BSCSlave) String() string {
+	return "BSCSlave"
+}
+
+// Register implements i2c.Target.
+func (b *BSCSlave) Register(addr uint16, h i2c.TargetHandler) error {
+	if addr >= 0x80 {
+		return errors.New("bcm283x-bscslave: invalid address")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.h != nil {
+		return fmt.Errorf("bcm283x-bscslave: address %d is already registered; only one address is supported", b.addr)
+	}
+	if drvGPIO.gpioMemory != nil {
+		for _, pin := range b.pins() {
+			pin.setFunction(alt3)
+		}
+	}
+	m := drvDMA.bscSlaveMemory
+	m.cr = 0
+	m.cr = bscBreak
+	m.rsr = 0
+	m.slv = uint32(addr)
+	m.cr = bscEnable | bscI2C | bscTXEnable | bscRXEnable
+	b.addr = addr
+	b.h = h
+	b.rx = nil
+	b.reading = false
+	b.tx = h.Read()
+	b.poll()
+	b.stop = make(chan struct{})
+	b.done = make(chan struct{})
+	go b.loop(b.stop, b.done)
+	return nil
+}
+
+// Unregister implements i2c.Target.
+func (b *BSCSlave) Unregister(addr uint16) error {
+	b.mu.Lock()
+	if b.h == nil || b.addr != addr {
+		b.mu.Unlock()
+		return fmt.Errorf("bcm283x-bscslave: address %d is not registered", addr)
+	}
+	stop, done := b.stop, b.done
+	b.mu.Unlock()
+	close(stop)
+	<-done
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m := drvDMA.bscSlaveMemory
+	m.cr = bscBreak
+	m.cr = 0
+	m.slv = 0
+	b.h = nil
+	b.rx = nil
+	b.tx = nil
+	return nil
+}
+
+// SDA returns the data pin.
+func (b *BSCSlave) SDA() gpio.PinIO {
+	return b.pins()[0]
+}
+
+// SCL returns the clock pin.
+func (b *BSCSlave) SCL() gpio.PinIO {
+	return b.pins()[1]
+}
+
+//
+
+// bscSlavePoll is the interval at which the FIFOs are serviced. At 100kHz,
+// the 16 bytes deep FIFO fills in about 1.4ms.
+var bscSlavePoll = 200 * time.Microsecond
+
+func (b *BSCSlave) pins() [2]*Pin {
+	if drvGPIO.isBCM2711 {
+		return [2]*Pin{GPIO10, GPIO11}
+	}
+	return [2]*Pin{GPIO18, GPIO19}
+}
+
+func (b *BSCSlave) loop(stop, done chan struct{}) {
+	defer close(done)
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		b.mu.Lock()
+		b.poll()
+		b.mu.Unlock()
+		time.Sleep(bscSlavePoll)
+	}
+}
+
+// poll services the FIFOs.
+func (b *BSCSlave) poll() {
+	m := drvDMA.bscSlaveMemory
+	fr := m.fr
+	for n := (fr & bscRXLevelMask) >> bscRXLevelShift; n > 0; n-- {
+		b.rx = append(b.rx, byte(m.dr))
+	}
+	refill := false
+	if fr&bscRXBusy == 0 && len(b.rx) != 0 {
+		b.h.Write(b.rx)
+		b.rx = nil
+		refill = true
+	}
+	if fr&bscTXBusy != 0 {
+		b.reading = true
+	} else if b.reading {
+		b.reading = false
+		refill = true
+	}
+	if refill {
+		// Discard the stale bytes in the TX FIFO.
+		m.cr |= bscBreak
+		m.cr &^= bscBreak
+		b.tx = b.h.Read()
+		fr = m.fr
+	}
+	for n := bscFIFOSize - (fr&bscTXLevelMask)>>bscTXLevelShift; n > 0 && len(b.tx) != 0; n-- {
+		m.dr = uint32(b.tx[0])
+		b.tx = b.tx[1:]
+	}
+}
+
+const bscFIFOSize = 16
+
+// Page 165
+type bscCR uint32
+
+const (
+	bscEnable   bscCR = 1 << 0 // EN
+	bscSPI      bscCR = 1 << 1 // SPI
+	bscI2C      bscCR = 1 << 2 // I2C
+	bscBreak    bscCR = 1 << 7 // BRK Stop operation and clear the FIFOs
+	bscTXEnable bscCR = 1 << 8 // TXE
+	bscRXEnable bscCR = 1 << 9 // RXE
+)
+
+// Page 167
+type bscFR uint32
+
+const (
+	bscRXLevelShift        = 11
+	bscRXLevelMask   bscFR = 0x1F << bscRXLevelShift // RXFLEVEL
+	bscTXLevelShift        = 6
+	bscTXLevelMask   bscFR = 0x1F << bscTXLevelShift // TXFLEVEL
+	bscRXBusy        bscFR = 1 << 5                  // RXBUSY
+	bscTXEmpty       bscFR = 1 << 4                  // TXFE
+	bscRXFull        bscFR = 1 << 3                  // RXFF
+	bscTXFull        bscFR = 1 << 2                  // TXFF
+	bscRXEmpty       bscFR = 1 << 1                  // RXFE
+	bscTXBusy        bscFR = 1 << 0                  // TXBUSY
+)
+
+// Page 160
+type bscSlaveMap struct {
+	dr      uint32 // DR Data register
+	rsr     uint32 // RSR Operation status and error clear
+	slv     uint32 // SLV Target address
+	cr      bscCR  // CR Control
+	fr      bscFR  // FR Flags
+	ifls    uint32 // IFLS Interrupt FIFO level select
+	imsc    uint32 // IMSC Interrupt mask set clear
+	ris     uint32 // RIS Raw interrupt status
+	mis     uint32 // MIS Masked interrupt status
+	icr     uint32 // ICR Interrupt clear
+	dmacr   uint32 // DMACR DMA control
+	tdr     uint32 // TDR FIFO test data
+	gpustat uint32 // GPUSTAT GPU status
+	hctrl   uint32 // HCTRL Host control
+	debug1  uint32 // DEBUG1 I2C debug
+	debug2  uint32 // DEBUG2 SPI debug
+}
+
+var _ i2c.Target = &BSCSlave{}
@@ -0,0 +1,126 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package bcm283x
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewBSCSlave(t *testing.T) {
+	defer reset()
+	if _, err := NewBSCSlave(); err == nil {
+		t.Fatal("bscSlaveMemory is nil")
+	}
+	drvDMA.bscSlaveMemory = &bscSlaveMap{}
+	b, err := NewBSCSlave()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := b.String(); s != "BSCSlave" {
+		t.Fatal(s)
+	}
+	defer func(l bool) {
+		drvGPIO.isBCM2711 = l
+	}(drvGPIO.isBCM2711)
+	drvGPIO.isBCM2711 = true
+	if b.SDA() != GPIO10 || b.SCL() != GPIO11 {
+		t.Fatal("unexpected pins")
+	}
+	drvGPIO.isBCM2711 = false
+	if b.SDA() != GPIO18 || b.SCL() != GPIO19 {
+		t.Fatal("unexpected pins")
+	}
+}
+
+func TestBSCSlave_Register(t *testing.T) {
+	defer reset()
+	m := &bscSlaveMap{}
+	drvDMA.bscSlaveMemory = m
+	b := BSCSlave{}
+	h := &fakeTargetHandler{}
+	if err := b.Register(0x80, h); err == nil {
+		t.Fatal("invalid address")
+	}
+	if err := b.Register(0x42, h); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Register(0x43, h); err == nil {
+		t.Fatal("only one address")
+	}
+	if m.slv != 0x42 {
+		t.Fatal(m.slv)
+	}
+	if err := b.Unregister(0x43); err == nil {
+		t.Fatal("not registered")
+	}
+	if err := b.Unregister(0x42); err != nil {
+		t.Fatal(err)
+	}
+	if m.slv != 0 || m.cr != 0 {
+		t.Fatalf("%#v", m)
+	}
+}
+
+func TestBSCSlave_poll(t *testing.T) {
+	defer reset()
+	m := &bscSlaveMap{}
+	drvDMA.bscSlaveMemory = m
+	h := &fakeTargetHandler{r: []byte{1, 2, 3}}
+	b := BSCSlave{h: h, tx: h.Read()}
+
+	// Fill the TX FIFO; the fake FIFO keeps the last value.
+	b.poll()
+	if m.dr != 3 || len(b.tx) != 0 {
+		t.Fatal(m.dr, b.tx)
+	}
+
+	// Two bytes received while still receiving.
+	m.dr = 0x42
+	m.fr = 2<<bscRXLevelShift | bscRXBusy
+	b.poll()
+	if len(h.w) != 0 || !bytes.Equal(b.rx, []byte{0x42, 0x42}) {
+		t.Fatal(h.w, b.rx)
+	}
+
+	// Reception done; the TX FIFO is refreshed.
+	h.r = []byte{4}
+	m.fr = 0
+	b.poll()
+	if len(h.w) != 1 || !bytes.Equal(h.w[0], []byte{0x42, 0x42}) {
+		t.Fatal(h.w)
+	}
+	if m.dr != 4 || m.cr&bscBreak != 0 {
+		t.Fatal(m.dr, m.cr)
+	}
+
+	// The controller reads then stops; the TX FIFO is refreshed.
+	h.r = []byte{5}
+	m.fr = bscTXBusy | bscFIFOSize<<bscTXLevelShift
+	b.poll()
+	if !b.reading || m.dr != 4 {
+		t.Fatal(b.reading, m.dr)
+	}
+	m.fr = 0
+	b.poll()
+	if b.reading || m.dr != 5 {
+		t.Fatal(b.reading, m.dr)
+	}
+}
+
+//
+
+type fakeTargetHandler struct {
+	w [][]byte
+	r []byte
+}
+
+func (f *fakeTargetHandler) Write(w []byte) {
+	f.w = append(f.w, w)
+}
+
+func (f *fakeTargetHandler) Read() []byte {
+	return f.r
+}
@@ -1131,7 +1131,7 @@ func smokeTest() error {
 // driverDMA implements periph.Driver.
 //
 // It implements much more than the DMA controller, it also exposes the clocks,
-// the PWM and PCM controllers.
+// the PWM, PCM and BSC slave controllers.
 type driverDMA struct {
 	pcmBaseAddr uint32
 	pwmBaseAddr uint32
@@ -1142,6 +1142,9 @@ type driverDMA struct {
 	clockMemory   *clockMap
 	timerMemory   *timerMap
 	gpioPadMemory *gpioPadMap
+	// bscSlaveMemory is the I²C/SPI target block.
+	bscSlaveMemory *bscSlaveMap
+
 	// Page 138
 	// - Two independent bit-streams
 	// - Each channel either a PWM or serialised version of a 32-bit word
@@ -1171,6 +1174,7 @@ func (d *driverDMA) Close() error {
 	d.dmaChannel15 = nil
 	d.pcmMemory = nil
 	d.clockMemory = nil
+	d.bscSlaveMemory = nil
 	d.timerMemory = nil
 	d.pwmMemory = nil
 	d.pwmBaseFreq = 0
@@ -1220,6 +1224,11 @@ func (d *driverDMA) Init() (bool, error) {
 	if err := pmem.MapAsPOD(uint64(drvGPIO.baseAddr+0x101000), &d.clockMemory); err != nil {
 		return true, err
 	}
+	// The BSC slave is optional; NewBSCSlave() reports it as not initialized.
+	if err := pmem.MapAsPOD(uint64(drvGPIO.baseAddr+0x214000), &d.bscSlaveMemory); err != nil {
+		log.Printf("bcm283x-dma: BSC slave not available: %v", err)
+		d.bscSlaveMemory = nil
+	}
 	if err := pmem.MapAsPOD(uint64(drvGPIO.baseAddr+0x3000), &d.timerMemory); err != nil {
 		return true, err
 	}
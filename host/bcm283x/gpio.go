@@ -80,6 +80,13 @@ var (
 // Present returns true if running on a Broadcom bcm283x based CPU.
 func Present() bool {
 	if isArm {
+		for _, line := range distro.DTCompatible() {
+			// The BCM2712 on the Raspberry Pi 5 has a different register layout and
+			// its GPIOs are on the RP1; see package rp1.
+			if strings.HasPrefix(line, "brcm,bcm2712") {
+				return false
+			}
+		}
 		for _, line := range distro.DTCompatible() {
 			if strings.HasPrefix(line, "brcm,bcm") {
 				return true
@@ -195,13 +202,15 @@ type Pin struct {
 	defaultPull gpio.Pull // Default pull at system boot, as per datasheet.
 
 	// Immutable after driver initialization.
-	sysfsPin *sysfs.Pin // Set to the corresponding sysfs.Pin, if any.
+	sysfsPin *sysfs.Pin      // Set to the corresponding sysfs.Pin, if any.
+	gpioLine *sysfs.GPIOLine // Set to the corresponding sysfs.GPIOLine, if any.
 
 	// Mutable.
-	usingEdge  bool           // Set when edge detection is enabled.
+	edgePin    sysfs.EdgePin  // Set when edge detection is enabled.
 	usingClock bool           // Set when a CLK, PWM or I2S/PCM clock is used.
 	dmaCh      *dmaChannel    // Set when DMA is used for PWM or I2S/PCM.
 	dmaBuf     *videocore.Mem // Set when DMA is used for PWM or I2S/PCM.
+	sysfsPWM   *sysfs.PWM     // Set when PWM is done via sysfs-pwm.
 }
 
 // String implements conn.Resource.
@@ -216,11 +225,17 @@ func (p *Pin) String() string {
 // In the case of clock or PWM, all pins with this clock source are also
 // disabled.
 func (p *Pin) Halt() error {
-	if p.usingEdge {
-		if err := p.sysfsPin.Halt(); err != nil {
+	if p.edgePin != nil {
+		if err := p.edgePin.Halt(); err != nil {
+			return p.wrap(err)
+		}
+		p.edgePin = nil
+	}
+	if p.sysfsPWM != nil {
+		if err := p.sysfsPWM.Halt(); err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = false
+		p.sysfsPWM = nil
 	}
 	return p.haltClock()
 }
@@ -370,19 +385,21 @@ func (p *Pin) SetFunc(f pin.Func) error {
 //
 // Will fail if requesting to change a pin that is set to special functionality.
 //
-// Using edge detection requires opening a gpio sysfs file handle. On Raspbian,
-// make sure the user is member of group 'gpio'. The pin will be exported at
-// /sys/class/gpio/gpio*/. Note that the pin will not be unexported at
-// shutdown.
+// Using edge detection requires requesting the line from the GPIO character
+// device /dev/gpiochipN, or if not available, opening a gpio sysfs file
+// handle. On Raspbian, make sure the user is member of group 'gpio'. With
+// sysfs, the pin will be exported at /sys/class/gpio/gpio*/. Note that the pin
+// will not be unexported at shutdown.
 //
 // For edge detection, the processor samples the input at its CPU clock rate
 // and looks for '011' to rising and '100' for falling detection to avoid
-// glitches. Because gpio sysfs is used, the latency is unpredictable.
+// glitches. Because the kernel is used, the latency is unpredictable.
 func (p *Pin) In(pull gpio.Pull, edge gpio.Edge) error {
-	if p.usingEdge && edge == gpio.NoEdge {
-		if err := p.sysfsPin.Halt(); err != nil {
+	if p.edgePin != nil && edge == gpio.NoEdge {
+		if err := p.edgePin.Halt(); err != nil {
 			return p.wrap(err)
 		}
+		p.edgePin = nil
 	}
 	if drvGPIO.gpioMemory == nil {
 		if p.sysfsPin == nil {
@@ -394,7 +411,9 @@ func (p *Pin) In(pull gpio.Pull, edge gpio.Edge) error {
 		if err := p.sysfsPin.In(pull, edge); err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = edge != gpio.NoEdge
+		if edge != gpio.NoEdge {
+			p.edgePin = p.sysfsPin
+		}
 		return nil
 	}
 	if err := p.haltClock(); err != nil {
@@ -454,14 +473,12 @@ func (p *Pin) In(pull gpio.Pull, edge gpio.Edge) error {
 		}
 	}
 	if edge != gpio.NoEdge {
-		if p.sysfsPin == nil {
-			return p.wrap(fmt.Errorf("pin %d is not exported by sysfs", p.number))
-		}
 		// This resets pending edges.
-		if err := p.sysfsPin.In(gpio.PullNoChange, edge); err != nil {
+		e, err := sysfs.StartEdge(p.gpioLine, p.sysfsPin, pull, edge)
+		if err != nil {
 			return p.wrap(err)
 		}
-		p.usingEdge = true
+		p.edgePin = e
 	}
 	return nil
 }
@@ -498,12 +515,26 @@ func (p *Pin) FastRead() gpio.Level {
 
 // WaitForEdge implements gpio.PinIn.
 func (p *Pin) WaitForEdge(timeout time.Duration) bool {
+	if p.edgePin != nil {
+		return p.edgePin.WaitForEdge(timeout)
+	}
 	if p.sysfsPin != nil {
 		return p.sysfsPin.WaitForEdge(timeout)
 	}
 	return false
 }
 
+// WaitForEvent implements gpio.PinEventer.
+//
+// The precision of the timestamp depends on whether the GPIO character device
+// is available; see sysfs.StartEdge.
+func (p *Pin) WaitForEvent(timeout time.Duration) (gpio.EdgeEvent, bool) {
+	if p.edgePin != nil {
+		return p.edgePin.WaitForEvent(timeout)
+	}
+	return gpio.EdgeEvent{}, false
+}
+
 // Pull implements gpio.PinIn.
 //
 // bcm2711/bcm2838 support querying the pull resistor of all GPIO pins. Prior
@@ -606,7 +637,26 @@ func (p *Pin) FastOut(l gpio.Level) {
 // The user must call either Halt(), In(), Out(), PWM(0,..) or
 // PWM(gpio.DutyMax,..) to stop the clock source and DMA engine before exiting
 // the program.
+//
+// If "bcm283x-dma" is not loaded and the board driver registered a sysfs-pwm
+// channel for this pin, the kernel driver is used instead. This requires the
+// pin to be configured by a device tree overlay, e.g. "dtoverlay=pwm". As a
+// channel can be routed to two pins, an error is returned if the pin is not
+// muxed to its PWM function. This can only be verified when /dev/gpiomem is
+// accessible.
 func (p *Pin) PWM(duty gpio.Duty, freq physic.Frequency) error {
+	if drvDMA.pwmMemory == nil {
+		if s := sysfs.PWMByPin(p.name); s != nil {
+			if drvGPIO.gpioMemory != nil && p.function() != p.pwmFunction() {
+				return p.wrap(fmt.Errorf("%s is not routed to this pin; check the device tree overlay", s))
+			}
+			if err := s.PWM(duty, freq); err != nil {
+				return p.wrap(err)
+			}
+			p.sysfsPWM = s
+			return nil
+		}
+	}
 	if duty == 0 {
 		return p.Out(gpio.Low)
 	} else if duty == gpio.DutyMax {
@@ -883,6 +933,19 @@ func (p *Pin) function() function {
 	return function((drvGPIO.gpioMemory.functionSelect[p.number/10] >> uint((p.number%10)*3)) & 7)
 }
 
+// pwmFunction returns the alternate function that routes a PWM channel to the
+// pin, or in if the pin doesn't support PWM.
+func (p *Pin) pwmFunction() function {
+	switch p.number {
+	case 12, 13, 40, 41, 45:
+		return alt0
+	case 18, 19:
+		return alt5
+	default:
+		return in
+	}
+}
+
 // setFunction changes the GPIO pin function.
 func (p *Pin) setFunction(f function) {
 	off := p.number / 10
@@ -896,6 +959,16 @@ func (p *Pin) setFunction(f function) {
 	// TODO(maruel): pinreg.Register()
 }
 
+// gpioLine returns the GPIO character device line of the GPIO number, if any.
+func gpioLine(number int) *sysfs.GPIOLine {
+	for _, label := range []string{"pinctrl-bcm2835", "pinctrl-bcm2711"} {
+		if l := sysfs.GPIOLineByLabel(label, number); l != nil {
+			return l
+		}
+	}
+	return nil
+}
+
 func (p *Pin) wrap(err error) error {
 	return fmt.Errorf("bcm283x-gpio (%s): %v", p, err)
 }
@@ -1253,6 +1326,8 @@ type driverGPIO struct {
 	// useLegacyPull is set when the old slow pull resistor setup method before
 	// bcm2711 must be used.
 	useLegacyPull bool
+	// isBCM2711 is set when the device tree reports a bcm2711 (RPi4).
+	isBCM2711 bool
 }
 
 func (d *driverGPIO) Close() {
@@ -1260,6 +1335,7 @@ func (d *driverGPIO) Close() {
 	d.dramBus = 0
 	d.gpioMemory = nil
 	d.gpioBaseAddr = 0
+	d.isBCM2711 = false
 }
 
 func (d *driverGPIO) String() string {
@@ -1271,7 +1347,7 @@ func (d *driverGPIO) Prerequisites() []string {
 }
 
 func (d *driverGPIO) After() []string {
-	return []string{"sysfs-gpio"}
+	return []string{"sysfs-gpio", "sysfs-gpiochip"}
 }
 
 func (d *driverGPIO) Init() (bool, error) {
@@ -1304,6 +1380,8 @@ func (d *driverGPIO) Init() (bool, error) {
 		// setup internal pull resistors.
 		d.useLegacyPull = false
 	}
+	d.isBCM2711 = strings.Contains(dTCompatible, "bcm2711") ||
+		strings.Contains(dTCompatible, "bcm2838")
 	// Page 6.
 	// Virtual addresses in kernel mode will range between 0xC0000000 and
 	// 0xEFFFFFFF.
@@ -1324,6 +1402,7 @@ func (d *driverGPIO) Init() (bool, error) {
 
 		// Initializes the sysfs corresponding pin right away.
 		cpuPins[i].sysfsPin = sysfs.Pins[cpuPins[i].number]
+		cpuPins[i].gpioLine = gpioLine(cpuPins[i].number)
 
 		// Unregister the pin if already registered. This happens with sysfs-gpio.
 		// Do not error on it, since sysfs-gpio may have failed to load.
@@ -1436,6 +1515,7 @@ func init() {
 var drvGPIO driverGPIO
 
 var _ gpio.PinIO = &Pin{}
+var _ gpio.PinEventer = &Pin{}
 var _ gpio.PinIn = &Pin{}
 var _ gpio.PinOut = &Pin{}
 var _ gpiostream.PinIn = &Pin{}
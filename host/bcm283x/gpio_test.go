@@ -6,6 +6,7 @@ package bcm283x
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/meandrewdev/periph/conn/gpio"
@@ -16,6 +17,7 @@ import (
 	"github.com/meandrewdev/periph/conn/spi"
 	"github.com/meandrewdev/periph/conn/uart"
 	"github.com/meandrewdev/periph/host/pmem"
+	"github.com/meandrewdev/periph/host/sysfs"
 	"github.com/meandrewdev/periph/host/videocore"
 )
 
@@ -93,6 +95,9 @@ func TestPin_NoMem(t *testing.T) {
 	if p.WaitForEdge(-1) {
 		t.Fatal("edge not initialized")
 	}
+	if _, ok := p.WaitForEvent(-1); ok {
+		t.Fatal("edge not initialized")
+	}
 
 	// gpio.PinOut
 	if p.Out(gpio.Low) == nil {
@@ -127,6 +132,10 @@ func TestPin(t *testing.T) {
 	if err := p.In(gpio.Float, gpio.NoEdge); err != nil {
 		t.Fatal(err)
 	}
+	// Neither GPIO character device line nor sysfs pin.
+	if err := p.In(gpio.Float, gpio.RisingEdge); err == nil {
+		t.Fatal("edge detection is not available")
+	}
 	if d := p.Read(); d != gpio.Low {
 		t.Fatal(d)
 	}
@@ -289,6 +298,20 @@ func TestPinPWM(t *testing.T) {
 	}
 }
 
+func TestPinPWM_sysfs(t *testing.T) {
+	defer reset()
+	drvGPIO.gpioMemory = &gpioMap{}
+	// PWM1 can be muxed on GPIO13 and GPIO19 but the overlay routes it to only
+	// one of them.
+	p := Pin{name: "C19", number: 19}
+	if err := sysfs.RegisterPWMPin(p.name, &sysfs.PWM{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.PWM(gpio.DutyHalf, physic.KiloHertz); err == nil || !strings.Contains(err.Error(), "is not routed to this pin") {
+		t.Fatal(err)
+	}
+}
+
 func TestPinStreamIn(t *testing.T) {
 	defer reset()
 	p := Pin{name: "C1", number: 4, defaultPull: gpio.PullDown}
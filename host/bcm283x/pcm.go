@@ -9,11 +9,235 @@ package bcm283x
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/i2s"
 	"github.com/meandrewdev/periph/conn/physic"
 )
 
+// NewPCM returns the I²S port of the PCM block, accessed via direct register
+// access.
+//
+// The PCM block is the bus master; it drives I2S_SCK and I2S_WS. Frames are
+// transferred through the FIFOs by polling, which is only suitable for low
+// sample rates or short bursts. For higher sample rates, use the ALSA driver
+// via sysfs.NewI2S().
+//
+// The pins GPIO18 to GPIO21 are used.
+func NewPCM() (*PCM, error) {
+	if drvDMA.pcmMemory == nil {
+		return nil, errors.New("bcm283x-pcm: subsystem PCM not initialized; try running as root?")
+	}
+	return &PCM{}, nil
+}
+
+// PCM is the I²S port of the PCM block.
+//
+// It implements both i2s.PortCloser and i2s.Conn; Read() and Write() can be
+// used concurrently as the link is full duplex.
+type PCM struct {
+	mu        sync.Mutex
+	connected bool
+	cfg       i2s.Config
+}
+
+func (p *PCM) String() string {
+	return "PCM"
+}
+
+// Close implements i2s.PortCloser.
+//
+// It stops the PCM block.
+func (p *PCM) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.connected {
+		drvDMA.pcmMemory.reset()
+		p.connected = false
+	}
+	return nil
+}
+
+// Connect implements i2s.Port.
+//
+// Only one or two channels are supported. The actual rate depends on the
+// available clock dividers; use Config() to retrieve it.
+func (p *PCM) Connect(c *i2s.Config) (i2s.Conn, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if c.Channels > 2 {
+		return nil, fmt.Errorf("bcm283x-pcm: %d channels is not supported", c.Channels)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.connected {
+		return nil, errors.New("bcm283x-pcm: Connect() can only be called exactly once")
+	}
+	if drvGPIO.gpioMemory != nil {
+		for _, pin := range []*Pin{GPIO18, GPIO19, GPIO20, GPIO21} {
+			pin.setFunction(alt0)
+		}
+	}
+	l := pcmFrameLength(c)
+	drvDMA.pcmMemory.reset()
+	actual, _, err := setPCMClockSource(c.Rate * physic.Frequency(l))
+	if err != nil {
+		return nil, fmt.Errorf("bcm283x-pcm: %v", err)
+	}
+	p.cfg = *c
+	p.cfg.Rate = actual / physic.Frequency(l)
+	drvDMA.pcmMemory.configure(c)
+	p.connected = true
+	return p, nil
+}
+
+// Halt implements conn.Resource.
+//
+// It discards the frames in the FIFOs.
+func (p *PCM) Halt() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.connected {
+		return nil
+	}
+	drvDMA.pcmMemory.cs |= pcmTXClear | pcmRXClear
+	return nil
+}
+
+// Config implements i2s.Conn.
+func (p *PCM) Config() i2s.Config {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cfg
+}
+
+// Read implements io.Reader.
+//
+// It captures len(b)/Config().FrameSize() frames.
+func (p *PCM) Read(b []byte) (int, error) {
+	s, err := p.sampleSize(len(b))
+	if err != nil {
+		return 0, err
+	}
+	m := drvDMA.pcmMemory
+	for i := 0; i < len(b); i += s {
+		if err := m.wait(pcmRXData); err != nil {
+			return i, err
+		}
+		putSample(b[i:i+s], m.fifo)
+	}
+	return len(b), nil
+}
+
+// Write implements io.Writer.
+//
+// It plays back len(b)/Config().FrameSize() frames.
+func (p *PCM) Write(b []byte) (int, error) {
+	s, err := p.sampleSize(len(b))
+	if err != nil {
+		return 0, err
+	}
+	m := drvDMA.pcmMemory
+	for i := 0; i < len(b); i += s {
+		if err := m.wait(pcmTXData); err != nil {
+			return i, err
+		}
+		m.fifo = getSample(b[i : i+s])
+	}
+	return len(b), nil
+}
+
+// SCK implements i2s.Pins.
+func (p *PCM) SCK() gpio.PinIO {
+	return GPIO18
+}
+
+// WS implements i2s.Pins.
+func (p *PCM) WS() gpio.PinIO {
+	return GPIO19
+}
+
+// IN implements i2s.Pins.
+func (p *PCM) IN() gpio.PinIn {
+	return GPIO20
+}
+
+// OUT implements i2s.Pins.
+func (p *PCM) OUT() gpio.PinOut {
+	return GPIO21
+}
+
+// Private details.
+
+// pcmFIFOTimeout is the maximum duration to wait for the FIFO to be ready.
+//
+// It is much longer than the duration of a sample at any usable rate, so it
+// is only hit when the clock is not running.
+const pcmFIFOTimeout = 100 * time.Millisecond
+
+func (p *PCM) sampleSize(l int) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.connected {
+		return 0, errors.New("bcm283x-pcm: not connected")
+	}
+	if l%p.cfg.FrameSize() != 0 {
+		return 0, fmt.Errorf("bcm283x-pcm: buffer length %d is not a multiple of the frame size %d", l, p.cfg.FrameSize())
+	}
+	return p.cfg.SampleSize(), nil
+}
+
+// pcmSlotWidth returns the number of clocks per channel.
+//
+// Right justified framing uses 32 bits slots, so the samples are aligned on
+// the end of the slot.
+func pcmSlotWidth(c *i2s.Config) int {
+	if c.Framing == i2s.RightJustified {
+		return 32
+	}
+	return c.Bits
+}
+
+// pcmFrameLength returns the number of clocks per frame.
+//
+// There are always two slots, even in mono. DSP framing adds one clock for
+// the frame sync pulse.
+func pcmFrameLength(c *i2s.Config) int {
+	l := 2 * pcmSlotWidth(c)
+	if c.Framing == i2s.DSP {
+		l++
+	}
+	return l
+}
+
+// pcmChannel returns the 16 bits channel configuration for the RXC and TXC
+// registers.
+//
+// The width is encoded as CHxWEX*16 + CHxWID + 8.
+func pcmChannel(pos, bits int) uint32 {
+	w := uint32(bits - 8)
+	return (w>>4)<<15 | 1<<14 | uint32(pos)<<4 | w&0xF
+}
+
+// putSample stores the little endian sample v in b.
+func putSample(b []byte, v uint32) {
+	for i := range b {
+		b[i] = byte(v >> uint(8*i))
+	}
+}
+
+// getSample returns the little endian sample in b.
+func getSample(b []byte) uint32 {
+	v := uint32(0)
+	for i := range b {
+		v |= uint32(b[i]) << uint(8*i)
+	}
+	return v
+}
+
 type pcmCS uint32
 
 // Pages 126-129
@@ -213,6 +437,61 @@ func (p *pcmMap) set() {
 	p.cs |= pcmTXEnable
 }
 
+// configure sets the PCM block as the bus master with the framing, sample
+// width and number of channels specified.
+//
+// The clock must be already set.
+func (p *pcmMap) configure(c *i2s.Config) {
+	slot := pcmSlotWidth(c)
+	// Position of the first bit of each channel, in clocks after the frame
+	// sync.
+	pos := 0
+	mode := pcmMode(pcmFrameLength(c)-1) << pcmFrameLengthShift
+	switch c.Framing {
+	case i2s.Philips:
+		// WS is low for the left channel and the data is delayed by one clock.
+		mode |= pcmFSInverted | pcmMode(slot)
+		pos = 1
+	case i2s.LeftJustified:
+		mode |= pcmMode(slot)
+	case i2s.RightJustified:
+		mode |= pcmMode(slot)
+		pos = slot - c.Bits
+	case i2s.DSP:
+		mode |= 1
+		pos = 1
+	}
+	ch := pcmChannel(pos, c.Bits) << 16
+	if c.Channels == 2 {
+		// In DSP mode, the channels are back to back.
+		next := pos + slot
+		if c.Framing == i2s.DSP {
+			next = pos + c.Bits
+		}
+		ch |= pcmChannel(next, c.Bits)
+	}
+	p.cs = pcmEnable
+	p.mode = mode
+	p.rxc = pcmRX(ch)
+	p.txc = pcmTX(ch)
+	p.cs |= pcmTXClear | pcmRXClear
+	// In theory need to wait the equivalent of 2 PCM clocks.
+	// TODO(maruel): Use pcmSync busy loop to synchronize.
+	Nanospin(time.Microsecond)
+	p.cs |= pcmRXSignExtend | pcmTXEnable | pcmRXEnable
+}
+
+// wait waits for the FIFO status flag f to be set.
+func (p *pcmMap) wait(f pcmCS) error {
+	for start := time.Now(); p.cs&f == 0; {
+		if time.Since(start) > pcmFIFOTimeout {
+			return errors.New("bcm283x-pcm: FIFO timed out; is the clock running?")
+		}
+		Nanospin(time.Microsecond)
+	}
+	return nil
+}
+
 // setPCMClockSource sets the PCM clock.
 //
 // It may select an higher frequency than the one requested.
@@ -232,3 +511,7 @@ func setPCMClockSource(f physic.Frequency) (physic.Frequency, uint32, error) {
 	// Convert divisor into wait cycles.
 	return actual, divs, err
 }
+
+var _ i2s.PortCloser = &PCM{}
+var _ i2s.Conn = &PCM{}
+var _ i2s.Pins = &PCM{}
@@ -0,0 +1,150 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package bcm283x
+
+import (
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/i2s"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+func TestNewPCM(t *testing.T) {
+	defer reset()
+	if _, err := NewPCM(); err == nil {
+		t.Fatal("pcmMemory is nil")
+	}
+	drvDMA.pcmMemory = &pcmMap{}
+	p, err := NewPCM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := p.String(); s != "PCM" {
+		t.Fatal(s)
+	}
+	if p.SCK() != GPIO18 || p.WS() != GPIO19 || p.IN() != GPIO20 || p.OUT() != GPIO21 {
+		t.Fatal("unexpected pins")
+	}
+	if _, err := p.Connect(&i2s.Config{}); err == nil {
+		t.Fatal("invalid config")
+	}
+	if _, err := p.Connect(&i2s.Config{Rate: 48 * physic.KiloHertz, Bits: 16, Channels: 4}); err == nil {
+		t.Fatal("too many channels")
+	}
+	if _, err := p.Connect(&i2s.Config{Rate: 48 * physic.KiloHertz, Bits: 16, Channels: 2}); err == nil {
+		t.Fatal("clockMemory is nil")
+	}
+	drvDMA.clockMemory = &clockMap{}
+	if _, err := p.Connect(&i2s.Config{Rate: 48 * physic.KiloHertz, Bits: 16, Channels: 2}); err == nil {
+		t.Fatal("can't write to clock register")
+	}
+	if _, err := p.Write(make([]byte, 4)); err == nil {
+		t.Fatal("not connected")
+	}
+	if err := p.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPCM_ReadWrite(t *testing.T) {
+	defer reset()
+	m := &pcmMap{}
+	drvDMA.pcmMemory = m
+	p := PCM{connected: true, cfg: i2s.Config{Rate: 8 * physic.KiloHertz, Bits: 24, Channels: 1}}
+	if c := p.Config(); c.Bits != 24 {
+		t.Fatal(c)
+	}
+	if _, err := p.Write(make([]byte, 3)); err == nil {
+		t.Fatal("partial frame")
+	}
+	m.cs = pcmTXData | pcmRXData
+	if n, err := p.Write([]byte{1, 2, 3, 0}); n != 4 || err != nil {
+		t.Fatal(n, err)
+	}
+	if m.fifo != 0x030201 {
+		t.Fatalf("0x%x", m.fifo)
+	}
+	m.fifo = 0xFFFFFFFE
+	var b [4]byte
+	if n, err := p.Read(b[:]); n != 4 || err != nil || b != [4]byte{0xFE, 0xFF, 0xFF, 0xFF} {
+		t.Fatal(n, err, b)
+	}
+	if err := p.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if m.cs&(pcmTXClear|pcmRXClear) != pcmTXClear|pcmRXClear {
+		t.Fatalf("0x%x", m.cs)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if m.cs != 0 {
+		t.Fatalf("0x%x", m.cs)
+	}
+}
+
+func TestPCMMap_wait(t *testing.T) {
+	m := pcmMap{}
+	if err := m.wait(pcmTXData); err == nil {
+		t.Fatal("expected timeout")
+	}
+}
+
+func TestPCMMap_configure(t *testing.T) {
+	data := []struct {
+		cfg  i2s.Config
+		mode pcmMode
+		ch   uint32
+	}{
+		{
+			i2s.Config{Bits: 16, Channels: 2, Framing: i2s.Philips},
+			pcmFSInverted | 31<<pcmFrameLengthShift | 16,
+			0x4018<<16 | 0x4118,
+		},
+		{
+			i2s.Config{Bits: 24, Channels: 2, Framing: i2s.LeftJustified},
+			47<<pcmFrameLengthShift | 24,
+			0xC000<<16 | 0xC180,
+		},
+		{
+			i2s.Config{Bits: 16, Channels: 1, Framing: i2s.RightJustified},
+			63<<pcmFrameLengthShift | 32,
+			0x4108 << 16,
+		},
+		{
+			i2s.Config{Bits: 8, Channels: 2, Framing: i2s.DSP},
+			16<<pcmFrameLengthShift | 1,
+			0x4010<<16 | 0x4090,
+		},
+	}
+	for i, line := range data {
+		m := pcmMap{}
+		m.configure(&line.cfg)
+		if m.mode != line.mode {
+			t.Fatalf("#%d: mode 0x%x != 0x%x", i, m.mode, line.mode)
+		}
+		if uint32(m.txc) != line.ch || uint32(m.rxc) != line.ch {
+			t.Fatalf("#%d: 0x%x 0x%x != 0x%x", i, m.txc, m.rxc, line.ch)
+		}
+		if e := pcmEnable | pcmTXClear | pcmRXClear | pcmRXSignExtend | pcmTXEnable | pcmRXEnable; m.cs != e {
+			t.Fatalf("#%d: cs 0x%x != 0x%x", i, m.cs, e)
+		}
+	}
+}
+
+func TestPCMFrameLength(t *testing.T) {
+	if l := pcmFrameLength(&i2s.Config{Bits: 16}); l != 32 {
+		t.Fatal(l)
+	}
+	if l := pcmFrameLength(&i2s.Config{Bits: 16, Framing: i2s.RightJustified}); l != 64 {
+		t.Fatal(l)
+	}
+	if l := pcmFrameLength(&i2s.Config{Bits: 16, Framing: i2s.DSP}); l != 33 {
+		t.Fatal(l)
+	}
+}
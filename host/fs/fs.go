@@ -9,6 +9,7 @@
 package fs
 
 import (
+	"context"
 	"errors"
 	"os"
 	"sync"
@@ -86,11 +87,28 @@ func (e *Event) MakeEvent(fd uintptr) error {
 	return e.event.makeEvent(fd)
 }
 
+// MakeReadEvent initializes an epoll *level* triggered event on linux that is
+// signaled when data is available for reading.
+//
+// This is the behavior expected by character devices that queue records to be
+// read, like the line handles returned by /dev/gpiochipN. The event stays
+// signaled until all the pending data is read.
+func (e *Event) MakeReadEvent(fd uintptr) error {
+	return e.event.makeReadEvent(fd)
+}
+
 // Wait waits for an event or the specified amount of time.
 func (e *Event) Wait(timeoutms int) (int, error) {
 	return e.event.wait(timeoutms)
 }
 
+// WaitContext waits for an event until ctx is done.
+//
+// Returns 1 when the event is signaled and ctx.Err() when ctx is done first.
+func (e *Event) WaitContext(ctx context.Context) (int, error) {
+	return e.event.waitContext(ctx)
+}
+
 //
 
 var (
@@ -5,6 +5,7 @@
 package fs
 
 import (
+	"context"
 	"strconv"
 	"strings"
 	"syscall"
@@ -86,6 +87,10 @@ type event struct {
 	event   [1]syscall.EpollEvent
 	epollFd int
 	fd      int
+	// wake is a non-blocking pipe used by waitContext() to interrupt
+	// EpollWait() upon cancellation. It is lazily created.
+	wake    [2]int
+	hasWake bool
 }
 
 // makeEvent creates an epoll *edge* triggered event.
@@ -95,6 +100,19 @@ type event struct {
 // syscall.EpollCreate: http://man7.org/linux/man-pages/man2/epoll_create.2.html
 // syscall.EpollCtl: http://man7.org/linux/man-pages/man2/epoll_ctl.2.html
 func (e *event) makeEvent(fd uintptr) error {
+	// EPOLLWAKEUP could be used to force the system to not go do sleep while
+	// waiting for an edge. This is generally a bad idea, as we'd instead have
+	// the system to *wake up* when an edge is triggered. Achieving this is
+	// outside the scope of this interface.
+	return e.add(fd, epollPRI|epollET)
+}
+
+// makeReadEvent creates an epoll *level* triggered event on data availability.
+func (e *event) makeReadEvent(fd uintptr) error {
+	return e.add(fd, epollIN)
+}
+
+func (e *event) add(fd uintptr, flags epollEvent) error {
 	epollFd, err := syscall.EpollCreate(1)
 	switch {
 	case err == nil:
@@ -109,11 +127,7 @@ func (e *event) makeEvent(fd uintptr) error {
 	}
 	e.epollFd = epollFd
 	e.fd = int(fd)
-	// EPOLLWAKEUP could be used to force the system to not go do sleep while
-	// waiting for an edge. This is generally a bad idea, as we'd instead have
-	// the system to *wake up* when an edge is triggered. Achieving this is
-	// outside the scope of this interface.
-	e.event[0].Events = uint32(epollPRI | epollET)
+	e.event[0].Events = uint32(flags)
 	e.event[0].Fd = int32(e.fd)
 	return syscall.EpollCtl(e.epollFd, epollCTLAdd, e.fd, &e.event[0])
 }
@@ -122,3 +136,75 @@ func (e *event) wait(timeoutms int) (int, error) {
 	// http://man7.org/linux/man-pages/man2/epoll_wait.2.html
 	return syscall.EpollWait(e.epollFd, e.event[:], timeoutms)
 }
+
+// waitContext waits for the event until ctx is done.
+//
+// A pipe is added to the epoll set so the wait can be interrupted by a
+// goroutine watching ctx.
+func (e *event) waitContext(ctx context.Context) (int, error) {
+	done := ctx.Done()
+	if done == nil {
+		return e.wait(-1)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if !e.hasWake {
+		if err := syscall.Pipe2(e.wake[:], syscall.O_NONBLOCK|syscall.O_CLOEXEC); err != nil {
+			return 0, err
+		}
+		ev := syscall.EpollEvent{Events: uint32(epollIN), Fd: int32(e.wake[0])}
+		if err := syscall.EpollCtl(e.epollFd, epollCTLAdd, e.wake[0], &ev); err != nil {
+			_ = syscall.Close(e.wake[0])
+			_ = syscall.Close(e.wake[1])
+			return 0, err
+		}
+		e.hasWake = true
+	}
+	stop := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-done:
+			_, _ = syscall.Write(e.wake[1], []byte{0})
+		case <-stop:
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-exited
+		e.drainWake()
+	}()
+	var events [2]syscall.EpollEvent
+	for {
+		// http://man7.org/linux/man-pages/man2/epoll_wait.2.html
+		n, err := syscall.EpollWait(e.epollFd, events[:], -1)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < n; i++ {
+			if events[i].Fd == int32(e.fd) {
+				e.event[0] = events[i]
+				return 1, nil
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		e.drainWake()
+	}
+}
+
+// drainWake empties the wake pipe.
+func (e *event) drainWake() {
+	var b [16]byte
+	for {
+		if n, err := syscall.Read(e.wake[0], b[:]); n <= 0 || err != nil {
+			return
+		}
+	}
+}
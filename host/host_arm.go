@@ -16,5 +16,6 @@ import (
 	// While this board is ARM64, it may run ARM 32 bits binaries so load it on
 	// 32 bits builds too.
 	_ "github.com/meandrewdev/periph/host/pine64"
+	_ "github.com/meandrewdev/periph/host/rp1"
 	_ "github.com/meandrewdev/periph/host/rpi"
 )
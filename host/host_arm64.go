@@ -9,5 +9,6 @@ import (
 	_ "github.com/meandrewdev/periph/host/allwinner"
 	_ "github.com/meandrewdev/periph/host/bcm283x"
 	_ "github.com/meandrewdev/periph/host/pine64"
+	_ "github.com/meandrewdev/periph/host/rp1"
 	_ "github.com/meandrewdev/periph/host/rpi"
 )
@@ -0,0 +1,34 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package rp1 exposes the GPIO and PWM functionality of the RP1 I/O
+// controller found on the Raspberry Pi 5.
+//
+// The RP1 is connected to the BCM2712 CPU over PCIe. Its registers are memory
+// mapped through /dev/mem, so this driver requires root access. It implements
+// memory-mapped GPIO pin manipulation and leverages the GPIO character device
+// for edge detection, falling back to sysfs-gpio when it is not available.
+//
+// The SPI, I²C and UART controllers of the RP1 are used via the kernel drivers
+// exposed by package sysfs; this package only handles the pin functions.
+//
+// GPIOs
+//
+// Only the bank 0, GPIO0 to GPIO27, is exposed. These are the pins routed to
+// the 40 pins header. Banks 1 and 2 are used internally by the board.
+//
+// GPIO state is changed via the registered I/O (RIO) block, which supports
+// atomic set and clear, so FastOut() is safe to use concurrently on different
+// pins.
+//
+// Aliases for GPCLK0, GPCLK1, GPCLK2 are created for corresponding CLKn pins.
+//
+// PWM
+//
+// The PWM0 controller has 4 channels, exposed as the functions PWM0 to PWM3.
+//
+// Datasheet
+//
+// https://datasheets.raspberrypi.com/rp1/rp1-peripherals.pdf
+package rp1
@@ -0,0 +1,925 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package rp1
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meandrewdev/periph"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/gpio/gpioreg"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/pin"
+	"github.com/meandrewdev/periph/host/distro"
+	"github.com/meandrewdev/periph/host/pmem"
+	"github.com/meandrewdev/periph/host/sysfs"
+)
+
+// All the pins supported by the RP1 bank 0.
+var (
+	GPIO0  *Pin // I2C0_SDA, UART1_TX, SPI2_CS0
+	GPIO1  *Pin // I2C0_SCL, UART1_RX, SPI2_MISO
+	GPIO2  *Pin // I2C1_SDA, UART1_CTS, SPI2_MOSI
+	GPIO3  *Pin // I2C1_SCL, UART1_RTS, SPI2_CLK
+	GPIO4  *Pin // CLK0, UART2_TX, I2C2_SDA, SPI3_CS0
+	GPIO5  *Pin // CLK1, UART2_RX, I2C2_SCL, SPI3_MISO
+	GPIO6  *Pin // CLK2, UART2_CTS, I2C3_SDA, SPI3_MOSI
+	GPIO7  *Pin // SPI0_CS1, UART2_RTS, I2C3_SCL, SPI3_CLK
+	GPIO8  *Pin // SPI0_CS0, UART3_TX, I2C0_SDA, SPI4_CS0
+	GPIO9  *Pin // SPI0_MISO, UART3_RX, I2C0_SCL, SPI4_MISO
+	GPIO10 *Pin // SPI0_MOSI, UART3_CTS, I2C1_SDA, SPI4_MOSI
+	GPIO11 *Pin // SPI0_CLK, UART3_RTS, I2C1_SCL, SPI4_CLK
+	GPIO12 *Pin // PWM0, UART4_TX, I2C2_SDA, SPI5_CS0
+	GPIO13 *Pin // PWM1, UART4_RX, I2C2_SCL, SPI5_MISO
+	GPIO14 *Pin // PWM2, UART4_CTS, I2C3_SDA, UART0_TX, SPI5_MOSI
+	GPIO15 *Pin // PWM3, UART4_RTS, I2C3_SCL, UART0_RX, SPI5_CLK
+	GPIO16 *Pin // SPI1_CS2, UART0_CTS
+	GPIO17 *Pin // SPI1_CS1, UART0_RTS
+	GPIO18 *Pin // SPI1_CS0, I2S0_SCK, PWM2, I2S1_SCK, CLK1
+	GPIO19 *Pin // SPI1_MISO, I2S0_WS, PWM3, I2S1_WS
+	GPIO20 *Pin // SPI1_MOSI, I2S0_DIN, CLK0, I2S1_DIN
+	GPIO21 *Pin // SPI1_CLK, I2S0_DOUT, CLK1, I2S1_DOUT
+	GPIO22 *Pin // I2C3_SDA
+	GPIO23 *Pin // I2C3_SCL
+	GPIO24 *Pin // SPI2_CS1
+	GPIO25 *Pin // SPI3_CS1
+	GPIO26 *Pin // SPI5_CS1
+	GPIO27 *Pin // SPI1_CS1
+)
+
+// Present returns true if running on a Raspberry Pi 5 class board, which
+// uses a RP1 I/O controller.
+func Present() bool {
+	if isArm {
+		for _, line := range distro.DTCompatible() {
+			if line == "brcm,bcm2712" || strings.HasPrefix(line, "raspberrypi,5") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PinsRead0To27 returns the value of all GPIO0 to GPIO27 at their
+// corresponding bit as a single read operation.
+//
+// This function is extremely fast and does no error checking.
+//
+// The returned bits are valid for both inputs and outputs.
+func PinsRead0To27() uint32 {
+	return drvGPIO.rioMemory.rw.syncIn & bank0Mask
+}
+
+// PinsClear0To27 clears the value of GPIO0 to GPIO27 pin for the bit set at
+// their corresponding bit as a single write operation.
+//
+// This function is extremely fast and does no error checking.
+func PinsClear0To27(mask uint32) {
+	drvGPIO.rioMemory.clr.out = mask & bank0Mask
+}
+
+// PinsSet0To27 sets the value of GPIO0 to GPIO27 pin for the bit set at their
+// corresponding bit as a single write operation.
+//
+// This function is extremely fast and does no error checking.
+func PinsSet0To27(mask uint32) {
+	drvGPIO.rioMemory.set.out = mask & bank0Mask
+}
+
+// Pin is a GPIO number (GPIOnn) on the RP1 bank 0.
+//
+// Pin implements gpio.PinIO.
+type Pin struct {
+	// Immutable.
+	number      int
+	name        string
+	defaultPull gpio.Pull // Default pull at system boot, as per datasheet.
+
+	// Immutable after driver initialization.
+	sysfsPin *sysfs.Pin      // Set to the corresponding sysfs.Pin, if any.
+	gpioLine *sysfs.GPIOLine // Set to the corresponding sysfs.GPIOLine, if any.
+
+	// Mutable.
+	edgePin  sysfs.EdgePin // Set when edge detection is enabled.
+	usingPWM bool          // Set when the PWM channel of the pin is enabled.
+	sysfsPWM *sysfs.PWM    // Set when PWM is done via sysfs-pwm.
+}
+
+// String implements conn.Resource.
+func (p *Pin) String() string {
+	return p.name
+}
+
+// Halt implements conn.Resource.
+//
+// If the pin is running PWM or waiting for edges, it is halted.
+//
+// In the case of PWM, all pins using this channel are also disabled.
+func (p *Pin) Halt() error {
+	if p.edgePin != nil {
+		if err := p.edgePin.Halt(); err != nil {
+			return p.wrap(err)
+		}
+		p.edgePin = nil
+	}
+	return p.haltPWM()
+}
+
+// Name implements pin.Pin.
+func (p *Pin) Name() string {
+	return p.name
+}
+
+// Number implements pin.Pin.
+//
+// This is the GPIO number, not the pin number on a header.
+func (p *Pin) Number() int {
+	return p.number
+}
+
+// Function implements pin.Pin.
+func (p *Pin) Function() string {
+	return string(p.Func())
+}
+
+// Func implements pin.PinFunc.
+func (p *Pin) Func() pin.Func {
+	if drvGPIO.ioMemory == nil {
+		if p.sysfsPin == nil {
+			return pin.FuncNone
+		}
+		return p.sysfsPin.Func()
+	}
+	switch f := p.function(); {
+	case f == funcSysRIO:
+		if drvGPIO.rioMemory.rw.oe&(1<<uint(p.number)) != 0 {
+			if p.FastRead() {
+				return gpio.OUT_HIGH
+			}
+			return gpio.OUT_LOW
+		}
+		if p.FastRead() {
+			return gpio.IN_HIGH
+		}
+		return gpio.IN_LOW
+	case f < alts:
+		if s := mapping[p.number][f]; len(s) != 0 {
+			return s
+		}
+		return pin.Func("ALT" + strconv.Itoa(int(f)))
+	default:
+		return pin.FuncNone
+	}
+}
+
+// SupportedFuncs implements pin.PinFunc.
+func (p *Pin) SupportedFuncs() []pin.Func {
+	f := make([]pin.Func, 0, 2+4)
+	f = append(f, gpio.IN, gpio.OUT)
+	for _, m := range mapping[p.number] {
+		if m != "" {
+			f = append(f, m)
+		}
+	}
+	return f
+}
+
+// SetFunc implements pin.PinFunc.
+func (p *Pin) SetFunc(f pin.Func) error {
+	if drvGPIO.ioMemory == nil {
+		if p.sysfsPin == nil {
+			return p.wrap(errors.New("subsystem not initialized and sysfs not accessible"))
+		}
+		return p.sysfsPin.SetFunc(f)
+	}
+	switch f {
+	case gpio.FLOAT:
+		return p.In(gpio.Float, gpio.NoEdge)
+	case gpio.IN:
+		return p.In(gpio.PullNoChange, gpio.NoEdge)
+	case gpio.IN_LOW:
+		return p.In(gpio.PullDown, gpio.NoEdge)
+	case gpio.IN_HIGH:
+		return p.In(gpio.PullUp, gpio.NoEdge)
+	case gpio.OUT_HIGH:
+		return p.Out(gpio.High)
+	case gpio.OUT_LOW:
+		return p.Out(gpio.Low)
+	default:
+		isGeneral := f == f.Generalize()
+		for i, m := range mapping[p.number] {
+			if m == f || (isGeneral && m != "" && m.Generalize() == f) {
+				if err := p.Halt(); err != nil {
+					return err
+				}
+				p.setFunction(function(i))
+				return nil
+			}
+		}
+		return p.wrap(errors.New("unsupported function"))
+	}
+}
+
+// In implements gpio.PinIn.
+//
+// The pull resistor is set in the pad control register and can be read back
+// with Pull(). For pull up and pull down, the resistor is 50kOhm~80kOhm.
+//
+// Using edge detection requires requesting the line from the GPIO character
+// device /dev/gpiochipN, or if not available, opening a gpio sysfs file
+// handle. With sysfs, the pin will be exported at /sys/class/gpio/gpio*/. Note
+// that the pin will not be unexported at shutdown.
+func (p *Pin) In(pull gpio.Pull, edge gpio.Edge) error {
+	if p.edgePin != nil && edge == gpio.NoEdge {
+		if err := p.edgePin.Halt(); err != nil {
+			return p.wrap(err)
+		}
+		p.edgePin = nil
+	}
+	if drvGPIO.ioMemory == nil {
+		if p.sysfsPin == nil {
+			return p.wrap(errors.New("subsystem not initialized and sysfs not accessible"))
+		}
+		if pull != gpio.PullNoChange {
+			return p.wrap(errors.New("pull cannot be used when subsystem not initialized"))
+		}
+		if err := p.sysfsPin.In(pull, edge); err != nil {
+			return p.wrap(err)
+		}
+		if edge != gpio.NoEdge {
+			p.edgePin = p.sysfsPin
+		}
+		return nil
+	}
+	if err := p.haltPWM(); err != nil {
+		return err
+	}
+	drvGPIO.rioMemory.clr.oe = 1 << uint(p.number)
+	pd := drvGPIO.padMemory.gpio[p.number]&^padOutputDisable | padInputEnable
+	switch pull {
+	case gpio.PullDown:
+		pd = pd&^padPullUp | padPullDown
+	case gpio.PullUp:
+		pd = pd&^padPullDown | padPullUp
+	case gpio.Float:
+		pd &^= padPullUp | padPullDown
+	}
+	drvGPIO.padMemory.gpio[p.number] = pd
+	p.setFunction(funcSysRIO)
+	if edge != gpio.NoEdge {
+		// This resets pending edges.
+		e, err := sysfs.StartEdge(p.gpioLine, p.sysfsPin, pull, edge)
+		if err != nil {
+			return p.wrap(err)
+		}
+		p.edgePin = e
+	}
+	return nil
+}
+
+// Read implements gpio.PinIn.
+//
+// This function is fast. It works even if the pin is set as output.
+func (p *Pin) Read() gpio.Level {
+	if drvGPIO.rioMemory == nil {
+		if p.sysfsPin == nil {
+			return gpio.Low
+		}
+		return p.sysfsPin.Read()
+	}
+	return p.FastRead()
+}
+
+// FastRead return the current pin level without any error checking.
+//
+// This function is very fast. It works even if the pin is set as output.
+func (p *Pin) FastRead() gpio.Level {
+	return gpio.Level(drvGPIO.rioMemory.rw.syncIn&(1<<uint(p.number&31)) != 0)
+}
+
+// WaitForEdge implements gpio.PinIn.
+func (p *Pin) WaitForEdge(timeout time.Duration) bool {
+	if p.edgePin != nil {
+		return p.edgePin.WaitForEdge(timeout)
+	}
+	if p.sysfsPin != nil {
+		return p.sysfsPin.WaitForEdge(timeout)
+	}
+	return false
+}
+
+// WaitForEvent implements gpio.PinEventer.
+//
+// The precision of the timestamp depends on whether the GPIO character device
+// is available; see sysfs.StartEdge.
+func (p *Pin) WaitForEvent(timeout time.Duration) (gpio.EdgeEvent, bool) {
+	if p.edgePin != nil {
+		return p.edgePin.WaitForEvent(timeout)
+	}
+	return gpio.EdgeEvent{}, false
+}
+
+// Pull implements gpio.PinIn.
+//
+// Unlike on the bcm283x, the RP1 supports querying the pull resistor.
+func (p *Pin) Pull() gpio.Pull {
+	if drvGPIO.padMemory == nil {
+		return gpio.PullNoChange
+	}
+	switch pd := drvGPIO.padMemory.gpio[p.number]; {
+	case pd&padPullUp != 0:
+		return gpio.PullUp
+	case pd&padPullDown != 0:
+		return gpio.PullDown
+	default:
+		return gpio.Float
+	}
+}
+
+// DefaultPull implements gpio.PinIn.
+func (p *Pin) DefaultPull() gpio.Pull {
+	return p.defaultPull
+}
+
+// Out implements gpio.PinOut.
+//
+// Fails if requesting to change a pin that is set to special functionality.
+func (p *Pin) Out(l gpio.Level) error {
+	if drvGPIO.ioMemory == nil {
+		if p.sysfsPin == nil {
+			return p.wrap(errors.New("subsystem not initialized and sysfs not accessible"))
+		}
+		return p.sysfsPin.Out(l)
+	}
+	if err := p.Halt(); err != nil {
+		return err
+	}
+	// Change output before changing mode to not create any glitch.
+	p.FastOut(l)
+	drvGPIO.rioMemory.set.oe = 1 << uint(p.number)
+	// Keep the input enabled so Read() returns the actual pin level.
+	drvGPIO.padMemory.gpio[p.number] = drvGPIO.padMemory.gpio[p.number]&^padOutputDisable | padInputEnable
+	p.setFunction(funcSysRIO)
+	return nil
+}
+
+// FastOut sets a pin output level with Absolutely No error checking.
+//
+// Out() Must be called once first before calling FastOut(), otherwise the
+// behavior is undefined. Then FastOut() can be used for minimal CPU overhead
+// to reach Mhz scale bit banging.
+func (p *Pin) FastOut(l gpio.Level) {
+	mask := uint32(1) << uint(p.number&31)
+	if l == gpio.Low {
+		drvGPIO.rioMemory.clr.out = mask
+	} else {
+		drvGPIO.rioMemory.set.out = mask
+	}
+}
+
+// PWM implements gpio.PinOut.
+//
+// It outputs a periodic signal on supported pins without CPU usage.
+//
+// PWM pins
+//
+// PWM0 is exposed on pin 12, PWM1 on pin 13, PWM2 on pins 14 and 18 and PWM3
+// on pins 15 and 19.
+//
+// The controller uses a 50MHz clock source, so the frequency must be at most
+// 25MHz. The effective resolution of duty is lower at high frequencies.
+//
+// There is no conflict verification when multiple pins are used
+// simultaneously. The last call to PWM() affects all pins using the same
+// channel.
+//
+// If the driver "rp1-pwm" was not loaded and the board driver registered a
+// sysfs-pwm channel for this pin, the kernel driver is used instead. This
+// requires the pin to be configured by a device tree overlay. As PWM2 and
+// PWM3 can be routed to two pins, an error is returned if the pin is not
+// muxed to its PWM function. This can only be verified when the GPIO
+// registers are accessible.
+func (p *Pin) PWM(duty gpio.Duty, freq physic.Frequency) error {
+	if drvPWM.pwmMemory == nil {
+		if s := sysfs.PWMByPin(p.name); s != nil {
+			if alt, ch := p.pwmChannel(); drvGPIO.ioMemory != nil && (ch == -1 || p.function() != alt) {
+				return p.wrap(fmt.Errorf("%s is not routed to this pin; check the device tree overlay", s))
+			}
+			if err := s.PWM(duty, freq); err != nil {
+				return p.wrap(err)
+			}
+			p.sysfsPWM = s
+			return nil
+		}
+	}
+	if duty == 0 {
+		return p.Out(gpio.Low)
+	} else if duty == gpio.DutyMax {
+		return p.Out(gpio.High)
+	}
+	alt, ch := p.pwmChannel()
+	if ch == -1 {
+		return p.wrap(errors.New("PWM is not supported on this pin"))
+	}
+	// Intentionally check later, so a more informative error is returned on
+	// unsupported pins.
+	if drvGPIO.ioMemory == nil {
+		return p.wrap(errors.New("subsystem not initialized"))
+	}
+	if drvPWM.pwmMemory == nil {
+		return p.wrap(errors.New("rp1-pwm not initialized; try again as root?"))
+	}
+	if err := drvPWM.pwmMemory.set(ch, duty, freq); err != nil {
+		return p.wrap(err)
+	}
+	p.setFunction(alt)
+	p.usingPWM = true
+	return nil
+}
+
+// Drive returns the configured output current drive strength for this GPIO.
+//
+// The value returned by this function is not yet verified to be correct.
+func (p *Pin) Drive() physic.ElectricCurrent {
+	if drvGPIO.padMemory == nil {
+		return 0
+	}
+	return padDrives[(drvGPIO.padMemory.gpio[p.number]&padDriveMask)>>padDriveShift]
+}
+
+// SlewLimit returns true if the output slew is limited to reduce interference.
+//
+// The value returned by this function is not yet verified to be correct.
+func (p *Pin) SlewLimit() bool {
+	if drvGPIO.padMemory == nil {
+		return false
+	}
+	return drvGPIO.padMemory.gpio[p.number]&padSlewFast == 0
+}
+
+// Hysteresis returns true if the input hysteresis via a schmitt trigger is
+// enabled.
+//
+// The value returned by this function is not yet verified to be correct.
+func (p *Pin) Hysteresis() bool {
+	if drvGPIO.padMemory == nil {
+		return false
+	}
+	return drvGPIO.padMemory.gpio[p.number]&padSchmitt != 0
+}
+
+// Setup changes the pad settings of the pin.
+//
+// Unlike on the bcm283x, each pin has its own pad control register. The drive
+// strength is rounded up to 2, 4, 8 or 12mA.
+func (p *Pin) Setup(drive physic.ElectricCurrent, slewLimit, hysteresis bool) error {
+	if drvGPIO.padMemory == nil {
+		return p.wrap(errors.New("subsystem not initialized"))
+	}
+	const mask = padDriveMask | padSlewFast | padSchmitt
+	drvGPIO.padMemory.gpio[p.number] = drvGPIO.padMemory.gpio[p.number]&^mask | toPad(drive, slewLimit, hysteresis)
+	return nil
+}
+
+//
+
+// pwmChannel returns the alternate function and the PWM0 channel of the pin,
+// or -1 if the pin doesn't support PWM.
+func (p *Pin) pwmChannel() (function, int) {
+	for i, m := range mapping[p.number] {
+		if len(m) == 4 && strings.HasPrefix(string(m), "PWM") {
+			return function(i), int(m[3] - '0')
+		}
+	}
+	return 0, -1
+}
+
+func (p *Pin) haltPWM() error {
+	if p.sysfsPWM != nil {
+		if err := p.sysfsPWM.Halt(); err != nil {
+			return p.wrap(err)
+		}
+		p.sysfsPWM = nil
+	}
+	if !p.usingPWM {
+		return nil
+	}
+	p.usingPWM = false
+	if drvPWM.pwmMemory != nil {
+		if _, ch := p.pwmChannel(); ch != -1 {
+			drvPWM.pwmMemory.disable(ch)
+		}
+	}
+	return nil
+}
+
+// function returns the current GPIO pin function.
+func (p *Pin) function() function {
+	return function(drvGPIO.ioMemory.gpio[p.number].ctrl & funcSelMask)
+}
+
+// setFunction changes the GPIO pin function.
+func (p *Pin) setFunction(f function) {
+	c := &drvGPIO.ioMemory.gpio[p.number].ctrl
+	*c = *c&^funcSelMask | uint32(f)
+}
+
+func (p *Pin) wrap(err error) error {
+	return fmt.Errorf("rp1-gpio (%s): %v", p, err)
+}
+
+//
+
+// Each pin can only be used by one function at a time.
+//
+// Mapping as the RP1 peripherals datasheet, section 3.1.1. This excludes the
+// functions sys_rio, proc_rio and pio (alternate functions 5, 6 and 7) and
+// the DPI and SDIO functions.
+var mapping = [28][alts]pin.Func{
+	{"", "", "UART1_TX", "I2C0_SDA", "", "", "", "", "SPI2_CS0"}, // 0
+	{"", "", "UART1_RX", "I2C0_SCL", "", "", "", "", "SPI2_MISO"},
+	{"", "", "UART1_CTS", "I2C1_SDA", "", "", "", "", "SPI2_MOSI"},
+	{"", "", "UART1_RTS", "I2C1_SCL", "", "", "", "", "SPI2_CLK"},
+	{"CLK0", "", "UART2_TX", "I2C2_SDA", "", "", "", "", "SPI3_CS0"},
+	{"CLK1", "", "UART2_RX", "I2C2_SCL", "", "", "", "", "SPI3_MISO"}, // 5
+	{"CLK2", "", "UART2_CTS", "I2C3_SDA", "", "", "", "", "SPI3_MOSI"},
+	{"SPI0_CS1", "", "UART2_RTS", "I2C3_SCL", "", "", "", "", "SPI3_CLK"},
+	{"SPI0_CS0", "", "UART3_TX", "I2C0_SDA", "", "", "", "", "SPI4_CS0"},
+	{"SPI0_MISO", "", "UART3_RX", "I2C0_SCL", "", "", "", "", "SPI4_MISO"},
+	{"SPI0_MOSI", "", "UART3_CTS", "I2C1_SDA", "", "", "", "", "SPI4_MOSI"}, // 10
+	{"SPI0_CLK", "", "UART3_RTS", "I2C1_SCL", "", "", "", "", "SPI4_CLK"},
+	{"PWM0", "", "UART4_TX", "I2C2_SDA", "", "", "", "", "SPI5_CS0"},
+	{"PWM1", "", "UART4_RX", "I2C2_SCL", "", "", "", "", "SPI5_MISO"},
+	{"PWM2", "", "UART4_CTS", "I2C3_SDA", "UART0_TX", "", "", "", "SPI5_MOSI"},
+	{"PWM3", "", "UART4_RTS", "I2C3_SCL", "UART0_RX", "", "", "", "SPI5_CLK"}, // 15
+	{"SPI1_CS2", "", "", "", "UART0_CTS"},
+	{"SPI1_CS1", "", "", "", "UART0_RTS"},
+	{"SPI1_CS0", "", "I2S0_SCK", "PWM2", "I2S1_SCK", "", "", "", "CLK1"},
+	{"SPI1_MISO", "", "I2S0_WS", "PWM3", "I2S1_WS"},
+	{"SPI1_MOSI", "", "I2S0_DIN", "CLK0", "I2S1_DIN"}, // 20
+	{"SPI1_CLK", "", "I2S0_DOUT", "CLK1", "I2S1_DOUT"},
+	{"", "", "", "I2C3_SDA"},
+	{"", "", "", "I2C3_SCL"},
+	{"", "", "", "", "", "", "", "", "SPI2_CS1"},
+	{"", "", "", "", "", "", "", "", "SPI3_CS1"}, // 25
+	{"", "", "", "", "", "", "", "", "SPI5_CS1"},
+	{"", "", "", "", "", "", "", "", "SPI1_CS1"},
+}
+
+var cpuPins = []Pin{
+	{number: 0, name: "GPIO0", defaultPull: gpio.PullUp},
+	{number: 1, name: "GPIO1", defaultPull: gpio.PullUp},
+	{number: 2, name: "GPIO2", defaultPull: gpio.PullUp},
+	{number: 3, name: "GPIO3", defaultPull: gpio.PullUp},
+	{number: 4, name: "GPIO4", defaultPull: gpio.PullUp},
+	{number: 5, name: "GPIO5", defaultPull: gpio.PullUp},
+	{number: 6, name: "GPIO6", defaultPull: gpio.PullUp},
+	{number: 7, name: "GPIO7", defaultPull: gpio.PullUp},
+	{number: 8, name: "GPIO8", defaultPull: gpio.PullUp},
+	{number: 9, name: "GPIO9", defaultPull: gpio.PullDown},
+	{number: 10, name: "GPIO10", defaultPull: gpio.PullDown},
+	{number: 11, name: "GPIO11", defaultPull: gpio.PullDown},
+	{number: 12, name: "GPIO12", defaultPull: gpio.PullDown},
+	{number: 13, name: "GPIO13", defaultPull: gpio.PullDown},
+	{number: 14, name: "GPIO14", defaultPull: gpio.PullDown},
+	{number: 15, name: "GPIO15", defaultPull: gpio.PullDown},
+	{number: 16, name: "GPIO16", defaultPull: gpio.PullDown},
+	{number: 17, name: "GPIO17", defaultPull: gpio.PullDown},
+	{number: 18, name: "GPIO18", defaultPull: gpio.PullDown},
+	{number: 19, name: "GPIO19", defaultPull: gpio.PullDown},
+	{number: 20, name: "GPIO20", defaultPull: gpio.PullDown},
+	{number: 21, name: "GPIO21", defaultPull: gpio.PullDown},
+	{number: 22, name: "GPIO22", defaultPull: gpio.PullDown},
+	{number: 23, name: "GPIO23", defaultPull: gpio.PullDown},
+	{number: 24, name: "GPIO24", defaultPull: gpio.PullDown},
+	{number: 25, name: "GPIO25", defaultPull: gpio.PullDown},
+	{number: 26, name: "GPIO26", defaultPull: gpio.PullDown},
+	{number: 27, name: "GPIO27", defaultPull: gpio.PullDown},
+}
+
+// function specifies the active functionality of a pin, as the FUNCSEL field
+// of the GPIOn_CTRL register. The alternative function is GPIO pin dependent.
+type function uint8
+
+const (
+	alts       function = 9    // Number of alternate functions a0 to a8.
+	funcSysRIO function = 5    // a5; GPIO controlled via the registered I/O block.
+	funcNull   function = 0x1f // Disconnected; the reset value.
+
+	funcSelMask uint32 = 0x1f
+
+	// bank0Mask is the mask of the GPIOs of bank 0 in the RIO registers.
+	bank0Mask uint32 = 1<<28 - 1
+)
+
+// ioRegs is the status and control registers of a GPIO.
+type ioRegs struct {
+	status uint32 // GPIOn_STATUS
+	ctrl   uint32 // GPIOn_CTRL; bits 4:0 are FUNCSEL
+}
+
+// Mapping as the RP1 peripherals datasheet, section 3.1.4, IO_BANK0 at
+// 0x400d0000.
+type ioBankMap struct {
+	gpio [28]ioRegs // 0x000~0x0dc
+}
+
+// rioRegs is the registered I/O block of a GPIO bank.
+type rioRegs struct {
+	out      uint32 // 0x00 RIO_OUT
+	oe       uint32 // 0x04 RIO_OE; output enable
+	nosyncIn uint32 // 0x08 RIO_NOSYNC_IN
+	syncIn   uint32 // 0x0c RIO_SYNC_IN; input synchronized to the clock
+}
+
+// Mapping as the RP1 peripherals datasheet, section 3.3, SYS_RIO0 at
+// 0x400e0000.
+//
+// Each register is aliased at 4 offsets; a normal read/write access, an atomic
+// XOR on write, an atomic bitmask set on write and an atomic bitmask clear on
+// write.
+type rioMap struct {
+	rw     rioRegs              // 0x0000
+	dummy0 [0x1000/4 - 4]uint32 //
+	xor    rioRegs              // 0x1000
+	dummy1 [0x1000/4 - 4]uint32 //
+	set    rioRegs              // 0x2000
+	dummy2 [0x1000/4 - 4]uint32 //
+	clr    rioRegs              // 0x3000
+}
+
+// pad defines the settings of a GPIO pad.
+type pad uint32
+
+const (
+	padOutputDisable pad = 1 << 7 // OD
+	padInputEnable   pad = 1 << 6 // IE
+	padDriveMask     pad = 3 << 4 // DRIVE
+	padDriveShift        = 4      //
+	padPullUp        pad = 1 << 3 // PUE
+	padPullDown      pad = 1 << 2 // PDE
+	padSchmitt       pad = 1 << 1 // SCHMITT
+	padSlewFast      pad = 1 << 0 // SLEWFAST
+	padDrive2mA      pad = 0 << padDriveShift
+	padDrive4mA      pad = 1 << padDriveShift
+	padDrive8mA      pad = 2 << padDriveShift
+	padDrive12mA     pad = 3 << padDriveShift
+)
+
+var padDrives = [4]physic.ElectricCurrent{
+	2 * physic.MilliAmpere,
+	4 * physic.MilliAmpere,
+	8 * physic.MilliAmpere,
+	12 * physic.MilliAmpere,
+}
+
+func toPad(drive physic.ElectricCurrent, slewLimit, hysteresis bool) pad {
+	var p pad
+	d := int(drive / physic.MilliAmpere)
+	switch {
+	case d <= 2:
+		p = padDrive2mA
+	case d <= 4:
+		p = padDrive4mA
+	case d <= 8:
+		p = padDrive8mA
+	default:
+		p = padDrive12mA
+	}
+	if !slewLimit {
+		p |= padSlewFast
+	}
+	if hysteresis {
+		p |= padSchmitt
+	}
+	return p
+}
+
+// Mapping as the RP1 peripherals datasheet, section 3.1.4, PADS_BANK0 at
+// 0x400f0000.
+type padMap struct {
+	voltageSelect uint32  // 0x00 VOLTAGE_SELECT; 0: 3.3V, 1: 1.8V
+	gpio          [28]pad // 0x04~0x70
+}
+
+func init() {
+	GPIO0 = &cpuPins[0]
+	GPIO1 = &cpuPins[1]
+	GPIO2 = &cpuPins[2]
+	GPIO3 = &cpuPins[3]
+	GPIO4 = &cpuPins[4]
+	GPIO5 = &cpuPins[5]
+	GPIO6 = &cpuPins[6]
+	GPIO7 = &cpuPins[7]
+	GPIO8 = &cpuPins[8]
+	GPIO9 = &cpuPins[9]
+	GPIO10 = &cpuPins[10]
+	GPIO11 = &cpuPins[11]
+	GPIO12 = &cpuPins[12]
+	GPIO13 = &cpuPins[13]
+	GPIO14 = &cpuPins[14]
+	GPIO15 = &cpuPins[15]
+	GPIO16 = &cpuPins[16]
+	GPIO17 = &cpuPins[17]
+	GPIO18 = &cpuPins[18]
+	GPIO19 = &cpuPins[19]
+	GPIO20 = &cpuPins[20]
+	GPIO21 = &cpuPins[21]
+	GPIO22 = &cpuPins[22]
+	GPIO23 = &cpuPins[23]
+	GPIO24 = &cpuPins[24]
+	GPIO25 = &cpuPins[25]
+	GPIO26 = &cpuPins[26]
+	GPIO27 = &cpuPins[27]
+}
+
+// The RP1 is mapped on the BCM2712 PCIe bus at 0x1f00000000. Its peripherals
+// are documented at their RP1 address, starting at 0x40000000.
+const (
+	rp1BaseAddr   = 0x1f00000000 - 0x40000000
+	ioBank0Addr   = rp1BaseAddr + 0x400d0000
+	sysRIO0Addr   = rp1BaseAddr + 0x400e0000
+	padsBank0Addr = rp1BaseAddr + 0x400f0000
+	pwm0Addr      = rp1BaseAddr + 0x40098000
+)
+
+// driverGPIO implements periph.Driver.
+type driverGPIO struct {
+	// ioMemory is the memory map of the IO_BANK0 registers.
+	ioMemory *ioBankMap
+	// rioMemory is the memory map of the SYS_RIO0 registers.
+	rioMemory *rioMap
+	// padMemory is the memory map of the PADS_BANK0 registers.
+	padMemory *padMap
+}
+
+func (d *driverGPIO) Close() {
+	d.ioMemory = nil
+	d.rioMemory = nil
+	d.padMemory = nil
+}
+
+func (d *driverGPIO) String() string {
+	return "rp1-gpio"
+}
+
+func (d *driverGPIO) Prerequisites() []string {
+	return nil
+}
+
+func (d *driverGPIO) After() []string {
+	return []string{"sysfs-gpio", "sysfs-gpiochip"}
+}
+
+func (d *driverGPIO) Init() (bool, error) {
+	if !Present() {
+		return false, errors.New("RP1 not detected")
+	}
+
+	// Mark the right pins as available even if the memory map fails so they can
+	// callback to sysfs.Pins.
+	base := sysfsBase()
+	functions := map[pin.Func]struct{}{}
+	for i := range cpuPins {
+		name := cpuPins[i].name
+		num := strconv.Itoa(cpuPins[i].number)
+
+		// Initializes the sysfs corresponding pin right away. The kernel
+		// numbering of the RP1 pins doesn't start at 0.
+		if base != -1 {
+			cpuPins[i].sysfsPin = sysfs.Pins[base+cpuPins[i].number]
+		}
+		cpuPins[i].gpioLine = sysfs.GPIOLineByLabel(chipLabel, cpuPins[i].number)
+
+		// Unregister the pin if already registered. This happens with sysfs-gpio.
+		// Do not error on it, since sysfs-gpio may have failed to load.
+		_ = gpioreg.Unregister(name)
+		_ = gpioreg.Unregister(num)
+
+		if err := gpioreg.Register(&cpuPins[i]); err != nil {
+			return true, err
+		}
+		if err := gpioreg.RegisterAlias(num, name); err != nil {
+			return true, err
+		}
+	}
+
+	m, err := pmem.Map(ioBank0Addr, padsBank0Addr-ioBank0Addr+4096)
+	if err != nil {
+		if os.IsPermission(err) {
+			return true, fmt.Errorf("need more access, try as root: %v", err)
+		}
+		return true, err
+	}
+	if err := d.mapMemory(m); err != nil {
+		return true, err
+	}
+
+	// Register the aliases of the active functions first, then the alternate
+	// functions.
+	for i := range cpuPins {
+		switch f := cpuPins[i].Func(); f {
+		case gpio.IN, gpio.OUT, gpio.IN_LOW, gpio.IN_HIGH, gpio.OUT_LOW, gpio.OUT_HIGH, pin.FuncNone:
+		default:
+			if _, ok := functions[f]; !ok {
+				functions[f] = struct{}{}
+				if err := gpioreg.RegisterAlias(string(f), cpuPins[i].name); err != nil {
+					return true, err
+				}
+			}
+		}
+	}
+	for i := range cpuPins {
+		for _, f := range cpuPins[i].SupportedFuncs() {
+			switch f {
+			case gpio.IN, gpio.OUT:
+			default:
+				if _, ok := functions[f]; !ok {
+					functions[f] = struct{}{}
+					if err := gpioreg.RegisterAlias(string(f), cpuPins[i].name); err != nil {
+						return true, err
+					}
+				}
+			}
+		}
+	}
+	aliases := [][2]string{
+		{"GPCLK0", "CLK0"},
+		{"GPCLK1", "CLK1"},
+		{"GPCLK2", "CLK2"},
+	}
+	for _, a := range aliases {
+		if err := gpioreg.RegisterAlias(a[0], a[1]); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// mapMemory maps the IO_BANK0, SYS_RIO0 and PADS_BANK0 registers from a view
+// starting at IO_BANK0.
+func (d *driverGPIO) mapMemory(m *pmem.View) error {
+	if len(m.Slice) < padsBank0Addr-ioBank0Addr+4096 {
+		return fmt.Errorf("rp1-gpio: memory map is too small: %d bytes", len(m.Slice))
+	}
+	if err := m.AsPOD(&d.ioMemory); err != nil {
+		return err
+	}
+	rio := m.Slice[sysRIO0Addr-ioBank0Addr:]
+	if err := rio.AsPOD(&d.rioMemory); err != nil {
+		return err
+	}
+	pads := m.Slice[padsBank0Addr-ioBank0Addr:]
+	return pads.AsPOD(&d.padMemory)
+}
+
+// chipLabel is the label of the GPIO chip of the RP1 bank 0, both in
+// /sys/class/gpio and /dev/gpiochipN.
+const chipLabel = "pinctrl-rp1"
+
+// sysfsBase returns the kernel number of GPIO0 as exposed by sysfs-gpio, or
+// -1 if not found.
+func sysfsBase() int {
+	items, err := filepath.Glob("/sys/class/gpio/gpiochip*")
+	if err != nil {
+		return -1
+	}
+	for _, item := range items {
+		b, err := ioutil.ReadFile(item + "/label")
+		if err != nil || strings.TrimSpace(string(b)) != chipLabel {
+			continue
+		}
+		if b, err = ioutil.ReadFile(item + "/base"); err != nil {
+			return -1
+		}
+		base, err := strconv.Atoi(strings.TrimSpace(string(b)))
+		if err != nil {
+			return -1
+		}
+		return base
+	}
+	return -1
+}
+
+func init() {
+	if isArm {
+		periph.MustRegister(&drvGPIO)
+	}
+}
+
+var drvGPIO driverGPIO
+
+var _ gpio.PinIO = &Pin{}
+var _ gpio.PinEventer = &Pin{}
+var _ gpio.PinIn = &Pin{}
+var _ gpio.PinOut = &Pin{}
+var _ pin.PinFunc = &Pin{}
@@ -0,0 +1,375 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package rp1
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/pin"
+	"github.com/meandrewdev/periph/conn/spi"
+	"github.com/meandrewdev/periph/host/pmem"
+)
+
+func TestPresent(t *testing.T) {
+	// It may return true or false, depending on hardware but it shouldn't crash.
+	Present()
+}
+
+func TestMapOffsets(t *testing.T) {
+	var io ioBankMap
+	if o := unsafe.Offsetof(io.gpio) + 27*unsafe.Sizeof(io.gpio[0]) + unsafe.Offsetof(io.gpio[0].ctrl); o != 0xdc {
+		t.Fatalf("GPIO27_CTRL: %#x", o)
+	}
+	var rio rioMap
+	if o := unsafe.Offsetof(rio.xor); o != 0x1000 {
+		t.Fatalf("xor: %#x", o)
+	}
+	if o := unsafe.Offsetof(rio.set); o != 0x2000 {
+		t.Fatalf("set: %#x", o)
+	}
+	if o := unsafe.Offsetof(rio.clr) + unsafe.Offsetof(rio.clr.oe); o != 0x3004 {
+		t.Fatalf("clr.oe: %#x", o)
+	}
+	var pads padMap
+	if o := unsafe.Offsetof(pads.gpio) + 27*unsafe.Sizeof(pads.gpio[0]); o != 0x70 {
+		t.Fatalf("GPIO27 pad: %#x", o)
+	}
+}
+
+func TestMapMemory(t *testing.T) {
+	defer reset()
+	d := driverGPIO{}
+	if d.mapMemory(&pmem.View{Slice: make([]byte, 4096)}) == nil {
+		t.Fatal("view too small")
+	}
+	v := &pmem.View{Slice: make([]byte, padsBank0Addr-ioBank0Addr+4096)}
+	if err := d.mapMemory(v); err != nil {
+		t.Fatal(err)
+	}
+	d.ioMemory.gpio[1].ctrl = 0x1f
+	d.rioMemory.set.oe = 1 << 2
+	d.padMemory.gpio[3] = padPullUp
+	u := v.Uint32()
+	if u[3] != 0x1f {
+		t.Fatal("IO_BANK0 GPIO1_CTRL")
+	}
+	if u[(sysRIO0Addr-ioBank0Addr+0x2004)/4] != 1<<2 {
+		t.Fatal("SYS_RIO0 RIO_OE set alias")
+	}
+	if u[(padsBank0Addr-ioBank0Addr+0x10)/4] != uint32(padPullUp) {
+		t.Fatal("PADS_BANK0 GPIO3")
+	}
+}
+
+func TestPins(t *testing.T) {
+	defer reset()
+	drvGPIO.rioMemory.rw.syncIn = 0xf0000011
+	if v := PinsRead0To27(); v != 0x00000011 {
+		t.Fatalf("%#x", v)
+	}
+	PinsClear0To27(0xffffffff)
+	if v := drvGPIO.rioMemory.clr.out; v != bank0Mask {
+		t.Fatalf("%#x", v)
+	}
+	PinsSet0To27(1)
+	if v := drvGPIO.rioMemory.set.out; v != 1 {
+		t.Fatalf("%#x", v)
+	}
+}
+
+func TestPin_NoMem(t *testing.T) {
+	defer reset()
+	drvGPIO.Close()
+	// Using Pin without the driver being initialized doesn't crash.
+	p := Pin{name: "Foo", number: 11, defaultPull: gpio.PullDown}
+
+	// conn.Resource
+	if s := p.String(); s != "Foo" {
+		t.Fatal(s)
+	}
+	if err := p.Halt(); err != nil {
+		t.Fatal(err)
+	}
+
+	// pin.Pin
+	if n := p.Number(); n != 11 {
+		t.Fatal(n)
+	}
+	if s := p.Function(); s != "" {
+		t.Fatal(s)
+	}
+
+	// pin.PinFunc
+	if s := p.Func(); s != pin.FuncNone {
+		t.Fatal(s)
+	}
+	if err := p.SetFunc(spi.CLK); err == nil {
+		t.Fatal("expected failure")
+	}
+
+	// gpio.PinIn
+	if err := p.In(gpio.PullNoChange, gpio.NoEdge); err == nil {
+		t.Fatal("expected failure")
+	}
+	if l := p.Read(); l != gpio.Low {
+		t.Fatal(l)
+	}
+	if p.WaitForEdge(-1) {
+		t.Fatal("edge not initialized")
+	}
+	if _, ok := p.WaitForEvent(-1); ok {
+		t.Fatal("edge not initialized")
+	}
+	if pull := p.Pull(); pull != gpio.PullNoChange {
+		t.Fatal(pull)
+	}
+	if pull := p.DefaultPull(); pull != gpio.PullDown {
+		t.Fatal(pull)
+	}
+
+	// gpio.PinOut
+	if err := p.Out(gpio.Low); err == nil {
+		t.Fatal("expected failure")
+	}
+	if err := p.PWM(gpio.DutyHalf, physic.KiloHertz); err == nil {
+		t.Fatal("expected failure")
+	}
+
+	// Pads
+	if d := p.Drive(); d != 0 {
+		t.Fatal(d)
+	}
+	if p.SlewLimit() {
+		t.Fatal("unexpected slew limit")
+	}
+	if p.Hysteresis() {
+		t.Fatal("unexpected hysteresis")
+	}
+	if err := p.Setup(4*physic.MilliAmpere, true, true); err == nil {
+		t.Fatal("expected failure")
+	}
+}
+
+func TestPin_Out(t *testing.T) {
+	defer reset()
+	p := GPIO17
+	drvGPIO.padMemory.gpio[17] = padOutputDisable
+	if err := p.Out(gpio.High); err != nil {
+		t.Fatal(err)
+	}
+	if f := p.function(); f != funcSysRIO {
+		t.Fatal(f)
+	}
+	if v := drvGPIO.rioMemory.set.oe; v != 1<<17 {
+		t.Fatalf("%#x", v)
+	}
+	if v := drvGPIO.rioMemory.set.out; v != 1<<17 {
+		t.Fatalf("%#x", v)
+	}
+	if v := drvGPIO.padMemory.gpio[17]; v != padInputEnable {
+		t.Fatalf("%#x", v)
+	}
+	p.FastOut(gpio.Low)
+	if v := drvGPIO.rioMemory.clr.out; v != 1<<17 {
+		t.Fatalf("%#x", v)
+	}
+
+	// The fake memory doesn't implement the atomic aliases.
+	drvGPIO.rioMemory.rw.oe = 1 << 17
+	if f := p.Func(); f != gpio.OUT_LOW {
+		t.Fatal(f)
+	}
+	drvGPIO.rioMemory.rw.syncIn = 1 << 17
+	if f := p.Func(); f != gpio.OUT_HIGH {
+		t.Fatal(f)
+	}
+	if l := p.Read(); l != gpio.High {
+		t.Fatal(l)
+	}
+}
+
+func TestPin_In(t *testing.T) {
+	defer reset()
+	p := GPIO4
+	drvGPIO.padMemory.gpio[4] = padOutputDisable | padPullDown
+	if err := p.In(gpio.PullUp, gpio.NoEdge); err != nil {
+		t.Fatal(err)
+	}
+	if f := p.function(); f != funcSysRIO {
+		t.Fatal(f)
+	}
+	if v := drvGPIO.rioMemory.clr.oe; v != 1<<4 {
+		t.Fatalf("%#x", v)
+	}
+	if v := drvGPIO.padMemory.gpio[4]; v != padInputEnable|padPullUp {
+		t.Fatalf("%#x", v)
+	}
+	if pull := p.Pull(); pull != gpio.PullUp {
+		t.Fatal(pull)
+	}
+	if err := p.In(gpio.PullDown, gpio.NoEdge); err != nil {
+		t.Fatal(err)
+	}
+	if pull := p.Pull(); pull != gpio.PullDown {
+		t.Fatal(pull)
+	}
+	if err := p.In(gpio.Float, gpio.NoEdge); err != nil {
+		t.Fatal(err)
+	}
+	if pull := p.Pull(); pull != gpio.Float {
+		t.Fatal(pull)
+	}
+	if f := p.Func(); f != gpio.IN_LOW {
+		t.Fatal(f)
+	}
+	drvGPIO.rioMemory.rw.syncIn = 1 << 4
+	if f := p.Func(); f != gpio.IN_HIGH {
+		t.Fatal(f)
+	}
+	if err := p.In(gpio.PullNoChange, gpio.BothEdges); err == nil {
+		t.Fatal("sysfs not initialized")
+	}
+}
+
+func TestPin_Func(t *testing.T) {
+	defer reset()
+	p := GPIO2
+	p.setFunction(funcNull)
+	if f := p.Func(); f != pin.FuncNone {
+		t.Fatal(f)
+	}
+	if err := p.SetFunc("I2C1_SDA"); err != nil {
+		t.Fatal(err)
+	}
+	if f := p.function(); f != 3 {
+		t.Fatal(f)
+	}
+	if f := p.Func(); f != "I2C1_SDA" {
+		t.Fatal(f)
+	}
+	if s := p.Function(); s != "I2C1_SDA" {
+		t.Fatal(s)
+	}
+	p.setFunction(1)
+	if f := p.Func(); f != "ALT1" {
+		t.Fatal(f)
+	}
+	if err := p.SetFunc(spi.MOSI); err != nil {
+		t.Fatal(err)
+	}
+	if f := p.Func(); f != "SPI2_MOSI" {
+		t.Fatal(f)
+	}
+	if err := p.SetFunc("PWM0"); err == nil {
+		t.Fatal("unsupported function")
+	}
+	if err := GPIO12.SetFunc(gpio.PWM); err != nil {
+		t.Fatal(err)
+	}
+	if f := GPIO12.Func(); f != "PWM0" {
+		t.Fatal(f)
+	}
+	if err := p.SetFunc(gpio.OUT_LOW); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetFunc(gpio.IN_HIGH); err != nil {
+		t.Fatal(err)
+	}
+	if pull := p.Pull(); pull != gpio.PullUp {
+		t.Fatal(pull)
+	}
+	expected := []pin.Func{gpio.IN, gpio.OUT, "UART1_CTS", "I2C1_SDA", "SPI2_MOSI"}
+	if f := p.SupportedFuncs(); !reflect.DeepEqual(f, expected) {
+		t.Fatal(f)
+	}
+}
+
+func TestPin_Pads(t *testing.T) {
+	defer reset()
+	p := GPIO21
+	if err := p.Setup(12*physic.MilliAmpere, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if d := p.Drive(); d != 12*physic.MilliAmpere {
+		t.Fatal(d)
+	}
+	if p.SlewLimit() {
+		t.Fatal("unexpected slew limit")
+	}
+	if !p.Hysteresis() {
+		t.Fatal("expected hysteresis")
+	}
+	drvGPIO.padMemory.gpio[21] |= padPullUp
+	if err := p.Setup(3*physic.MilliAmpere, true, false); err != nil {
+		t.Fatal(err)
+	}
+	if v := drvGPIO.padMemory.gpio[21]; v != padDrive4mA|padPullUp {
+		t.Fatalf("%#x", v)
+	}
+	if d := p.Drive(); d != 4*physic.MilliAmpere {
+		t.Fatal(d)
+	}
+	if !p.SlewLimit() {
+		t.Fatal("expected slew limit")
+	}
+}
+
+func TestToPad(t *testing.T) {
+	data := []struct {
+		drive      physic.ElectricCurrent
+		slewLimit  bool
+		hysteresis bool
+		expected   pad
+	}{
+		{0, true, false, padDrive2mA},
+		{2 * physic.MilliAmpere, false, false, padDrive2mA | padSlewFast},
+		{4 * physic.MilliAmpere, true, true, padDrive4mA | padSchmitt},
+		{6 * physic.MilliAmpere, true, false, padDrive8mA},
+		{8 * physic.MilliAmpere, true, false, padDrive8mA},
+		{16 * physic.MilliAmpere, true, false, padDrive12mA},
+	}
+	for i, line := range data {
+		if p := toPad(line.drive, line.slewLimit, line.hysteresis); p != line.expected {
+			t.Fatalf("#%d: %#x != %#x", i, p, line.expected)
+		}
+	}
+}
+
+func TestDriverGPIO(t *testing.T) {
+	defer reset()
+	if s := drvGPIO.String(); s != "rp1-gpio" {
+		t.Fatal(s)
+	}
+	if s := drvGPIO.Prerequisites(); s != nil {
+		t.Fatal(s)
+	}
+	if s := drvGPIO.After(); len(s) != 2 {
+		t.Fatal(s)
+	}
+}
+
+//
+
+func init() {
+	reset()
+}
+
+// reset sets the driver memory to fake registers, all zeros.
+func reset() {
+	drvGPIO.Close()
+	drvPWM.Close()
+	for i := range cpuPins {
+		cpuPins[i].edgePin = nil
+		cpuPins[i].usingPWM = false
+	}
+	v := &pmem.View{Slice: make([]byte, padsBank0Addr-ioBank0Addr+4096)}
+	if err := drvGPIO.mapMemory(v); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package rp1
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/meandrewdev/periph"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/host/pmem"
+)
+
+// pwmClock is the frequency of clk_pwm0 as configured by the Raspberry Pi 5
+// device tree.
+const pwmClock = 50 * physic.MegaHertz
+
+const (
+	pwmSetUpdate pwmGlobal = 1 << 31 // SET_UPDATE; latches the channel registers
+	// 30:4 reserved
+	pwmChan3Enable pwmGlobal = 1 << 3 // CHAN3_EN
+	pwmChan2Enable pwmGlobal = 1 << 2 // CHAN2_EN
+	pwmChan1Enable pwmGlobal = 1 << 1 // CHAN1_EN
+	pwmChan0Enable pwmGlobal = 1 << 0 // CHAN0_EN
+)
+
+// Section 3.6.
+type pwmGlobal uint32
+
+const (
+	pwmFIFOPopMask pwmChanCtrl = 1 << 8 // FIFO_POP_MASK; 1: duty is read from CHANn_DUTY
+	pwmInvert      pwmChanCtrl = 1 << 3 // INVERT; inverts the output
+	// MODE: 0: zero, 1: trailing edge mark-space, 2: phase correct, 3: pulse
+	// density, 4: MSB serialiser, 5: PPM, 6: leading edge mark-space, 7: LSB
+	// serialiser.
+	pwmModeMask              pwmChanCtrl = 7 << 0
+	pwmModeTrailingMarkSpace pwmChanCtrl = 1 << 0
+)
+
+// Section 3.6.
+type pwmChanCtrl uint32
+
+// pwmChannel is the registers of a PWM channel.
+type pwmChannel struct {
+	ctrl  pwmChanCtrl // CHANn_CTRL
+	rng   uint32      // CHANn_RANGE; period in clock cycles
+	phase uint32      // CHANn_PHASE
+	duty  uint32      // CHANn_DUTY; high time in clock cycles
+}
+
+// Mapping as the RP1 peripherals datasheet, section 3.6, PWM0 at 0x40098000.
+type pwmMap struct {
+	global      pwmGlobal     // 0x00 GLOBAL_CTRL
+	fifoCtrl    uint32        // 0x04 FIFO_CTRL
+	commonRange uint32        // 0x08 COMMON_RANGE
+	commonDuty  uint32        // 0x0C COMMON_DUTY
+	dutyFIFO    uint32        // 0x10 DUTY_FIFO
+	channels    [4]pwmChannel // 0x14~0x50
+}
+
+// set configures and enables the channel ch.
+func (p *pwmMap) set(ch int, duty gpio.Duty, freq physic.Frequency) error {
+	if freq <= 0 {
+		return errors.New("frequency must be positive")
+	}
+	if m := pwmClock / 2; m < freq {
+		return fmt.Errorf("frequency must be at most %s", m)
+	}
+	// Total cycles in the period.
+	rng := uint64(pwmClock / freq)
+	// Pulse width cycles.
+	dat := uint32((rng*uint64(duty) + uint64(gpio.DutyHalf)) / uint64(gpio.DutyMax))
+	c := &p.channels[ch]
+	c.ctrl = c.ctrl&^(pwmModeMask|pwmInvert) | pwmFIFOPopMask | pwmModeTrailingMarkSpace
+	c.rng = uint32(rng)
+	c.phase = 0
+	c.duty = dat
+	p.global |= pwmChan0Enable<<uint(ch) | pwmSetUpdate
+	return nil
+}
+
+// disable stops the channel ch.
+func (p *pwmMap) disable(ch int) {
+	p.global = p.global&^(pwmChan0Enable<<uint(ch)) | pwmSetUpdate
+}
+
+// driverPWM implements periph.Driver.
+type driverPWM struct {
+	// pwmMemory is the memory map of the PWM0 registers.
+	pwmMemory *pwmMap
+}
+
+func (d *driverPWM) Close() {
+	d.pwmMemory = nil
+}
+
+func (d *driverPWM) String() string {
+	return "rp1-pwm"
+}
+
+func (d *driverPWM) Prerequisites() []string {
+	return []string{"rp1-gpio"}
+}
+
+func (d *driverPWM) After() []string {
+	return nil
+}
+
+func (d *driverPWM) Init() (bool, error) {
+	if err := pmem.MapAsPOD(pwm0Addr, &d.pwmMemory); err != nil {
+		if os.IsPermission(err) {
+			return true, fmt.Errorf("need more access, try as root: %v", err)
+		}
+		return true, err
+	}
+	return true, nil
+}
+
+func init() {
+	if isArm {
+		periph.MustRegister(&drvPWM)
+	}
+}
+
+var drvPWM driverPWM
@@ -0,0 +1,114 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package rp1
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/host/sysfs"
+)
+
+func TestPWMMapOffsets(t *testing.T) {
+	var p pwmMap
+	if o := unsafe.Offsetof(p.channels); o != 0x14 {
+		t.Fatalf("CHAN0_CTRL: %#x", o)
+	}
+	if o := unsafe.Offsetof(p.channels) + 3*unsafe.Sizeof(p.channels[0]) + unsafe.Offsetof(p.channels[0].duty); o != 0x50 {
+		t.Fatalf("CHAN3_DUTY: %#x", o)
+	}
+}
+
+func TestPWMMap(t *testing.T) {
+	p := pwmMap{}
+	if err := p.set(1, gpio.DutyHalf, 0); err == nil {
+		t.Fatal("invalid frequency")
+	}
+	if err := p.set(1, gpio.DutyHalf, 30*physic.MegaHertz); err == nil {
+		t.Fatal("frequency too high")
+	}
+	p.channels[1].ctrl = pwmInvert | 5
+	if err := p.set(1, gpio.DutyHalf, physic.KiloHertz); err != nil {
+		t.Fatal(err)
+	}
+	if c := p.channels[1]; c.ctrl != pwmFIFOPopMask|pwmModeTrailingMarkSpace || c.rng != 50000 || c.duty != 25000 {
+		t.Fatalf("%#v", c)
+	}
+	if p.global != pwmSetUpdate|pwmChan1Enable {
+		t.Fatalf("%#x", p.global)
+	}
+	p.global |= pwmChan3Enable
+	p.disable(1)
+	if p.global != pwmSetUpdate|pwmChan3Enable {
+		t.Fatalf("%#x", p.global)
+	}
+}
+
+func TestPin_PWM(t *testing.T) {
+	defer reset()
+	if err := GPIO18.PWM(gpio.DutyHalf, physic.KiloHertz); err == nil {
+		t.Fatal("rp1-pwm not initialized")
+	}
+	if err := GPIO17.PWM(gpio.DutyHalf, physic.KiloHertz); err == nil {
+		t.Fatal("PWM not supported on GPIO17")
+	}
+	drvPWM.pwmMemory = &pwmMap{}
+	if err := GPIO18.PWM(gpio.DutyHalf, 100*physic.MegaHertz); err == nil {
+		t.Fatal("frequency too high")
+	}
+	if err := GPIO18.PWM(gpio.DutyMax/4, 10*physic.KiloHertz); err != nil {
+		t.Fatal(err)
+	}
+	if f := GPIO18.Func(); f != "PWM2" {
+		t.Fatal(f)
+	}
+	if c := drvPWM.pwmMemory.channels[2]; c.rng != 5000 || c.duty != 1250 {
+		t.Fatalf("%#v", c)
+	}
+	if drvPWM.pwmMemory.global&pwmChan2Enable == 0 {
+		t.Fatal("channel not enabled")
+	}
+	if err := GPIO18.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if drvPWM.pwmMemory.global&pwmChan2Enable != 0 {
+		t.Fatal("channel not disabled")
+	}
+	if err := GPIO13.PWM(gpio.DutyMax, physic.KiloHertz); err != nil {
+		t.Fatal(err)
+	}
+	if f := GPIO13.function(); f != funcSysRIO {
+		t.Fatal(f)
+	}
+}
+
+func TestPin_PWM_sysfs(t *testing.T) {
+	defer reset()
+	// PWM2 can be muxed on GPIO14 and GPIO18 but the overlay routes it to only
+	// one of them.
+	p := &Pin{name: "C18", number: 18}
+	if err := sysfs.RegisterPWMPin(p.name, &sysfs.PWM{}); err != nil {
+		t.Fatal(err)
+	}
+	p.setFunction(funcSysRIO)
+	if err := p.PWM(gpio.DutyHalf, physic.KiloHertz); err == nil || !strings.Contains(err.Error(), "is not routed to this pin") {
+		t.Fatal(err)
+	}
+}
+
+func TestDriverPWM(t *testing.T) {
+	if s := drvPWM.String(); s != "rp1-pwm" {
+		t.Fatal(s)
+	}
+	if s := drvPWM.Prerequisites(); len(s) != 1 || s[0] != "rp1-gpio" {
+		t.Fatal(s)
+	}
+	if s := drvPWM.After(); s != nil {
+		t.Fatal(s)
+	}
+}
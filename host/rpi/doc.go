@@ -3,7 +3,7 @@
 // that can be found in the LICENSE file.
 
 // Package rpi contains Raspberry Pi hardware logic. It is intrinsically
-// related to package bcm283x.
+// related to package bcm283x, and to package rp1 on the Raspberry Pi 5.
 //
 // Assumes Raspbian but does not directly depend on the distro being Raspbian.
 // Windows IoT is currently not supported.
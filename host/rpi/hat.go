@@ -0,0 +1,61 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package rpi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strconv"
+)
+
+// HAT describes the HAT detected by the firmware at boot.
+//
+// The firmware reads the HAT ID EEPROM and exposes its vendor info in the
+// device tree. Use package hateeprom to read the full EEPROM content.
+type HAT struct {
+	Vendor     string
+	Product    string
+	UUID       string
+	ProductID  uint16
+	ProductVer uint16
+}
+
+// DetectedHAT returns the HAT detected by the firmware at boot, or nil if
+// none was detected.
+func DetectedHAT() *HAT {
+	const root = "/proc/device-tree/hat/"
+	h := &HAT{}
+	var ok bool
+	if h.Product, ok = readDTString(root + "product"); !ok {
+		return nil
+	}
+	h.Vendor, _ = readDTString(root + "vendor")
+	h.UUID, _ = readDTString(root + "uuid")
+	if s, ok := readDTString(root + "product_id"); ok {
+		v, _ := strconv.ParseUint(s, 0, 16)
+		h.ProductID = uint16(v)
+	}
+	if s, ok := readDTString(root + "product_ver"); ok {
+		v, _ := strconv.ParseUint(s, 0, 16)
+		h.ProductVer = uint16(v)
+	}
+	return h
+}
+
+//
+
+var readFile = ioutil.ReadFile
+
+// readDTString reads a NUL terminated string property from the device tree.
+func readDTString(path string) (string, bool) {
+	b, err := readFile(path)
+	if err != nil {
+		return "", false
+	}
+	if i := bytes.IndexByte(b, 0); i != -1 {
+		b = b[:i]
+	}
+	return string(b), true
+}
@@ -0,0 +1,46 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package rpi
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDetectedHAT(t *testing.T) {
+	defer func() {
+		readFile = ioutil.ReadFile
+	}()
+	files := map[string]string{
+		"/proc/device-tree/hat/product":     "Sense HAT\x00",
+		"/proc/device-tree/hat/vendor":      "Raspberry Pi\x00",
+		"/proc/device-tree/hat/uuid":        "12345678-9abc-def0-0123-456789abcdef\x00",
+		"/proc/device-tree/hat/product_id":  "0x0001\x00",
+		"/proc/device-tree/hat/product_ver": "0x0002\x00",
+	}
+	readFile = func(path string) ([]byte, error) {
+		if s, ok := files[path]; ok {
+			return []byte(s), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	expected := &HAT{
+		Vendor:     "Raspberry Pi",
+		Product:    "Sense HAT",
+		UUID:       "12345678-9abc-def0-0123-456789abcdef",
+		ProductID:  1,
+		ProductVer: 2,
+	}
+	if h := DetectedHAT(); !reflect.DeepEqual(h, expected) {
+		t.Fatalf("%#v", h)
+	}
+
+	files = map[string]string{}
+	if h := DetectedHAT(); h != nil {
+		t.Fatalf("%#v", h)
+	}
+}
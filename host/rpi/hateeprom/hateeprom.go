@@ -0,0 +1,342 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package hateeprom parses and generates the content of the ID EEPROM of
+// Raspberry Pi HATs.
+//
+// The EEPROM is a 24C32 connected on the ID_SD/ID_SC pins (I²C bus 0) at
+// address 0x50. The firmware reads it at boot and exposes the vendor info in
+// the device tree; see rpi.DetectedHAT().
+//
+// Specification
+//
+// https://github.com/raspberrypi/hats/blob/master/eeprom-format.md
+package hateeprom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// AtomType is the type of an atom.
+type AtomType uint16
+
+// Atom types.
+const (
+	VendorInfoAtom AtomType = 0x0001
+	GPIOMapAtom    AtomType = 0x0002
+	LinuxDTBAtom   AtomType = 0x0003
+	CustomAtom     AtomType = 0x0004
+)
+
+// UUID is a product UUID in its canonical big endian byte order.
+type UUID [16]byte
+
+func (u UUID) String() string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// VendorInfo is the mandatory vendor info atom.
+type VendorInfo struct {
+	UUID       UUID
+	ProductID  uint16
+	ProductVer uint16
+	Vendor     string
+	Product    string
+}
+
+// Pull is the pull resistor setting of a pin.
+type Pull uint8
+
+// Pull settings.
+const (
+	PullDefault Pull = 0
+	PullUp      Pull = 1
+	PullDown    Pull = 2
+	PullNone    Pull = 3
+)
+
+// GPIOPin describes how a HAT uses a GPIO pin.
+type GPIOPin struct {
+	// Used is true if the HAT uses this pin.
+	Used bool
+	// Func is the function select, 0 for input, 1 for output and 2 to 7 for
+	// ALT5, ALT4, ALT0, ALT1, ALT2 and ALT3.
+	Func uint8
+	Pull Pull
+}
+
+// GPIOMap is the mandatory GPIO map atom, describing the pins GPIO0 to
+// GPIO27.
+type GPIOMap struct {
+	// Drive is the drive strength; 0 leaves the default and 1 to 8 are 2mA to
+	// 16mA.
+	Drive uint8
+	// Slew is 0 for the default, 1 to enable slew rate limiting and 2 to
+	// disable it.
+	Slew uint8
+	// Hysteresis is 0 for the default, 1 to disable and 2 to enable.
+	Hysteresis uint8
+	// BackPower is 0 if the HAT doesn't back power the board, 1 if it
+	// provides 1.3A and 2 if it provides 2A.
+	BackPower uint8
+	Pins      [28]GPIOPin
+}
+
+// EEPROM is the content of a HAT ID EEPROM.
+type EEPROM struct {
+	Vendor VendorInfo
+	GPIO   GPIOMap
+	// DTB is the optional device tree overlay blob.
+	DTB []byte
+	// Custom is the optional vendor specific data, one item per atom.
+	Custom [][]byte
+}
+
+// Load reads and parses the EEPROM content from r, usually an *at24.Dev.
+func Load(r io.ReaderAt) (*EEPROM, error) {
+	b, err := ReadRaw(r)
+	if err != nil {
+		return nil, err
+	}
+	e := &EEPROM{}
+	if err := e.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ReadRaw reads the EEPROM content from r, usually an *at24.Dev, without
+// parsing it.
+//
+// Only the header is verified; the length of the returned slice is the one
+// stored in the header.
+func ReadRaw(r io.ReaderAt) ([]byte, error) {
+	var hdr [headerSize]byte
+	if _, err := r.ReadAt(hdr[:], 0); err != nil {
+		return nil, fmt.Errorf("hateeprom: %v", err)
+	}
+	if binary.LittleEndian.Uint32(hdr[:]) != signature {
+		return nil, errors.New("hateeprom: invalid signature; is the EEPROM programmed?")
+	}
+	l := binary.LittleEndian.Uint32(hdr[8:])
+	if l < headerSize || l > maxSize {
+		return nil, fmt.Errorf("hateeprom: invalid length %d", l)
+	}
+	b := make([]byte, l)
+	if _, err := r.ReadAt(b, 0); err != nil {
+		return nil, fmt.Errorf("hateeprom: %v", err)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// It verifies the CRC of each atom.
+func (e *EEPROM) UnmarshalBinary(b []byte) error {
+	if len(b) < headerSize || binary.LittleEndian.Uint32(b) != signature {
+		return errors.New("hateeprom: invalid signature")
+	}
+	if b[4] != version {
+		return fmt.Errorf("hateeprom: unsupported version %d", b[4])
+	}
+	n := int(binary.LittleEndian.Uint16(b[6:]))
+	l := binary.LittleEndian.Uint32(b[8:])
+	if l < headerSize || l > uint32(len(b)) {
+		return fmt.Errorf("hateeprom: invalid length %d for %d bytes of data", l, len(b))
+	}
+	b = b[:l]
+	*e = EEPROM{}
+	seen := map[AtomType]bool{}
+	b = b[headerSize:]
+	for i := 0; i < n; i++ {
+		if len(b) < atomHeaderSize+2 {
+			return fmt.Errorf("hateeprom: atom %d: truncated", i)
+		}
+		t := AtomType(binary.LittleEndian.Uint16(b))
+		if c := binary.LittleEndian.Uint16(b[2:]); int(c) != i {
+			return fmt.Errorf("hateeprom: atom %d: unexpected count %d", i, c)
+		}
+		l := binary.LittleEndian.Uint32(b[4:])
+		if l < 2 || uint64(l) > uint64(len(b)-atomHeaderSize) {
+			return fmt.Errorf("hateeprom: atom %d: invalid length %d", i, l)
+		}
+		end := atomHeaderSize + int(l)
+		if crc := binary.LittleEndian.Uint16(b[end-2:]); crc != crc16(b[:end-2]) {
+			return fmt.Errorf("hateeprom: atom %d: invalid CRC", i)
+		}
+		d := b[atomHeaderSize : end-2]
+		b = b[end:]
+		if t != CustomAtom {
+			if seen[t] {
+				return fmt.Errorf("hateeprom: atom %d: duplicate type %d", i, t)
+			}
+			seen[t] = true
+		}
+		var err error
+		switch t {
+		case VendorInfoAtom:
+			err = e.Vendor.unmarshal(d)
+		case GPIOMapAtom:
+			err = e.GPIO.unmarshal(d)
+		case LinuxDTBAtom:
+			e.DTB = append([]byte{}, d...)
+		case CustomAtom:
+			e.Custom = append(e.Custom, append([]byte{}, d...))
+		default:
+			err = fmt.Errorf("unsupported type %d", t)
+		}
+		if err != nil {
+			return fmt.Errorf("hateeprom: atom %d: %v", i, err)
+		}
+	}
+	if !seen[VendorInfoAtom] || !seen[GPIOMapAtom] {
+		return errors.New("hateeprom: missing mandatory vendor info or GPIO map atom")
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (e *EEPROM) MarshalBinary() ([]byte, error) {
+	v, err := e.Vendor.marshal()
+	if err != nil {
+		return nil, err
+	}
+	g, err := e.GPIO.marshal()
+	if err != nil {
+		return nil, err
+	}
+	atoms := []atom{{VendorInfoAtom, v}, {GPIOMapAtom, g}}
+	if len(e.DTB) != 0 {
+		atoms = append(atoms, atom{LinuxDTBAtom, e.DTB})
+	}
+	for _, c := range e.Custom {
+		atoms = append(atoms, atom{CustomAtom, c})
+	}
+	out := make([]byte, headerSize, maxSize)
+	binary.LittleEndian.PutUint32(out, signature)
+	out[4] = version
+	binary.LittleEndian.PutUint16(out[6:], uint16(len(atoms)))
+	for i, a := range atoms {
+		start := len(out)
+		var h [atomHeaderSize]byte
+		binary.LittleEndian.PutUint16(h[:], uint16(a.t))
+		binary.LittleEndian.PutUint16(h[2:], uint16(i))
+		binary.LittleEndian.PutUint32(h[4:], uint32(len(a.d)+2))
+		out = append(out, h[:]...)
+		out = append(out, a.d...)
+		var crc [2]byte
+		binary.LittleEndian.PutUint16(crc[:], crc16(out[start:]))
+		out = append(out, crc[:]...)
+	}
+	if len(out) > maxSize {
+		return nil, fmt.Errorf("hateeprom: content is too large: %d bytes", len(out))
+	}
+	binary.LittleEndian.PutUint32(out[8:], uint32(len(out)))
+	return out, nil
+}
+
+//
+
+const (
+	signature      = 0x69502D52 // "R-Pi"
+	version        = 1
+	headerSize     = 12
+	atomHeaderSize = 8
+	// maxSize is the size of the 24C32.
+	maxSize = 4096
+)
+
+type atom struct {
+	t AtomType
+	d []byte
+}
+
+func (v *VendorInfo) unmarshal(d []byte) error {
+	if len(d) < 22 {
+		return errors.New("vendor info is too short")
+	}
+	for i := range v.UUID {
+		v.UUID[i] = d[15-i]
+	}
+	v.ProductID = binary.LittleEndian.Uint16(d[16:])
+	v.ProductVer = binary.LittleEndian.Uint16(d[18:])
+	vl, pl := int(d[20]), int(d[21])
+	if len(d) != 22+vl+pl {
+		return errors.New("invalid vendor info strings length")
+	}
+	v.Vendor = string(d[22 : 22+vl])
+	v.Product = string(d[22+vl:])
+	return nil
+}
+
+func (v *VendorInfo) marshal() ([]byte, error) {
+	if len(v.Vendor) > 255 || len(v.Product) > 255 {
+		return nil, errors.New("hateeprom: vendor and product strings must be at most 255 bytes")
+	}
+	d := make([]byte, 22, 22+len(v.Vendor)+len(v.Product))
+	// The UUID is stored as 4 little endian 32 bits words, least significant
+	// first.
+	for i := range v.UUID {
+		d[15-i] = v.UUID[i]
+	}
+	binary.LittleEndian.PutUint16(d[16:], v.ProductID)
+	binary.LittleEndian.PutUint16(d[18:], v.ProductVer)
+	d[20] = uint8(len(v.Vendor))
+	d[21] = uint8(len(v.Product))
+	d = append(d, v.Vendor...)
+	return append(d, v.Product...), nil
+}
+
+func (g *GPIOMap) unmarshal(d []byte) error {
+	if len(d) != 2+len(g.Pins) {
+		return fmt.Errorf("invalid GPIO map length %d", len(d))
+	}
+	g.Drive = d[0] & 0xF
+	g.Slew = (d[0] >> 4) & 3
+	g.Hysteresis = d[0] >> 6
+	g.BackPower = d[1] & 3
+	for i := range g.Pins {
+		p := d[2+i]
+		g.Pins[i] = GPIOPin{Used: p&0x80 != 0, Func: p & 7, Pull: Pull((p >> 5) & 3)}
+	}
+	return nil
+}
+
+func (g *GPIOMap) marshal() ([]byte, error) {
+	if g.Drive > 8 || g.Slew > 2 || g.Hysteresis > 2 || g.BackPower > 2 {
+		return nil, errors.New("hateeprom: invalid GPIO bank settings")
+	}
+	d := make([]byte, 2+len(g.Pins))
+	d[0] = g.Drive | g.Slew<<4 | g.Hysteresis<<6
+	d[1] = g.BackPower
+	for i, p := range g.Pins {
+		if p.Func > 7 || p.Pull > PullNone {
+			return nil, fmt.Errorf("hateeprom: invalid settings for GPIO%d", i)
+		}
+		d[2+i] = p.Func | uint8(p.Pull)<<5
+		if p.Used {
+			d[2+i] |= 0x80
+		}
+	}
+	return d, nil
+}
+
+// crc16 is CRC-16/ARC, as used by the reference eepmake tool.
+func crc16(b []byte) uint16 {
+	var crc uint16
+	for _, c := range b {
+		crc ^= uint16(c)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package hateeprom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestCRC16(t *testing.T) {
+	if c := crc16([]byte("123456789")); c != 0xBB3D {
+		t.Fatalf("0x%04X", c)
+	}
+}
+
+func TestUUID(t *testing.T) {
+	u := UUID{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+	if s := u.String(); s != "12345678-9abc-def0-0123-456789abcdef" {
+		t.Fatal(s)
+	}
+}
+
+func TestEEPROM_Marshal(t *testing.T) {
+	e := getEEPROM()
+	b, err := e.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Header.
+	if !bytes.Equal(b[:8], []byte{'R', '-', 'P', 'i', 1, 0, 4, 0}) {
+		t.Fatal(b[:8])
+	}
+	if l := binary.LittleEndian.Uint32(b[8:]); int(l) != len(b) {
+		t.Fatal(l, len(b))
+	}
+	// Vendor info atom: type 1, count 0, 22+4+6 bytes of data plus CRC.
+	if !bytes.Equal(b[12:20], []byte{1, 0, 0, 0, 34, 0, 0, 0}) {
+		t.Fatal(b[12:20])
+	}
+	// The UUID is stored least significant byte first.
+	if b[20] != 0xef || b[35] != 0x12 {
+		t.Fatal(b[20:36])
+	}
+	if !bytes.Equal(b[36:46], []byte{0x34, 0x12, 2, 0, 4, 6, 'A', 'C', 'M', 'E'}) {
+		t.Fatal(b[36:46])
+	}
+	if crc := binary.LittleEndian.Uint16(b[52:]); crc != crc16(b[12:52]) {
+		t.Fatal(crc)
+	}
+	// GPIO map atom.
+	if !bytes.Equal(b[54:64], []byte{2, 0, 1, 0, 32, 0, 0, 0, 0x14, 1}) {
+		t.Fatal(b[54:64])
+	}
+	// GPIO4 is used as an input with pull up.
+	if b[64+4] != 0xA0 {
+		t.Fatalf("0x%02X", b[64+4])
+	}
+
+	got := &EEPROM{}
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, e) {
+		t.Fatalf("%#v", got)
+	}
+	got, err = Load(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, e) {
+		t.Fatalf("%#v", got)
+	}
+	// Trailing bytes after the length stored in the header are not returned.
+	raw, err := ReadRaw(bytes.NewReader(append(append([]byte{}, b...), 0xFF, 0xFF)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw, b) {
+		t.Fatal(raw)
+	}
+}
+
+func TestEEPROM_Marshal_err(t *testing.T) {
+	e := getEEPROM()
+	e.GPIO.Drive = 9
+	if _, err := e.MarshalBinary(); err == nil {
+		t.Fatal("invalid drive")
+	}
+	e = getEEPROM()
+	e.GPIO.Pins[0].Func = 8
+	if _, err := e.MarshalBinary(); err == nil {
+		t.Fatal("invalid func")
+	}
+	e = getEEPROM()
+	e.Vendor.Vendor = string(make([]byte, 256))
+	if _, err := e.MarshalBinary(); err == nil {
+		t.Fatal("vendor too long")
+	}
+	e = getEEPROM()
+	e.DTB = make([]byte, 4096)
+	if _, err := e.MarshalBinary(); err == nil {
+		t.Fatal("too large")
+	}
+}
+
+func TestEEPROM_Unmarshal_err(t *testing.T) {
+	good, err := getEEPROM().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []func(b []byte) []byte{
+		func(b []byte) []byte { return b[:8] },
+		func(b []byte) []byte { b[0] = 'r'; return b },
+		func(b []byte) []byte { b[4] = 2; return b },
+		func(b []byte) []byte { return b[:len(b)-1] },
+		// Invalid CRC.
+		func(b []byte) []byte { b[40] ^= 1; return b },
+		// Invalid count.
+		func(b []byte) []byte { b[14] = 1; return b },
+		// Invalid atom length.
+		func(b []byte) []byte { b[16] = 0xFF; return b },
+		// Missing atoms.
+		func(b []byte) []byte { b[6] = 1; return b },
+		func(b []byte) []byte { b[6] = 5; return b },
+	}
+	for i, f := range data {
+		b := f(append([]byte{}, good...))
+		if err := (&EEPROM{}).UnmarshalBinary(b); err == nil {
+			t.Fatal(i)
+		}
+	}
+	if _, err := Load(bytes.NewReader(make([]byte, 4096))); err == nil {
+		t.Fatal("blank")
+	}
+}
+
+//
+
+func getEEPROM() *EEPROM {
+	e := &EEPROM{
+		Vendor: VendorInfo{
+			UUID:       UUID{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+			ProductID:  0x1234,
+			ProductVer: 2,
+			Vendor:     "ACME",
+			Product:    "Widget",
+		},
+		GPIO:   GPIOMap{Drive: 4, Slew: 1, BackPower: 1},
+		DTB:    []byte{0xd0, 0x0d, 0xfe, 0xed},
+		Custom: [][]byte{[]byte("serial=42")},
+	}
+	e.GPIO.Pins[4] = GPIOPin{Used: true, Pull: PullUp}
+	e.GPIO.Pins[17] = GPIOPin{Used: true, Func: 1, Pull: PullNone}
+	return e
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/meandrewdev/periph"
 	"github.com/meandrewdev/periph/conn/gpio"
@@ -17,6 +18,8 @@ import (
 	"github.com/meandrewdev/periph/conn/pin/pinreg"
 	"github.com/meandrewdev/periph/host/bcm283x"
 	"github.com/meandrewdev/periph/host/distro"
+	"github.com/meandrewdev/periph/host/rp1"
+	"github.com/meandrewdev/periph/host/sysfs"
 )
 
 // Present returns true if running on a Raspberry Pi board.
@@ -42,6 +45,10 @@ func Present() bool {
 // Some header info here: http://elinux.org/RPi_Low-level_peripherals
 //
 // P1 is also known as J8 on A+, B+, 2 and later.
+//
+// On the Raspberry Pi 5, the GPIOs of the 40 pins header are connected to the
+// RP1 I/O controller instead; the P1 pins are then set to the pins of package
+// rp1 with the same GPIO number.
 var (
 	// Raspberry Pi A and B, 26 pin header:
 	P1_1  pin.Pin    = pin.V3_3       // max 30mA
@@ -384,6 +391,8 @@ const (
 	memory1GB   revisionCode = 2 << memoryShift
 	memory2GB   revisionCode = 3 << memoryShift
 	memory4GB   revisionCode = 4 << memoryShift
+	memory8GB   revisionCode = 5 << memoryShift
+	memory16GB  revisionCode = 6 << memoryShift
 
 	sonyUK    revisionCode = 0 << manufacturerShift
 	egoman    revisionCode = 1 << manufacturerShift
@@ -396,6 +405,7 @@ const (
 	bcm2836 revisionCode = 1 << processorShift
 	bcm2837 revisionCode = 2 << processorShift
 	bcm2711 revisionCode = 3 << processorShift
+	bcm2712 revisionCode = 4 << processorShift
 
 	board1A       revisionCode = 0x0 << boardShift
 	board1B       revisionCode = 0x1 << boardShift
@@ -413,6 +423,10 @@ const (
 	boardReserved revisionCode = 0xf << boardShift
 	boardCM3Plus  revisionCode = 0x10 << boardShift
 	board4B       revisionCode = 0x11 << boardShift
+	board5        revisionCode = 0x17 << boardShift
+	boardCM5      revisionCode = 0x18 << boardShift
+	board500      revisionCode = 0x19 << boardShift
+	boardCM5Lite  revisionCode = 0x1a << boardShift
 )
 
 // features represents the different features on various Raspberry Pi boards.
@@ -427,6 +441,7 @@ type features struct {
 	audioLeft41 bool // AUDIO_LEFT uses GPIO41 (RPi3 and later) instead of GPIO45 (old boards)
 	hdrHDMI     bool // At least one HDMI port is present
 	hdrSODIMM   bool // SODIMM port is present
+	rp1         bool // P1 GPIOs are on the RP1 I/O controller (Raspberry Pi 5 and later)
 }
 
 func (f *features) init(v uint32) error {
@@ -502,6 +517,13 @@ func (f *features) init(v uint32) error {
 		f.hdrAudio = true
 		f.audioLeft41 = true
 		f.hdrHDMI = true
+	case board5, board500:
+		f.hdrP1P40 = true
+		f.rp1 = true
+	case boardCM5, boardCM5Lite:
+		// TODO: define the CM5 board to board connectors if anyone ever needs
+		// it. Please file an issue at
+		// https://github.com/meandrewdev/periph/issues/new/choose
 	default:
 		return fmt.Errorf("rpi: unknown hardware version: 0x%x", r)
 	}
@@ -548,6 +570,37 @@ func (f *features) registerHeaders() error {
 		P1_39 = pin.INVALID
 		P1_40 = gpio.INVALID
 	} else if f.hdrP1P40 {
+		if f.rp1 {
+			// Same layout as the other 40 pins headers, but routed to the RP1.
+			P1_3 = rp1.GPIO2
+			P1_5 = rp1.GPIO3
+			P1_7 = rp1.GPIO4
+			P1_8 = rp1.GPIO14
+			P1_10 = rp1.GPIO15
+			P1_11 = rp1.GPIO17
+			P1_12 = rp1.GPIO18
+			P1_13 = rp1.GPIO27
+			P1_15 = rp1.GPIO22
+			P1_16 = rp1.GPIO23
+			P1_18 = rp1.GPIO24
+			P1_19 = rp1.GPIO10
+			P1_21 = rp1.GPIO9
+			P1_22 = rp1.GPIO25
+			P1_23 = rp1.GPIO11
+			P1_24 = rp1.GPIO8
+			P1_26 = rp1.GPIO7
+			P1_27 = rp1.GPIO0
+			P1_28 = rp1.GPIO1
+			P1_29 = rp1.GPIO5
+			P1_31 = rp1.GPIO6
+			P1_32 = rp1.GPIO12
+			P1_33 = rp1.GPIO13
+			P1_35 = rp1.GPIO19
+			P1_36 = rp1.GPIO16
+			P1_37 = rp1.GPIO26
+			P1_38 = rp1.GPIO20
+			P1_40 = rp1.GPIO21
+		}
 		if err := pinreg.Register("P1", [][]pin.Pin{
 			{P1_1, P1_2},
 			{P1_3, P1_4},
@@ -766,6 +819,39 @@ func (f *features) registerHeaders() error {
 	return nil
 }
 
+// registerPWMs routes the PWM channels exposed by sysfs-pwm to the GPIO pins
+// they can be muxed on, so PWM() works without access to /dev/gpiomem.
+//
+// The routing itself is done by the device tree, e.g. "dtoverlay=pwm-2chan".
+// A channel is registered on all the pins it can be muxed on but it is only
+// routed to one of them; the GPIO drivers verify the pin function before using
+// the channel.
+func (f *features) registerPWMs() error {
+	// Channel number to pin names.
+	var device string
+	var channels [][]string
+	if f.rp1 {
+		device = "1f00098000.pwm"
+		channels = [][]string{{"GPIO12"}, {"GPIO13"}, {"GPIO14", "GPIO18"}, {"GPIO15", "GPIO19"}}
+	} else {
+		// "2020c000.pwm", "3f20c000.pwm" or "fe20c000.pwm" depending on the
+		// SoC.
+		device = "20c000.pwm"
+		channels = [][]string{{"GPIO12", "GPIO18"}, {"GPIO13", "GPIO19"}}
+	}
+	for _, p := range sysfs.PWMs {
+		if !strings.HasSuffix(p.Device(), device) || p.Number() >= len(channels) {
+			continue
+		}
+		for _, name := range channels[p.Number()] {
+			if err := sysfs.RegisterPWMPin(name, p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // driver implements periph.Driver.
 type driver struct {
 }
@@ -779,7 +865,7 @@ func (d *driver) Prerequisites() []string {
 }
 
 func (d *driver) After() []string {
-	return []string{"bcm283x-gpio"}
+	return []string{"bcm283x-gpio", "rp1-gpio", "sysfs-pwm"}
 }
 
 func (d *driver) Init() (bool, error) {
@@ -801,7 +887,10 @@ func (d *driver) Init() (bool, error) {
 		return true, err
 	}
 
-	return true, f.registerHeaders()
+	if err := f.registerHeaders(); err != nil {
+		return true, err
+	}
+	return true, f.registerPWMs()
 }
 
 func init() {
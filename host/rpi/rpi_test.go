@@ -61,6 +61,10 @@ func TestParseRevision(t *testing.T) {
 		{0xa03111, newFormat | memory1GB | sonyUK | bcm2711 | board4B | 1},
 		{0xb03111, newFormat | memory2GB | sonyUK | bcm2711 | board4B | 1},
 		{0xc03111, newFormat | memory4GB | sonyUK | bcm2711 | board4B | 1},
+		{0xc04170, newFormat | memory4GB | sonyUK | bcm2712 | board5},
+		{0xd04170, newFormat | memory8GB | sonyUK | bcm2712 | board5},
+		{0xe04171, newFormat | memory16GB | sonyUK | bcm2712 | board5 | 1},
+		{0xd04190, newFormat | memory8GB | sonyUK | bcm2712 | board500},
 	}
 	for i, line := range data {
 		r, err := parseRevision(line.v)
@@ -129,6 +133,10 @@ func TestFeaturesInit(t *testing.T) {
 		{0xa03111, features{hdrP1P40: true, hdrAudio: true, audioLeft41: true, hdrHDMI: true}}, // board4B
 		{0xb03111, features{hdrP1P40: true, hdrAudio: true, audioLeft41: true, hdrHDMI: true}}, // board4B
 		{0xc03111, features{hdrP1P40: true, hdrAudio: true, audioLeft41: true, hdrHDMI: true}}, // board4B
+		{0xc04170, features{hdrP1P40: true, rp1: true}},                                        // board5
+		{0xd04170, features{hdrP1P40: true, rp1: true}},                                        // board5
+		{0xd04190, features{hdrP1P40: true, rp1: true}},                                        // board500
+		{0xd04180, features{}}, // boardCM5
 	}
 	for i, line := range data {
 		f := features{}
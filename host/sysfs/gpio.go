@@ -5,6 +5,7 @@
 package sysfs
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +13,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/meandrewdev/periph"
@@ -48,6 +50,7 @@ type Pin struct {
 	fValue     fileIO    // handle to /sys/class/gpio/gpio*/value; never closed
 	event      fs.Event  // Initialized once
 	buf        [4]byte   // scratch buffer for Func(), Read() and Out()
+	seq        uint32    // Sequence number of the last edge event; accessed atomically
 }
 
 // String implements conn.Resource.
@@ -187,6 +190,7 @@ func (p *Pin) In(pull gpio.Pull, edge gpio.Edge) error {
 		}
 	}
 	p.edge = edge
+	atomic.StoreUint32(&p.seq, 0)
 	// This helps to remove accumulated edges but this is not 100% sufficient.
 	// Most of the time the interrupts are handled promptly enough that this loop
 	// flushes the accumulated interrupt.
@@ -248,6 +252,46 @@ func (p *Pin) WaitForEdge(timeout time.Duration) bool {
 	}
 }
 
+// WaitForEdgeContext implements gpio.WaitForEdgeContexter.
+//
+// Returns false as soon as ctx is done.
+func (p *Pin) WaitForEdgeContext(ctx context.Context) bool {
+	// Run lockless, like WaitForEdge().
+	nr, err := p.event.WaitContext(ctx)
+	return err == nil && nr == 1
+}
+
+// WaitForEvent implements gpio.PinEventer.
+//
+// gpio sysfs doesn't report the edge direction nor when it happened. The
+// event is timestamped when the process is woken up, and the direction of the
+// edge is inferred from the level of the pin at that moment when edge
+// detection is set to gpio.BothEdges. Edges that were coalesced by the kernel
+// are not accounted for in the sequence number.
+func (p *Pin) WaitForEvent(timeout time.Duration) (gpio.EdgeEvent, bool) {
+	if !p.WaitForEdge(timeout) {
+		return gpio.EdgeEvent{}, false
+	}
+	e := gpio.EdgeEvent{Time: time.Since(epoch), Seq: atomic.AddUint32(&p.seq, 1)}
+	// p.edge is modified by In() and Halt() under the lock.
+	p.mu.Lock()
+	edge := p.edge
+	p.mu.Unlock()
+	switch edge {
+	case gpio.RisingEdge:
+		e.Edge = gpio.RisingEdge
+	case gpio.FallingEdge:
+		e.Edge = gpio.FallingEdge
+	default:
+		if p.Read() {
+			e.Edge = gpio.RisingEdge
+		} else {
+			e.Edge = gpio.FallingEdge
+		}
+	}
+	return e, true
+}
+
 // Pull implements gpio.PinIn.
 //
 // It returns gpio.PullNoChange since gpio sysfs has no support for input pull
@@ -302,8 +346,12 @@ func (p *Pin) Out(l gpio.Level) error {
 
 // PWM implements gpio.PinOut.
 //
-// This is not supported on sysfs.
-func (p *Pin) PWM(gpio.Duty, physic.Frequency) error {
+// This is only supported if a PWM channel was registered for this pin with
+// RegisterPWMPin().
+func (p *Pin) PWM(duty gpio.Duty, freq physic.Frequency) error {
+	if s := PWMByPin(p.name); s != nil {
+		return s.PWM(duty, freq)
+	}
 	return p.wrap(errors.New("pwm is not supported via sysfs"))
 }
 
@@ -421,6 +469,10 @@ func readInt(path string) (int, error) {
 	return strconv.Atoi(string(raw[:len(raw)-1]))
 }
 
+// epoch is the reference used to timestamp edge events detected in user
+// space. time.Since() uses the monotonic clock.
+var epoch = time.Now()
+
 // driverGPIO implements periph.Driver.
 type driverGPIO struct {
 	exportHandle io.Writer // handle to /sys/class/gpio/export
@@ -517,4 +569,6 @@ var _ conn.Resource = &Pin{}
 var _ gpio.PinIn = &Pin{}
 var _ gpio.PinOut = &Pin{}
 var _ gpio.PinIO = &Pin{}
+var _ gpio.PinEventer = &Pin{}
+var _ gpio.WaitForEdgeContexter = &Pin{}
 var _ pin.PinFunc = &Pin{}
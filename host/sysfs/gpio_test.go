@@ -5,6 +5,7 @@
 package sysfs
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -139,6 +140,26 @@ func TestPin_WaitForEdges(t *testing.T) {
 	}
 }
 
+func TestPin_WaitForEvent(t *testing.T) {
+	p := Pin{number: 42, name: "foo", root: "/tmp/gpio/priv/"}
+	if _, ok := p.WaitForEvent(-1); ok {
+		t.Fatal("broken pin doesn't have edge triggered")
+	}
+}
+
+func TestPin_WaitForEdgeContext(t *testing.T) {
+	p := Pin{number: 42, name: "foo", root: "/tmp/gpio/priv/"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if p.WaitForEdgeContext(ctx) {
+		t.Fatal("broken pin doesn't have edge triggered")
+	}
+	cancel()
+	if p.WaitForEdgeContext(ctx) {
+		t.Fatal("context is canceled")
+	}
+}
+
 func TestPin_Pull(t *testing.T) {
 	p := Pin{number: 42, name: "foo", root: "/tmp/gpio/priv/"}
 	if pull := p.Pull(); pull != gpio.PullNoChange {
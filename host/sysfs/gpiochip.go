@@ -0,0 +1,871 @@
+// Copyright 2020 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sysfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/meandrewdev/periph"
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/gpio/gpioreg"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/pin"
+	"github.com/meandrewdev/periph/host/fs"
+)
+
+// GPIOChips is all the GPIO character devices found at /dev/gpiochip*.
+//
+// This global variable is initialized once at driver initialization and isn't
+// mutated afterward. Do not modify it.
+var GPIOChips []*GPIOChip
+
+// GPIOChip is a GPIO controller as exposed by the Linux GPIO character device
+// /dev/gpiochipN.
+//
+// The character device is the replacement for the deprecated /sys/class/gpio
+// interface. It is documented at
+// https://www.kernel.org/doc/html/latest/userspace-api/gpio/chardev.html
+type GPIOChip struct {
+	name  string // Something like gpiochip0
+	label string // Something like pinctrl-bcm2711
+	f     ioctlCloser
+	lines []*GPIOLine
+}
+
+// String implements fmt.Stringer.
+func (c *GPIOChip) String() string {
+	return fmt.Sprintf("%s(%s)", c.name, c.label)
+}
+
+// Name returns the kernel name of the chip, e.g. "gpiochip0".
+func (c *GPIOChip) Name() string {
+	return c.name
+}
+
+// Label returns the label of the chip as provided by its kernel driver, e.g.
+// "pinctrl-bcm2711".
+func (c *GPIOChip) Label() string {
+	return c.label
+}
+
+// Lines returns all the lines exposed by this chip, ordered by offset.
+func (c *GPIOChip) Lines() []*GPIOLine {
+	return c.lines
+}
+
+// GPIOLineByLabel returns the line at offset of the first chip in GPIOChips
+// with this label, or nil if there is none.
+//
+// It is meant for CPU drivers to retrieve the line of one of their pins, e.g.
+// to use the edge events timestamped by the kernel.
+func GPIOLineByLabel(label string, offset int) *GPIOLine {
+	for _, c := range GPIOChips {
+		if c.label == label && offset >= 0 && offset < len(c.lines) {
+			return c.lines[offset]
+		}
+	}
+	return nil
+}
+
+// EdgePin is implemented by GPIOLine and Pin, which CPU drivers use for edge
+// detection.
+type EdgePin interface {
+	gpio.PinIn
+	gpio.PinEventer
+}
+
+// StartEdge enables edge detection on the GPIO character device line if
+// available, otherwise on the sysfs pin. It is meant for CPU drivers that
+// read and write their pins via memory mapped registers.
+//
+// pull is passed to the line so its bias matches the pull the CPU driver just
+// set; it is not changed on the sysfs pin.
+//
+// With the line, the events are timestamped by the kernel when the interrupt
+// is serviced, see GPIOLine.WaitForEvent. With the sysfs pin, they are
+// timestamped when the process is woken up, which can be milliseconds later
+// on a loaded system, see Pin.WaitForEvent.
+func StartEdge(line *GPIOLine, s *Pin, pull gpio.Pull, edge gpio.Edge) (EdgePin, error) {
+	if line != nil {
+		// The line may be busy, e.g. if it is exported in gpio sysfs. Fall back
+		// to sysfs in this case.
+		if err := line.In(pull, edge); err == nil {
+			return line, nil
+		}
+	}
+	if s == nil {
+		return nil, errors.New("sysfs-gpio: pin is not exported by sysfs")
+	}
+	if err := s.In(gpio.PullNoChange, edge); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Drive specifies the output drive mode of a GPIOLine.
+type Drive uint8
+
+// Acceptable drive values.
+const (
+	PushPull   Drive = 0 // Drive both high and low; the default
+	OpenDrain  Drive = 1 // Drive low only, let the line float when high
+	OpenSource Drive = 2 // Drive high only, let the line float when low
+)
+
+const driveName = "PushPullOpenDrainOpenSource"
+
+var driveIndex = [...]uint8{0, 8, 17, 27}
+
+func (i Drive) String() string {
+	if i >= Drive(len(driveIndex)-1) {
+		return "Drive(" + strconv.Itoa(int(i)) + ")"
+	}
+	return driveName[driveIndex[i]:driveIndex[i+1]]
+}
+
+// GPIOLine represents one GPIO line of a GPIOChip.
+//
+// Unlike Pin, it supports internal pull resistors and output drive modes when
+// the kernel driver of the chip supports it.
+type GPIOLine struct {
+	chip   *GPIOChip
+	offset uint32
+	name   string
+
+	mu        sync.Mutex
+	fLine     fileIO    // handle returned by GPIO_V2_GET_LINE_IOCTL; never closed
+	direction direction // Cache of the last known direction
+	edge      gpio.Edge // Cache of the last edge used
+	bias      uint64    // Cache of the last bias flags used
+	drive     Drive     // Drive mode to use when set as output
+	level     gpio.Level
+	event     fs.Event // Initialized once
+
+	muEvents sync.Mutex
+	events   []gpio.EdgeEvent // Events read from the kernel but not returned yet
+}
+
+// String implements conn.Resource.
+func (l *GPIOLine) String() string {
+	return l.name
+}
+
+// Halt implements conn.Resource.
+//
+// It stops edge detection if enabled.
+func (l *GPIOLine) Halt() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.haltEdge()
+}
+
+// Name implements pin.Pin.
+func (l *GPIOLine) Name() string {
+	return l.name
+}
+
+// Number implements pin.Pin.
+//
+// It returns the offset of the line within its chip.
+func (l *GPIOLine) Number() int {
+	return int(l.offset)
+}
+
+// Function implements pin.Pin.
+func (l *GPIOLine) Function() string {
+	return string(l.Func())
+}
+
+// Func implements pin.PinFunc.
+func (l *GPIOLine) Func() pin.Func {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch l.direction {
+	case dIn:
+		if l.Read() {
+			return gpio.IN_HIGH
+		}
+		return gpio.IN_LOW
+	case dOut:
+		if l.Read() {
+			return gpio.OUT_HIGH
+		}
+		return gpio.OUT_LOW
+	}
+	// The line is not requested by this process, query the kernel for its
+	// current state.
+	info, err := l.info()
+	if err != nil {
+		return pin.FuncNone
+	}
+	if info.flags&lineFlagOutput != 0 {
+		return gpio.OUT
+	}
+	if info.flags&lineFlagInput != 0 {
+		return gpio.IN
+	}
+	return pin.FuncNone
+}
+
+// SupportedFuncs implements pin.PinFunc.
+func (l *GPIOLine) SupportedFuncs() []pin.Func {
+	return []pin.Func{gpio.IN, gpio.OUT}
+}
+
+// SetFunc implements pin.PinFunc.
+func (l *GPIOLine) SetFunc(f pin.Func) error {
+	switch f {
+	case gpio.IN:
+		return l.In(gpio.PullNoChange, gpio.NoEdge)
+	case gpio.OUT_HIGH:
+		return l.Out(gpio.High)
+	case gpio.OUT, gpio.OUT_LOW:
+		return l.Out(gpio.Low)
+	default:
+		return l.wrap(errors.New("unsupported function"))
+	}
+}
+
+// In implements gpio.PinIn.
+func (l *GPIOLine) In(pull gpio.Pull, edge gpio.Edge) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bias := l.bias
+	switch pull {
+	case gpio.PullNoChange:
+	case gpio.Float:
+		bias = lineFlagBiasDisabled
+	case gpio.PullDown:
+		bias = lineFlagBiasPullDown
+	case gpio.PullUp:
+		bias = lineFlagBiasPullUp
+	default:
+		return l.wrap(fmt.Errorf("invalid pull %s", pull))
+	}
+	flags := lineFlagInput | bias
+	switch edge {
+	case gpio.NoEdge:
+	case gpio.RisingEdge:
+		flags |= lineFlagEdgeRising
+	case gpio.FallingEdge:
+		flags |= lineFlagEdgeFalling
+	case gpio.BothEdges:
+		flags |= lineFlagEdgeRising | lineFlagEdgeFalling
+	default:
+		return l.wrap(fmt.Errorf("invalid edge %s", edge))
+	}
+	if err := l.configure(flags, gpio.Low); err != nil {
+		return l.wrap(err)
+	}
+	l.direction = dIn
+	l.edge = edge
+	l.bias = bias
+	l.muEvents.Lock()
+	l.events = l.events[:0]
+	l.muEvents.Unlock()
+	// This helps to remove accumulated edges but this is not 100% sufficient,
+	// as the kernel may deliver an edge that occurred before the
+	// reconfiguration afterward.
+	if edge != gpio.NoEdge {
+		l.WaitForEdge(0)
+	}
+	return nil
+}
+
+// Read implements gpio.PinIn.
+func (l *GPIOLine) Read() gpio.Level {
+	// There's no lock here.
+	if l.fLine == nil {
+		return gpio.Low
+	}
+	v := gpioV2LineValues{mask: 1}
+	if err := l.fLine.Ioctl(ioctlGPIOV2LineGetValues, uintptr(unsafe.Pointer(&v))); err != nil {
+		return gpio.Low
+	}
+	return v.bits&1 != 0
+}
+
+// WaitForEdge implements gpio.PinIn.
+//
+// The edge events queued by the kernel are consumed, so multiple edges that
+// occurred since the last call are coalesced into one.
+func (l *GPIOLine) WaitForEdge(timeout time.Duration) bool {
+	l.muEvents.Lock()
+	pending := len(l.events) != 0
+	l.events = l.events[:0]
+	l.muEvents.Unlock()
+	if pending {
+		return true
+	}
+	if !l.waitReadable(timeout) {
+		return false
+	}
+	_, err := l.readEvents()
+	return err == nil
+}
+
+// WaitForEdgeContext implements gpio.WaitForEdgeContexter.
+//
+// Returns false as soon as ctx is done.
+func (l *GPIOLine) WaitForEdgeContext(ctx context.Context) bool {
+	l.muEvents.Lock()
+	pending := len(l.events) != 0
+	l.events = l.events[:0]
+	l.muEvents.Unlock()
+	if pending {
+		return true
+	}
+	if nr, err := l.event.WaitContext(ctx); err != nil || nr != 1 {
+		return false
+	}
+	_, err := l.readEvents()
+	return err == nil
+}
+
+// WaitForEvent implements gpio.PinEventer.
+//
+// The events are timestamped by the kernel when the interrupt is serviced,
+// using CLOCK_MONOTONIC. The sequence number is the one of the line as
+// reported by the kernel, so edges dropped because the kernel buffer
+// overflowed are detected.
+func (l *GPIOLine) WaitForEvent(timeout time.Duration) (gpio.EdgeEvent, bool) {
+	if e, ok := l.popEvent(); ok {
+		return e, true
+	}
+	if !l.waitReadable(timeout) {
+		return gpio.EdgeEvent{}, false
+	}
+	ev, err := l.readEvents()
+	if err != nil {
+		return gpio.EdgeEvent{}, false
+	}
+	l.muEvents.Lock()
+	for i := range ev {
+		e := gpio.EdgeEvent{
+			Edge: gpio.FallingEdge,
+			Time: time.Duration(ev[i].timestampNs),
+			Seq:  ev[i].lineSeqno,
+		}
+		if ev[i].id == lineEventRisingEdge {
+			e.Edge = gpio.RisingEdge
+		}
+		l.events = append(l.events, e)
+	}
+	l.muEvents.Unlock()
+	return l.popEvent()
+}
+
+// Pull implements gpio.PinIn.
+//
+// It returns the pull last requested by this process or reported by the
+// kernel. It returns gpio.PullNoChange if the bias is unknown.
+func (l *GPIOLine) Pull() gpio.Pull {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return biasToPull(l.bias)
+}
+
+// DefaultPull implements gpio.PinIn.
+//
+// It returns gpio.PullNoChange since the character device doesn't expose the
+// pull on reset.
+func (l *GPIOLine) DefaultPull() gpio.Pull {
+	return gpio.PullNoChange
+}
+
+// Out implements gpio.PinOut.
+func (l *GPIOLine) Out(level gpio.Level) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.direction != dOut {
+		// The initial value is part of the line configuration, which makes it
+		// glitch free.
+		if err := l.configure(lineFlagOutput|l.bias|driveFlags[l.drive], level); err != nil {
+			return l.wrap(err)
+		}
+		l.direction = dOut
+		l.edge = gpio.NoEdge
+		l.level = level
+		return nil
+	}
+	v := gpioV2LineValues{mask: 1}
+	if level {
+		v.bits = 1
+	}
+	if err := l.fLine.Ioctl(ioctlGPIOV2LineSetValues, uintptr(unsafe.Pointer(&v))); err != nil {
+		return l.wrap(err)
+	}
+	l.level = level
+	return nil
+}
+
+// PWM implements gpio.PinOut.
+//
+// This is not supported on the GPIO character device.
+func (l *GPIOLine) PWM(gpio.Duty, physic.Frequency) error {
+	return l.wrap(errors.New("pwm is not supported via gpiochip"))
+}
+
+// SetDrive sets the drive mode used when the line is an output.
+//
+// If the line is currently an output, it is reconfigured immediately while
+// retaining its level. Otherwise the drive mode is used on the next call to
+// Out().
+func (l *GPIOLine) SetDrive(d Drive) error {
+	if d >= Drive(len(driveFlags)) {
+		return l.wrap(fmt.Errorf("invalid drive %s", d))
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.direction == dOut && d != l.drive {
+		if err := l.configure(lineFlagOutput|l.bias|driveFlags[d], l.level); err != nil {
+			return l.wrap(err)
+		}
+	}
+	l.drive = d
+	return nil
+}
+
+// Drive returns the drive mode used when the line is an output.
+func (l *GPIOLine) Drive() Drive {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.drive
+}
+
+//
+
+// configure requests the line if it was not requested yet, otherwise
+// reconfigures it.
+//
+// out is only used when flags contains lineFlagOutput.
+//
+// lock must be held.
+func (l *GPIOLine) configure(flags uint64, out gpio.Level) error {
+	var cfg gpioV2LineConfig
+	cfg.flags = flags
+	if flags&lineFlagOutput != 0 {
+		cfg.numAttrs = 1
+		cfg.attrs[0].attr.id = lineAttrIDOutputValues
+		if out {
+			cfg.attrs[0].attr.value = 1
+		}
+		cfg.attrs[0].mask = 1
+	}
+	if l.fLine != nil {
+		return l.fLine.Ioctl(ioctlGPIOV2LineSetConfig, uintptr(unsafe.Pointer(&cfg)))
+	}
+	if l.chip.f == nil {
+		return errors.New("gpiochip is not initialized")
+	}
+	req := gpioV2LineRequest{config: cfg, numLines: 1}
+	req.offsets[0] = l.offset
+	copy(req.consumer[:], consumerName)
+	if err := l.chip.f.Ioctl(ioctlGPIOV2GetLine, uintptr(unsafe.Pointer(&req))); err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("need more access, try as root or setup udev rules: %v", err)
+		}
+		return err
+	}
+	f, err := lineFileOpen(uintptr(req.fd), fmt.Sprintf("%s-%d", l.chip.name, l.offset))
+	if err != nil {
+		return err
+	}
+	if err := l.event.MakeReadEvent(f.Fd()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	l.fLine = f
+	return nil
+}
+
+// info retrieves the line information from the kernel.
+func (l *GPIOLine) info() (*gpioV2LineInfo, error) {
+	if l.chip.f == nil {
+		return nil, errors.New("gpiochip is not initialized")
+	}
+	info := &gpioV2LineInfo{offset: l.offset}
+	if err := l.chip.f.Ioctl(ioctlGPIOV2GetLineInfo, uintptr(unsafe.Pointer(info))); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// waitReadable waits for edge events to be queued by the kernel.
+func (l *GPIOLine) waitReadable(timeout time.Duration) bool {
+	// Run lockless, as the normal use is to call in a busy loop.
+	var ms int
+	if timeout == -1 {
+		ms = -1
+	} else {
+		ms = int(timeout / time.Millisecond)
+	}
+	start := time.Now()
+	for {
+		if nr, err := l.event.Wait(ms); err != nil {
+			return false
+		} else if nr == 1 {
+			return true
+		}
+		// A signal occurred.
+		if timeout != -1 {
+			ms = int((timeout - time.Since(start)) / time.Millisecond)
+		}
+		if ms <= 0 {
+			return false
+		}
+	}
+}
+
+// popEvent returns the oldest event read from the kernel but not yet returned
+// by WaitForEvent.
+func (l *GPIOLine) popEvent() (gpio.EdgeEvent, bool) {
+	l.muEvents.Lock()
+	defer l.muEvents.Unlock()
+	if len(l.events) == 0 {
+		return gpio.EdgeEvent{}, false
+	}
+	e := l.events[0]
+	copy(l.events, l.events[1:])
+	l.events = l.events[:len(l.events)-1]
+	return e, true
+}
+
+// readEvents consumes the edge events queued by the kernel on the line handle.
+//
+// It must only be called when data is known to be available, otherwise it
+// blocks.
+func (l *GPIOLine) readEvents() ([]gpioV2LineEvent, error) {
+	var ev [16]gpioV2LineEvent
+	b := (*[unsafe.Sizeof(ev)]byte)(unsafe.Pointer(&ev[0]))[:]
+	n, err := l.fLine.Read(b)
+	if err != nil {
+		return nil, err
+	}
+	return ev[:n/int(unsafe.Sizeof(ev[0]))], nil
+}
+
+// haltEdge stops any on-going edge detection.
+//
+// lock must be held.
+func (l *GPIOLine) haltEdge() error {
+	if l.edge != gpio.NoEdge {
+		if err := l.configure(lineFlagInput|l.bias, gpio.Low); err != nil {
+			return l.wrap(err)
+		}
+		l.edge = gpio.NoEdge
+		// This is still important to remove an accumulated edge.
+		l.WaitForEdge(0)
+	}
+	return nil
+}
+
+func (l *GPIOLine) wrap(err error) error {
+	return fmt.Errorf("sysfs-gpiochip (%s): %v", l, err)
+}
+
+func biasToPull(bias uint64) gpio.Pull {
+	switch {
+	case bias&lineFlagBiasPullUp != 0:
+		return gpio.PullUp
+	case bias&lineFlagBiasPullDown != 0:
+		return gpio.PullDown
+	case bias&lineFlagBiasDisabled != 0:
+		return gpio.Float
+	default:
+		return gpio.PullNoChange
+	}
+}
+
+var lineFileOpen = lineFileOpenDefault
+
+// lineFileOpenDefault wraps the file descriptor returned by the kernel for a
+// requested line.
+func lineFileOpenDefault(fd uintptr, name string) (fileIO, error) {
+	return &fs.File{File: os.NewFile(fd, name)}, nil
+}
+
+// consumerName is the consumer label reported to the kernel for requested
+// lines, as visible with gpioinfo.
+const consumerName = "periph"
+
+// GPIO character device IOCTL control codes.
+//
+// Constants and structure definition can be found at
+// /usr/include/linux/gpio.h.
+const (
+	gpioMaxNameSize       = 32 // GPIO_MAX_NAME_SIZE
+	gpioV2LinesMax        = 64 // GPIO_V2_LINES_MAX
+	gpioV2LineNumAttrsMax = 10 // GPIO_V2_LINE_NUM_ATTRS_MAX
+)
+
+// gpio_v2_line_flag
+const (
+	lineFlagUsed          = 1 << 0
+	lineFlagActiveLow     = 1 << 1
+	lineFlagInput         = 1 << 2
+	lineFlagOutput        = 1 << 3
+	lineFlagEdgeRising    = 1 << 4
+	lineFlagEdgeFalling   = 1 << 5
+	lineFlagOpenDrain     = 1 << 6
+	lineFlagOpenSource    = 1 << 7
+	lineFlagBiasPullUp    = 1 << 8
+	lineFlagBiasPullDown  = 1 << 9
+	lineFlagBiasDisabled  = 1 << 10
+	lineFlagEventRealtime = 1 << 11 // TODO(maruel): Expose this
+)
+
+var driveFlags = [...]uint64{
+	PushPull:   0,
+	OpenDrain:  lineFlagOpenDrain,
+	OpenSource: lineFlagOpenSource,
+}
+
+// gpio_v2_line_attr_id
+const (
+	lineAttrIDFlags        = 1
+	lineAttrIDOutputValues = 2
+	lineAttrIDDebounce     = 3 // TODO(maruel): Expose this
+)
+
+// gpio_v2_line_event_id
+const (
+	lineEventRisingEdge  = 1
+	lineEventFallingEdge = 2
+)
+
+const gpioIOCMagic uint = 0xB4
+
+var (
+	ioctlGPIOGetChipInfo     = fs.IOR(gpioIOCMagic, 0x01, uint(unsafe.Sizeof(gpioChipInfo{})))       // GPIO_GET_CHIPINFO_IOCTL
+	ioctlGPIOV2GetLineInfo   = fs.IOWR(gpioIOCMagic, 0x05, uint(unsafe.Sizeof(gpioV2LineInfo{})))    // GPIO_V2_GET_LINEINFO_IOCTL
+	ioctlGPIOV2GetLine       = fs.IOWR(gpioIOCMagic, 0x07, uint(unsafe.Sizeof(gpioV2LineRequest{}))) // GPIO_V2_GET_LINE_IOCTL
+	ioctlGPIOV2LineSetConfig = fs.IOWR(gpioIOCMagic, 0x0D, uint(unsafe.Sizeof(gpioV2LineConfig{})))  // GPIO_V2_LINE_SET_CONFIG_IOCTL
+	ioctlGPIOV2LineGetValues = fs.IOWR(gpioIOCMagic, 0x0E, uint(unsafe.Sizeof(gpioV2LineValues{})))  // GPIO_V2_LINE_GET_VALUES_IOCTL
+	ioctlGPIOV2LineSetValues = fs.IOWR(gpioIOCMagic, 0x0F, uint(unsafe.Sizeof(gpioV2LineValues{})))  // GPIO_V2_LINE_SET_VALUES_IOCTL
+)
+
+// gpioChipInfo is struct gpiochip_info.
+type gpioChipInfo struct {
+	name  [gpioMaxNameSize]byte
+	label [gpioMaxNameSize]byte
+	lines uint32
+}
+
+// gpioV2LineAttribute is struct gpio_v2_line_attribute.
+type gpioV2LineAttribute struct {
+	id      uint32
+	padding uint32
+	value   uint64 // union of flags, values and debounce_period_us
+}
+
+// gpioV2LineConfigAttribute is struct gpio_v2_line_config_attribute.
+type gpioV2LineConfigAttribute struct {
+	attr gpioV2LineAttribute
+	mask uint64
+}
+
+// gpioV2LineConfig is struct gpio_v2_line_config.
+type gpioV2LineConfig struct {
+	flags    uint64
+	numAttrs uint32
+	padding  [5]uint32
+	attrs    [gpioV2LineNumAttrsMax]gpioV2LineConfigAttribute
+}
+
+// gpioV2LineRequest is struct gpio_v2_line_request.
+type gpioV2LineRequest struct {
+	offsets         [gpioV2LinesMax]uint32
+	consumer        [gpioMaxNameSize]byte
+	config          gpioV2LineConfig
+	numLines        uint32
+	eventBufferSize uint32
+	padding         [5]uint32
+	fd              int32
+}
+
+// gpioV2LineInfo is struct gpio_v2_line_info.
+type gpioV2LineInfo struct {
+	name     [gpioMaxNameSize]byte
+	consumer [gpioMaxNameSize]byte
+	offset   uint32
+	numAttrs uint32
+	flags    uint64
+	attrs    [gpioV2LineNumAttrsMax]gpioV2LineAttribute
+	padding  [4]uint32
+}
+
+// gpioV2LineValues is struct gpio_v2_line_values.
+type gpioV2LineValues struct {
+	bits uint64
+	mask uint64
+}
+
+// gpioV2LineEvent is struct gpio_v2_line_event.
+type gpioV2LineEvent struct {
+	timestampNs uint64
+	id          uint32
+	offset      uint32
+	seqno       uint32
+	lineSeqno   uint32
+	padding     [6]uint32
+}
+
+// cString converts a NUL terminated C string to a Go string.
+func cString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
+
+//
+
+func newGPIOChip(path string) (*GPIOChip, error) {
+	f, err := ioctlOpen(path, os.O_RDWR)
+	if err != nil {
+		if os.IsPermission(err) {
+			return nil, fmt.Errorf("need more access, try as root or setup udev rules: %v", err)
+		}
+		return nil, err
+	}
+	var info gpioChipInfo
+	if err := f.Ioctl(ioctlGPIOGetChipInfo, uintptr(unsafe.Pointer(&info))); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	c := &GPIOChip{
+		name:  cString(info.name[:]),
+		label: cString(info.label[:]),
+		f:     f,
+		lines: make([]*GPIOLine, info.lines),
+	}
+	for i := range c.lines {
+		l := &GPIOLine{chip: c, offset: uint32(i)}
+		li, err := l.info()
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		l.name = cString(li.name[:])
+		l.bias = li.flags & (lineFlagBiasPullUp | lineFlagBiasPullDown | lineFlagBiasDisabled)
+		c.lines[i] = l
+	}
+	return c, nil
+}
+
+// driverGPIOChip implements periph.Driver.
+type driverGPIOChip struct {
+}
+
+func (d *driverGPIOChip) String() string {
+	return "sysfs-gpiochip"
+}
+
+func (d *driverGPIOChip) Prerequisites() []string {
+	return nil
+}
+
+func (d *driverGPIOChip) After() []string {
+	// Supersede the pins registered by the legacy sysfs GPIO driver.
+	return []string{"sysfs-gpio"}
+}
+
+// Init initializes GPIO character device handling code.
+//
+// Uses the GPIO v2 userspace API as described at
+// https://www.kernel.org/doc/html/latest/userspace-api/gpio/chardev.html
+//
+// Lines are registered by their name as set in the device tree, e.g. GPIO17.
+// Lines without a name, or with a name already in use, are registered with
+// the chip name and the line offset, e.g. gpiochip0_17, which is registered as
+// an alias otherwise.
+func (d *driverGPIOChip) Init() (bool, error) {
+	prefix := "/dev/gpiochip"
+	items, err := filepath.Glob(prefix + "*")
+	if err != nil {
+		return true, err
+	}
+	if len(items) == 0 {
+		return false, errors.New("no GPIO character device found")
+	}
+	var numbers []int
+	for _, item := range items {
+		if n, err := strconv.Atoi(item[len(prefix):]); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	for _, n := range numbers {
+		c, err := newGPIOChip(prefix + strconv.Itoa(n))
+		if err != nil {
+			return true, err
+		}
+		GPIOChips = append(GPIOChips, c)
+	}
+	return true, d.register(GPIOChips)
+}
+
+func (d *driverGPIOChip) register(chips []*GPIOChip) error {
+	used := map[string]struct{}{}
+	for _, c := range chips {
+		for _, l := range c.lines {
+			qualified := fmt.Sprintf("%s_%d", c.name, l.offset)
+			if _, ok := used[l.name]; ok || l.name == "" {
+				l.name = qualified
+			} else {
+				// Unregister the pin if already registered. This happens with
+				// sysfs-gpio. Do not error on it, since sysfs-gpio may have failed to
+				// load.
+				_ = gpioreg.Unregister(l.name)
+			}
+			if err := gpioreg.Register(l); err != nil {
+				if l.name == qualified {
+					return err
+				}
+				l.name = qualified
+				if err := gpioreg.Register(l); err != nil {
+					return err
+				}
+			}
+			used[l.name] = struct{}{}
+			if l.name != qualified {
+				if err := gpioreg.RegisterAlias(qualified, l.name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	if isLinux {
+		periph.MustRegister(&drvGPIOChip)
+	}
+}
+
+var drvGPIOChip driverGPIOChip
+
+var _ conn.Resource = &GPIOLine{}
+var _ gpio.PinIn = &GPIOLine{}
+var _ gpio.PinOut = &GPIOLine{}
+var _ gpio.PinIO = &GPIOLine{}
+var _ gpio.PinEventer = &GPIOLine{}
+var _ gpio.WaitForEdgeContexter = &GPIOLine{}
+var _ pin.PinFunc = &GPIOLine{}
+var _ fmt.Stringer = &GPIOChip{}
@@ -0,0 +1,550 @@
+// Copyright 2020 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sysfs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/gpio/gpioreg"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/pin"
+)
+
+func TestGPIOChip_structs(t *testing.T) {
+	// Sizes must match the kernel's layout.
+	data := []struct {
+		name string
+		got  uintptr
+		want uintptr
+	}{
+		{"gpiochip_info", unsafe.Sizeof(gpioChipInfo{}), 68},
+		{"gpio_v2_line_attribute", unsafe.Sizeof(gpioV2LineAttribute{}), 16},
+		{"gpio_v2_line_config", unsafe.Sizeof(gpioV2LineConfig{}), 272},
+		{"gpio_v2_line_request", unsafe.Sizeof(gpioV2LineRequest{}), 592},
+		{"gpio_v2_line_info", unsafe.Sizeof(gpioV2LineInfo{}), 256},
+		{"gpio_v2_line_values", unsafe.Sizeof(gpioV2LineValues{}), 16},
+		{"gpio_v2_line_event", unsafe.Sizeof(gpioV2LineEvent{}), 48},
+	}
+	for _, line := range data {
+		if line.got != line.want {
+			t.Errorf("%s: %d != %d", line.name, line.got, line.want)
+		}
+	}
+	if ioctlGPIOV2GetLine != 0xC250B407 {
+		t.Fatalf("0x%X", ioctlGPIOV2GetLine)
+	}
+}
+
+func TestGPIOChip(t *testing.T) {
+	defer reset()
+	f := &fakeGPIOChip{names: []string{"GPIO0", "", "GPIO0"}, flags: []uint64{lineFlagInput | lineFlagBiasPullUp, lineFlagOutput, 0}}
+	ioctlOpen = func(path string, flag int) (ioctlCloser, error) {
+		if path != "/dev/gpiochip0" {
+			t.Fatal(path)
+		}
+		return f, nil
+	}
+	c, err := newGPIOChip("/dev/gpiochip0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := c.String(); s != "gpiochip0(fake)" {
+		t.Fatal(s)
+	}
+	if s := c.Name(); s != "gpiochip0" {
+		t.Fatal(s)
+	}
+	if s := c.Label(); s != "fake" {
+		t.Fatal(s)
+	}
+	lines := c.Lines()
+	if len(lines) != 3 {
+		t.Fatal(len(lines))
+	}
+	if p := lines[0].Pull(); p != gpio.PullUp {
+		t.Fatal(p)
+	}
+	if fn := lines[0].Func(); fn != gpio.IN {
+		t.Fatal(fn)
+	}
+	if fn := lines[1].Func(); fn != gpio.OUT {
+		t.Fatal(fn)
+	}
+	if fn := lines[2].Func(); fn != pin.FuncNone {
+		t.Fatal(fn)
+	}
+
+	d := driverGPIOChip{}
+	if err := d.register([]*GPIOChip{c}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, name := range []string{"GPIO0", "gpiochip0_0", "gpiochip0_1", "gpiochip0_2"} {
+			if err := gpioreg.Unregister(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}()
+	if s := lines[0].Name(); s != "GPIO0" {
+		t.Fatal(s)
+	}
+	if s := lines[1].Name(); s != "gpiochip0_1" {
+		t.Fatal(s)
+	}
+	if s := lines[2].Name(); s != "gpiochip0_2" {
+		t.Fatal(s)
+	}
+	if r, ok := gpioreg.ByName("gpiochip0_0").(gpio.RealPin); !ok || r.Real() != lines[0] {
+		t.Fatal("expected alias to GPIO0")
+	}
+
+	GPIOChips = []*GPIOChip{c}
+	defer func() {
+		GPIOChips = nil
+	}()
+	if l := GPIOLineByLabel("fake", 1); l != lines[1] {
+		t.Fatal(l)
+	}
+	for _, offset := range []int{-1, 3} {
+		if l := GPIOLineByLabel("fake", offset); l != nil {
+			t.Fatal(l)
+		}
+	}
+	if l := GPIOLineByLabel("other", 0); l != nil {
+		t.Fatal(l)
+	}
+}
+
+func TestGPIOChip_open_fail(t *testing.T) {
+	defer reset()
+	ioctlOpen = func(path string, flag int) (ioctlCloser, error) {
+		return nil, os.ErrPermission
+	}
+	if _, err := newGPIOChip("/dev/gpiochip0"); err == nil {
+		t.Fatal("expected failure")
+	}
+	ioctlOpen = func(path string, flag int) (ioctlCloser, error) {
+		return &ioctlClose{ioctlErr: errors.New("injected")}, nil
+	}
+	if _, err := newGPIOChip("/dev/gpiochip0"); err == nil {
+		t.Fatal("expected failure")
+	}
+}
+
+func TestGPIOLine_In(t *testing.T) {
+	defer reset()
+	l, f := newFakeGPIOLine(t)
+	if l.In(gpio.Pull(10), gpio.NoEdge) == nil {
+		t.Fatal("invalid pull")
+	}
+	if l.In(gpio.PullNoChange, gpio.Edge(10)) == nil {
+		t.Fatal("invalid edge")
+	}
+	if err := l.In(gpio.PullDown, gpio.NoEdge); err != nil {
+		t.Fatal(err)
+	}
+	if f.requested != 1 {
+		t.Fatal("line must be requested")
+	}
+	if got := f.req.config.flags; got != lineFlagInput|lineFlagBiasPullDown {
+		t.Fatalf("0x%X", got)
+	}
+	if s := string(f.req.consumer[:len(consumerName)]); s != consumerName {
+		t.Fatal(s)
+	}
+	if p := l.Pull(); p != gpio.PullDown {
+		t.Fatal(p)
+	}
+	if err := l.In(gpio.PullNoChange, gpio.BothEdges); err != nil {
+		t.Fatal(err)
+	}
+	if f.requested != 1 {
+		t.Fatal("line must be reconfigured, not requested again")
+	}
+	want := uint64(lineFlagInput | lineFlagBiasPullDown | lineFlagEdgeRising | lineFlagEdgeFalling)
+	if got := f.line.config.flags; got != want {
+		t.Fatalf("0x%X", got)
+	}
+	if err := l.In(gpio.PullUp, gpio.RisingEdge); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.line.config.flags; got != lineFlagInput|lineFlagBiasPullUp|lineFlagEdgeRising {
+		t.Fatalf("0x%X", got)
+	}
+	if err := l.In(gpio.Float, gpio.FallingEdge); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.line.config.flags; got != lineFlagInput|lineFlagBiasDisabled|lineFlagEdgeFalling {
+		t.Fatalf("0x%X", got)
+	}
+	if p := l.Pull(); p != gpio.Float {
+		t.Fatal(p)
+	}
+	if err := l.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.line.config.flags; got != lineFlagInput|lineFlagBiasDisabled {
+		t.Fatalf("0x%X", got)
+	}
+
+	f.line.values = 1
+	if v := l.Read(); v != gpio.High {
+		t.Fatal(v)
+	}
+	if fn := l.Func(); fn != gpio.IN_HIGH {
+		t.Fatal(fn)
+	}
+	f.line.values = 0
+	if v := l.Read(); v != gpio.Low {
+		t.Fatal(v)
+	}
+	f.line.err = errors.New("injected")
+	if v := l.Read(); v != gpio.Low {
+		t.Fatal(v)
+	}
+	if l.In(gpio.PullNoChange, gpio.NoEdge) == nil {
+		t.Fatal("expected failure")
+	}
+}
+
+func TestStartEdge(t *testing.T) {
+	defer reset()
+	l, f := newFakeGPIOLine(t)
+	e, err := StartEdge(l, nil, gpio.PullUp, gpio.RisingEdge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e != l {
+		t.Fatal("expected the line")
+	}
+	if got := f.req.config.flags; got != lineFlagInput|lineFlagBiasPullUp|lineFlagEdgeRising {
+		t.Fatalf("0x%X", got)
+	}
+	// The line can't be requested and there is no sysfs pin to fall back to.
+	busy := &GPIOLine{chip: &GPIOChip{name: "gpiochip0", f: &ioctlClose{ioctlErr: os.ErrPermission}}, name: "foo"}
+	if _, err := StartEdge(busy, nil, gpio.PullNoChange, gpio.RisingEdge); err == nil {
+		t.Fatal("no sysfs pin")
+	}
+	if _, err := StartEdge(nil, nil, gpio.PullNoChange, gpio.RisingEdge); err == nil {
+		t.Fatal("no sysfs pin")
+	}
+}
+
+func TestGPIOLine_In_fail(t *testing.T) {
+	l := &GPIOLine{chip: &GPIOChip{name: "gpiochip0"}, name: "foo"}
+	if l.In(gpio.PullNoChange, gpio.NoEdge) == nil {
+		t.Fatal("chip not opened")
+	}
+	if fn := l.Func(); fn != pin.FuncNone {
+		t.Fatal(fn)
+	}
+	if v := l.Read(); v != gpio.Low {
+		t.Fatal(v)
+	}
+	l.chip.f = &ioctlClose{ioctlErr: os.ErrPermission}
+	if l.In(gpio.PullNoChange, gpio.NoEdge) == nil {
+		t.Fatal("permission denied")
+	}
+}
+
+func TestGPIOLine_Out(t *testing.T) {
+	defer reset()
+	l, f := newFakeGPIOLine(t)
+	if err := l.Out(gpio.High); err != nil {
+		t.Fatal(err)
+	}
+	c := &f.req.config
+	if c.flags != lineFlagOutput || c.numAttrs != 1 || c.attrs[0].attr.id != lineAttrIDOutputValues || c.attrs[0].attr.value != 1 || c.attrs[0].mask != 1 {
+		t.Fatalf("%#v", c)
+	}
+	if err := l.Out(gpio.Low); err != nil {
+		t.Fatal(err)
+	}
+	if f.line.values != 0 {
+		t.Fatal("expected low")
+	}
+	if fn := l.Func(); fn != gpio.OUT_LOW {
+		t.Fatal(fn)
+	}
+	if err := l.SetFunc(gpio.OUT_HIGH); err != nil {
+		t.Fatal(err)
+	}
+	if f.line.values != 1 {
+		t.Fatal("expected high")
+	}
+	if l.SetDrive(Drive(3)) == nil {
+		t.Fatal("invalid drive")
+	}
+	if err := l.SetDrive(OpenDrain); err != nil {
+		t.Fatal(err)
+	}
+	if d := l.Drive(); d != OpenDrain {
+		t.Fatal(d)
+	}
+	if got := f.line.config.flags; got != lineFlagOutput|lineFlagOpenDrain {
+		t.Fatalf("0x%X", got)
+	}
+	if got := f.line.config.attrs[0].attr.value; got != 1 {
+		t.Fatal("level must be retained")
+	}
+	if err := l.SetFunc(gpio.IN); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetDrive(OpenSource); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetFunc(gpio.OUT); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.line.config.flags; got != lineFlagOutput|lineFlagOpenSource {
+		t.Fatalf("0x%X", got)
+	}
+	if l.SetFunc(pin.FuncNone) == nil {
+		t.Fatal("unsupported function")
+	}
+	f.line.err = errors.New("injected")
+	if l.Out(gpio.High) == nil {
+		t.Fatal("expected failure")
+	}
+	if l.PWM(gpio.DutyHalf, physic.KiloHertz) == nil {
+		t.Fatal("gpiochip doesn't support PWM")
+	}
+}
+
+func TestGPIOLine_WaitForEdge(t *testing.T) {
+	if !isLinux {
+		t.Skip("epoll is only supported on linux")
+	}
+	defer reset()
+	l, f := newFakeGPIOLine(t)
+	if l.WaitForEdge(-1) {
+		t.Fatal("line not requested")
+	}
+	if err := l.In(gpio.PullNoChange, gpio.BothEdges); err != nil {
+		t.Fatal(err)
+	}
+	if l.WaitForEdge(0) {
+		t.Fatal("no edge pending")
+	}
+	f.line.push(t, gpioV2LineEvent{timestampNs: 1000, id: lineEventRisingEdge, seqno: 1, lineSeqno: 1})
+	if !l.WaitForEdge(time.Second) {
+		t.Fatal("expected edge")
+	}
+	if l.WaitForEdge(time.Millisecond) {
+		t.Fatal("edge must be consumed")
+	}
+}
+
+func TestGPIOLine_WaitForEdgeContext(t *testing.T) {
+	if !isLinux {
+		t.Skip("epoll is only supported on linux")
+	}
+	defer reset()
+	l, f := newFakeGPIOLine(t)
+	if err := l.In(gpio.PullNoChange, gpio.BothEdges); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if l.WaitForEdgeContext(ctx) {
+		t.Fatal("no edge pending")
+	}
+	if ctx.Err() == nil {
+		t.Fatal("returned before the deadline")
+	}
+	f.line.push(t, gpioV2LineEvent{timestampNs: 1000, id: lineEventRisingEdge, seqno: 1, lineSeqno: 1})
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	if !l.WaitForEdgeContext(ctx) {
+		t.Fatal("expected edge")
+	}
+	go func() {
+		time.Sleep(time.Millisecond)
+		cancel()
+	}()
+	if l.WaitForEdgeContext(ctx) {
+		t.Fatal("edge must be consumed")
+	}
+}
+
+func TestGPIOLine_WaitForEvent(t *testing.T) {
+	if !isLinux {
+		t.Skip("epoll is only supported on linux")
+	}
+	defer reset()
+	l, f := newFakeGPIOLine(t)
+	if _, ok := l.WaitForEvent(-1); ok {
+		t.Fatal("line not requested")
+	}
+	if err := l.In(gpio.PullNoChange, gpio.BothEdges); err != nil {
+		t.Fatal(err)
+	}
+	f.line.push(t, gpioV2LineEvent{timestampNs: 1000, id: lineEventRisingEdge, seqno: 1, lineSeqno: 1})
+	f.line.push(t, gpioV2LineEvent{timestampNs: 3000, id: lineEventFallingEdge, seqno: 3, lineSeqno: 3})
+	want := []gpio.EdgeEvent{
+		{Edge: gpio.RisingEdge, Time: 1000, Seq: 1},
+		{Edge: gpio.FallingEdge, Time: 3000, Seq: 3},
+	}
+	for i, w := range want {
+		if e, ok := l.WaitForEvent(time.Second); !ok || e != w {
+			t.Fatal(i, e, ok)
+		}
+	}
+	if _, ok := l.WaitForEvent(time.Millisecond); ok {
+		t.Fatal("unexpected event")
+	}
+
+	// WaitForEdge consumes the events not yet returned.
+	f.line.push(t, gpioV2LineEvent{timestampNs: 4000, id: lineEventRisingEdge, seqno: 4, lineSeqno: 4})
+	f.line.push(t, gpioV2LineEvent{timestampNs: 5000, id: lineEventFallingEdge, seqno: 5, lineSeqno: 5})
+	if _, ok := l.WaitForEvent(time.Second); !ok {
+		t.Fatal("expected event")
+	}
+	if !l.WaitForEdge(0) {
+		t.Fatal("expected edge")
+	}
+	if l.WaitForEdge(0) {
+		t.Fatal("edge must be consumed")
+	}
+}
+
+func TestDrive_String(t *testing.T) {
+	data := []struct {
+		d    Drive
+		want string
+	}{
+		{PushPull, "PushPull"},
+		{OpenDrain, "OpenDrain"},
+		{OpenSource, "OpenSource"},
+		{Drive(3), "Drive(3)"},
+	}
+	for _, line := range data {
+		if s := line.d.String(); s != line.want {
+			t.Fatal(s)
+		}
+	}
+}
+
+func TestGPIOChipDriver(t *testing.T) {
+	d := &driverGPIOChip{}
+	if len(d.Prerequisites()) != 0 {
+		t.Fatal("unexpected GPIO chip prerequisites")
+	}
+	if a := d.After(); len(a) != 1 || a[0] != "sysfs-gpio" {
+		t.Fatal(a)
+	}
+}
+
+//
+
+// newFakeGPIOLine returns a line on a fake chip whose requested line handle is
+// backed by a pipe, so edge events can be injected.
+func newFakeGPIOLine(t *testing.T) (*GPIOLine, *fakeGPIOChip) {
+	f := &fakeGPIOChip{names: []string{"GPIO0"}, flags: []uint64{0}}
+	c := &GPIOChip{name: "gpiochip0", label: "fake", f: f}
+	l := &GPIOLine{chip: c, name: "GPIO0"}
+	c.lines = []*GPIOLine{l}
+	lineFileOpen = func(fd uintptr, name string) (fileIO, error) {
+		if fd != 42 {
+			t.Fatal(fd)
+		}
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.line = &fakeGPIOLineFile{File: r, w: w}
+		return f.line, nil
+	}
+	return l, f
+}
+
+// fakeGPIOChip implements ioctlCloser and emulates /dev/gpiochipN.
+type fakeGPIOChip struct {
+	names     []string
+	flags     []uint64
+	req       gpioV2LineRequest
+	requested int
+	line      *fakeGPIOLineFile
+}
+
+func (f *fakeGPIOChip) Ioctl(op uint, data uintptr) error {
+	switch op {
+	case ioctlGPIOGetChipInfo:
+		info := (*gpioChipInfo)(ioctlArg(data))
+		copy(info.name[:], "gpiochip0")
+		copy(info.label[:], "fake")
+		info.lines = uint32(len(f.names))
+		return nil
+	case ioctlGPIOV2GetLineInfo:
+		info := (*gpioV2LineInfo)(ioctlArg(data))
+		if int(info.offset) >= len(f.names) {
+			return errors.New("invalid offset")
+		}
+		copy(info.name[:], f.names[info.offset])
+		info.flags = f.flags[info.offset]
+		return nil
+	case ioctlGPIOV2GetLine:
+		req := (*gpioV2LineRequest)(ioctlArg(data))
+		f.req = *req
+		f.requested++
+		req.fd = 42
+		return nil
+	default:
+		return errors.New("unexpected ioctl")
+	}
+}
+
+func (f *fakeGPIOChip) Close() error {
+	return nil
+}
+
+// fakeGPIOLineFile implements fileIO and emulates a requested line handle.
+type fakeGPIOLineFile struct {
+	*os.File
+	w      *os.File
+	config gpioV2LineConfig
+	values uint64
+	err    error
+}
+
+func (f *fakeGPIOLineFile) Ioctl(op uint, data uintptr) error {
+	if f.err != nil {
+		return f.err
+	}
+	switch op {
+	case ioctlGPIOV2LineSetConfig:
+		f.config = *(*gpioV2LineConfig)(ioctlArg(data))
+		return nil
+	case ioctlGPIOV2LineGetValues:
+		v := (*gpioV2LineValues)(ioctlArg(data))
+		v.bits = f.values & v.mask
+		return nil
+	case ioctlGPIOV2LineSetValues:
+		v := (*gpioV2LineValues)(ioctlArg(data))
+		f.values = (f.values &^ v.mask) | (v.bits & v.mask)
+		return nil
+	default:
+		return errors.New("unexpected ioctl")
+	}
+}
+
+// push queues an edge event as the kernel would.
+func (f *fakeGPIOLineFile) push(t *testing.T, e gpioV2LineEvent) {
+	b := (*[unsafe.Sizeof(e)]byte)(unsafe.Pointer(&e))[:]
+	if _, err := f.w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ioctlArg converts an ioctl argument back to the pointer it was created from.
+func ioctlArg(data uintptr) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&data))
+}
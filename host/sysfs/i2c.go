@@ -5,6 +5,7 @@
 package sysfs
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -21,6 +22,7 @@ import (
 	"github.com/meandrewdev/periph/conn/gpio/gpioreg"
 	"github.com/meandrewdev/periph/conn/i2c"
 	"github.com/meandrewdev/periph/conn/i2c/i2creg"
+	"github.com/meandrewdev/periph/conn/i2c/smbus"
 	"github.com/meandrewdev/periph/conn/physic"
 )
 
@@ -128,6 +130,97 @@ func (i *I2C) Tx(addr uint16, w, r []byte) error {
 	return nil
 }
 
+// Transfer implements i2c.Messenger.
+//
+// The flags are checked against the adapter functionality; for example
+// i2c.NoStart requires I2C_FUNC_NOSTART and i2c.IgnoreNAK requires
+// I2C_FUNC_PROTOCOL_MANGLING.
+func (i *I2C) Transfer(msgs []i2c.Msg) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	if len(msgs) > rdwrMaxMsgs {
+		return fmt.Errorf("sysfs-i2c: too many messages: %d > %d", len(msgs), rdwrMaxMsgs)
+	}
+	buf := make([]i2cMsg, len(msgs))
+	for j := range msgs {
+		m := &msgs[j]
+		if err := i.checkMsg(m); err != nil {
+			return err
+		}
+		buf[j].addr = m.Addr
+		buf[j].flags = uint16(m.Flags)
+		buf[j].length = uint16(len(m.Buf))
+		if len(m.Buf) != 0 {
+			if m.Flags&i2c.RecvLen != 0 {
+				// The kernel expects the number of bytes already in the buffer;
+				// one for the length byte.
+				m.Buf[0] = 1
+			}
+			buf[j].buf = uintptr(unsafe.Pointer(&m.Buf[0]))
+		}
+	}
+	p := rdwrIoctlData{
+		msgs:  uintptr(unsafe.Pointer(&buf[0])),
+		nmsgs: uint32(len(buf)),
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err := i.f.Ioctl(ioctlRdwr, uintptr(unsafe.Pointer(&p))); err != nil {
+		return fmt.Errorf("sysfs-i2c: %v", err)
+	}
+	return nil
+}
+
+// TxContext implements i2c.TxContexter.
+//
+// The transaction is a single ioctl that cannot be interrupted once started,
+// so ctx is only checked before starting it.
+func (i *I2C) TxContext(ctx context.Context, addr uint16, w, r []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return i.Tx(addr, w, r)
+}
+
+// Functionality implements smbus.Xferer.
+func (i *I2C) Functionality() smbus.Functionality {
+	return smbus.Functionality(i.fn)
+}
+
+// SMBusXfer implements smbus.Xferer.
+//
+// It uses the I2C_SMBUS ioctl so it works on SMBus only adapters.
+//
+// Like Tx(), which uses I2C_RDWR, it works even if the address is bound to a
+// kernel driver, as the address is selected with I2C_SLAVE_FORCE. Accessing a
+// device concurrently with its kernel driver may confuse both.
+func (i *I2C) SMBusXfer(addr uint16, read bool, cmd byte, p smbus.Protocol, pec bool, d *smbus.Data) error {
+	if addr >= 0x80 {
+		return errors.New("sysfs-i2c: invalid address")
+	}
+	s := smbusIoctlData{command: cmd, size: uint32(p), data: uintptr(unsafe.Pointer(d))}
+	if read {
+		s.readWrite = 1
+	}
+	usePEC := uintptr(0)
+	if pec {
+		usePEC = 1
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err := i.f.Ioctl(ioctlSlaveForce, uintptr(addr)); err != nil {
+		return fmt.Errorf("sysfs-i2c: %v", err)
+	}
+	if err := i.f.Ioctl(ioctlPEC, usePEC); err != nil {
+		return fmt.Errorf("sysfs-i2c: %v", err)
+	}
+	if err := i.f.Ioctl(ioctlSMBus, uintptr(unsafe.Pointer(&s))); err != nil {
+		return fmt.Errorf("sysfs-i2c: %v", err)
+	}
+	return nil
+}
+
 // SetSpeed implements i2c.Bus.
 func (i *I2C) SetSpeed(f physic.Frequency) error {
 	if f > 100*physic.MegaHertz {
@@ -158,6 +251,30 @@ func (i *I2C) SDA() gpio.PinIO {
 
 // Private details.
 
+// checkMsg verifies that the adapter supports the message.
+func (i *I2C) checkMsg(m *i2c.Msg) error {
+	if m.Flags&i2c.TenBit != 0 {
+		if m.Addr >= 0x400 || i.fn&func10BitAddr == 0 {
+			return errors.New("sysfs-i2c: invalid address")
+		}
+	} else if m.Addr >= 0x80 {
+		return errors.New("sysfs-i2c: invalid address")
+	}
+	if m.Flags&i2c.NoStart != 0 && i.fn&funcNOSTART == 0 {
+		return errors.New("sysfs-i2c: NoStart is not supported")
+	}
+	if m.Flags&(i2c.Stop|i2c.RevDirAddr|i2c.IgnoreNAK|i2c.NoReadAck) != 0 && i.fn&funcProtocolMangling == 0 {
+		return fmt.Errorf("sysfs-i2c: %s is not supported", m.Flags&(i2c.Stop|i2c.RevDirAddr|i2c.IgnoreNAK|i2c.NoReadAck))
+	}
+	if m.Flags&i2c.RecvLen != 0 && (m.Flags&i2c.Read == 0 || len(m.Buf) < 1+smbus.BlockMax) {
+		return fmt.Errorf("sysfs-i2c: RecvLen requires a read of at least %d bytes", 1+smbus.BlockMax)
+	}
+	if len(m.Buf) > 0xFFFF {
+		return errors.New("sysfs-i2c: message too long")
+	}
+	return nil
+}
+
 func newI2C(busNumber int) (*I2C, error) {
 	// Use the devfs path for now instead of sysfs path.
 	f, err := ioctlOpen(fmt.Sprintf("/dev/i2c-%d", busNumber), os.O_RDWR)
@@ -203,14 +320,20 @@ func (i *I2C) initPins() {
 // Constants and structure definition can be found at
 // /usr/include/linux/i2c-dev.h and /usr/include/linux/i2c.h.
 const (
-	ioctlRetries = 0x701 // TODO(maruel): Expose this
-	ioctlTimeout = 0x702 // TODO(maruel): Expose this; in units of 10ms
-	ioctlSlave   = 0x703
-	ioctlTenBits = 0x704 // TODO(maruel): Expose this but the header says it's broken (!?)
-	ioctlFuncs   = 0x705
-	ioctlRdwr    = 0x707
+	ioctlRetries    = 0x701 // TODO(maruel): Expose this
+	ioctlTimeout    = 0x702 // TODO(maruel): Expose this; in units of 10ms
+	ioctlSlave      = 0x703
+	ioctlTenBits    = 0x704 // TODO(maruel): Expose this but the header says it's broken (!?)
+	ioctlFuncs      = 0x705
+	ioctlSlaveForce = 0x706
+	ioctlRdwr       = 0x707
+	ioctlPEC        = 0x708
+	ioctlSMBus      = 0x720
 )
 
+// rdwrMaxMsgs is I2C_RDWR_IOCTL_MAX_MSGS.
+const rdwrMaxMsgs = 42
+
 // flags
 const (
 	flagTEN        = 0x0010 // this is a ten bit chip address
@@ -311,6 +434,14 @@ type rdwrIoctlData struct {
 	nmsgs uint32
 }
 
+// smbusIoctlData is struct i2c_smbus_ioctl_data.
+type smbusIoctlData struct {
+	readWrite uint8 // 1 for read
+	command   uint8
+	size      uint32  // smbus.Protocol
+	data      uintptr // Pointer to smbus.Data
+}
+
 type i2cMsg struct {
 	addr   uint16 // Address to communicate with
 	flags  uint16 // 1 for read, see i2c.h for more details
@@ -392,3 +523,6 @@ var drvI2C driverI2C
 
 var _ i2c.Bus = &I2C{}
 var _ i2c.BusCloser = &I2C{}
+var _ i2c.TxContexter = &I2C{}
+var _ i2c.Messenger = &I2C{}
+var _ smbus.Xferer = &I2C{}
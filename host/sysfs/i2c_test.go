@@ -5,9 +5,13 @@
 package sysfs
 
 import (
+	"context"
+	"syscall"
 	"testing"
 
+	"github.com/meandrewdev/periph/conn/i2c"
 	"github.com/meandrewdev/periph/conn/i2c/i2creg"
+	"github.com/meandrewdev/periph/conn/i2c/smbus"
 	"github.com/meandrewdev/periph/conn/physic"
 )
 
@@ -51,6 +55,98 @@ func TestI2C_faked(t *testing.T) {
 	}
 }
 
+func TestI2C_TxContext(t *testing.T) {
+	bus := I2C{f: &ioctlClose{}, busNumber: 24}
+	if err := bus.TxContext(context.Background(), 1, []byte{0}, nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := bus.TxContext(ctx, 1, []byte{0}, nil); err != context.Canceled {
+		t.Fatal(err)
+	}
+}
+
+func TestI2C_SMBusXfer(t *testing.T) {
+	f := &fakeSMBus{}
+	bus := I2C{f: f, busNumber: 24, fn: funcSMBusReadWordData | funcSMBusPEC}
+	if fn := bus.Functionality(); fn != smbus.FuncReadWordData|smbus.FuncPEC {
+		t.Fatal(fn)
+	}
+	d := smbus.Dev{Bus: &bus, Addr: 0x40, PEC: true}
+	v, err := d.ReadWordData(0x12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 0x3412 {
+		t.Fatalf("0x%x", v)
+	}
+	if f.addr != 0x40 || !f.pec || f.last.readWrite != 1 || f.last.command != 0x12 || f.last.size != uint32(smbus.WordData) {
+		t.Fatalf("%#v", f)
+	}
+	if bus.SMBusXfer(0x80, true, 0, smbus.Quick, false, &smbus.Data{}) == nil {
+		t.Fatal("10 bits address")
+	}
+	f.err = syscall.EIO
+	if _, err := d.ReadWordData(0x12); err == nil {
+		t.Fatal("ioctl failed")
+	}
+}
+
+func TestI2C_Transfer(t *testing.T) {
+	f := &fakeRDWR{}
+	bus := I2C{f: f, busNumber: 24, fn: func10BitAddr}
+	if err := bus.Transfer(nil); err != nil {
+		t.Fatal(err)
+	}
+	r := make([]byte, 2)
+	msgs := []i2c.Msg{
+		{Addr: 0x10, Buf: []byte{1}},
+		{Addr: 0x3FF, Flags: i2c.TenBit | i2c.Read, Buf: r},
+	}
+	if err := bus.Transfer(msgs); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.msgs) != 2 || f.msgs[0].addr != 0x10 || f.msgs[0].length != 1 || f.msgs[1].addr != 0x3FF || f.msgs[1].flags != flagTEN|flagRD || f.msgs[1].length != 2 {
+		t.Fatalf("%#v", f.msgs)
+	}
+	if r[0] != 0xAA || r[1] != 0xAA {
+		t.Fatal(r)
+	}
+
+	data := []struct {
+		fn   functionality
+		msgs []i2c.Msg
+	}{
+		{0, []i2c.Msg{{Addr: 0x80}}},
+		{0, []i2c.Msg{{Addr: 0x10, Flags: i2c.TenBit}}},
+		{func10BitAddr, []i2c.Msg{{Addr: 0x400, Flags: i2c.TenBit}}},
+		{0, []i2c.Msg{{Addr: 0x10, Flags: i2c.NoStart}}},
+		{funcNOSTART, []i2c.Msg{{Addr: 0x10, Flags: i2c.IgnoreNAK}}},
+		{0, []i2c.Msg{{Addr: 0x10, Flags: i2c.Read | i2c.RecvLen, Buf: make([]byte, 2)}}},
+		{0, make([]i2c.Msg, rdwrMaxMsgs+1)},
+	}
+	for i, line := range data {
+		bus.fn = line.fn
+		if bus.Transfer(line.msgs) == nil {
+			t.Fatal(i)
+		}
+	}
+
+	bus.fn = 0
+	r = make([]byte, 33)
+	if err := bus.Transfer([]i2c.Msg{{Addr: 0x10, Flags: i2c.Read | i2c.RecvLen, Buf: r}}); err != nil {
+		t.Fatal(err)
+	}
+	if f.recvLen != 1 {
+		t.Fatal(f.recvLen)
+	}
+	f.err = syscall.EIO
+	if bus.Transfer(msgs) == nil {
+		t.Fatal("ioctl failed")
+	}
+}
+
 func TestI2C_functionality(t *testing.T) {
 	expected := "I2C|10BIT_ADDR|PROTOCOL_MANGLING|SMBUS_PEC|NOSTART|SMBUS_BLOCK_PROC_CALL|SMBUS_QUICK|SMBUS_READ_BYTE|SMBUS_WRITE_BYTE|SMBUS_READ_BYTE_DATA|SMBUS_WRITE_BYTE_DATA|SMBUS_READ_WORD_DATA|SMBUS_WRITE_WORD_DATA|SMBUS_PROC_CALL|SMBUS_READ_BLOCK_DATA|SMBUS_WRITE_BLOCK_DATA|SMBUS_READ_I2C_BLOCK|SMBUS_WRITE_I2C_BLOCK"
 	if s := functionality(0xFFFFFFFF).String(); s != expected {
@@ -112,3 +208,75 @@ func BenchmarkI2C(b *testing.B) {
 		}
 	}
 }
+
+//
+
+// fakeSMBus emulates the I2C_SMBUS ioctl of i2c-dev.
+type fakeSMBus struct {
+	addr uint16
+	pec  bool
+	last smbusIoctlData
+	err  error
+}
+
+func (f *fakeSMBus) Ioctl(op uint, data uintptr) error {
+	if f.err != nil {
+		return f.err
+	}
+	switch op {
+	case ioctlSlaveForce:
+		f.addr = uint16(data)
+	case ioctlPEC:
+		f.pec = data != 0
+	case ioctlSMBus:
+		f.last = *(*smbusIoctlData)(ioctlArg(data))
+		d := (*smbus.Data)(ioctlArg(f.last.data))
+		d[0] = 0x12
+		d[1] = 0x34
+	default:
+		return syscall.ENOTTY
+	}
+	return nil
+}
+
+func (f *fakeSMBus) Close() error {
+	return nil
+}
+
+// fakeRDWR emulates the I2C_RDWR ioctl of i2c-dev; read messages are filled
+// with 0xAA.
+type fakeRDWR struct {
+	msgs    []i2cMsg
+	recvLen byte
+	err     error
+}
+
+func (f *fakeRDWR) Ioctl(op uint, data uintptr) error {
+	if f.err != nil {
+		return f.err
+	}
+	if op != ioctlRdwr {
+		return syscall.ENOTTY
+	}
+	p := (*rdwrIoctlData)(ioctlArg(data))
+	f.msgs = make([]i2cMsg, p.nmsgs)
+	copy(f.msgs, (*[rdwrMaxMsgs]i2cMsg)(ioctlArg(p.msgs))[:p.nmsgs])
+	for _, m := range f.msgs {
+		if m.flags&flagRD == 0 {
+			continue
+		}
+		b := (*[1 << 16]byte)(ioctlArg(m.buf))[:m.length]
+		if m.flags&flagRecvLen != 0 {
+			f.recvLen = b[0]
+			continue
+		}
+		for i := range b {
+			b[i] = 0xAA
+		}
+	}
+	return nil
+}
+
+func (f *fakeRDWR) Close() error {
+	return nil
+}
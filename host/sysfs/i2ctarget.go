@@ -0,0 +1,205 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sysfs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/meandrewdev/periph/conn/i2c"
+)
+
+// NewI2CTarget returns an i2c.Target on the I²C bus, backed by the Linux
+// i2c-slave-eeprom driver.
+//
+// The bus controller driver must support target mode and the kernel must be
+// built with CONFIG_I2C_SLAVE_EEPROM. See
+// https://www.kernel.org/doc/Documentation/i2c/slave-interface for details.
+func NewI2CTarget(busNumber int) (*I2CTarget, error) {
+	if !isLinux {
+		return nil, errors.New("sysfs-i2c: is not supported on this platform")
+	}
+	if busNumber < 0 {
+		return nil, fmt.Errorf("sysfs-i2c: invalid bus #%d", busNumber)
+	}
+	return &I2CTarget{busNumber: busNumber}, nil
+}
+
+// I2CTarget is an I²C target using the Linux i2c-slave-eeprom driver.
+//
+// The kernel answers the controller itself from a 256 bytes memory, the
+// pointer being set by the first byte written. The handler is mapped on it
+// this way:
+//
+// - On registration, the memory is filled with Read().
+//
+// - The memory is polled for changes; the changed bytes are reported to
+// Write() prefixed with their offset, then the memory is filled with Read()
+// starting at this offset.
+//
+// As such, writes that only set the pointer are not reported and reads are
+// served ahead of time. Only one address can be registered at a time.
+type I2CTarget struct {
+	busNumber int
+
+	mu   sync.Mutex
+	addr uint16
+	h    i2c.TargetHandler
+	f    fileIO
+	mem  [i2cTargetSize]byte
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (i *I2CTarget) String() string {
+	return fmt.Sprintf("I2C%d-target", i.busNumber)
+}
+
+// Register implements i2c.Target.
+func (i *I2CTarget) Register(addr uint16, h i2c.TargetHandler) error {
+	if addr >= 0x80 {
+		return errors.New("sysfs-i2c: invalid address")
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.h != nil {
+		return fmt.Errorf("sysfs-i2c: address %d is already registered; only one address is supported", i.addr)
+	}
+	// 0x1000 is I2C_SLAVE_ADDR_OFFSET.
+	if err := i.writeSysfs("new_device", fmt.Sprintf("slave-24c02 0x%x", 0x1000|addr)); err != nil {
+		return err
+	}
+	f, err := fileIOOpen(fmt.Sprintf("/sys/bus/i2c/devices/%d-%04x/slave-eeprom", i.busNumber, 0x1000|addr), os.O_RDWR)
+	if err != nil {
+		_ = i.writeSysfs("delete_device", fmt.Sprintf("0x%x", 0x1000|addr))
+		return fmt.Errorf("sysfs-i2c: %v", err)
+	}
+	i.addr = addr
+	i.h = h
+	i.f = f
+	if err := i.fill(0); err != nil {
+		_ = i.unregister()
+		return err
+	}
+	i.stop = make(chan struct{})
+	i.done = make(chan struct{})
+	go i.loop(i.stop, i.done)
+	return nil
+}
+
+// Unregister implements i2c.Target.
+func (i *I2CTarget) Unregister(addr uint16) error {
+	i.mu.Lock()
+	if i.h == nil || i.addr != addr {
+		i.mu.Unlock()
+		return fmt.Errorf("sysfs-i2c: address %d is not registered", addr)
+	}
+	stop, done := i.stop, i.done
+	i.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.unregister()
+}
+
+// Private details.
+
+// i2cTargetSize is the memory size of the slave-24c02 device.
+const i2cTargetSize = 256
+
+// i2cTargetPoll is the interval at which the memory is polled for writes.
+var i2cTargetPoll = 10 * time.Millisecond
+
+func (i *I2CTarget) loop(stop, done chan struct{}) {
+	defer close(done)
+	t := time.NewTicker(i2cTargetPoll)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			i.mu.Lock()
+			// There's no one to report the error to; try again on the next tick.
+			_ = i.poll()
+			i.mu.Unlock()
+		}
+	}
+}
+
+// poll reports the bytes changed by the controller.
+func (i *I2CTarget) poll() error {
+	var cur [i2cTargetSize]byte
+	if _, err := seekRead(i.f, cur[:]); err != nil {
+		return fmt.Errorf("sysfs-i2c: %v", err)
+	}
+	if bytes.Equal(cur[:], i.mem[:]) {
+		return nil
+	}
+	start := 0
+	for cur[start] == i.mem[start] {
+		start++
+	}
+	end := i2cTargetSize
+	for cur[end-1] == i.mem[end-1] {
+		end--
+	}
+	i.mem = cur
+	i.h.Write(append([]byte{byte(start)}, cur[start:end]...))
+	return i.fill(start)
+}
+
+// fill sets the memory starting at offset with the handler's data.
+func (i *I2CTarget) fill(offset int) error {
+	n := copy(i.mem[offset:], i.h.Read())
+	if n == 0 {
+		return nil
+	}
+	if _, err := i.f.Seek(int64(offset), 0); err != nil {
+		return fmt.Errorf("sysfs-i2c: %v", err)
+	}
+	if _, err := i.f.Write(i.mem[offset : offset+n]); err != nil {
+		return fmt.Errorf("sysfs-i2c: %v", err)
+	}
+	return nil
+}
+
+func (i *I2CTarget) unregister() error {
+	var err error
+	if i.f != nil {
+		if err2 := i.f.Close(); err2 != nil {
+			err = fmt.Errorf("sysfs-i2c: %v", err2)
+		}
+		i.f = nil
+	}
+	if err2 := i.writeSysfs("delete_device", fmt.Sprintf("0x%x", 0x1000|i.addr)); err == nil {
+		err = err2
+	}
+	i.h = nil
+	i.stop = nil
+	i.done = nil
+	return err
+}
+
+func (i *I2CTarget) writeSysfs(name, value string) error {
+	f, err := fileIOOpen(fmt.Sprintf("/sys/bus/i2c/devices/i2c-%d/%s", i.busNumber, name), os.O_WRONLY)
+	if err != nil {
+		return fmt.Errorf("sysfs-i2c: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte(value)); err != nil {
+		return fmt.Errorf("sysfs-i2c: %v", err)
+	}
+	return nil
+}
+
+var _ i2c.Target = &I2CTarget{}
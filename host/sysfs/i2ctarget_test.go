@@ -0,0 +1,174 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sysfs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewI2CTarget(t *testing.T) {
+	if _, err := NewI2CTarget(-1); err == nil {
+		t.Fatal("invalid bus")
+	}
+	i, err := NewI2CTarget(1)
+	if err != nil {
+		if isLinux {
+			t.Fatal(err)
+		}
+		return
+	}
+	if s := i.String(); s != "I2C1-target" {
+		t.Fatal(s)
+	}
+}
+
+func TestI2CTarget(t *testing.T) {
+	defer reset()
+	root := fakeSysfsI2CTarget(t)
+	i := &I2CTarget{busNumber: 1}
+	h := &fakeTargetHandler{r: []byte{1, 2, 3}}
+	if err := i.Register(0x80, h); err == nil {
+		t.Fatal("invalid address")
+	}
+	if err := i.Register(0x42, h); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Register(0x43, h); err == nil {
+		t.Fatal("only one address")
+	}
+	if b := readFile(t, root, "i2c-1/new_device"); b != "slave-24c02 0x1042" {
+		t.Fatal(b)
+	}
+	mem := readFile(t, root, "1-1042/slave-eeprom")
+	if mem[:4] != "\x01\x02\x03\x00" {
+		t.Fatalf("%q", mem[:4])
+	}
+
+	// The controller writes 2 bytes at offset 0x10.
+	i.mu.Lock()
+	if _, err := i.f.Seek(0x10, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.f.Write([]byte{0xAA, 0xBB}); err != nil {
+		t.Fatal(err)
+	}
+	h.r = []byte{4, 5, 6}
+	if err := i.poll(); err != nil {
+		t.Fatal(err)
+	}
+	i.mu.Unlock()
+	if len(h.w) != 1 || !bytes.Equal(h.w[0], []byte{0x10, 0xAA, 0xBB}) {
+		t.Fatal(h.w)
+	}
+	mem = readFile(t, root, "1-1042/slave-eeprom")
+	if mem[0x10:0x13] != "\x04\x05\x06" {
+		t.Fatalf("%q", mem[0x10:0x13])
+	}
+	i.mu.Lock()
+	if err := i.poll(); err != nil {
+		t.Fatal(err)
+	}
+	i.mu.Unlock()
+	if len(h.w) != 1 {
+		t.Fatal("unexpected write")
+	}
+
+	if err := i.Unregister(0x43); err == nil {
+		t.Fatal("not registered")
+	}
+	if err := i.Unregister(0x42); err != nil {
+		t.Fatal(err)
+	}
+	if b := readFile(t, root, "i2c-1/delete_device"); b != "0x1042" {
+		t.Fatal(b)
+	}
+}
+
+func TestI2CTarget_loop(t *testing.T) {
+	defer reset()
+	defer func() {
+		i2cTargetPoll = 10 * time.Millisecond
+	}()
+	i2cTargetPoll = time.Millisecond
+	fakeSysfsI2CTarget(t)
+	i := &I2CTarget{busNumber: 1}
+	h := &fakeTargetHandler{}
+	if err := i.Register(0x42, h); err != nil {
+		t.Fatal(err)
+	}
+	i.mu.Lock()
+	if _, err := i.f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.f.Write([]byte{0xAA}); err != nil {
+		t.Fatal(err)
+	}
+	i.mu.Unlock()
+	for {
+		i.mu.Lock()
+		n := len(h.w)
+		i.mu.Unlock()
+		if n != 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := i.Unregister(0x42); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//
+
+// fakeSysfsI2CTarget redirects the sysfs files to a temporary directory.
+func fakeSysfsI2CTarget(t *testing.T) string {
+	root := t.TempDir()
+	for _, d := range []string{"i2c-1", "1-1042"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "1-1042", "slave-eeprom"), make([]byte, i2cTargetSize), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fileIOOpen = func(path string, flag int) (fileIO, error) {
+		rel, err := filepath.Rel("/sys/bus/i2c/devices", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.OpenFile(filepath.Join(root, rel), flag|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, err
+		}
+		return &fakeFile{f}, nil
+	}
+	return root
+}
+
+func readFile(t *testing.T, root, name string) string {
+	b, err := ioutil.ReadFile(filepath.Join(root, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+type fakeTargetHandler struct {
+	w [][]byte
+	r []byte
+}
+
+func (f *fakeTargetHandler) Write(w []byte) {
+	f.w = append(f.w, w)
+}
+
+func (f *fakeTargetHandler) Read() []byte {
+	return f.r
+}
@@ -0,0 +1,339 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sysfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/meandrewdev/periph/conn/i2s"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/host/fs"
+)
+
+// NewI2S opens an ALSA PCM device via its devfs interface as described at
+// https://www.kernel.org/doc/html/latest/sound/designs/index.html.
+//
+// card and device are the numbers of the ALSA sound card and PCM device, as
+// listed in /proc/asound/pcm. capture selects the capture device, e.g.
+// /dev/snd/pcmC0D0c, instead of the playback one, e.g. /dev/snd/pcmC0D0p. The
+// resulting connection only supports Read() when capture is true and only
+// supports Write() otherwise.
+//
+// The framing of the I²S link is determined by the sound card's device tree
+// description and cannot be changed at runtime.
+//
+// The resulting object is safe for concurrent use.
+//
+// Do not use sysfs.NewI2S() directly as the package sysfs is providing a
+// https://github.com/meandrewdev/periph/conn/i2s Linux-specific implementation.
+//
+// periph.io works on many OSes!
+func NewI2S(card, device int, capture bool) (*I2S, error) {
+	if isLinux {
+		return newI2S(card, device, capture)
+	}
+	return nil, errors.New("sysfs-i2s: is not supported on this platform")
+}
+
+// I2S is an open ALSA PCM device via devfs.
+//
+// The samples are transferred in interleaved mode; each frame contains one
+// sample per channel.
+type I2S struct {
+	conn i2sConn
+}
+
+// Close implements i2s.PortCloser.
+func (i *I2S) Close() error {
+	i.conn.mu.Lock()
+	defer i.conn.mu.Unlock()
+	if err := i.conn.f.Close(); err != nil {
+		return fmt.Errorf("sysfs-i2s: %v", err)
+	}
+	return nil
+}
+
+// String implements i2s.Port.
+func (i *I2S) String() string {
+	return i.conn.String()
+}
+
+// Connect implements i2s.Port.
+//
+// The sound card must support the requested rate and channel count; ALSA's
+// kernel interface doesn't resample.
+func (i *I2S) Connect(c *i2s.Config) (i2s.Conn, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	i.conn.mu.Lock()
+	defer i.conn.mu.Unlock()
+	if i.conn.connected {
+		return nil, errors.New("sysfs-i2s: Connect() can only be called exactly once")
+	}
+	var p sndPCMHwParams
+	p.init()
+	p.setMask(sndPCMHwParamAccess, sndPCMAccessRWInterleaved)
+	p.setMask(sndPCMHwParamFormat, sndPCMFormat(c.Bits))
+	p.setMask(sndPCMHwParamSubformat, sndPCMSubformatStd)
+	p.setInterval(sndPCMHwParamChannels, uint32(c.Channels))
+	p.setInterval(sndPCMHwParamRate, uint32(c.Rate/physic.Hertz))
+	if err := i.conn.f.Ioctl(sndPCMIoctlHwParams, uintptr(unsafe.Pointer(&p))); err != nil {
+		return nil, fmt.Errorf("sysfs-i2s: configuring %s failed: %v", c, err)
+	}
+	if err := i.conn.f.Ioctl(sndPCMIoctlPrepare, 0); err != nil {
+		return nil, fmt.Errorf("sysfs-i2s: %v", err)
+	}
+	i.conn.cfg = *c
+	i.conn.cfg.Rate = physic.Frequency(p.interval(sndPCMHwParamRate).min) * physic.Hertz
+	i.conn.cfg.Channels = int(p.interval(sndPCMHwParamChannels).min)
+	i.conn.frameSize = i.conn.cfg.FrameSize()
+	i.conn.connected = true
+	return &i.conn, nil
+}
+
+// Private details.
+
+func newI2S(card, device int, capture bool) (*I2S, error) {
+	if card < 0 || card > 255 {
+		return nil, fmt.Errorf("sysfs-i2s: invalid card %d", card)
+	}
+	if device < 0 || device > 255 {
+		return nil, fmt.Errorf("sysfs-i2s: invalid device %d", device)
+	}
+	d := 'p'
+	if capture {
+		d = 'c'
+	}
+	name := fmt.Sprintf("pcmC%dD%d%c", card, device, d)
+	f, err := ioctlOpen("/dev/snd/"+name, os.O_RDWR)
+	if err != nil {
+		return nil, fmt.Errorf("sysfs-i2s: %v", err)
+	}
+	return &I2S{i2sConn{name: name, f: f, capture: capture}}, nil
+}
+
+// i2sConn implements i2s.Conn.
+type i2sConn struct {
+	// Immutable
+	name    string
+	f       ioctlCloser
+	capture bool
+
+	mu        sync.Mutex
+	connected bool
+	cfg       i2s.Config
+	frameSize int
+
+	// xmu serializes transfers. It is separate from mu so Halt() can drop the
+	// stream while a transfer is blocked in the kernel.
+	xmu sync.Mutex
+	// Heap optimization: reduce the amount of memory allocations during
+	// transfers.
+	x sndXferi
+}
+
+func (i *i2sConn) String() string {
+	return i.name
+}
+
+// Halt implements conn.Resource.
+//
+// It drops the buffered frames and prepares the stream so it restarts on the
+// next Read() or Write(). A Read() or Write() blocked concurrently returns an
+// error.
+func (i *i2sConn) Halt() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err := i.f.Ioctl(sndPCMIoctlDrop, 0); err != nil {
+		return fmt.Errorf("sysfs-i2s: %v", err)
+	}
+	if err := i.f.Ioctl(sndPCMIoctlPrepare, 0); err != nil {
+		return fmt.Errorf("sysfs-i2s: %v", err)
+	}
+	return nil
+}
+
+// Config implements i2s.Conn.
+func (i *i2sConn) Config() i2s.Config {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.cfg
+}
+
+// Read implements io.Reader.
+func (i *i2sConn) Read(b []byte) (int, error) {
+	if !i.capture {
+		return 0, i2s.ErrNotSupported
+	}
+	return i.xfer(sndPCMIoctlReadiFrames, b)
+}
+
+// Write implements io.Writer.
+func (i *i2sConn) Write(b []byte) (int, error) {
+	if i.capture {
+		return 0, i2s.ErrNotSupported
+	}
+	return i.xfer(sndPCMIoctlWriteiFrames, b)
+}
+
+// xfer transfers all the frames in b.
+//
+// On overrun or underrun, the stream is prepared again and the transfer is
+// resumed.
+func (i *i2sConn) xfer(op uint, b []byte) (int, error) {
+	i.mu.Lock()
+	frameSize := i.frameSize
+	i.mu.Unlock()
+	if len(b)%frameSize != 0 {
+		return 0, fmt.Errorf("sysfs-i2s: buffer length %d is not a multiple of the frame size %d", len(b), frameSize)
+	}
+	// Do not hold mu across the ioctl, which blocks until the frames are
+	// transferred.
+	i.xmu.Lock()
+	defer i.xmu.Unlock()
+	n := 0
+	for n != len(b) {
+		i.x.result = 0
+		i.x.buf = uintptr(unsafe.Pointer(&b[n]))
+		i.x.frames = uint((len(b) - n) / frameSize)
+		if err := i.f.Ioctl(op, uintptr(unsafe.Pointer(&i.x))); err != nil {
+			if err != syscall.EPIPE {
+				return n, fmt.Errorf("sysfs-i2s: %v", err)
+			}
+			if err := i.f.Ioctl(sndPCMIoctlPrepare, 0); err != nil {
+				return n, fmt.Errorf("sysfs-i2s: %v", err)
+			}
+			continue
+		}
+		n += int(i.x.result) * frameSize
+	}
+	return n, nil
+}
+
+// ALSA PCM ioctls, as defined in include/uapi/sound/asound.h.
+
+const sndPCMIoctlMagic = 'A'
+
+var (
+	sndPCMIoctlHwParams     = fs.IOWR(sndPCMIoctlMagic, 0x11, uint(unsafe.Sizeof(sndPCMHwParams{})))
+	sndPCMIoctlPrepare      = fs.IO(sndPCMIoctlMagic, 0x40)
+	sndPCMIoctlDrop         = fs.IO(sndPCMIoctlMagic, 0x43)
+	sndPCMIoctlWriteiFrames = fs.IOW(sndPCMIoctlMagic, 0x50, uint(unsafe.Sizeof(sndXferi{})))
+	sndPCMIoctlReadiFrames  = fs.IOR(sndPCMIoctlMagic, 0x51, uint(unsafe.Sizeof(sndXferi{})))
+)
+
+// Hardware parameters; the first ones are masks, the others are intervals.
+const (
+	sndPCMHwParamAccess        = 0
+	sndPCMHwParamFormat        = 1
+	sndPCMHwParamSubformat     = 2
+	sndPCMHwParamFirstInterval = 8
+	sndPCMHwParamChannels      = 10
+	sndPCMHwParamRate          = 11
+)
+
+const (
+	sndPCMAccessRWInterleaved = 3
+	sndPCMSubformatStd        = 0
+
+	sndPCMFormatS8    = 0
+	sndPCMFormatS16LE = 2
+	sndPCMFormatS24LE = 6 // 24 bits in the low bits of 4 bytes.
+	sndPCMFormatS32LE = 10
+)
+
+// sndPCMFormat returns the ALSA sample format matching the storage described
+// in i2s.Config.SampleSize().
+func sndPCMFormat(bits int) uint32 {
+	switch {
+	case bits <= 8:
+		return sndPCMFormatS8
+	case bits <= 16:
+		return sndPCMFormatS16LE
+	case bits <= 24:
+		return sndPCMFormatS24LE
+	default:
+		return sndPCMFormatS32LE
+	}
+}
+
+// sndIntervalInteger is the snd_interval flag to only accept integer values.
+const sndIntervalInteger = 1 << 2
+
+// sndMask is struct snd_mask.
+type sndMask struct {
+	bits [8]uint32
+}
+
+// sndInterval is struct snd_interval.
+type sndInterval struct {
+	min   uint32
+	max   uint32
+	flags uint32
+}
+
+// sndPCMHwParams is struct snd_pcm_hw_params.
+type sndPCMHwParams struct {
+	flags     uint32
+	masks     [3]sndMask
+	mres      [5]sndMask
+	intervals [12]sndInterval
+	ires      [9]sndInterval
+	rmask     uint32
+	cmask     uint32
+	info      uint32
+	msbits    uint32
+	rateNum   uint32
+	rateDen   uint32
+	fifoSize  uint // snd_pcm_uframes_t
+	reserved  [64]byte
+}
+
+// init sets all the parameters to accept any value, so the kernel refines
+// them.
+func (p *sndPCMHwParams) init() {
+	for i := range p.masks {
+		for j := range p.masks[i].bits {
+			p.masks[i].bits[j] = ^uint32(0)
+		}
+	}
+	for i := range p.intervals {
+		p.intervals[i] = sndInterval{max: ^uint32(0)}
+	}
+	p.rmask = ^uint32(0)
+	p.info = ^uint32(0)
+}
+
+// setMask restricts the mask parameter to a single value.
+func (p *sndPCMHwParams) setMask(param int, v uint32) {
+	m := &p.masks[param]
+	m.bits = [8]uint32{}
+	m.bits[v>>5] = 1 << (v & 31)
+}
+
+func (p *sndPCMHwParams) interval(param int) *sndInterval {
+	return &p.intervals[param-sndPCMHwParamFirstInterval]
+}
+
+// setInterval restricts the interval parameter to a single value.
+func (p *sndPCMHwParams) setInterval(param int, v uint32) {
+	*p.interval(param) = sndInterval{min: v, max: v, flags: sndIntervalInteger}
+}
+
+// sndXferi is struct snd_xferi.
+type sndXferi struct {
+	result int // snd_pcm_sframes_t
+	buf    uintptr
+	frames uint // snd_pcm_uframes_t
+}
+
+var _ i2s.PortCloser = &I2S{}
+var _ i2s.Conn = &i2sConn{}
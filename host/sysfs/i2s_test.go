@@ -0,0 +1,291 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sysfs
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/meandrewdev/periph/conn/i2s"
+	"github.com/meandrewdev/periph/conn/physic"
+)
+
+func TestNewI2S(t *testing.T) {
+	if i, err := NewI2S(-1, 0, false); i != nil || err == nil {
+		t.Fatal("invalid card")
+	}
+	if i, err := NewI2S(0, 256, false); i != nil || err == nil {
+		t.Fatal("invalid device")
+	}
+	if !isLinux {
+		t.Skip("linux only")
+	}
+	defer reset()
+	var path string
+	ioctlOpen = func(p string, flag int) (ioctlCloser, error) {
+		path = p
+		return &fakeALSA{}, nil
+	}
+	i, err := NewI2S(1, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/dev/snd/pcmC1D2c" {
+		t.Fatal(path)
+	}
+	if s := i.String(); s != "pcmC1D2c" {
+		t.Fatal(s)
+	}
+	ioctlOpen = func(p string, flag int) (ioctlCloser, error) {
+		return nil, os.ErrNotExist
+	}
+	if _, err := NewI2S(0, 0, false); err == nil {
+		t.Fatal("open failed")
+	}
+}
+
+func TestI2S_Connect(t *testing.T) {
+	f := &fakeALSA{rate: 44100}
+	i := I2S{i2sConn{name: "fake", f: f}}
+	if _, err := i.Connect(&i2s.Config{}); err == nil {
+		t.Fatal("invalid config")
+	}
+	if _, err := i.Connect(&i2s.Config{Rate: 48 * physic.KiloHertz, Bits: 16, Channels: 2}); err == nil {
+		t.Fatal("unsupported rate")
+	}
+	cfg := i2s.Config{Rate: 44100 * physic.Hertz, Bits: 24, Channels: 2}
+	c, err := i.Connect(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Connect(&cfg); err == nil {
+		t.Fatal("second Connect() must fail")
+	}
+	if f.format != sndPCMFormatS24LE || f.access != sndPCMAccessRWInterleaved || f.prepared != 1 {
+		t.Fatal(f.format, f.access, f.prepared)
+	}
+	if got := c.Config(); got != cfg {
+		t.Fatal(got)
+	}
+	if s := c.String(); s != "fake" {
+		t.Fatal(s)
+	}
+	if err := i.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestI2S_Write(t *testing.T) {
+	f := &fakeALSA{rate: 48000, maxFrames: 2, xrun: true}
+	i := I2S{i2sConn{name: "fake", f: f}}
+	c, err := i.Connect(&i2s.Config{Rate: 48 * physic.KiloHertz, Bits: 16, Channels: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Read(make([]byte, 4)); err != i2s.ErrNotSupported {
+		t.Fatal(err)
+	}
+	if _, err := c.Write(make([]byte, 3)); err == nil {
+		t.Fatal("partial frame")
+	}
+	w := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	if n, err := c.Write(w); n != len(w) || err != nil {
+		t.Fatal(n, err)
+	}
+	if !bytes.Equal(f.buf.Bytes(), w) {
+		t.Fatal(f.buf.Bytes())
+	}
+	// Once for Connect(), once to recover from the underrun.
+	if f.prepared != 2 {
+		t.Fatal(f.prepared)
+	}
+	if err := c.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if f.dropped != 1 || f.prepared != 3 {
+		t.Fatal(f.dropped, f.prepared)
+	}
+	f.err = errors.New("foo")
+	if _, err := c.Write(w); err == nil || err.Error() != "sysfs-i2s: foo" {
+		t.Fatal(err)
+	}
+	if err := c.Halt(); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestI2S_Read(t *testing.T) {
+	f := &fakeALSA{rate: 8000}
+	f.buf.Write([]byte{1, 2, 3, 4})
+	i := I2S{i2sConn{name: "fake", f: f, capture: true}}
+	c, err := i.Connect(&i2s.Config{Rate: 8 * physic.KiloHertz, Bits: 8, Channels: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Write(make([]byte, 4)); err != i2s.ErrNotSupported {
+		t.Fatal(err)
+	}
+	var b [4]byte
+	if n, err := c.Read(b[:]); n != 4 || err != nil || b != [4]byte{1, 2, 3, 4} {
+		t.Fatal(n, err, b)
+	}
+}
+
+func TestI2S_Halt_blocked(t *testing.T) {
+	f := &fakeALSA{rate: 8000, started: make(chan struct{}), blocked: make(chan struct{})}
+	i := I2S{i2sConn{name: "fake", f: f, capture: true}}
+	c, err := i.Connect(&i2s.Config{Rate: 8 * physic.KiloHertz, Bits: 8, Channels: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		_, err := c.Read(make([]byte, 4))
+		done <- err
+	}()
+	<-f.started
+	// Halt() must not wait for the blocked Read().
+	if err := c.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err == nil {
+		t.Fatal("expected error")
+	}
+	if f.dropped != 1 {
+		t.Fatal(f.dropped)
+	}
+}
+
+func TestSndPCMHwParams(t *testing.T) {
+	if s := unsafe.Sizeof(sndPCMHwParams{}); unsafe.Sizeof(uint(0)) == 8 && s != 608 {
+		t.Fatal(s)
+	}
+	var p sndPCMHwParams
+	p.init()
+	p.setMask(sndPCMHwParamFormat, 35)
+	if p.masks[1].bits != [8]uint32{0, 8} {
+		t.Fatal(p.masks[1].bits)
+	}
+	p.setInterval(sndPCMHwParamRate, 1000)
+	if p.intervals[3] != (sndInterval{1000, 1000, sndIntervalInteger}) {
+		t.Fatal(p.intervals[3])
+	}
+	data := []struct {
+		bits     int
+		expected uint32
+	}{
+		{8, sndPCMFormatS8},
+		{12, sndPCMFormatS16LE},
+		{16, sndPCMFormatS16LE},
+		{20, sndPCMFormatS24LE},
+		{32, sndPCMFormatS32LE},
+	}
+	for i, line := range data {
+		if f := sndPCMFormat(line.bits); f != line.expected {
+			t.Fatalf("#%d: %d != %d", i, f, line.expected)
+		}
+	}
+}
+
+//
+
+// fakeALSA emulates the kernel side of an ALSA PCM device.
+type fakeALSA struct {
+	rate      uint32 // Only supported rate
+	maxFrames uint   // Maximum number of frames transferred per ioctl, if not 0
+	xrun      bool   // Return EPIPE on the first transfer
+	err       error
+	started   chan struct{} // If set, transfers block until the stream is dropped
+	blocked   chan struct{}
+
+	access   uint32
+	format   uint32
+	channels uint32
+	prepared int
+	dropped  int
+	buf      bytes.Buffer
+}
+
+func (f *fakeALSA) Close() error {
+	return nil
+}
+
+func (f *fakeALSA) Ioctl(op uint, data uintptr) error {
+	if f.err != nil {
+		return f.err
+	}
+	switch op {
+	case sndPCMIoctlHwParams:
+		p := (*sndPCMHwParams)(ioctlArg(data))
+		f.access = maskValue(&p.masks[sndPCMHwParamAccess])
+		f.format = maskValue(&p.masks[sndPCMHwParamFormat])
+		r := p.interval(sndPCMHwParamRate)
+		if r.min > f.rate || r.max < f.rate {
+			return syscall.EINVAL
+		}
+		f.channels = p.interval(sndPCMHwParamChannels).min
+	case sndPCMIoctlPrepare:
+		f.prepared++
+	case sndPCMIoctlDrop:
+		f.dropped++
+		if f.blocked != nil {
+			close(f.blocked)
+			f.blocked = nil
+		}
+	case sndPCMIoctlWriteiFrames, sndPCMIoctlReadiFrames:
+		if f.started != nil {
+			blocked := f.blocked
+			f.started <- struct{}{}
+			<-blocked
+			return syscall.EBADFD
+		}
+		if f.xrun {
+			f.xrun = false
+			return syscall.EPIPE
+		}
+		x := (*sndXferi)(ioctlArg(data))
+		frames := x.frames
+		if f.maxFrames != 0 && frames > f.maxFrames {
+			frames = f.maxFrames
+		}
+		size := f.frameSize()
+		b := (*[1 << 20]byte)(ioctlArg(x.buf))[:int(frames)*size]
+		if op == sndPCMIoctlWriteiFrames {
+			f.buf.Write(b)
+		} else {
+			f.buf.Read(b)
+		}
+		x.result = int(frames)
+	default:
+		return errors.New("unexpected ioctl")
+	}
+	return nil
+}
+
+func (f *fakeALSA) frameSize() int {
+	s := 4
+	switch f.format {
+	case sndPCMFormatS8:
+		s = 1
+	case sndPCMFormatS16LE:
+		s = 2
+	}
+	return s * int(f.channels)
+}
+
+func maskValue(m *sndMask) uint32 {
+	for i, b := range m.bits {
+		for j := uint32(0); j < 32; j++ {
+			if b&(1<<j) != 0 {
+				return uint32(i)*32 + j
+			}
+		}
+	}
+	return 0
+}
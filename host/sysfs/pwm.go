@@ -0,0 +1,441 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sysfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meandrewdev/periph"
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/pin"
+)
+
+// PWMs is all the PWM channels discovered on this host via sysfs, sorted by
+// chip and channel number.
+var PWMs []*PWM
+
+// PWMByName returns the PWM channel named name, e.g. "PWMCHIP0_1" for the
+// channel 1 of /sys/class/pwm/pwmchip0.
+func PWMByName(name string) (*PWM, error) {
+	for _, p := range PWMs {
+		if p.name == name {
+			return p, nil
+		}
+	}
+	return nil, errors.New("sysfs-pwm: invalid PWM name " + strconv.Quote(name))
+}
+
+// RegisterPWMPin records that the PWM channel p is routed to the GPIO pin
+// named pin, e.g. "GPIO18".
+//
+// It is meant to be called by board drivers, which know how the PWM
+// controllers are wired to the header pins. The pin's PWM() then uses the
+// kernel driver when direct register access is not available.
+func RegisterPWMPin(pin string, p *PWM) error {
+	if p == nil {
+		return errors.New("sysfs-pwm: can't register nil PWM for pin " + strconv.Quote(pin))
+	}
+	pwmMu.Lock()
+	defer pwmMu.Unlock()
+	if o, ok := pwmByPin[pin]; ok {
+		return errors.New("sysfs-pwm: pin " + strconv.Quote(pin) + " is already routed to " + o.name)
+	}
+	pwmByPin[pin] = p
+	return nil
+}
+
+// PWMByPin returns the PWM channel routed to the GPIO pin named pin, or nil
+// if none was registered with RegisterPWMPin().
+func PWMByPin(pin string) *PWM {
+	pwmMu.Lock()
+	defer pwmMu.Unlock()
+	return pwmByPin[pin]
+}
+
+// PWM represents one PWM channel exposed by a PWM controller via
+// /sys/class/pwm.
+//
+// The channel is exported on first use. The routing of the channel to a pin
+// is done by the device tree, usually with an overlay, e.g. "dtoverlay=pwm"
+// on a Raspberry Pi.
+//
+// Accessing the channel only requires write access to /sys/class/pwm, which
+// can be granted to a non-root user via udev rules.
+type PWM struct {
+	number int    // Channel number in the chip.
+	chip   int    // N in /sys/class/pwm/pwmchipN.
+	name   string //
+	root   string // /sys/class/pwm/pwmchipN/
+	device string // Name of the controller device, e.g. "fe20c000.pwm".
+
+	mu       sync.Mutex
+	err      error         // If open() failed.
+	fPeriod  fileIO        // handle to period; never closed
+	fDuty    fileIO        // handle to duty_cycle; never closed
+	fEnable  fileIO        // handle to enable; never closed
+	period   time.Duration // Current period.
+	freq     physic.Frequency
+	enabled  bool
+	inverted bool
+}
+
+// String implements conn.Resource.
+func (p *PWM) String() string {
+	return p.name
+}
+
+// Halt implements conn.Resource.
+//
+// It disables the output.
+func (p *PWM) Halt() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.open(); err != nil {
+		return p.wrap(err)
+	}
+	return p.disable()
+}
+
+// Name implements pin.Pin.
+func (p *PWM) Name() string {
+	return p.name
+}
+
+// Number implements pin.Pin.
+//
+// This is the channel number in the PWM controller.
+func (p *PWM) Number() int {
+	return p.number
+}
+
+// Function implements pin.Pin.
+func (p *PWM) Function() string {
+	return string(p.Func())
+}
+
+// Func implements pin.PinFunc.
+func (p *PWM) Func() pin.Func {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.enabled {
+		return gpio.PWM
+	}
+	return pin.FuncNone
+}
+
+// SupportedFuncs implements pin.PinFunc.
+func (p *PWM) SupportedFuncs() []pin.Func {
+	return []pin.Func{gpio.PWM}
+}
+
+// SetFunc implements pin.PinFunc.
+func (p *PWM) SetFunc(f pin.Func) error {
+	return p.wrap(errors.New("not supported"))
+}
+
+// Device returns the name of the PWM controller device, as found in
+// /sys/bus/platform/devices.
+func (p *PWM) Device() string {
+	return p.device
+}
+
+// Out implements gpio.PinOut.
+//
+// It outputs a constant level by setting a duty of 0% or 100% at the last
+// frequency used, or 1kHz.
+func (p *PWM) Out(l gpio.Level) error {
+	p.mu.Lock()
+	f := p.freq
+	p.mu.Unlock()
+	if f == 0 {
+		f = physic.KiloHertz
+	}
+	d := gpio.Duty(0)
+	if l {
+		d = gpio.DutyMax
+	}
+	return p.PWM(d, f)
+}
+
+// PWM implements gpio.PinOut.
+//
+// The resolution is 1ns, so the frequency must be at most 1GHz; in practice
+// it is limited by the controller's clock.
+func (p *PWM) PWM(duty gpio.Duty, freq physic.Frequency) error {
+	if duty < 0 || duty > gpio.DutyMax {
+		return p.wrap(fmt.Errorf("invalid duty %s", duty))
+	}
+	period := freq.Period()
+	if freq <= 0 || period <= 0 {
+		return p.wrap(fmt.Errorf("invalid frequency %s", freq))
+	}
+	d := time.Duration((int64(period)*int64(duty) + int64(gpio.DutyHalf)) / int64(gpio.DutyMax))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.open(); err != nil {
+		return p.wrap(err)
+	}
+	// The kernel rejects a duty cycle larger than the period, so the order of
+	// the writes depends on whether the period grows or shrinks.
+	if period < p.period {
+		if err := seekWrite(p.fDuty, []byte(strconv.FormatInt(int64(d), 10))); err != nil {
+			return p.wrap(err)
+		}
+		if err := seekWrite(p.fPeriod, []byte(strconv.FormatInt(int64(period), 10))); err != nil {
+			return p.wrap(err)
+		}
+	} else {
+		if err := seekWrite(p.fPeriod, []byte(strconv.FormatInt(int64(period), 10))); err != nil {
+			return p.wrap(err)
+		}
+		if err := seekWrite(p.fDuty, []byte(strconv.FormatInt(int64(d), 10))); err != nil {
+			return p.wrap(err)
+		}
+	}
+	p.period = period
+	p.freq = freq
+	if !p.enabled {
+		if err := seekWrite(p.fEnable, []byte("1")); err != nil {
+			return p.wrap(err)
+		}
+		p.enabled = true
+	}
+	return nil
+}
+
+// Inverted returns true if the output polarity is inverted.
+func (p *PWM) Inverted() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.inverted
+}
+
+// SetInverted changes the output polarity.
+//
+// When inverted, the duty specifies the time the output is low. Many
+// controllers only accept a polarity change while disabled, so the channel is
+// briefly disabled if needed.
+func (p *PWM) SetInverted(inverted bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.open(); err != nil {
+		return p.wrap(err)
+	}
+	if inverted == p.inverted {
+		return nil
+	}
+	enabled := p.enabled
+	if err := p.disable(); err != nil {
+		return err
+	}
+	v := []byte("normal")
+	if inverted {
+		v = []byte("inversed")
+	}
+	f, err := fileIOOpen(p.channelRoot()+"polarity", os.O_WRONLY)
+	if err != nil {
+		return p.wrap(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(v); err != nil {
+		return p.wrap(err)
+	}
+	p.inverted = inverted
+	if enabled {
+		if err := seekWrite(p.fEnable, []byte("1")); err != nil {
+			return p.wrap(err)
+		}
+		p.enabled = true
+	}
+	return nil
+}
+
+//
+
+func (p *PWM) channelRoot() string {
+	return p.root + "pwm" + strconv.Itoa(p.number) + "/"
+}
+
+// open exports the channel and opens the sysfs handles.
+//
+// lock must be held.
+func (p *PWM) open() error {
+	if p.fEnable != nil || p.err != nil {
+		return p.err
+	}
+	root := p.channelRoot()
+	// Try to open the channel if it was there. It's possible it had been
+	// exported already.
+	if p.fPeriod, p.err = fileIOOpen(root+"period", os.O_RDWR); p.err != nil {
+		if !os.IsNotExist(p.err) {
+			// It exists but not accessible, not worth doing the remainder.
+			p.err = fmt.Errorf("need more access, try as root or setup udev rules: %v", p.err)
+			return p.err
+		}
+		var f fileIO
+		if f, p.err = fileIOOpen(p.root+"export", os.O_WRONLY); p.err != nil {
+			if os.IsPermission(p.err) {
+				p.err = fmt.Errorf("need more access, try as root or setup udev rules: %v", p.err)
+			}
+			return p.err
+		}
+		_, p.err = f.Write([]byte(strconv.Itoa(p.number)))
+		_ = f.Close()
+		if p.err != nil && !isErrBusy(p.err) {
+			return p.err
+		}
+		// Same as for GPIOs, udev may take a while to change the file mode.
+		for start := time.Now(); time.Since(start) < 5*time.Second; {
+			if p.fPeriod, p.err = fileIOOpen(root+"period", os.O_RDWR); p.err == nil || !os.IsPermission(p.err) {
+				break
+			}
+		}
+		if p.err != nil {
+			return p.err
+		}
+	}
+	if p.fDuty, p.err = fileIOOpen(root+"duty_cycle", os.O_RDWR); p.err != nil {
+		_ = p.fPeriod.Close()
+		p.fPeriod = nil
+		return p.err
+	}
+	if p.fEnable, p.err = fileIOOpen(root+"enable", os.O_RDWR); p.err != nil {
+		_ = p.fPeriod.Close()
+		p.fPeriod = nil
+		_ = p.fDuty.Close()
+		p.fDuty = nil
+		return p.err
+	}
+	// Read back the current state, as the channel may have been configured by
+	// another process.
+	var b [24]byte
+	if n, err := seekRead(p.fPeriod, b[:]); err == nil {
+		if v, err := strconv.ParseInt(strings.TrimSpace(string(b[:n])), 10, 64); err == nil {
+			p.period = time.Duration(v)
+		}
+	}
+	if n, err := seekRead(p.fEnable, b[:]); err == nil {
+		p.enabled = strings.TrimSpace(string(b[:n])) == "1"
+	}
+	if f, err := fileIOOpen(root+"polarity", os.O_RDONLY); err == nil {
+		if n, err := f.Read(b[:]); err == nil {
+			p.inverted = strings.TrimSpace(string(b[:n])) == "inversed"
+		}
+		_ = f.Close()
+	}
+	return nil
+}
+
+// disable stops the output.
+//
+// lock must be held.
+func (p *PWM) disable() error {
+	if !p.enabled {
+		return nil
+	}
+	if err := seekWrite(p.fEnable, []byte("0")); err != nil {
+		return p.wrap(err)
+	}
+	p.enabled = false
+	return nil
+}
+
+func (p *PWM) wrap(err error) error {
+	return fmt.Errorf("sysfs-pwm (%s): %v", p, err)
+}
+
+var (
+	pwmMu    sync.Mutex
+	pwmByPin = map[string]*PWM{}
+)
+
+// driverPWM implements periph.Driver.
+type driverPWM struct {
+}
+
+func (d *driverPWM) String() string {
+	return "sysfs-pwm"
+}
+
+func (d *driverPWM) Prerequisites() []string {
+	return nil
+}
+
+func (d *driverPWM) After() []string {
+	return nil
+}
+
+// Init initializes PWM sysfs handling code.
+//
+// Uses pwm sysfs as described at
+// https://www.kernel.org/doc/Documentation/pwm.txt
+func (d *driverPWM) Init() (bool, error) {
+	items, err := filepath.Glob("/sys/class/pwm/pwmchip*")
+	if err != nil {
+		return true, err
+	}
+	if len(items) == 0 {
+		return false, errors.New("no PWM found")
+	}
+	for _, item := range items {
+		if err := d.parsePWMChip(item + "/"); err != nil {
+			return true, err
+		}
+	}
+	sort.Slice(PWMs, func(i, j int) bool {
+		if PWMs[i].chip != PWMs[j].chip {
+			return PWMs[i].chip < PWMs[j].chip
+		}
+		return PWMs[i].number < PWMs[j].number
+	})
+	return true, nil
+}
+
+func (d *driverPWM) parsePWMChip(path string) error {
+	chip, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "pwmchip"))
+	if err != nil {
+		return fmt.Errorf("sysfs-pwm: invalid chip %s: %v", path, err)
+	}
+	number, err := readInt(path + "npwm")
+	if err != nil {
+		return fmt.Errorf("sysfs-pwm: %s: %v", path, err)
+	}
+	device := ""
+	if l, err := os.Readlink(path + "device"); err == nil {
+		device = filepath.Base(l)
+	}
+	for i := 0; i < number; i++ {
+		PWMs = append(PWMs, &PWM{
+			number: i,
+			chip:   chip,
+			name:   fmt.Sprintf("PWMCHIP%d_%d", chip, i),
+			root:   path,
+			device: device,
+		})
+	}
+	return nil
+}
+
+func init() {
+	if isLinux {
+		periph.MustRegister(&drvPWM)
+	}
+}
+
+var drvPWM driverPWM
+
+var _ conn.Resource = &PWM{}
+var _ gpio.PinOut = &PWM{}
+var _ pin.PinFunc = &PWM{}
@@ -0,0 +1,287 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sysfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/pin"
+)
+
+func TestPWMByName(t *testing.T) {
+	defer resetPWM()
+	PWMs = []*PWM{{number: 1, chip: 2, name: "PWMCHIP2_1"}}
+	if _, err := PWMByName("PWMCHIP0_0"); err == nil {
+		t.Fatal("invalid name")
+	}
+	p, err := PWMByName("PWMCHIP2_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := p.String(); s != "PWMCHIP2_1" {
+		t.Fatal(s)
+	}
+	if n := p.Number(); n != 1 {
+		t.Fatal(n)
+	}
+}
+
+func TestRegisterPWMPin(t *testing.T) {
+	defer resetPWM()
+	p := &PWM{name: "PWMCHIP0_0"}
+	if err := RegisterPWMPin("GPIO18", nil); err == nil {
+		t.Fatal("nil PWM")
+	}
+	if PWMByPin("GPIO18") != nil {
+		t.Fatal("not registered yet")
+	}
+	if err := RegisterPWMPin("GPIO18", p); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterPWMPin("GPIO18", p); err == nil {
+		t.Fatal("already registered")
+	}
+	if PWMByPin("GPIO18") != p {
+		t.Fatal("expected registered PWM")
+	}
+}
+
+func TestPWM_parsePWMChip(t *testing.T) {
+	defer reset()
+	defer resetPWM()
+	root := fakeSysfsPWM(t, false)
+	if err := os.Symlink("../../../devices/platform/soc/fe20c000.pwm", filepath.Join(root, "device")); err != nil {
+		t.Fatal(err)
+	}
+	d := driverPWM{}
+	if err := d.parsePWMChip(filepath.Join(filepath.Dir(root), "pwmchipX") + "/"); err == nil {
+		t.Fatal("invalid chip")
+	}
+	if err := d.parsePWMChip(root + "/"); err != nil {
+		t.Fatal(err)
+	}
+	if len(PWMs) != 2 {
+		t.Fatal(PWMs)
+	}
+	if s := PWMs[1].String(); s != "PWMCHIP0_1" {
+		t.Fatal(s)
+	}
+	if s := PWMs[1].Device(); s != "fe20c000.pwm" {
+		t.Fatal(s)
+	}
+}
+
+func TestPWM_PWM(t *testing.T) {
+	defer reset()
+	root := fakeSysfsPWM(t, false)
+	p := &PWM{number: 0, name: "PWMCHIP0_0", root: root + "/"}
+	if err := p.PWM(-1, physic.KiloHertz); err == nil {
+		t.Fatal("invalid duty")
+	}
+	if err := p.PWM(gpio.DutyHalf, 0); err == nil {
+		t.Fatal("invalid frequency")
+	}
+	if f := p.Func(); f != pin.FuncNone {
+		t.Fatal(f)
+	}
+	if err := p.PWM(gpio.DutyHalf, physic.KiloHertz); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, root, "export"); s != "" {
+		t.Fatal("channel was already exported")
+	}
+	if s := readFile(t, root, "pwm0/period"); s != "1000000" {
+		t.Fatal(s)
+	}
+	if s := readFile(t, root, "pwm0/duty_cycle"); s != "500000" {
+		t.Fatal(s)
+	}
+	if s := readFile(t, root, "pwm0/enable"); s != "1" {
+		t.Fatal(s)
+	}
+	if f := p.Func(); f != gpio.PWM {
+		t.Fatal(f)
+	}
+	// Shorter period; duty_cycle is written first.
+	if err := p.PWM(gpio.DutyMax/4, 10*physic.KiloHertz); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, root, "pwm0/period"); s != "100000" {
+		t.Fatal(s)
+	}
+	if s := readFile(t, root, "pwm0/duty_cycle"); s != "25000" {
+		t.Fatal(s)
+	}
+	if err := p.Out(gpio.High); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, root, "pwm0/duty_cycle"); s != "100000" {
+		t.Fatal(s)
+	}
+	if err := p.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, root, "pwm0/enable"); s != "0" {
+		t.Fatal(s)
+	}
+}
+
+func TestPWM_export(t *testing.T) {
+	defer reset()
+	root := fakeSysfsPWM(t, true)
+	p := &PWM{number: 1, name: "PWMCHIP0_1", root: root + "/"}
+	if err := p.Out(gpio.Low); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, root, "export"); s != "1" {
+		t.Fatal(s)
+	}
+	if s := readFile(t, root, "pwm1/period"); s != "1000000" {
+		t.Fatal(s)
+	}
+	if s := readFile(t, root, "pwm1/duty_cycle"); s != "0" {
+		t.Fatal(s)
+	}
+}
+
+func TestPWM_open_fail(t *testing.T) {
+	defer reset()
+	fileIOOpen = func(path string, flag int) (fileIO, error) {
+		return nil, os.ErrPermission
+	}
+	p := &PWM{number: 0, name: "PWMCHIP0_0", root: "/sys/class/pwm/pwmchip0/"}
+	if err := p.PWM(gpio.DutyHalf, physic.KiloHertz); err == nil || !strings.Contains(err.Error(), "need more access") {
+		t.Fatal(err)
+	}
+	// The error is sticky.
+	if err := p.Halt(); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestPWM_SetInverted(t *testing.T) {
+	defer reset()
+	root := fakeSysfsPWM(t, false)
+	p := &PWM{number: 0, name: "PWMCHIP0_0", root: root + "/"}
+	if p.Inverted() {
+		t.Fatal("expected normal")
+	}
+	if err := p.PWM(gpio.DutyHalf, physic.KiloHertz); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetInverted(true); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Inverted() {
+		t.Fatal("expected inversed")
+	}
+	if s := readFile(t, root, "pwm0/polarity"); s != "inversed" {
+		t.Fatal(s)
+	}
+	if s := readFile(t, root, "pwm0/enable"); s != "1" {
+		t.Fatal(s)
+	}
+	if err := p.SetInverted(false); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, root, "pwm0/polarity"); s != "normal" {
+		t.Fatal(s)
+	}
+}
+
+func TestPWM_Func(t *testing.T) {
+	p := &PWM{name: "PWMCHIP0_0"}
+	if s := p.Function(); s != string(pin.FuncNone) {
+		t.Fatal(s)
+	}
+	if f := p.SupportedFuncs(); len(f) != 1 || f[0] != gpio.PWM {
+		t.Fatal(f)
+	}
+	if err := p.SetFunc(gpio.PWM); err == nil {
+		t.Fatal("not supported")
+	}
+}
+
+//
+
+func resetPWM() {
+	PWMs = nil
+	pwmMu.Lock()
+	pwmByPin = map[string]*PWM{}
+	pwmMu.Unlock()
+}
+
+// fakeSysfsPWM creates a pwmchip directory with 2 channels in a temporary
+// directory. pwm0 is already exported unless unexported is true.
+func fakeSysfsPWM(t *testing.T, unexported bool) string {
+	root := filepath.Join(t.TempDir(), "pwmchip0")
+	if err := os.MkdirAll(root, 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFakePWMFile(t, filepath.Join(root, "npwm"), "2\n")
+	writeFakePWMFile(t, filepath.Join(root, "export"), "")
+	if !unexported {
+		fakePWMChannel(t, filepath.Join(root, "pwm0"))
+	}
+	fileIOOpen = func(path string, flag int) (fileIO, error) {
+		f, err := os.OpenFile(path, flag, 0600)
+		if err != nil {
+			return nil, err
+		}
+		return &fakePWMFile{fakeFile{f}, path}, nil
+	}
+	return root
+}
+
+// fakePWMChannel creates the files of an exported channel.
+func fakePWMChannel(t *testing.T, dir string) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, v := range map[string]string{"period": "0\n", "duty_cycle": "0\n", "enable": "0\n", "polarity": "normal\n"} {
+		writeFakePWMFile(t, filepath.Join(dir, name), v)
+	}
+}
+
+func writeFakePWMFile(t *testing.T, path, v string) {
+	if err := ioutil.WriteFile(path, []byte(v), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakePWMFile replaces the content on each write, like sysfs attributes, and
+// creates the channel directory when written to export.
+type fakePWMFile struct {
+	fakeFile
+	path string
+}
+
+func (f *fakePWMFile) Write(b []byte) (int, error) {
+	if err := f.Truncate(0); err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return 0, err
+	}
+	n, err := f.File.Write(b)
+	if err == nil && filepath.Base(f.path) == "export" {
+		dir := filepath.Join(filepath.Dir(f.path), "pwm"+string(b))
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return n, err
+		}
+		for _, name := range []string{"period", "duty_cycle", "enable", "polarity"} {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, err
+}
@@ -5,16 +5,19 @@
 package sysfs
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/meandrewdev/periph"
@@ -94,9 +97,12 @@ func (s *SPI) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, er
 	if f < 100*physic.Hertz {
 		return nil, fmt.Errorf("sysfs-spi: invalid speed %s; minimum supported clock is 100Hz; did you forget to multiply by physic.MegaHertz?", f)
 	}
-	if mode&^(spi.Mode3|spi.HalfDuplex|spi.NoCS|spi.LSBFirst) != 0 {
+	if mode&^(spi.Mode3|spi.HalfDuplex|spi.NoCS|spi.LSBFirst|spi.TxDual|spi.TxQuad|spi.RxDual|spi.RxQuad) != 0 {
 		return nil, fmt.Errorf("sysfs-spi: invalid mode %v", mode)
 	}
+	if mode&spi.TxDual != 0 && mode&spi.TxQuad != 0 || mode&spi.RxDual != 0 && mode&spi.RxQuad != 0 {
+		return nil, fmt.Errorf("sysfs-spi: invalid mode %v; dual and quad are mutually exclusive", mode)
+	}
 	if bits < 1 || bits >= 256 {
 		return nil, fmt.Errorf("sysfs-spi: invalid bits %d", bits)
 	}
@@ -130,9 +136,27 @@ func (s *SPI) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, er
 	if mode&spi.LSBFirst != 0 {
 		m |= lSBFirst
 	}
-	// Only the first 8 bits are used. This only works because the system is
-	// running in little endian.
-	if err := s.conn.setFlag(spiIOCMode, uint64(m)); err != nil {
+	s.conn.lanes = mode & (spi.TxDual | spi.TxQuad | spi.RxDual | spi.RxQuad)
+	op := spiIOCMode
+	if s.conn.lanes != 0 {
+		// The dual and quad flags do not fit in 8 bits.
+		op = spiIOCMode32
+		if mode&spi.TxDual != 0 {
+			m |= txDual
+		}
+		if mode&spi.TxQuad != 0 {
+			m |= txQuad
+		}
+		if mode&spi.RxDual != 0 {
+			m |= rxDual
+		}
+		if mode&spi.RxQuad != 0 {
+			m |= rxQuad
+		}
+	}
+	// Only the first 8 or 32 bits are used. This only works because the system
+	// is running in little endian.
+	if err := s.conn.setFlag(op, uint64(m)); err != nil {
 		return nil, fmt.Errorf("sysfs-spi: setting mode %v failed: %v", mode, err)
 	}
 	return &s.conn, nil
@@ -204,6 +228,7 @@ type spiConn struct {
 	connected   bool
 	halfDuplex  bool
 	noCS        bool
+	lanes       spi.Mode // TxDual, TxQuad, RxDual and RxQuad specified at Connect()
 	// Heap optimization: reduce the amount of memory allocations during
 	// transactions.
 	io [4]spiIOCTransfer
@@ -308,6 +333,18 @@ func (s *spiConn) Tx(w, r []byte) error {
 // 4096 bytes. See the platform documentation to learn how to increase the
 // limit.
 func (s *spiConn) TxPackets(p []spi.Packet) error {
+	return s.TxPacketsContext(context.Background(), p)
+}
+
+// TxPacketsContext implements spi.TxPacketsContexter.
+//
+// When ctx can be canceled, the packets are sent as one ioctl per group of
+// packets ending with KeepCS false, checking ctx between each group. A group
+// cannot be interrupted once started.
+func (s *spiConn) TxPacketsContext(ctx context.Context, p []spi.Packet) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	total := 0
 	for i := range p {
 		lW := len(p[i].W)
@@ -320,6 +357,15 @@ func (s *spiConn) TxPackets(p []spi.Packet) error {
 			l = lR
 		}
 		total += l
+		if p[i].Speed < 0 {
+			return fmt.Errorf("sysfs-spi: invalid speed %s", p[i].Speed)
+		}
+		if p[i].DelayBefore < 0 || p[i].DelayAfter < 0 || toMicroseconds(p[i].DelayBefore) > math.MaxUint16 || toMicroseconds(p[i].DelayAfter) > math.MaxUint16 {
+			return fmt.Errorf("sysfs-spi: delays must be between 0 and %s", math.MaxUint16*time.Microsecond)
+		}
+		if p[i].WordDelay < 0 || toMicroseconds(p[i].WordDelay) > math.MaxUint8 {
+			return fmt.Errorf("sysfs-spi: word delay must be between 0 and %s", math.MaxUint8*time.Microsecond)
+		}
 	}
 	if total == 0 {
 		return errors.New("sysfs-spi: empty packets")
@@ -337,8 +383,29 @@ func (s *spiConn) TxPackets(p []spi.Packet) error {
 			}
 		}
 	}
-	if err := s.txPackets(p); err != nil {
-		return fmt.Errorf("sysfs-spi: TxPackets() failed: %v", err)
+	for i := range p {
+		if err := s.checkLanes(&p[i]); err != nil {
+			return err
+		}
+	}
+	if ctx.Done() == nil {
+		if err := s.txPackets(p); err != nil {
+			return fmt.Errorf("sysfs-spi: TxPackets() failed: %v", err)
+		}
+		return nil
+	}
+	for start := 0; start < len(p); {
+		end := start + 1
+		for end < len(p) && p[end-1].KeepCS {
+			end++
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.txPackets(p[start:end]); err != nil {
+			return fmt.Errorf("sysfs-spi: TxPackets() failed: %v", err)
+		}
+		start = end
 	}
 	return nil
 }
@@ -383,22 +450,32 @@ func (s *spiConn) CS() gpio.PinOut {
 //
 
 func (s *spiConn) txPackets(p []spi.Packet) error {
-	// Convert the packets.
-	f := s.freqPort
-	if s.freqConn != 0 && (s.freqPort == 0 || s.freqConn < s.freqPort) {
-		f = s.freqConn
+	// Convert the packets. An empty transfer is inserted for each DelayBefore,
+	// since the kernel only supports a delay after a transfer.
+	n := len(p)
+	for i := range p {
+		if p[i].DelayBefore != 0 {
+			n++
+		}
 	}
 	var m []spiIOCTransfer
-	if len(p) > len(s.io) {
-		m = make([]spiIOCTransfer, len(p))
+	if n > len(s.io) {
+		m = make([]spiIOCTransfer, n)
 	} else {
-		m = s.io[:len(p)]
+		m = s.io[:n]
 	}
+	j := 0
 	for i := range p {
+		f := s.freq(p[i].Speed)
 		bits := p[i].BitsPerWord
 		if bits == 0 {
 			bits = s.bitsPerWord
 		}
+		if p[i].DelayBefore != 0 {
+			m[j].reset(nil, nil, f, bits, false)
+			m[j].delayUsecs = uint16(toMicroseconds(p[i].DelayBefore))
+			j++
+		}
 		csInvert := false
 		if !s.noCS {
 			// Invert CS behavior when a packet has KeepCS false, except for the last
@@ -406,11 +483,56 @@ func (s *spiConn) txPackets(p []spi.Packet) error {
 			last := i == len(p)-1
 			csInvert = p[i].KeepCS == last
 		}
-		m[i].reset(p[i].W, p[i].R, f, bits, csInvert)
+		m[j].reset(p[i].W, p[i].R, f, bits, csInvert)
+		m[j].txNBits = p[i].TxLanes
+		m[j].rxNBits = p[i].RxLanes
+		m[j].delayUsecs = uint16(toMicroseconds(p[i].DelayAfter))
+		m[j].wordDelayUsecs = uint8(toMicroseconds(p[i].WordDelay))
+		j++
 	}
 	return s.f.Ioctl(spiIOCTx(len(m)), uintptr(unsafe.Pointer(&m[0])))
 }
 
+// checkLanes verifies that the number of data lines used by a packet was
+// enabled at Connect().
+func (s *spiConn) checkLanes(p *spi.Packet) error {
+	if !validLanes(p.TxLanes, s.lanes&spi.TxDual != 0, s.lanes&spi.TxQuad != 0) {
+		return fmt.Errorf("sysfs-spi: %d tx lanes not supported with mode %v", p.TxLanes, s.lanes)
+	}
+	if !validLanes(p.RxLanes, s.lanes&spi.RxDual != 0, s.lanes&spi.RxQuad != 0) {
+		return fmt.Errorf("sysfs-spi: %d rx lanes not supported with mode %v", p.RxLanes, s.lanes)
+	}
+	if (p.TxLanes > 1 || p.RxLanes > 1) && len(p.W) != 0 && len(p.R) != 0 {
+		return errors.New("sysfs-spi: can only specify one of w or r when using multiple lanes")
+	}
+	return nil
+}
+
+func validLanes(lanes uint8, dual, quad bool) bool {
+	switch lanes {
+	case 0, 1:
+		return true
+	case 2:
+		return dual || quad
+	case 4:
+		return quad
+	default:
+		return false
+	}
+}
+
+// freq returns the clock speed to use for a packet.
+func (s *spiConn) freq(speed physic.Frequency) physic.Frequency {
+	f := s.freqConn
+	if speed != 0 {
+		f = speed
+	}
+	if s.freqPort != 0 && (f == 0 || s.freqPort < f) {
+		f = s.freqPort
+	}
+	return f
+}
+
 func (s *spiConn) setFlag(op uint, arg uint64) error {
 	return s.f.Ioctl(op, uintptr(unsafe.Pointer(&arg)))
 }
@@ -456,6 +578,10 @@ const (
 	noCS      spi.Mode = 0x40 // do not assert CS
 	ready     spi.Mode = 0x80 // slave pulls low to pause
 	// The driver optionally support dual and quad data lines.
+	txDual spi.Mode = 0x100 // transmit with 2 wires
+	txQuad spi.Mode = 0x200 // transmit with 4 wires
+	rxDual spi.Mode = 0x400 // receive with 2 wires
+	rxQuad spi.Mode = 0x800 // receive with 4 wires
 )
 
 // spidev driver IOCTL control codes.
@@ -483,16 +609,17 @@ func spiIOCTx(l int) uint {
 // Also documented as struct spi_transfer at
 // https://www.kernel.org/doc/html/latest/driver-api/spi.html
 type spiIOCTransfer struct {
-	tx          uint64 // Pointer to byte slice
-	rx          uint64 // Pointer to byte slice
-	length      uint32 // buffer length of tx and rx in bytes
-	speedHz     uint32 // temporarily override the speed
-	delayUsecs  uint16 // µs to sleep before selecting the device before the next transfer
-	bitsPerWord uint8  // temporarily override the number of bytes per word
-	csChange    uint8  // true to deassert CS before next transfer
-	txNBits     uint8
-	rxNBits     uint8
-	pad         uint16
+	tx             uint64 // Pointer to byte slice
+	rx             uint64 // Pointer to byte slice
+	length         uint32 // buffer length of tx and rx in bytes
+	speedHz        uint32 // temporarily override the speed
+	delayUsecs     uint16 // µs to sleep before selecting the device before the next transfer
+	bitsPerWord    uint8  // temporarily override the number of bytes per word
+	csChange       uint8  // true to deassert CS before next transfer
+	txNBits        uint8
+	rxNBits        uint8
+	wordDelayUsecs uint8 // µs to sleep between words; ignored before Linux 5.0
+	pad            uint8
 }
 
 func (s *spiIOCTransfer) reset(w, r []byte, f physic.Frequency, bitsPerWord uint8, csInvert bool) {
@@ -518,9 +645,15 @@ func (s *spiIOCTransfer) reset(w, r []byte, f physic.Frequency, bitsPerWord uint
 	}
 	s.txNBits = 0
 	s.rxNBits = 0
+	s.wordDelayUsecs = 0
 	s.pad = 0
 }
 
+// toMicroseconds rounds d up to a number of µs.
+func toMicroseconds(d time.Duration) int64 {
+	return int64((d + time.Microsecond - 1) / time.Microsecond)
+}
+
 //
 
 // driverSPI implements periph.Driver.
@@ -615,6 +748,7 @@ var _ conn.Limits = &spiConn{}
 var _ io.Reader = &spiConn{}
 var _ io.Writer = &spiConn{}
 var _ spi.Conn = &spiConn{}
+var _ spi.TxPacketsContexter = &spiConn{}
 var _ spi.Pins = &SPI{}
 var _ spi.Pins = &spiConn{}
 var _ spi.Port = &SPI{}
@@ -5,9 +5,12 @@
 package sysfs
 
 import (
+	"context"
 	"errors"
 	"io"
 	"testing"
+	"time"
+	"unsafe"
 
 	"github.com/meandrewdev/periph/conn"
 	"github.com/meandrewdev/periph/conn/gpio"
@@ -124,6 +127,132 @@ func TestSPI_TxPackets(t *testing.T) {
 	}
 }
 
+func TestSPI_TxPacketsContext(t *testing.T) {
+	f := ioctlRecord{}
+	p := SPI{spiConn{f: &f, busNumber: 24}}
+	c, err := p.Connect(100*physic.Hertz, spi.Mode3, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := c.(spi.TxPacketsContexter)
+	f.ops = nil
+	pkt := []spi.Packet{
+		{W: []byte{0}, KeepCS: true},
+		{W: []byte{1}},
+		{W: []byte{2}},
+	}
+	// Without cancellation, a single ioctl is used.
+	if err := s.TxPacketsContext(context.Background(), pkt); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.ops) != 1 || f.ops[0] != spiIOCTx(3) {
+		t.Fatal(f.ops)
+	}
+	// With cancellation, the packets are split at CS boundaries.
+	f.ops = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := s.TxPacketsContext(ctx, pkt); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.ops) != 2 || f.ops[0] != spiIOCTx(2) || f.ops[1] != spiIOCTx(1) {
+		t.Fatal(f.ops)
+	}
+	f.ops = nil
+	cancel()
+	if err := s.TxPacketsContext(ctx, pkt); err != context.Canceled {
+		t.Fatal(err)
+	}
+	if len(f.ops) != 0 {
+		t.Fatal(f.ops)
+	}
+}
+
+func TestSPI_TxPackets_timing(t *testing.T) {
+	f := ioctlTransfers{}
+	p := SPI{spiConn{f: &f, busNumber: 24}}
+	if err := p.LimitSpeed(10 * physic.MegaHertz); err != nil {
+		t.Fatal(err)
+	}
+	c, err := p.Connect(physic.MegaHertz, spi.Mode3, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkt := []spi.Packet{
+		{W: []byte{0}, DelayBefore: 1500 * time.Nanosecond, KeepCS: true},
+		{R: []byte{0}, Speed: 20 * physic.MegaHertz, WordDelay: time.Microsecond},
+		{W: []byte{1}, Speed: 2 * physic.MegaHertz, DelayAfter: 10 * time.Microsecond},
+	}
+	if err := c.TxPackets(pkt); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.xfers) != 4 {
+		t.Fatal(f.xfers)
+	}
+	// The delay before the first packet is an empty transfer.
+	if x := f.xfers[0]; x.length != 0 || x.delayUsecs != 2 || x.csChange != 0 || x.speedHz != 1000000 {
+		t.Fatalf("%#v", x)
+	}
+	if x := f.xfers[1]; x.length != 1 || x.delayUsecs != 0 || x.csChange != 0 {
+		t.Fatalf("%#v", x)
+	}
+	// Speed is limited by LimitSpeed.
+	if x := f.xfers[2]; x.speedHz != 10000000 || x.wordDelayUsecs != 1 || x.csChange != 1 {
+		t.Fatalf("%#v", x)
+	}
+	if x := f.xfers[3]; x.speedHz != 2000000 || x.delayUsecs != 10 || x.csChange != 0 {
+		t.Fatalf("%#v", x)
+	}
+
+	data := [][]spi.Packet{
+		{{W: []byte{0}, Speed: -1}},
+		{{W: []byte{0}, DelayBefore: -1}},
+		{{W: []byte{0}, DelayAfter: time.Second}},
+		{{W: []byte{0}, WordDelay: time.Millisecond}},
+	}
+	for i, pkt := range data {
+		if err := c.TxPackets(pkt); err == nil {
+			t.Fatal(i)
+		}
+	}
+}
+
+func TestSPI_TxPackets_lanes(t *testing.T) {
+	p := SPI{spiConn{f: &ioctlClose{}, busNumber: 24}}
+	if _, err := p.Connect(physic.MegaHertz, spi.Mode0|spi.TxDual|spi.TxQuad, 8); err == nil {
+		t.Fatal("dual and quad are exclusive")
+	}
+	f := ioctlTransfers{}
+	p = SPI{spiConn{f: &f, busNumber: 24}}
+	c, err := p.Connect(physic.MegaHertz, spi.Mode0|spi.TxQuad|spi.RxDual, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.modeOp != spiIOCMode32 || spi.Mode(f.mode) != txQuad|rxDual {
+		t.Fatalf("0x%x 0x%x", f.modeOp, f.mode)
+	}
+	pkt := []spi.Packet{
+		{W: []byte{0xEB}, KeepCS: true},
+		{W: []byte{0, 0, 0}, TxLanes: 4, KeepCS: true},
+		{R: make([]byte, 4), RxLanes: 2},
+	}
+	if err := c.TxPackets(pkt); err != nil {
+		t.Fatal(err)
+	}
+	if f.xfers[0].txNBits != 0 || f.xfers[1].txNBits != 4 || f.xfers[2].rxNBits != 2 {
+		t.Fatalf("%#v", f.xfers)
+	}
+	data := []spi.Packet{
+		{W: []byte{0}, TxLanes: 3},
+		{R: []byte{0}, RxLanes: 4},
+		{W: []byte{0}, R: []byte{0}, TxLanes: 2},
+	}
+	for i, pkt := range data {
+		if err := c.TxPackets([]spi.Packet{pkt}); err == nil {
+			t.Fatal(i)
+		}
+	}
+}
+
 func TestSPI_Read(t *testing.T) {
 	f := ioctlClose{}
 	p := SPI{spiConn{f: &f, busNumber: 24}}
@@ -370,3 +499,46 @@ func BenchmarkSPI_TxPackets5(b *testing.B) {
 func init() {
 	drvSPI.bufSize = 4096
 }
+
+//
+
+// ioctlRecord records the ioctl ops.
+type ioctlRecord struct {
+	ops []uint
+}
+
+func (i *ioctlRecord) Ioctl(op uint, data uintptr) error {
+	i.ops = append(i.ops, op)
+	return nil
+}
+
+func (i *ioctlRecord) Close() error {
+	return nil
+}
+
+// ioctlTransfers keeps a copy of the mode and the transfers sent with
+// spiIOCTx.
+type ioctlTransfers struct {
+	modeOp uint
+	mode   uint32
+	xfers  []spiIOCTransfer
+}
+
+func (i *ioctlTransfers) Ioctl(op uint, data uintptr) error {
+	if op == spiIOCMode || op == spiIOCMode32 {
+		i.modeOp = op
+		i.mode = *(*uint32)(ioctlArg(data))
+		return nil
+	}
+	for n := 1; n < 16; n++ {
+		if op == spiIOCTx(n) {
+			i.xfers = append([]spiIOCTransfer{}, unsafe.Slice((*spiIOCTransfer)(ioctlArg(data)), n)...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (i *ioctlTransfers) Close() error {
+	return nil
+}
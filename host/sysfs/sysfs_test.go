@@ -19,6 +19,7 @@ func init() {
 func reset() {
 	fileIOOpen = fileIOOpenDefault
 	ioctlOpen = ioctlOpenDefault
+	uartOpen = uartOpenDefault
 	// Soon.
 	//fileIOOpen = fileIOOpenPanic
 	//ioctlOpen = ioctlOpenPanic
@@ -0,0 +1,680 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sysfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/meandrewdev/periph"
+	"github.com/meandrewdev/periph/conn"
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/gpio/gpioreg"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/uart"
+	"github.com/meandrewdev/periph/conn/uart/uartreg"
+	"github.com/meandrewdev/periph/host/fs"
+)
+
+// NewUART opens an UART port via its devfs interface as described at
+// http://man7.org/linux/man-pages/man3/termios.3.html.
+//
+// path is the path to the tty device, for example /dev/ttyAMA0 or
+// /dev/ttyUSB0.
+//
+// The resulting object is safe for concurent use.
+//
+// Do not use sysfs.NewUART() directly as the package sysfs is providing a
+// https://github.com/meandrewdev/periph/conn/uart Linux-specific implementation.
+//
+// periph.io works on many OSes!
+//
+// Instead, use https://github.com/meandrewdev/periph/conn/uart/uartreg#Open. This
+// permits it to work on all operating systems, or devices like UART over USB.
+func NewUART(path string) (*UART, error) {
+	if isLinux {
+		return newUART(path, uartPortNumber(path))
+	}
+	return nil, errors.New("sysfs-uart: is not supported on this platform")
+}
+
+// UART is an open UART port via devfs.
+//
+// The port is configured in raw mode; no character is interpreted by the
+// kernel.
+type UART struct {
+	conn uartConn
+}
+
+// Close implements uart.PortCloser.
+func (u *UART) Close() error {
+	u.conn.mu.Lock()
+	defer u.conn.mu.Unlock()
+	if err := u.conn.f.Close(); err != nil {
+		return fmt.Errorf("sysfs-uart: %v", err)
+	}
+	return nil
+}
+
+// String implements uart.Port.
+func (u *UART) String() string {
+	return u.conn.String()
+}
+
+// Connect implements uart.Port.
+func (u *UART) Connect(f physic.Frequency, stopBit uart.Stop, parity uart.Parity, flow uart.Flow, bits int) (uart.Conn, error) {
+	if err := checkUARTSpeed(f); err != nil {
+		return nil, err
+	}
+	if bits < 5 || bits > 8 {
+		return nil, fmt.Errorf("sysfs-uart: invalid bits %d; must be between 5 and 8", bits)
+	}
+	u.conn.mu.Lock()
+	defer u.conn.mu.Unlock()
+	if u.conn.connected {
+		return nil, errors.New("sysfs-uart: Connect() can only be called exactly once")
+	}
+	u.conn.freqConn = f
+	t, err := u.conn.getTermios()
+	if err != nil {
+		return nil, err
+	}
+	t.makeRaw()
+	if err := t.setFormat(stopBit, parity, bits); err != nil {
+		return nil, err
+	}
+	if err := t.setFlow(flow); err != nil {
+		return nil, err
+	}
+	t.setSpeed(u.conn.freq())
+	if err := u.conn.setTermios(&t); err != nil {
+		return nil, err
+	}
+	if flow != uart.RTSCTS {
+		u.conn.muPins.Lock()
+		u.conn.rts = gpio.INVALID
+		u.conn.cts = gpio.INVALID
+		u.conn.muPins.Unlock()
+	}
+	u.conn.connected = true
+	return &u.conn, nil
+}
+
+// LimitSpeed implements uart.PortCloser.
+func (u *UART) LimitSpeed(f physic.Frequency) error {
+	if err := checkUARTSpeed(f); err != nil {
+		return err
+	}
+	u.conn.mu.Lock()
+	defer u.conn.mu.Unlock()
+	u.conn.freqPort = f
+	if !u.conn.connected {
+		return nil
+	}
+	t, err := u.conn.getTermios()
+	if err != nil {
+		return err
+	}
+	t.setSpeed(u.conn.freq())
+	return u.conn.setTermios(&t)
+}
+
+// RX implements uart.Pins.
+func (u *UART) RX() gpio.PinIn {
+	return u.conn.RX()
+}
+
+// TX implements uart.Pins.
+func (u *UART) TX() gpio.PinOut {
+	return u.conn.TX()
+}
+
+// RTS implements uart.Pins.
+func (u *UART) RTS() gpio.PinOut {
+	return u.conn.RTS()
+}
+
+// CTS implements uart.Pins.
+func (u *UART) CTS() gpio.PinIn {
+	return u.conn.CTS()
+}
+
+//
+
+// uartFile is the subset of *os.File used by uartConn.
+type uartFile interface {
+	io.Closer
+	io.Reader
+	io.Writer
+	fs.Ioctler
+	SetReadDeadline(t time.Time) error
+}
+
+// uartFlags are the flags used to open the tty device.
+//
+// O_NONBLOCK is needed so opening doesn't wait for the carrier detect line;
+// the file is then handled by the Go runtime poller, which enables read
+// deadlines.
+const uartFlags = os.O_RDWR | syscall.O_NOCTTY | syscall.O_NONBLOCK
+
+func uartOpenDefault(path string) (uartFile, error) {
+	f, err := fs.Open(path, uartFlags)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+var uartOpen = uartOpenDefault
+
+func newUART(path string, portNumber int) (*UART, error) {
+	f, err := uartOpen(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("sysfs-uart: port %s is not configured: %v", path, err)
+		}
+		// TODO(maruel): This is a debianism.
+		return nil, fmt.Errorf("sysfs-uart: are you member of group 'dialout'? %v", err)
+	}
+	return &UART{uartConn{name: path, f: f, portNumber: portNumber}}, nil
+}
+
+// uartPortNumber returns the UART number for ports provided by the CPU, or -1.
+func uartPortNumber(path string) int {
+	name := filepath.Base(path)
+	for _, prefix := range []string{"ttyAMA", "ttyS"} {
+		if strings.HasPrefix(name, prefix) {
+			if i, err := strconv.Atoi(name[len(prefix):]); err == nil {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func checkUARTSpeed(f physic.Frequency) error {
+	if f > physic.GigaHertz {
+		return fmt.Errorf("sysfs-uart: invalid speed %s; maximum supported clock is 1GHz", f)
+	}
+	if f < 50*physic.Hertz {
+		return fmt.Errorf("sysfs-uart: invalid speed %s; minimum supported clock is 50Hz; did you forget to multiply by physic.KiloHertz?", f)
+	}
+	return nil
+}
+
+type uartConn struct {
+	// Immutable
+	name       string
+	f          uartFile
+	portNumber int
+
+	mu        sync.Mutex
+	freqPort  physic.Frequency // Frequency specified at LimitSpeed()
+	freqConn  physic.Frequency // Frequency specified at Connect()
+	connected bool
+	timeout   time.Duration
+
+	// Use a separate lock for the pins, so that they can be queried while a
+	// transaction is happening.
+	muPins sync.Mutex
+	rx     gpio.PinIn
+	tx     gpio.PinOut
+	rts    gpio.PinOut
+	cts    gpio.PinIn
+}
+
+// String implements conn.Conn.
+func (u *uartConn) String() string {
+	return u.name
+}
+
+// Duplex implements conn.Conn.
+func (u *uartConn) Duplex() conn.Duplex {
+	return conn.Full
+}
+
+// Read implements io.Reader.
+func (u *uartConn) Read(b []byte) (int, error) {
+	u.mu.Lock()
+	var deadline time.Time
+	if u.timeout != 0 {
+		deadline = time.Now().Add(u.timeout)
+	}
+	u.mu.Unlock()
+	if err := u.f.SetReadDeadline(deadline); err != nil && !deadline.IsZero() {
+		return 0, fmt.Errorf("sysfs-uart: %v", err)
+	}
+	return u.f.Read(b)
+}
+
+// Write implements io.Writer.
+func (u *uartConn) Write(b []byte) (int, error) {
+	return u.f.Write(b)
+}
+
+// Tx implements conn.Conn.
+func (u *uartConn) Tx(w, r []byte) error {
+	if len(w) != 0 {
+		if _, err := u.Write(w); err != nil {
+			return err
+		}
+	}
+	if len(r) != 0 {
+		_, err := io.ReadFull(u, r)
+		return err
+	}
+	return nil
+}
+
+// SetReadTimeout implements uart.Conn.
+func (u *uartConn) SetReadTimeout(d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("sysfs-uart: invalid timeout %s", d)
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.timeout = d
+	return nil
+}
+
+// SetFlow implements uart.Conn.
+func (u *uartConn) SetFlow(f uart.Flow) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	t, err := u.getTermios()
+	if err != nil {
+		return err
+	}
+	if err := t.setFlow(f); err != nil {
+		return err
+	}
+	return u.setTermios(&t)
+}
+
+// Break implements uart.Conn.
+func (u *uartConn) Break(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("sysfs-uart: invalid break duration %s", d)
+	}
+	if err := u.f.Ioctl(ioctlTIOCSBRK, 0); err != nil {
+		return fmt.Errorf("sysfs-uart: %v", err)
+	}
+	time.Sleep(d)
+	if err := u.f.Ioctl(ioctlTIOCCBRK, 0); err != nil {
+		return fmt.Errorf("sysfs-uart: %v", err)
+	}
+	return nil
+}
+
+// Modem implements uart.Conn.
+func (u *uartConn) Modem() (uart.Modem, error) {
+	var v uint32
+	if err := u.f.Ioctl(ioctlTIOCMGET, uintptr(unsafe.Pointer(&v))); err != nil {
+		return 0, fmt.Errorf("sysfs-uart: %v", err)
+	}
+	var m uart.Modem
+	for _, l := range modemLines {
+		if v&l.tiocm != 0 {
+			m |= l.m
+		}
+	}
+	return m, nil
+}
+
+// SetModem implements uart.Conn.
+func (u *uartConn) SetModem(mask, m uart.Modem) error {
+	if mask&^(uart.ModemDTR|uart.ModemRTS) != 0 {
+		return fmt.Errorf("sysfs-uart: can't set modem lines %s", mask&^(uart.ModemDTR|uart.ModemRTS))
+	}
+	var set, clear uint32
+	for _, l := range modemLines {
+		if mask&l.m != 0 {
+			if m&l.m != 0 {
+				set |= l.tiocm
+			} else {
+				clear |= l.tiocm
+			}
+		}
+	}
+	if set != 0 {
+		if err := u.f.Ioctl(ioctlTIOCMBIS, uintptr(unsafe.Pointer(&set))); err != nil {
+			return fmt.Errorf("sysfs-uart: %v", err)
+		}
+	}
+	if clear != 0 {
+		if err := u.f.Ioctl(ioctlTIOCMBIC, uintptr(unsafe.Pointer(&clear))); err != nil {
+			return fmt.Errorf("sysfs-uart: %v", err)
+		}
+	}
+	return nil
+}
+
+// RX implements uart.Pins.
+func (u *uartConn) RX() gpio.PinIn {
+	u.initPins()
+	return u.rx
+}
+
+// TX implements uart.Pins.
+func (u *uartConn) TX() gpio.PinOut {
+	u.initPins()
+	return u.tx
+}
+
+// RTS implements uart.Pins.
+func (u *uartConn) RTS() gpio.PinOut {
+	u.initPins()
+	return u.rts
+}
+
+// CTS implements uart.Pins.
+func (u *uartConn) CTS() gpio.PinIn {
+	u.initPins()
+	return u.cts
+}
+
+// freq returns the lowest speed between the port and the connection.
+//
+// It must be called with mu held.
+func (u *uartConn) freq() physic.Frequency {
+	f := u.freqPort
+	if u.freqConn != 0 && (u.freqPort == 0 || u.freqConn < u.freqPort) {
+		f = u.freqConn
+	}
+	return f
+}
+
+func (u *uartConn) getTermios() (termios2, error) {
+	var t termios2
+	if err := u.f.Ioctl(ioctlTCGETS2, uintptr(unsafe.Pointer(&t))); err != nil {
+		return t, fmt.Errorf("sysfs-uart: %v", err)
+	}
+	return t, nil
+}
+
+func (u *uartConn) setTermios(t *termios2) error {
+	if err := u.f.Ioctl(ioctlTCSETS2, uintptr(unsafe.Pointer(t))); err != nil {
+		return fmt.Errorf("sysfs-uart: %v", err)
+	}
+	return nil
+}
+
+func (u *uartConn) initPins() {
+	u.muPins.Lock()
+	defer u.muPins.Unlock()
+	if u.rx != nil {
+		return
+	}
+	if u.rx = gpioreg.ByName(fmt.Sprintf("UART%d_RX", u.portNumber)); u.rx == nil {
+		u.rx = gpio.INVALID
+	}
+	if u.tx = gpioreg.ByName(fmt.Sprintf("UART%d_TX", u.portNumber)); u.tx == nil {
+		u.tx = gpio.INVALID
+	}
+	// u.rts is set to INVALID if no hardware RTS/CTS flow control is used.
+	if u.rts == nil {
+		if u.rts = gpioreg.ByName(fmt.Sprintf("UART%d_RTS", u.portNumber)); u.rts == nil {
+			u.rts = gpio.INVALID
+		}
+		if u.cts = gpioreg.ByName(fmt.Sprintf("UART%d_CTS", u.portNumber)); u.cts == nil {
+			u.cts = gpio.INVALID
+		}
+	}
+}
+
+// termios2 is struct termios2 as defined in asm-generic/termbits.h.
+//
+// It is used instead of struct termios since it supports arbitrary speeds via
+// BOTHER.
+type termios2 struct {
+	iflag  uint32
+	oflag  uint32
+	cflag  uint32
+	lflag  uint32
+	line   uint8
+	cc     [19]uint8
+	ispeed uint32
+	ospeed uint32
+}
+
+// makeRaw does the equivalent of cfmakeraw(3).
+func (t *termios2) makeRaw() {
+	t.iflag &^= termIGNBRK | termBRKINT | termPARMRK | termISTRIP | termINLCR | termIGNCR | termICRNL | termIXON
+	t.oflag &^= termOPOST
+	t.lflag &^= termECHO | termECHONL | termICANON | termISIG | termIEXTEN
+	t.cflag |= termCREAD | termCLOCAL
+	// Return as soon as one byte is available.
+	t.cc[termVMIN] = 1
+	t.cc[termVTIME] = 0
+}
+
+func (t *termios2) setFormat(stopBit uart.Stop, parity uart.Parity, bits int) error {
+	t.cflag &^= termCSIZE | termCSTOPB | termPARENB | termPARODD | termCMSPAR
+	t.iflag &^= termINPCK
+	t.cflag |= uint32(bits-5) << 4
+	switch stopBit {
+	case uart.One:
+	case uart.Two:
+		t.cflag |= termCSTOPB
+	default:
+		return fmt.Errorf("sysfs-uart: unsupported stop bit %d", stopBit)
+	}
+	switch parity {
+	case uart.NoParity:
+	case uart.Odd:
+		t.cflag |= termPARENB | termPARODD
+	case uart.Even:
+		t.cflag |= termPARENB
+	case uart.Mark:
+		t.cflag |= termPARENB | termPARODD | termCMSPAR
+	case uart.Space:
+		t.cflag |= termPARENB | termCMSPAR
+	default:
+		return fmt.Errorf("sysfs-uart: invalid parity %q", byte(parity))
+	}
+	if parity != uart.NoParity {
+		t.iflag |= termINPCK
+	}
+	return nil
+}
+
+func (t *termios2) setFlow(f uart.Flow) error {
+	t.cflag &^= termCRTSCTS
+	t.iflag &^= termIXON | termIXOFF | termIXANY
+	switch f {
+	case uart.NoFlow:
+	case uart.RTSCTS:
+		t.cflag |= termCRTSCTS
+	default:
+		xon, xoff, ok := f.XOnXOff()
+		if !ok {
+			return fmt.Errorf("sysfs-uart: invalid flow %s", f)
+		}
+		t.iflag |= termIXON | termIXOFF
+		t.cc[termVSTART] = xon
+		t.cc[termVSTOP] = xoff
+	}
+	return nil
+}
+
+func (t *termios2) setSpeed(f physic.Frequency) {
+	baud := uint32(f / physic.Hertz)
+	t.cflag &^= termCBAUD | termCBAUD<<termIBSHIFT
+	t.cflag |= termBOTHER | termBOTHER<<termIBSHIFT
+	t.ispeed = baud
+	t.ospeed = baud
+}
+
+// termios flags as defined in asm-generic/termbits.h.
+//
+// These are defined here so we don't need to import golang.org/x/sys/unix.
+const (
+	// iflag
+	termIGNBRK = 0000001
+	termBRKINT = 0000002
+	termPARMRK = 0000010
+	termINPCK  = 0000020
+	termISTRIP = 0000040
+	termINLCR  = 0000100
+	termIGNCR  = 0000200
+	termICRNL  = 0000400
+	termIXON   = 0002000
+	termIXANY  = 0004000
+	termIXOFF  = 0010000
+
+	// oflag
+	termOPOST = 0000001
+
+	// cflag
+	termCBAUD   = 0010017
+	termCSIZE   = 0000060
+	termCSTOPB  = 0000100
+	termCREAD   = 0000200
+	termPARENB  = 0000400
+	termPARODD  = 0001000
+	termCLOCAL  = 0004000
+	termBOTHER  = 0010000
+	termCMSPAR  = 010000000000
+	termCRTSCTS = 020000000000
+	termIBSHIFT = 16
+
+	// lflag
+	termISIG   = 0000001
+	termICANON = 0000002
+	termECHO   = 0000010
+	termECHONL = 0000100
+	termIEXTEN = 0100000
+
+	// cc index
+	termVTIME  = 5
+	termVMIN   = 6
+	termVSTART = 8
+	termVSTOP  = 9
+)
+
+// Modem lines bits as defined in asm-generic/termios.h.
+const (
+	tiocmDTR = 0x002
+	tiocmRTS = 0x004
+	tiocmCTS = 0x020
+	tiocmCAR = 0x040
+	tiocmRNG = 0x080
+	tiocmDSR = 0x100
+)
+
+var modemLines = [...]struct {
+	m     uart.Modem
+	tiocm uint32
+}{
+	{uart.ModemDTR, tiocmDTR},
+	{uart.ModemRTS, tiocmRTS},
+	{uart.ModemCTS, tiocmCTS},
+	{uart.ModemDSR, tiocmDSR},
+	{uart.ModemDCD, tiocmCAR},
+	{uart.ModemRI, tiocmRNG},
+}
+
+// tty ioctls as defined in asm-generic/ioctls.h.
+const (
+	ioctlTIOCMGET = 0x5415
+	ioctlTIOCMBIS = 0x5416
+	ioctlTIOCMBIC = 0x5417
+	ioctlTIOCSBRK = 0x5427
+	ioctlTIOCCBRK = 0x5428
+)
+
+var (
+	ioctlTCGETS2 = fs.IOR('T', 0x2A, uint(unsafe.Sizeof(termios2{})))
+	ioctlTCSETS2 = fs.IOW('T', 0x2B, uint(unsafe.Sizeof(termios2{})))
+)
+
+// driverUART implements periph.Driver.
+type driverUART struct {
+	ports []string
+}
+
+func (d *driverUART) String() string {
+	return "sysfs-uart"
+}
+
+func (d *driverUART) Prerequisites() []string {
+	return nil
+}
+
+func (d *driverUART) After() []string {
+	return nil
+}
+
+func (d *driverUART) Init() (bool, error) {
+	var items []string
+	for _, prefix := range []string{"/dev/ttyAMA", "/dev/ttyS", "/dev/ttyUSB", "/dev/ttyACM"} {
+		m, err := filepath.Glob(prefix + "*")
+		if err != nil {
+			return true, err
+		}
+		items = append(items, m...)
+	}
+	if len(items) == 0 {
+		return false, errors.New("no UART port found")
+	}
+	// Make sure they are registered in order.
+	sort.Strings(items)
+	numbers := map[int]bool{}
+	for _, item := range items {
+		var aliases []string
+		number := uartPortNumber(item)
+		if number != -1 {
+			if numbers[number] {
+				// On Raspberry Pis, both ttyAMA0 and ttyS0 exist; the PL011 wins.
+				number = -1
+			} else {
+				numbers[number] = true
+				aliases = []string{fmt.Sprintf("UART%d", number)}
+			}
+		}
+		d.ports = append(d.ports, item)
+		if err := uartreg.Register(item, aliases, number, openerUART{item, number}.Open); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+type openerUART struct {
+	path   string
+	number int
+}
+
+func (o openerUART) Open() (uart.PortCloser, error) {
+	u, err := newUART(o.path, o.number)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func init() {
+	if isLinux {
+		periph.MustRegister(&drvUART)
+	}
+}
+
+var drvUART driverUART
+
+var _ uart.PortCloser = &UART{}
+var _ uart.Pins = &UART{}
+var _ uart.Conn = &uartConn{}
+var _ uart.Pins = &uartConn{}
+var _ fmt.Stringer = &UART{}
@@ -0,0 +1,319 @@
+// Copyright 2018 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package sysfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/meandrewdev/periph/conn/gpio"
+	"github.com/meandrewdev/periph/conn/physic"
+	"github.com/meandrewdev/periph/conn/uart"
+)
+
+func TestNewUART(t *testing.T) {
+	if u, err := NewUART("/dev/ttyDoesNotExist"); u != nil || err == nil {
+		t.Fatal("invalid port")
+	}
+}
+
+func TestUARTPortNumber(t *testing.T) {
+	data := []struct {
+		path     string
+		expected int
+	}{
+		{"/dev/ttyAMA0", 0},
+		{"/dev/ttyS2", 2},
+		{"/dev/ttyUSB0", -1},
+		{"/dev/ttySfoo", -1},
+	}
+	for i, line := range data {
+		if n := uartPortNumber(line.path); n != line.expected {
+			t.Fatalf("#%d: %d != %d", i, n, line.expected)
+		}
+	}
+}
+
+func TestUART_pty(t *testing.T) {
+	u, m := newFakeUART(t)
+	defer m.Close()
+	defer u.Close()
+	if s := u.String(); s == "" {
+		t.Fatal(s)
+	}
+	if err := u.LimitSpeed(9600 * physic.Hertz); err != nil {
+		t.Fatal(err)
+	}
+	c, err := u.Connect(115200*physic.Hertz, uart.One, uart.Odd, uart.RTSCTS, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.Connect(115200*physic.Hertz, uart.One, uart.NoParity, uart.NoFlow, 8); err == nil {
+		t.Fatal("second Connect() must fail")
+	}
+	tio, err := u.conn.getTermios()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tio.ospeed != 9600 || tio.cflag&termBOTHER == 0 {
+		t.Fatal(tio.ospeed, tio.cflag)
+	}
+	// The character size and parity can't be verified, as pseudo-terminals
+	// always force CS8 without parity.
+	if tio.cflag&termCRTSCTS == 0 || tio.lflag&termICANON != 0 {
+		t.Fatalf("0%o 0%o", tio.cflag, tio.lflag)
+	}
+	if err := c.SetFlow(uart.MakeXOnXOffFlow('a', 'b')); err != nil {
+		t.Fatal(err)
+	}
+	if tio, err = u.conn.getTermios(); err != nil {
+		t.Fatal(err)
+	}
+	if tio.cflag&termCRTSCTS != 0 || tio.iflag&termIXON == 0 || tio.cc[termVSTART] != 'a' || tio.cc[termVSTOP] != 'b' {
+		t.Fatalf("0%o 0%o %v", tio.cflag, tio.iflag, tio.cc)
+	}
+	if err := c.SetFlow(uart.Flow(1)); err == nil {
+		t.Fatal("invalid flow")
+	}
+
+	// Write to the port, read from the other end.
+	if _, err := c.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	var b [5]byte
+	if _, err := io.ReadFull(m, b[:]); err != nil || string(b[:]) != "hello" {
+		t.Fatal(string(b[:]), err)
+	}
+	// Write to the other end, read from the port.
+	if _, err := m.Write([]byte("world")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Tx(nil, b[:]); err != nil || string(b[:]) != "world" {
+		t.Fatal(string(b[:]), err)
+	}
+
+	// Timeout.
+	if err := c.SetReadTimeout(-1); err == nil {
+		t.Fatal("invalid timeout")
+	}
+	if err := c.SetReadTimeout(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	n, err := c.Read(b[:])
+	if n != 0 || err == nil {
+		t.Fatal(n, err)
+	}
+	if e, ok := err.(interface{ Timeout() bool }); !ok || !e.Timeout() {
+		t.Fatal(err)
+	}
+
+	// Break.
+	if err := c.Break(0); err == nil {
+		t.Fatal("invalid duration")
+	}
+	if err := c.Break(time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetModem(uart.ModemCTS, 0); err == nil {
+		t.Fatal("CTS is an input")
+	}
+	if d := c.Duplex(); d.String() != "Full" {
+		t.Fatal(d)
+	}
+}
+
+func TestUART_Connect_Err(t *testing.T) {
+	u, m := newFakeUART(t)
+	defer m.Close()
+	defer u.Close()
+	if _, err := u.Connect(physic.Hertz, uart.One, uart.NoParity, uart.NoFlow, 8); err == nil {
+		t.Fatal("speed too low")
+	}
+	if _, err := u.Connect(2*physic.GigaHertz, uart.One, uart.NoParity, uart.NoFlow, 8); err == nil {
+		t.Fatal("speed too high")
+	}
+	if _, err := u.Connect(9600*physic.Hertz, uart.One, uart.NoParity, uart.NoFlow, 9); err == nil {
+		t.Fatal("invalid bits")
+	}
+	if _, err := u.Connect(9600*physic.Hertz, uart.OneHalf, uart.NoParity, uart.NoFlow, 8); err == nil {
+		t.Fatal("unsupported stop bit")
+	}
+	if _, err := u.Connect(9600*physic.Hertz, uart.One, uart.Parity('X'), uart.NoFlow, 8); err == nil {
+		t.Fatal("invalid parity")
+	}
+	if _, err := u.Connect(9600*physic.Hertz, uart.One, uart.NoParity, uart.Flow(1), 8); err == nil {
+		t.Fatal("invalid flow")
+	}
+	if err := u.LimitSpeed(0); err == nil {
+		t.Fatal("invalid speed")
+	}
+}
+
+func TestTermios2_setFormat(t *testing.T) {
+	data := []struct {
+		stop   uart.Stop
+		parity uart.Parity
+		bits   int
+		cflag  uint32
+	}{
+		{uart.One, uart.NoParity, 8, 0060},
+		{uart.Two, uart.Odd, 7, 0040 | termCSTOPB | termPARENB | termPARODD},
+		{uart.One, uart.Even, 6, 0020 | termPARENB},
+		{uart.One, uart.Mark, 5, termPARENB | termPARODD | termCMSPAR},
+		{uart.One, uart.Space, 8, 0060 | termPARENB | termCMSPAR},
+	}
+	for i, line := range data {
+		tio := termios2{cflag: termCSIZE | termCSTOPB | termPARENB | termPARODD | termCMSPAR}
+		if err := tio.setFormat(line.stop, line.parity, line.bits); err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if tio.cflag != line.cflag {
+			t.Fatalf("#%d: 0%o != 0%o", i, tio.cflag, line.cflag)
+		}
+		if (tio.iflag&termINPCK != 0) != (line.parity != uart.NoParity) {
+			t.Fatalf("#%d: 0%o", i, tio.iflag)
+		}
+	}
+}
+
+func TestUART_Modem(t *testing.T) {
+	f := &fakeModemFile{}
+	u := UART{uartConn{name: "fake", f: f, portNumber: -1}}
+	f.v = tiocmCTS | tiocmDSR
+	if m, err := u.conn.Modem(); err != nil || m != uart.ModemCTS|uart.ModemDSR {
+		t.Fatal(m, err)
+	}
+	f.v = tiocmRTS
+	if err := u.conn.SetModem(uart.ModemDTR|uart.ModemRTS, uart.ModemDTR); err != nil {
+		t.Fatal(err)
+	}
+	if f.v != tiocmDTR {
+		t.Fatal(f.v)
+	}
+	f.err = errors.New("foo")
+	if _, err := u.conn.Modem(); err == nil || err.Error() != "sysfs-uart: foo" {
+		t.Fatal(err)
+	}
+	if err := u.conn.SetModem(uart.ModemDTR, 0); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestUART_Pins(t *testing.T) {
+	u := UART{uartConn{name: "fake", f: &fakeModemFile{}, portNumber: 42}}
+	if p := u.RX(); p != gpio.INVALID {
+		t.Fatal(p)
+	}
+	if p := u.TX(); p != gpio.INVALID {
+		t.Fatal(p)
+	}
+	if p := u.RTS(); p != gpio.INVALID {
+		t.Fatal(p)
+	}
+	if p := u.CTS(); p != gpio.INVALID {
+		t.Fatal(p)
+	}
+}
+
+func TestUARTDriver(t *testing.T) {
+	if len((&driverUART{}).Prerequisites()) != 0 {
+		t.Fatal("unexpected UART prerequisites")
+	}
+}
+
+//
+
+// newFakeUART returns an UART connected to a pseudo-terminal and the master
+// side of the pseudo-terminal.
+func newFakeUART(t *testing.T) (*UART, *os.File) {
+	if !isLinux {
+		t.Skip("pseudo-terminals are only supported on linux")
+	}
+	m, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	var unlock int32
+	if err := (&fakeFile{m}).Ioctl(ioctlTIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); err != nil {
+		m.Close()
+		t.Fatal(err)
+	}
+	var ptn uint32
+	if err := (&fakeFile{m}).Ioctl(ioctlTIOCGPTN, uintptr(unsafe.Pointer(&ptn))); err != nil {
+		m.Close()
+		t.Fatal(err)
+	}
+	uartOpen = func(path string) (uartFile, error) {
+		f, err := os.OpenFile(path, uartFlags, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &fakeFile{f}, nil
+	}
+	defer reset()
+	u, err := newUART("/dev/pts/"+strconv.Itoa(int(ptn)), -1)
+	if err != nil {
+		m.Close()
+		t.Fatal(err)
+	}
+	return u, m
+}
+
+const (
+	ioctlTIOCGPTN   = 0x80045430
+	ioctlTIOCSPTLCK = 0x40045431
+)
+
+// fakeFile adds Ioctl() to an *os.File without going through fs.Open().
+type fakeFile struct {
+	*os.File
+}
+
+func (f *fakeFile) Ioctl(op uint, data uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(op), data); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// fakeModemFile emulates the modem lines ioctls.
+type fakeModemFile struct {
+	bytes.Buffer
+	v   uint32
+	err error
+}
+
+func (f *fakeModemFile) Close() error {
+	return nil
+}
+
+func (f *fakeModemFile) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (f *fakeModemFile) Ioctl(op uint, data uintptr) error {
+	if f.err != nil {
+		return f.err
+	}
+	p := (*uint32)(ioctlArg(data))
+	switch op {
+	case ioctlTIOCMGET:
+		*p = f.v
+	case ioctlTIOCMBIS:
+		f.v |= *p
+	case ioctlTIOCMBIC:
+		f.v &^= *p
+	default:
+		return errors.New("unexpected ioctl")
+	}
+	return nil
+}